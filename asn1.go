@@ -5,7 +5,10 @@ asn1.go handles ITU-T X.690 DER encoding for various types.
 */
 
 import (
+	"math"
 	"math/big"
+	"sort"
+	"strconv"
 )
 
 // ASN.1 tag constants
@@ -14,7 +17,9 @@ const (
 	tagInteger         = 2
 	tagBitString       = 3
 	tagOctetString     = 4
+	tagNull            = 5
 	tagOID             = 6
+	tagReal            = 9
 	tagEnum            = 10
 	tagUTF8String      = 12
 	tagSequence        = 16
@@ -25,6 +30,8 @@ const (
 	tagUTCTime         = 23
 	tagGeneralizedTime = 24
 	tagGeneralString   = 27
+	tagUniversalString = 28
+	tagBMPString       = 30
 )
 
 // ASN.1 class constants
@@ -43,12 +50,25 @@ var ClassNames = map[int]string{
 }
 
 var TagNames = map[int]string{
-	tagBoolean:     "BOOLEAN",
-	tagInteger:     "INTEGER",
-	tagOctetString: "OCTET STRING",
-	tagEnum:        "ENUM",
-	tagSequence:    "SEQUENCE",
-	tagSet:         "SET",
+	tagBoolean:         "BOOLEAN",
+	tagInteger:         "INTEGER",
+	tagBitString:       "BIT STRING",
+	tagOctetString:     "OCTET STRING",
+	tagNull:            "NULL",
+	tagOID:             "OBJECT IDENTIFIER",
+	tagReal:            "REAL",
+	tagEnum:            "ENUM",
+	tagUTF8String:      "UTF8String",
+	tagSequence:        "SEQUENCE",
+	tagSet:             "SET",
+	tagPrintableString: "PrintableString",
+	tagT61String:       "TeletexString",
+	tagIA5String:       "IA5String",
+	tagUTCTime:         "UTCTime",
+	tagGeneralizedTime: "GeneralizedTime",
+	tagGeneralString:   "GeneralString",
+	tagUniversalString: "UniversalString",
+	tagBMPString:       "BMPString",
 }
 
 var CompoundNames = map[bool]string{
@@ -56,6 +76,14 @@ var CompoundNames = map[bool]string{
 	false: "NOT COMPOUND",
 }
 
+/*
+Null implements the ASN.1 NULL type per [ITU-T Rec. X.680]. Instances
+of this type bear no value and are encoded with zero (0) content octets.
+
+[ITU-T Rec. X.680]: https://www.itu.int/rec/T-REC-X.680
+*/
+type Null struct{}
+
 // TagAndLength represents a parsed DER tag/length header.
 type TagAndLength struct {
 	Class      int
@@ -72,17 +100,54 @@ DERPacket encapsulates an [ITU-T Rec. X.690] DER-encoded byte slice and an offse
 type DERPacket struct {
 	data   []byte
 	offset int
+	mode   DERMode
 }
 
+/*
+DERMode describes the level of scrutiny applied by a *[DERPacket] when
+reading encoded elements.
+*/
+type DERMode uint8
+
+const (
+	// DERLenient, the default, verifies only the structural
+	// integrity of elements being read.
+	DERLenient DERMode = iota
+
+	// DERStrict additionally rejects encodings which, while
+	// structurally sound, are not canonical per ITU-T Rec.
+	// X.690, e.g.: non-minimal lengths, BOOLEAN TRUE values
+	// other than 0xFF, INTEGERs bearing redundant leading
+	// octets, BIT STRINGs with non-zero unused bits, unsorted
+	// SET OF elements and non-canonical time strings. Such
+	// violations are reported as *[DERCanonicalError].
+	DERStrict
+)
+
 /*
 DER returns an instance of *[DERPacket] alongside an error.
+
+A [DERMode] may be included among the variadic input to govern the
+validation performed when reading from the return instance.
 */
 func (r X690) DER(x ...any) (*DERPacket, error) {
 	var (
-		der *DERPacket
-		err error
+		der  *DERPacket
+		err  error
+		mode DERMode
 	)
 
+	// Extract any DERMode from the input.
+	var args []any
+	for _, arg := range x {
+		if m, ok := arg.(DERMode); ok {
+			mode = m
+		} else {
+			args = append(args, arg)
+		}
+	}
+	x = args
+
 	if len(x) > 0 {
 		switch tv := x[0].(type) {
 		case []byte:
@@ -103,10 +168,30 @@ func (r X690) DER(x ...any) (*DERPacket, error) {
 	} else {
 		der = newDERPacket([]byte{})
 	}
+	der.mode = mode
 
 	return der, err
 }
 
+/*
+DecodeDER returns an instance of *[DERPacket] for reading the encoded
+elements within data alongside an error. If the optional mode is
+[DERStrict], the entirety of data is first subjected to the checks
+performed by [DERPacket.Validate].
+*/
+func (r X690) DecodeDER(data []byte, mode ...DERMode) (der *DERPacket, err error) {
+	der = newDERPacket(data)
+	if len(mode) > 0 {
+		der.mode = mode[0]
+	}
+
+	if der.mode == DERStrict {
+		err = der.Validate()
+	}
+
+	return
+}
+
 /*
 newDERPacket returns an empty *[DERPacket] for read or write operations.
 
@@ -145,6 +230,24 @@ within the receiver instance.
 */
 func (r DERPacket) Offset() int { return r.offset }
 
+/*
+Mode returns the [DERMode] in force for the receiver instance.
+*/
+func (r DERPacket) Mode() DERMode { return r.mode }
+
+/*
+SetMode sets the [DERMode] in force for the receiver instance.
+*/
+func (r *DERPacket) SetMode(mode DERMode) { r.mode = mode }
+
+/*
+sub returns a new *[DERPacket] for reading data, inheriting the
+[DERMode] of the receiver instance.
+*/
+func (r DERPacket) sub(data []byte) *DERPacket {
+	return &DERPacket{data: data, mode: r.mode}
+}
+
 /*
 SetOffset replaces the current offset position index of the underlying
 value within the receiver instance with a user-supplied value.
@@ -207,9 +310,12 @@ func (r *DERPacket) TagAndLength() (TagAndLength, error) {
 
 	// If the tag field is 0x1f, we have a long-form tag.
 	if tal.Tag == 0x1f {
+		leading := r.offset < len(r.data) && r.data[r.offset] == 0x80
 		longTag, err := r.readBase128Int()
 		if err != nil {
 			return tal, err
+		} else if r.mode == DERStrict && (leading || longTag < 0x1f) {
+			return tal, derNonMinimalTagErr
 		}
 		tal.Tag = longTag
 	}
@@ -228,16 +334,28 @@ func (r *DERPacket) TagAndLength() (TagAndLength, error) {
 		// Long form: lower 7 bits indicate the number of subsequent bytes.
 		numBytes := int(b & 0x7f)
 		if numBytes == 0 {
-			return tal, errorTxt("indefinite lengths are not permitted in DER")
+			return tal, derIndefiniteLengthErr
+		} else if numBytes > strconv.IntSize/8 {
+			return tal, derLengthErr
+		} else if r.mode == DERStrict && r.offset < len(r.data) && r.data[r.offset] == 0 {
+			return tal, derNonMinimalLengthErr
 		}
 		length := 0
 		for i := 0; i < numBytes; i++ {
 			if r.offset >= len(r.data) {
 				return tal, errorTxt("truncated length at offset " + itoa(r.offset))
 			}
+			if length > math.MaxInt>>8 {
+				// Hostile input must never yield
+				// a negative (overflowed) length.
+				return tal, derLengthErr
+			}
 			length = (length << 8) | int(r.data[r.offset])
 			r.offset++
 		}
+		if r.mode == DERStrict && length < 0x80 {
+			return tal, derNonMinimalLengthErr
+		}
 		tal.Length = length
 	}
 
@@ -308,13 +426,29 @@ func (r *DERPacket) WriteTagAndLength(class int, isCompound bool, tag, length in
 Write returns an int alongside an error following an attempt to write
 val into the receiver instance.
 
-val may be int, int32, int64, *[big.Int], [Integer], bool, [Boolean]
-or [OctetString].
+val may be any of the following:
+
+  - int, int32, int64, *[big.Int] or [Integer] (INTEGER)
+  - bool or [Boolean] (BOOLEAN)
+  - [Enumerated] (ENUMERATED)
+  - [OctetString], [LDAPString], string or []byte (OCTET STRING)
+  - [NumericOID] (OBJECT IDENTIFIER)
+  - [BitString] (BIT STRING)
+  - [Null] or nil (NULL)
+  - float32 or float64 (REAL)
+  - [UTF8String], [PrintableString], [IA5String], [TeletexString],
+    [BMPString] or [UniversalString] (character strings)
+  - [GeneralizedTime] or [UTCTime] (time types)
+
+All values are written in their canonical DER form, e.g.: BIT STRING
+unused bits are zeroed, and time values are expressed in UTC with any
+redundant fractional digits removed. See also [DERPacket.WriteSequenceOf]
+and [DERPacket.WriteSetOf] for the writing of collections.
 
 b indicates the number of bytes written.
 */
 func (r *DERPacket) Write(val any) (b int, err error) {
-	switch val.(type) {
+	switch tv := val.(type) {
 	case bool, Boolean:
 		b, err = r.writeBoolean(val)
 	case int, int32, int64, *big.Int, Integer:
@@ -327,6 +461,32 @@ func (r *DERPacket) Write(val any) (b int, err error) {
 		b, err = r.writeOctetString(val)
 	case LDAPString:
 		b, err = r.writeOctetString(OctetString(val.(LDAPString)))
+	case NumericOID:
+		b, err = derWriteNumericOID(r, tv)
+	case BitString:
+		b, err = derWriteBitString(r, tv)
+	case Null, nil:
+		b = derWritePrimitive(r, tagNull, nil)
+	case float32:
+		b = derWriteReal(r, float64(tv))
+	case float64:
+		b = derWriteReal(r, tv)
+	case UTF8String:
+		b, err = derWriteUTF8String(r, tv)
+	case PrintableString:
+		b, err = derWritePrintableString(r, tv)
+	case IA5String:
+		b, err = derWriteIA5String(r, tv)
+	case TeletexString:
+		b, err = derWriteTeletexString(r, tv)
+	case BMPString:
+		b, err = derWriteBMPString(r, tv)
+	case UniversalString:
+		b, err = derWriteUniversalString(r, tv)
+	case GeneralizedTime:
+		b = derWriteGeneralizedTime(r, tv)
+	case UTCTime:
+		b, err = derWriteUTCTime(r, tv)
 	default:
 		err = errorBadType("DER write")
 	}
@@ -433,7 +593,9 @@ func (r *DERPacket) writeInteger(i any) (b int, err error) {
 }
 
 /*
-Read returns an error following an attempt to read into primitive x. x must be a pointer.
+Read returns an error following an attempt to read into primitive x. x must be a pointer
+to any of the types supported by [DERPacket.Write], excluding the Go primitives other
+than float64. See also [DERPacket.ReadSequenceOf] and [DERPacket.ReadSetOf].
 
 z provides variadic input. Currently, this is only used for map[Enumerated]string
 instances and only when x is *[Enumerated].
@@ -475,6 +637,33 @@ func (r *DERPacket) Read(x any, z ...any) (err error) {
 		} else {
 			err = errorBadType("Enumerated map")
 		}
+	case *NumericOID:
+		err = derReadNumericOID(tv, r, tal)
+	case *BitString:
+		err = derReadBitString(tv, r, tal)
+	case *Null:
+		_, err = derReadPrimitive(r, tal, tagNull)
+		if err == nil && tal.Length != 0 {
+			err = errorTxt("NULL must have zero (0) content octets")
+		}
+	case *float64:
+		err = derReadReal(tv, r, tal)
+	case *UTF8String:
+		err = derReadUTF8String(tv, r, tal)
+	case *PrintableString:
+		err = derReadPrintableString(tv, r, tal)
+	case *IA5String:
+		err = derReadIA5String(tv, r, tal)
+	case *TeletexString:
+		err = derReadTeletexString(tv, r, tal)
+	case *BMPString:
+		err = derReadBMPString(tv, r, tal)
+	case *UniversalString:
+		err = derReadUniversalString(tv, r, tal)
+	case *GeneralizedTime:
+		err = derReadGeneralizedTime(tv, r, tal)
+	case *UTCTime:
+		err = derReadUTCTime(tv, r, tal)
 	default:
 		err = errorBadType("DERPacket read")
 	}
@@ -494,6 +683,410 @@ func (r *DERPacket) readOctetString(x *OctetString, tal TagAndLength) (err error
 	return derReadOctetString(x, r, tal)
 }
 
+/*
+derWritePrimitive writes the DER header for a primitive UNIVERSAL element
+bearing tag, followed by content, into der. The number of bytes written
+is returned.
+*/
+func derWritePrimitive(der *DERPacket, tag int, content []byte) int {
+	written := der.WriteTagAndLength(classUniversal, false, tag, len(content))
+	der.data = append(der.data, content...)
+	der.offset = len(der.data)
+
+	return written + len(content)
+}
+
+/*
+derReadPrimitive returns the content octets of the primitive element
+described by tal alongside an error following verification of the tag,
+the primitive form and the available data. The offset of der is moved
+beyond said content octets.
+*/
+func derReadPrimitive(der *DERPacket, tal TagAndLength, tag int) (content []byte, err error) {
+	if tal.Tag != tag {
+		err = errorTxt("expected " + TagNames[tag] + " (tag " + itoa(tag) +
+			") but got tag " + itoa(tal.Tag))
+	} else if tal.IsCompound && der.mode == DERStrict && derIsStringTag(tag) {
+		err = derConstructedStringErr
+	} else if tal.IsCompound {
+		err = errorTxt("constructed " + TagNames[tag] + " not permitted in DER")
+	} else if der.offset+tal.Length > len(der.data) {
+		err = errorTxt("insufficient data for " + TagNames[tag])
+	} else {
+		content = der.data[der.offset : der.offset+tal.Length]
+		der.offset += tal.Length
+	}
+
+	return
+}
+
+/*
+derWriteReal writes f as a DER-encoded REAL per § 11.3 of ITU-T Rec.
+X.690: zero is encoded with no content octets, special values use their
+single-octet forms and all others use base 2 with an odd mantissa and a
+scaling factor of zero (0).
+*/
+func derWriteReal(der *DERPacket, f float64) int {
+	var content []byte
+
+	switch {
+	case math.IsNaN(f):
+		content = []byte{0x42}
+	case math.IsInf(f, 1):
+		content = []byte{0x40}
+	case math.IsInf(f, -1):
+		content = []byte{0x41}
+	case f == 0 && math.Signbit(f):
+		content = []byte{0x43}
+	case f == 0:
+		// no content octets
+	default:
+		first := byte(0x80)
+		if f < 0 {
+			first |= 0x40
+			f = -f
+		}
+
+		frac, exp := math.Frexp(f)
+		mantissa := uint64(math.Ldexp(frac, 53))
+		exp -= 53
+		for mantissa&1 == 0 {
+			mantissa >>= 1
+			exp++
+		}
+
+		// minimal two's complement exponent
+		var expBytes []byte
+		for e := exp; ; e >>= 8 {
+			expBytes = append([]byte{byte(e)}, expBytes...)
+			if -128 <= e && e < 128 {
+				break
+			}
+		}
+
+		first |= byte(len(expBytes) - 1)
+		content = append([]byte{first}, expBytes...)
+		content = append(content, newBigInt(0).SetUint64(mantissa).Bytes()...)
+	}
+
+	return derWritePrimitive(der, tagReal, content)
+}
+
+/*
+derReadReal returns an error following an attempt to read a REAL
+into x. Binary (base 2, 8 or 16), decimal and special forms are
+supported.
+*/
+func derReadReal(x *float64, der *DERPacket, tal TagAndLength) (err error) {
+	var content []byte
+	if content, err = derReadPrimitive(der, tal, tagReal); err != nil {
+		return
+	} else if len(content) == 0 {
+		*x = 0
+		return
+	}
+
+	first := content[0]
+	switch {
+	case first&0x80 != 0:
+		*x, err = derDecodeBinaryReal(content)
+	case first&0x40 != 0:
+		if len(content) != 1 {
+			err = errorTxt("invalid REAL special value length")
+			return
+		}
+		switch first {
+		case 0x40:
+			*x = math.Inf(1)
+		case 0x41:
+			*x = math.Inf(-1)
+		case 0x42:
+			*x = math.NaN()
+		case 0x43:
+			*x = math.Copysign(0, -1)
+		default:
+			err = errorTxt("invalid REAL special value " + fmtUint(uint64(first), 16))
+		}
+	default:
+		// ISO 6093 decimal form (NR1, NR2 or NR3)
+		if nr := first & 0x3f; nr < 1 || nr > 3 {
+			err = errorTxt("invalid REAL decimal form " + itoa(int(nr)))
+		} else {
+			*x, err = pfloat(repAll(trimS(string(content[1:])), ",", "."), 64)
+		}
+	}
+
+	return
+}
+
+func derDecodeBinaryReal(content []byte) (f float64, err error) {
+	first := content[0]
+	content = content[1:]
+
+	var shift int
+	switch (first >> 4) & 0x3 {
+	case 0:
+		shift = 1
+	case 1:
+		shift = 3
+	case 2:
+		shift = 4
+	default:
+		err = errorTxt("invalid REAL base")
+		return
+	}
+
+	elen := int(first&0x3) + 1
+	if elen == 4 {
+		if len(content) == 0 {
+			err = errorTxt("truncated REAL exponent length")
+			return
+		}
+		elen = int(content[0])
+		content = content[1:]
+	}
+	if elen == 0 || elen > 4 || len(content) <= elen {
+		err = errorTxt("invalid REAL exponent or mantissa length")
+		return
+	}
+
+	exp := int(int8(content[0]))
+	for _, b := range content[1:elen] {
+		exp = exp<<8 | int(b)
+	}
+
+	mant := content[elen:]
+	if len(mant) > 8 {
+		err = errorTxt("REAL mantissa exceeds 64 bits")
+		return
+	}
+	var m uint64
+	for _, b := range mant {
+		m = m<<8 | uint64(b)
+	}
+
+	f = math.Ldexp(float64(m), exp*shift+int((first>>2)&0x3))
+	if first&0x40 != 0 {
+		f = -f
+	}
+
+	return
+}
+
+/*
+WriteSequenceOf returns an int alongside an error following an attempt to
+write a DER-encoded SEQUENCE OF, comprised of each element in elems in the
+order provided, into the receiver instance. Each element may be of any
+type supported by [DERPacket.Write].
+*/
+func (r *DERPacket) WriteSequenceOf(elems ...any) (int, error) {
+	return r.writeCollectionOf(tagSequence, elems)
+}
+
+/*
+WriteSetOf returns an int alongside an error following an attempt to
+write a DER-encoded SET OF into the receiver instance. As mandated by
+§ 11.6 of ITU-T Rec. X.690, the encodings of the elements in elems are
+placed in ascending order.
+*/
+func (r *DERPacket) WriteSetOf(elems ...any) (int, error) {
+	return r.writeCollectionOf(tagSet, elems)
+}
+
+func (r *DERPacket) writeCollectionOf(tag int, elems []any) (n int, err error) {
+	encs := make([][]byte, len(elems))
+	for i, elem := range elems {
+		sub := newDERPacket([]byte{})
+		if _, err = sub.Write(elem); err != nil {
+			return
+		}
+		encs[i] = sub.data
+	}
+
+	if tag == tagSet {
+		sort.SliceStable(encs, func(i, j int) bool {
+			return string(encs[i]) < string(encs[j])
+		})
+	}
+
+	n, err = r.WriteConstructed(classUniversal, tag, func(sub *DERPacket) error {
+		for _, enc := range encs {
+			sub.data = append(sub.data, enc...)
+		}
+		return nil
+	})
+
+	if err == nil {
+		r.offset = 0
+	}
+
+	return
+}
+
+/*
+ReadSequenceOf returns an error following an attempt to read a SEQUENCE
+OF from the receiver instance. The callback is executed once per element,
+and is expected to consume it, e.g.: by way of [DERPacket.Read].
+*/
+func (r *DERPacket) ReadSequenceOf(callback func(sub *DERPacket) error) error {
+	return r.readCollectionOf(tagSequence, callback)
+}
+
+/*
+ReadSetOf returns an error following an attempt to read a SET OF from
+the receiver instance. The callback is executed once per element, and
+is expected to consume it, e.g.: by way of [DERPacket.Read].
+*/
+func (r *DERPacket) ReadSetOf(callback func(sub *DERPacket) error) error {
+	return r.readCollectionOf(tagSet, callback)
+}
+
+func (r *DERPacket) readCollectionOf(tag int, callback func(sub *DERPacket) error) error {
+	return r.ReadConstructed(classUniversal, tag, func(sub *DERPacket) (err error) {
+		var prev string
+		for sub.HasMoreData() && err == nil {
+			start := sub.offset
+			if err = callback(sub); err == nil && sub.offset == start {
+				err = errorTxt(TagNames[tag] + " OF element not consumed")
+			} else if err == nil && tag == tagSet && sub.mode == DERStrict {
+				elem := string(sub.data[start:sub.offset])
+				if start > 0 && elem < prev {
+					err = derSetOfOrderErr
+				}
+				prev = elem
+			}
+		}
+		return
+	})
+}
+
+/*
+Validate returns an error following an examination of all elements within
+the receiver instance, at any depth, in the context of the canonical rules
+enforced by [DERStrict]. The receiver offset is not altered.
+
+The content octets of elements of a class other than UNIVERSAL are only
+examined if they are constructed. UNIVERSAL SET elements are examined for
+ordering per § 11.6 (SET OF) or § 10.3 (SET) of ITU-T Rec. X.690.
+*/
+func (r DERPacket) Validate() error {
+	return derValidate(&DERPacket{data: r.data, mode: DERStrict})
+}
+
+func derValidate(der *DERPacket) (err error) {
+	for der.HasMoreData() && err == nil {
+		start := der.offset
+		var tal TagAndLength
+		if tal, err = der.TagAndLength(); err != nil {
+			break
+		}
+
+		end := der.offset + tal.Length
+		if end > len(der.data) {
+			err = errorTxt("truncated element at offset " + itoa(start))
+			break
+		}
+
+		universal := tal.Class == classUniversal
+		switch {
+		case tal.IsCompound && universal && derIsStringTag(tal.Tag):
+			err = derConstructedStringErr
+		case tal.IsCompound:
+			sub := der.sub(der.data[der.offset:end])
+			if universal && tal.Tag == tagSet {
+				err = derValidateSet(sub)
+			}
+			if err == nil {
+				err = derValidate(sub)
+			}
+		case universal:
+			err = derValidatePrimitive(der, tal)
+		}
+		der.offset = end
+	}
+
+	return
+}
+
+/*
+derValidateSet returns an error if the elements within der are neither in
+ascending order of their encodings (SET OF) nor of their tags (SET).
+*/
+func derValidateSet(der *DERPacket) (err error) {
+	var (
+		prevEnc            string
+		prevTag            TagAndLength
+		encOrder, tagOrder bool = true, true
+	)
+
+	for i := 0; der.HasMoreData(); i++ {
+		start := der.offset
+		var tal TagAndLength
+		if tal, err = der.TagAndLength(); err != nil {
+			return
+		}
+		der.offset += tal.Length
+		if der.offset > len(der.data) {
+			break // reported by derValidate
+		}
+
+		enc := string(der.data[start:der.offset])
+		if i > 0 {
+			encOrder = encOrder && prevEnc <= enc
+			tagOrder = tagOrder && (prevTag.Class < tal.Class ||
+				(prevTag.Class == tal.Class && prevTag.Tag < tal.Tag))
+		}
+		prevEnc, prevTag = enc, tal
+	}
+
+	if !encOrder && !tagOrder {
+		err = derSetOfOrderErr
+	}
+
+	return
+}
+
+/*
+derValidatePrimitive returns an error following an examination of the
+content octets of the primitive UNIVERSAL element described by tal.
+*/
+func derValidatePrimitive(der *DERPacket, tal TagAndLength) (err error) {
+	switch tal.Tag {
+	case tagBoolean:
+		err = derReadBoolean(new(Boolean), der, tal)
+	case tagInteger:
+		err = derReadInteger(new(Integer), der, tal)
+	case tagEnum:
+		if der.offset+tal.Length <= len(der.data) {
+			err = derCheckInteger(der.data[der.offset : der.offset+tal.Length])
+		}
+	case tagBitString:
+		err = derReadBitString(new(BitString), der, tal)
+	case tagGeneralizedTime:
+		err = derReadGeneralizedTime(new(GeneralizedTime), der, tal)
+	case tagUTCTime:
+		err = derReadUTCTime(new(UTCTime), der, tal)
+	}
+
+	return
+}
+
+/*
+derIsStringTag returns a Boolean value indicative of whether tag denotes
+a UNIVERSAL string type, which DER requires be of primitive form.
+*/
+func derIsStringTag(tag int) bool {
+	switch tag {
+	case tagBitString, tagOctetString, tagUTF8String, tagPrintableString,
+		tagT61String, tagIA5String, tagUTCTime, tagGeneralizedTime,
+		tagGeneralString, tagUniversalString, tagBMPString,
+		18, 21, 25, 26: // Numeric, Videotex, Graphic and VisibleString
+		return true
+	}
+
+	return false
+}
+
 /*
 ReadConstructed returns an error following an attempt to read a constructed (compound)
 element from the callback-revealed *[DERPacket] into the receiver *[DERPacket].
@@ -529,10 +1122,7 @@ func (r *DERPacket) ReadConstructed(expectedClass int, expectedTag int, callback
 	}
 
 	// Create a temporary DERPacket for the sub-bytes.
-	subPacket := &DERPacket{
-		data:   r.data[start:end],
-		offset: 0,
-	}
+	subPacket := r.sub(r.data[start:end])
 
 	// Invoke the callback to process the sub-bytes.
 	if err = callback(subPacket); err != nil {
@@ -0,0 +1,986 @@
+package dirsyn
+
+/*
+asn1_marshal.go implements struct tag driven DER marshaling and
+unmarshaling by way of reflection.
+*/
+
+import (
+	"encoding/asn1"
+	"math/big"
+	"reflect"
+	"sort"
+	"time"
+)
+
+/*
+DERMarshaler is implemented by types which write their own DER encoding,
+e.g.: by way of [DERPacket.WriteConstructed], for use with [DERPacket.Marshal].
+
+MarshalDER must write exactly one (1) complete element into the input
+*[DERPacket] instance, returning the number of bytes written alongside
+an error.
+*/
+type DERMarshaler interface {
+	MarshalDER(*DERPacket) (int, error)
+}
+
+/*
+DERUnmarshaler is implemented by types which read their own DER encoding,
+e.g.: by way of [DERPacket.ReadConstructed], for use with [DERPacket.Unmarshal].
+
+UnmarshalDER is given a *[DERPacket] containing exactly one (1) complete
+element, which must be consumed in its entirety. Elements of implicitly
+tagged fields are presented as-is, bearing the tag of the field.
+*/
+type DERUnmarshaler interface {
+	UnmarshalDER(*DERPacket) error
+}
+
+/*
+derFieldParams contains the parsed contents of an "asn1" struct tag.
+*/
+type derFieldParams struct {
+	class    int     // class of tag, if tagged
+	tag      int     // -1 if untagged
+	strTag   int     // universal tag for string kinds
+	explicit bool    // explicit tagging
+	optional bool    // OPTIONAL
+	set      bool    // SET or SET OF
+	choice   bool    // CHOICE
+	dflt     *string // DEFAULT
+}
+
+func newDERFieldParams() derFieldParams {
+	return derFieldParams{
+		class:  classContextSpecific,
+		tag:    -1,
+		strTag: tagOctetString,
+	}
+}
+
+/*
+parseDERFieldParams returns an instance of derFieldParams alongside an
+error following an attempt to parse the contents of an "asn1" struct tag.
+Unrecognized keywords are ignored.
+*/
+func parseDERFieldParams(str string) (p derFieldParams, err error) {
+	p = newDERFieldParams()
+	if str == "" {
+		return
+	}
+
+	for _, part := range split(str, `,`) {
+		switch part = trimS(part); {
+		case hasPfx(part, `tag:`):
+			if p.tag, err = atoi(part[4:]); err != nil || p.tag < 0 {
+				err = errorTxt("invalid asn1 tag '" + part + "'")
+				return
+			}
+		case hasPfx(part, `default:`):
+			dflt := part[8:]
+			p.dflt = &dflt
+		case part == `explicit`:
+			p.explicit = true
+		case part == `optional`:
+			p.optional = true
+		case part == `set`:
+			p.set = true
+		case part == `choice`:
+			p.choice = true
+		case part == `application`:
+			p.class = classApplication
+		case part == `utf8`:
+			p.strTag = tagUTF8String
+		case part == `printable`:
+			p.strTag = tagPrintableString
+		case part == `ia5`:
+			p.strTag = tagIA5String
+		}
+	}
+
+	return
+}
+
+/*
+isExplicit returns a Boolean value indicative of whether the tagging of a
+field of type t is explicit. Tagged CHOICE and interface fields are always
+explicitly tagged per § 31.2.7 of ITU-T Rec. X.680.
+*/
+func (r derFieldParams) isExplicit(t reflect.Type) bool {
+	return r.explicit || r.choice || t.Kind() == reflect.Interface
+}
+
+/*
+elem returns the derFieldParams for elements of a SEQUENCE OF or SET OF,
+which inherit only the string type of the receiver.
+*/
+func (r derFieldParams) elem() derFieldParams {
+	p := newDERFieldParams()
+	p.strTag = r.strTag
+	return p
+}
+
+var (
+	derBigIntType        reflect.Type = typeOf((*big.Int)(nil))
+	derTimeType          reflect.Type = typeOf(time.Time{})
+	derASN1BitStringType reflect.Type = typeOf(asn1.BitString{})
+	derEnumeratedType    reflect.Type = typeOf(Enumerated(0))
+	derMarshalerType     reflect.Type = typeOf((*DERMarshaler)(nil)).Elem()
+	derUnmarshalerType   reflect.Type = typeOf((*DERUnmarshaler)(nil)).Elem()
+)
+
+/*
+derUniversalTypes maps the package types, and select standard library
+types, supported by [DERPacket.Write] to their UNIVERSAL tags.
+*/
+var derUniversalTypes = map[reflect.Type]int{
+	typeOf(Boolean{}):           tagBoolean,
+	typeOf(Integer{}):           tagInteger,
+	derBigIntType:               tagInteger,
+	derEnumeratedType:           tagEnum,
+	typeOf(OctetString{}):       tagOctetString,
+	typeOf(LDAPString{}):        tagOctetString,
+	typeOf(NumericOID{}):        tagOID,
+	typeOf(BitString{}):         tagBitString,
+	derASN1BitStringType:        tagBitString,
+	typeOf(Null{}):              tagNull,
+	typeOf(UTF8String(``)):      tagUTF8String,
+	typeOf(PrintableString(``)): tagPrintableString,
+	typeOf(IA5String(``)):       tagIA5String,
+	typeOf(TeletexString(``)):   tagT61String,
+	typeOf(BMPString{}):         tagBMPString,
+	typeOf(UniversalString(``)): tagUniversalString,
+	typeOf(GeneralizedTime{}):   tagGeneralizedTime,
+	typeOf(UTCTime{}):           tagUTCTime,
+	derTimeType:                 tagGeneralizedTime,
+}
+
+/*
+derNaturalTag returns the UNIVERSAL tag and compound state of elements of
+type t alongside an error.
+*/
+func derNaturalTag(t reflect.Type, p derFieldParams) (tag int, compound bool, err error) {
+	var ok bool
+	if tag, ok = derUniversalTypes[t]; ok {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		tag = tagBoolean
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		tag = tagInteger
+	case reflect.Float32, reflect.Float64:
+		tag = tagReal
+	case reflect.String:
+		tag = p.strTag
+	case reflect.Slice:
+		if tag = tagOctetString; t.Elem().Kind() != reflect.Uint8 {
+			tag, compound = tagSequence, true
+			if p.set {
+				tag = tagSet
+			}
+		}
+	case reflect.Struct:
+		tag, compound = tagSequence, true
+		if p.set {
+			tag = tagSet
+		}
+	case reflect.Ptr:
+		tag, compound, err = derNaturalTag(t.Elem(), p)
+	default:
+		err = errorTxt("no UNIVERSAL tag known for " + t.String())
+	}
+
+	return
+}
+
+/*
+derField describes a single struct field subject to DER marshaling.
+*/
+type derField struct {
+	name  string
+	value reflect.Value
+	p     derFieldParams
+}
+
+/*
+derFields returns the fields of struct v alongside an error. Anonymous
+struct fields bearing no "asn1" tag are flattened, thereby implementing
+"COMPONENTS OF". Unexported fields and those tagged `asn1:"-"` are skipped.
+*/
+func derFields(v reflect.Value) (fields []derField, err error) {
+	t := v.Type()
+	for i := 0; i < t.NumField() && err == nil; i++ {
+		f := t.Field(i)
+		tag, tagged := f.Tag.Lookup(`asn1`)
+		if !f.IsExported() || tag == `-` {
+			continue
+		}
+
+		if f.Anonymous && !tagged && f.Type.Kind() == reflect.Struct {
+			var sub []derField
+			if sub, err = derFields(v.Field(i)); err == nil {
+				fields = append(fields, sub...)
+			}
+			continue
+		}
+
+		var p derFieldParams
+		if p, err = parseDERFieldParams(tag); err == nil {
+			fields = append(fields, derField{
+				name:  t.Name() + `.` + f.Name,
+				value: v.Field(i),
+				p:     p,
+			})
+		}
+	}
+
+	return
+}
+
+/*
+Marshal returns an int alongside an error following an attempt to write
+x, which may be any type supported by [DERPacket.Write] as well as structs,
+slices and pointers thereof, into the receiver instance.
+
+Structs are written as SEQUENCE, and slices (other than []byte) as SEQUENCE
+OF. The encoding of each struct field may be altered by way of an "asn1"
+struct tag, which accepts the following comma-delimited keywords:
+
+  - tag:N: the field is tagged with context-specific tag N
+  - application: the tag is of the APPLICATION class
+  - explicit: the tag is explicit rather than implicit
+  - optional: the field is OPTIONAL, and is omitted if zero
+  - default:V: the field is omitted if equal to V
+  - set: the struct or slice is written as SET or SET OF
+  - choice: the field is a [Choice], encoded as its present alternative
+  - utf8, printable, ia5: strings are written as the respective type
+    rather than OCTET STRING
+
+Anonymous struct fields bearing no "asn1" tag are written as though their
+fields were those of the outer struct ("COMPONENTS OF"). Types which
+implement [DERMarshaler] write their own encoding.
+
+Per ITU-T Rec. X.690, SET components are written in the canonical order
+of their tags, SET OF elements are written in ascending order of their
+encodings, and values equal to their DEFAULT are omitted.
+*/
+func (r *DERPacket) Marshal(x any) (n int, err error) {
+	var enc []byte
+	if enc, err = derMarshalValue(valOf(x), newDERFieldParams()); err == nil {
+		r.data = append(r.data, enc...)
+		r.offset = 0
+		n = len(enc)
+	}
+
+	return
+}
+
+/*
+derMarshalValue returns the complete DER encoding of v, including any
+tagging described by p, alongside an error.
+*/
+func derMarshalValue(v reflect.Value, p derFieldParams) (enc []byte, err error) {
+	if !v.IsValid() {
+		err = errorBadType("DER marshal")
+		return
+	}
+
+	if enc, err = derMarshalUntagged(v, p); err == nil && p.tag >= 0 {
+		enc = derRetag(enc, p.class, p.tag, p.isExplicit(v.Type()))
+	}
+
+	return
+}
+
+func derMarshalUntagged(v reflect.Value, p derFieldParams) (enc []byte, err error) {
+	if v.Type().Implements(derMarshalerType) {
+		if v.Kind() == reflect.Ptr && v.IsNil() {
+			err = errorTxt("cannot DER encode nil " + v.Type().String())
+			return
+		}
+		sub := newDERPacket([]byte{})
+		if _, err = v.Interface().(DERMarshaler).MarshalDER(sub); err == nil {
+			enc = sub.data
+		}
+		return
+	} else if v.CanAddr() && v.Addr().Type().Implements(derMarshalerType) {
+		enc, err = derMarshalUntagged(v.Addr(), p)
+		return
+	}
+
+	if _, ok := derUniversalTypes[v.Type()]; ok {
+		enc, err = derWriteValue(derUniversalValue(v))
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			err = errorTxt("cannot DER encode nil " + v.Type().String())
+		} else {
+			enc, err = derMarshalUntagged(v.Elem(), p)
+		}
+	case reflect.Bool:
+		enc, err = derWriteValue(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		enc, err = derWriteValue(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		enc, err = derWriteValue(newBigInt(0).SetUint64(v.Uint()))
+	case reflect.Float32, reflect.Float64:
+		enc, err = derWriteValue(v.Float())
+	case reflect.String:
+		enc, err = derWriteValue(derStringValue(v.String(), p.strTag))
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			enc, err = derWriteValue(OctetString(v.Bytes()))
+			break
+		}
+
+		encs := make([][]byte, v.Len())
+		for i := 0; i < v.Len() && err == nil; i++ {
+			encs[i], err = derMarshalValue(v.Index(i), p.elem())
+		}
+		if err == nil {
+			if p.set {
+				derSortSetOf(encs)
+			}
+			enc = derConstructed(encs, p.set)
+		}
+	case reflect.Struct:
+		var fields []derField
+		if fields, err = derFields(v); err != nil {
+			return
+		}
+
+		var encs [][]byte
+		for _, field := range fields {
+			if derOmitField(field.value, field.p) {
+				continue
+			}
+			var fenc []byte
+			if fenc, err = derMarshalValue(field.value, field.p); err != nil {
+				err = errorTxt(field.name + `: ` + err.Error())
+				return
+			}
+			encs = append(encs, fenc)
+		}
+		if p.set {
+			derSortSet(encs)
+		}
+		enc = derConstructed(encs, p.set)
+	default:
+		err = errorBadType("DER marshal " + v.Type().String())
+	}
+
+	return
+}
+
+/*
+derWriteValue returns the DER encoding of x, which must be supported by
+[DERPacket.Write], alongside an error.
+*/
+func derWriteValue(x any) (enc []byte, err error) {
+	der := newDERPacket([]byte{})
+	if _, err = der.Write(x); err == nil {
+		enc = der.data
+	}
+
+	return
+}
+
+/*
+derUniversalValue returns the value of v in a form supported by
+[DERPacket.Write].
+*/
+func derUniversalValue(v reflect.Value) any {
+	switch v.Type() {
+	case derTimeType:
+		return GeneralizedTime(v.Interface().(time.Time))
+	case derASN1BitStringType:
+		return BitString(v.Interface().(asn1.BitString))
+	}
+
+	return v.Interface()
+}
+
+/*
+derStringValue returns str cast as the string type bearing tag.
+*/
+func derStringValue(str string, tag int) (x any) {
+	switch tag {
+	case tagUTF8String:
+		x = UTF8String(str)
+	case tagPrintableString:
+		x = PrintableString(str)
+	case tagIA5String:
+		x = IA5String(str)
+	default:
+		x = OctetString(str)
+	}
+
+	return
+}
+
+/*
+derConstructed returns a SEQUENCE, or SET if set is true, comprised of
+encs, which are written in the order given.
+*/
+func derConstructed(encs [][]byte, set bool) []byte {
+	tag := tagSequence
+	if set {
+		tag = tagSet
+	}
+
+	der := newDERPacket([]byte{})
+	der.WriteConstructed(classUniversal, tag, func(sub *DERPacket) error {
+		for _, enc := range encs {
+			sub.data = append(sub.data, enc...)
+		}
+		return nil
+	})
+
+	return der.data
+}
+
+/*
+derSortSet sorts encs, being the components of a SET, in the canonical
+order of their tags per § 10.3 of X.690: first by class, then by tag
+number.
+*/
+func derSortSet(encs [][]byte) {
+	tals := make([]TagAndLength, len(encs))
+	idx := make([]int, len(encs))
+	for i, enc := range encs {
+		tals[i], _ = newDERPacket(enc).TagAndLength()
+		idx[i] = i
+	}
+
+	sort.SliceStable(idx, func(i, j int) bool {
+		a, b := tals[idx[i]], tals[idx[j]]
+		return a.Class < b.Class || (a.Class == b.Class && a.Tag < b.Tag)
+	})
+
+	sorted := make([][]byte, len(encs))
+	for i, j := range idx {
+		sorted[i] = encs[j]
+	}
+	copy(encs, sorted)
+}
+
+/*
+derSortSetOf sorts encs, being the elements of a SET OF, in ascending
+order of their encodings per § 11.6 of X.690.
+*/
+func derSortSetOf(encs [][]byte) {
+	sort.SliceStable(encs, func(i, j int) bool {
+		return string(encs[i]) < string(encs[j])
+	})
+}
+
+/*
+derRetag returns enc bearing the specified class and tag. If explicit is
+true, enc is wrapped within a constructed element bearing said tag, else
+the identifier of enc is replaced.
+*/
+func derRetag(enc []byte, class, tag int, explicit bool) []byte {
+	der := newDERPacket([]byte{})
+	if explicit {
+		der.WriteTagAndLength(class, true, tag, len(enc))
+		der.data = append(der.data, enc...)
+		return der.data
+	}
+
+	src := newDERPacket(enc)
+	if tal, err := src.TagAndLength(); err == nil {
+		der.WriteTagAndLength(class, tal.IsCompound, tag, tal.Length)
+		der.data = append(der.data, enc[src.offset:]...)
+	}
+
+	return der.data
+}
+
+/*
+derOmitField returns a Boolean value indicative of whether v should be
+omitted from the encoding of its struct, either due to being equal to
+its DEFAULT or to being a zero OPTIONAL value.
+*/
+func derOmitField(v reflect.Value, p derFieldParams) bool {
+	if p.dflt != nil {
+		return derIsDefault(v, *p.dflt)
+	}
+
+	return p.optional && v.IsZero()
+}
+
+func derIsDefault(v reflect.Value, dflt string) (is bool) {
+	switch v.Kind() {
+	case reflect.String:
+		is = v.String() == dflt
+	case reflect.Bool:
+		is = bool2str(v.Bool()) == lc(dflt)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := atoi(dflt)
+		is = err == nil && int64(i) == v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, err := puint(dflt, 10, 64)
+		is = err == nil && i == v.Uint()
+	default:
+		is = v.IsZero()
+	}
+
+	return
+}
+
+/*
+derSetDefault assigns the DEFAULT value dflt, if non-nil, to v. Otherwise
+v is zeroed.
+*/
+func derSetDefault(v reflect.Value, dflt *string) {
+	v.Set(reflect.Zero(v.Type()))
+	if dflt == nil {
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(*dflt)
+	case reflect.Bool:
+		v.SetBool(lc(*dflt) == `true`)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if i, err := atoi(*dflt); err == nil {
+			v.SetInt(int64(i))
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if i, err := puint(*dflt, 10, 64); err == nil {
+			v.SetUint(i)
+		}
+	}
+}
+
+/*
+Unmarshal returns an error following an attempt to read the next element
+of the receiver instance into x, which must be a non-nil pointer to any
+type supported by [DERPacket.Marshal]. See [DERPacket.Marshal] for the
+"asn1" struct tag keywords honored.
+
+Interface fields tagged as "choice" are populated with a fresh instance
+of the first of the [Choice] qualifiers within choices which accepts the
+tag of the element read. Concrete types which implement [Choice] by way
+of a pointer receiver need not be registered. Types which implement
+[DERUnmarshaler] read their own encoding.
+
+Absent OPTIONAL fields are zeroed, while absent DEFAULT fields are set to
+their DEFAULT value.
+*/
+func (r *DERPacket) Unmarshal(x any, choices ...Choice) (err error) {
+	v := valOf(x)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		err = errorBadType("DER unmarshal")
+		return
+	}
+
+	dec := derDecoder{choices: Choices(choices)}
+	err = dec.value(r, v.Elem(), newDERFieldParams())
+
+	return
+}
+
+/*
+derDecoder contains the state of a [DERPacket.Unmarshal] operation.
+*/
+type derDecoder struct {
+	choices Choices
+}
+
+/*
+value reads the next element of der into v per p. Absent OPTIONAL or
+DEFAULT elements are tolerated.
+*/
+func (r derDecoder) value(der *DERPacket, v reflect.Value, p derFieldParams) (err error) {
+	absent := p.optional || p.dflt != nil
+	if !der.HasMoreData() {
+		if absent {
+			derSetDefault(v, p.dflt)
+		} else {
+			err = errorTxt("missing mandatory " + v.Type().String() + " element")
+		}
+		return
+	}
+
+	start := der.offset
+	var tal TagAndLength
+	if tal, err = der.TagAndLength(); err != nil {
+		return
+	}
+
+	end := der.offset + tal.Length
+	if tal.Length < 0 || end > len(der.data) {
+		err = errorTxt("truncated element; expecting " + itoa(tal.Length) +
+			" bytes at offset " + itoa(der.offset))
+		return
+	}
+
+	if !r.matches(v.Type(), tal, p) {
+		der.offset = start
+		if absent {
+			derSetDefault(v, p.dflt)
+		} else {
+			err = errorTxt("unexpected " + ClassNames[tal.Class] + " tag " +
+				itoa(tal.Tag) + " for " + v.Type().String())
+		}
+		return
+	}
+
+	full, content := der.data[start:end], der.data[der.offset:end]
+	der.offset = end
+
+	if p.tag >= 0 {
+		if p.isExplicit(v.Type()) {
+			if !tal.IsCompound {
+				err = errorTxt("explicitly tagged element must be constructed")
+				return
+			}
+			inner := p
+			inner.tag, inner.optional, inner.dflt = -1, false, nil
+
+			sub := der.sub(content)
+			if err = r.value(sub, v, inner); err == nil && sub.HasMoreData() {
+				err = errorTxt("explicitly tagged element contains trailing data")
+			}
+			return
+		} else if !derUnmarshalerValue(v).IsValid() {
+			// Restore the natural tag of implicitly tagged
+			// elements, thereby allowing the use of Read.
+			var ntag int
+			if ntag, _, err = derNaturalTag(v.Type(), p); err != nil {
+				return
+			}
+			full = derRetag(full, classUniversal, ntag, false)
+		}
+	}
+
+	return r.universal(der.sub(full), v, p)
+}
+
+/*
+matches returns a Boolean value indicative of whether the element described
+by tal may be read into a value of type t per p.
+*/
+func (r derDecoder) matches(t reflect.Type, tal TagAndLength, p derFieldParams) bool {
+	if p.tag >= 0 {
+		return tal.Class == p.class && tal.Tag == p.tag
+	} else if p.choice {
+		return r.choiceAlt(t, tal.Tag).IsValid()
+	} else if t.Kind() == reflect.Interface ||
+		t.Implements(derUnmarshalerType) ||
+		reflect.PointerTo(t).Implements(derUnmarshalerType) {
+		// no way of knowing in advance
+		return true
+	}
+
+	tag, compound, err := derNaturalTag(t, p)
+	return err == nil && tal.Class == classUniversal &&
+		tal.Tag == tag && tal.IsCompound == compound
+}
+
+/*
+choiceAlt returns a pointer to a fresh instance of the [Choice] qualifier
+suitable for a field of type t which accepts tag, or an invalid value if
+none were found.
+*/
+func (r derDecoder) choiceAlt(t reflect.Type, tag int) (alt reflect.Value) {
+	if t.Kind() != reflect.Interface {
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		ptr := reflect.New(t)
+		if ch, ok := ptr.Interface().(Choice); ok && ch.AcceptsTag(tag) {
+			alt = ptr
+		}
+		return
+	}
+
+	for i := 0; i < r.choices.Len() && !alt.IsValid(); i++ {
+		ct := typeOf(r.choices[i])
+		if ct.Kind() == reflect.Ptr && ct.AssignableTo(t) &&
+			r.choices[i].AcceptsTag(tag) {
+			alt = reflect.New(ct.Elem())
+		}
+	}
+
+	return
+}
+
+/*
+derUnmarshalerValue returns v, or its address, as a [DERUnmarshaler]
+value. An invalid value is returned if neither qualifies.
+*/
+func derUnmarshalerValue(v reflect.Value) (u reflect.Value) {
+	if v.Kind() == reflect.Ptr && v.Type().Implements(derUnmarshalerType) {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		u = v
+	} else if v.CanAddr() && v.Addr().Type().Implements(derUnmarshalerType) {
+		u = v.Addr()
+	}
+
+	return
+}
+
+/*
+universal reads the complete element held by der, bearing its natural
+tag, into v.
+*/
+func (r derDecoder) universal(der *DERPacket, v reflect.Value, p derFieldParams) (err error) {
+	if p.choice {
+		return r.choice(der, v)
+	} else if u := derUnmarshalerValue(v); u.IsValid() {
+		if err = u.Interface().(DERUnmarshaler).UnmarshalDER(der); err == nil && der.HasMoreData() {
+			err = errorTxt(v.Type().String() + ": element not consumed")
+		}
+		return
+	} else if _, ok := derUniversalTypes[v.Type()]; ok {
+		return derReadUniversal(der, v)
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		err = r.universal(der, v.Elem(), p)
+	case reflect.Bool:
+		var b Boolean
+		if err = der.Read(&b); err == nil {
+			v.SetBool(b.True())
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i Integer
+		if err = der.Read(&i); err == nil {
+			bi := (*big.Int)(&i)
+			if !bi.IsInt64() || v.OverflowInt(bi.Int64()) {
+				err = errorTxt("INTEGER overflows " + v.Type().String())
+			} else {
+				v.SetInt(bi.Int64())
+			}
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var i Integer
+		if err = der.Read(&i); err == nil {
+			bi := (*big.Int)(&i)
+			if !bi.IsUint64() || v.OverflowUint(bi.Uint64()) {
+				err = errorTxt("INTEGER overflows " + v.Type().String())
+			} else {
+				v.SetUint(bi.Uint64())
+			}
+		}
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if err = der.Read(&f); err == nil {
+			v.SetFloat(f)
+		}
+	case reflect.String:
+		err = derReadString(der, v, p.strTag)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			var o OctetString
+			if err = der.Read(&o); err == nil {
+				v.SetBytes(append([]byte{}, o...))
+			}
+			break
+		}
+
+		v.Set(reflect.MakeSlice(v.Type(), 0, 0))
+		err = der.readCollectionOf(derCollectionTag(p.set), func(sub *DERPacket) (err error) {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err = r.value(sub, elem, p.elem()); err == nil {
+				v.Set(reflect.Append(v, elem))
+			}
+			return
+		})
+	case reflect.Struct:
+		err = der.ReadConstructed(classUniversal, derCollectionTag(p.set), func(sub *DERPacket) error {
+			return r.fields(sub, v, p.set)
+		})
+	default:
+		err = errorBadType("DER unmarshal " + v.Type().String())
+	}
+
+	return
+}
+
+/*
+derCollectionTag returns the UNIVERSAL tag for SET if set is true, else
+SEQUENCE.
+*/
+func derCollectionTag(set bool) int {
+	if set {
+		return tagSet
+	}
+	return tagSequence
+}
+
+/*
+fields reads the components of struct v from der. The components of a
+SEQUENCE are read in order, while those of a SET are matched by tag.
+*/
+func (r derDecoder) fields(der *DERPacket, v reflect.Value, set bool) (err error) {
+	var fields []derField
+	if fields, err = derFields(v); err != nil {
+		return
+	}
+
+	if !set {
+		for _, field := range fields {
+			if err = r.value(der, field.value, field.p); err != nil {
+				err = errorTxt(field.name + `: ` + err.Error())
+				break
+			}
+		}
+		return
+	}
+
+	done := make([]bool, len(fields))
+	for der.HasMoreData() && err == nil {
+		start := der.offset
+		var tal TagAndLength
+		if tal, err = der.TagAndLength(); err != nil {
+			break
+		}
+		der.offset = start
+
+		idx := -1
+		for i := 0; i < len(fields) && idx == -1; i++ {
+			if !done[i] && r.matches(fields[i].value.Type(), tal, fields[i].p) {
+				idx = i
+			}
+		}
+
+		if idx == -1 {
+			err = errorTxt("no SET component matches " + ClassNames[tal.Class] +
+				" tag " + itoa(tal.Tag))
+		} else if err = r.value(der, fields[idx].value, fields[idx].p); err != nil {
+			err = errorTxt(fields[idx].name + `: ` + err.Error())
+		} else {
+			done[idx] = true
+		}
+	}
+
+	for i := 0; i < len(fields) && err == nil; i++ {
+		if done[i] {
+			continue
+		} else if fields[i].p.optional || fields[i].p.dflt != nil {
+			derSetDefault(fields[i].value, fields[i].p.dflt)
+		} else {
+			err = errorTxt(fields[i].name + `: missing mandatory SET component`)
+		}
+	}
+
+	return
+}
+
+/*
+choice reads the complete element held by der into v by way of the
+appropriate [Choice] qualifier.
+*/
+func (r derDecoder) choice(der *DERPacket, v reflect.Value) (err error) {
+	var tal TagAndLength
+	if tal, err = der.TagAndLength(); err != nil {
+		return
+	}
+
+	raw := asn1.RawValue{
+		Class:      tal.Class,
+		Tag:        tal.Tag,
+		IsCompound: tal.IsCompound,
+		Bytes:      der.data[der.offset:],
+		FullBytes:  der.data,
+	}
+	der.offset = len(der.data)
+
+	if alt := r.choiceAlt(v.Type(), tal.Tag); !alt.IsValid() {
+		err = errEmptyChoice(nil, tal.Tag)
+	} else if err = alt.Interface().(Choice).DecodeChoice(raw); err == nil {
+		if k := v.Kind(); k == reflect.Interface || k == reflect.Ptr {
+			v.Set(alt)
+		} else {
+			v.Set(alt.Elem())
+		}
+	}
+
+	return
+}
+
+/*
+derReadUniversal reads the element held by der into v, the type of which
+is known to derUniversalTypes.
+*/
+func derReadUniversal(der *DERPacket, v reflect.Value) (err error) {
+	switch v.Type() {
+	case derTimeType:
+		var gt GeneralizedTime
+		if err = der.Read(&gt); err == nil {
+			v.Set(valOf(gt.Cast()))
+		}
+	case derASN1BitStringType:
+		var bs BitString
+		if err = der.Read(&bs); err == nil {
+			v.Set(valOf(asn1.BitString(bs)))
+		}
+	case derBigIntType:
+		var i Integer
+		if err = der.Read(&i); err == nil {
+			v.Set(valOf(newBigInt(0).Set((*big.Int)(&i))))
+		}
+	case derEnumeratedType:
+		// Read the content as an INTEGER, as no map
+		// of permitted values is available here.
+		var i Integer
+		if err = der.sub(derRetag(der.data, classUniversal,
+			tagInteger, false)).Read(&i); err == nil {
+			v.SetInt((*big.Int)(&i).Int64())
+			der.offset = len(der.data)
+		}
+	default:
+		err = der.Read(v.Addr().Interface())
+	}
+
+	return
+}
+
+/*
+derReadString reads the element held by der, which must bear the string
+type identified by tag, into string-kinded v.
+*/
+func derReadString(der *DERPacket, v reflect.Value, tag int) (err error) {
+	var str string
+	switch tag {
+	case tagUTF8String:
+		var u UTF8String
+		err = der.Read(&u)
+		str = string(u)
+	case tagPrintableString:
+		var ps PrintableString
+		err = der.Read(&ps)
+		str = string(ps)
+	case tagIA5String:
+		var ia5 IA5String
+		err = der.Read(&ia5)
+		str = string(ia5)
+	default:
+		var o OctetString
+		err = der.Read(&o)
+		str = string(o)
+	}
+
+	if err == nil {
+		v.SetString(str)
+	}
+
+	return
+}
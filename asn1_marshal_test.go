@@ -0,0 +1,325 @@
+package dirsyn
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+)
+
+type testDERPerson struct {
+	Name     string      `asn1:"utf8"`
+	Age      int         `asn1:"tag:0,optional"`
+	Admin    bool        `asn1:"tag:1,default:false"`
+	Nicks    []string    `asn1:"tag:2,set,ia5,optional"`
+	Born     time.Time   `asn1:"tag:3,explicit,optional"`
+	Contact  Choice      `asn1:"choice"`
+	Manager  *intChoice  `asn1:"tag:4,choice,optional"`
+	Employee EmployeeNum `asn1:"application,tag:5"`
+}
+
+// EmployeeNum demonstrates the DERMarshaler/DERUnmarshaler hooks by
+// writing itself as a fixed-length OCTET STRING of four (4) bytes.
+type EmployeeNum uint32
+
+func (r EmployeeNum) MarshalDER(der *DERPacket) (int, error) {
+	return der.Write([]byte{byte(r >> 24), byte(r >> 16), byte(r >> 8), byte(r)})
+}
+
+func (r *EmployeeNum) UnmarshalDER(der *DERPacket) (err error) {
+	var tal TagAndLength
+	if tal, err = der.TagAndLength(); err == nil {
+		if tal.Length != 4 {
+			err = errorTxt("bad EmployeeNum length")
+		} else {
+			b := der.data[der.offset : der.offset+4]
+			*r = EmployeeNum(b[0])<<24 | EmployeeNum(b[1])<<16 |
+				EmployeeNum(b[2])<<8 | EmployeeNum(b[3])
+			der.offset += 4
+		}
+	}
+	return
+}
+
+/*
+This example demonstrates the struct tag driven DER encoding of a type
+which bears OPTIONAL, DEFAULT and tagged fields.
+*/
+func ExampleDERPacket_Marshal() {
+	type Example struct {
+		Name   string `asn1:"utf8"`
+		Count  int    `asn1:"tag:0,default:1"`
+		Labels []string
+	}
+
+	der, _ := srcs.X690().DER()
+	if _, err := der.Marshal(Example{Name: `x`, Count: 1}); err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("%x\n", der.Data())
+
+	var ex Example
+	err := der.Unmarshal(&ex)
+	fmt.Println(ex.Name, ex.Count, len(ex.Labels), err)
+	// Output:
+	// 30050c01783000
+	// x 1 0 <nil>
+}
+
+func TestDERPacket_Marshal(t *testing.T) {
+	contact := intChoice(5551234)
+	boss := intChoice(7)
+	in := testDERPerson{
+		Name:     `Jesse`,
+		Age:      44,
+		Nicks:    []string{`jc`, `ab`},
+		Born:     time.Date(1980, 1, 2, 3, 4, 5, 0, time.UTC),
+		Contact:  &contact,
+		Manager:  &boss,
+		Employee: 1234,
+	}
+
+	der, _ := srcs.X690().DER()
+	if _, err := der.Marshal(&in); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+
+	var out testDERPerson
+	if err := der.Unmarshal(&out, new(intChoice), new(stringChoice)); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+
+	if got, want := fmt.Sprintf("%s %d %t %v %s %v %d %d", out.Name, out.Age, out.Admin,
+		out.Nicks, out.Born.Format(time.RFC3339), *out.Contact.(*intChoice),
+		*out.Manager, out.Employee), `Jesse 44 false [ab jc] 1980-01-02T03:04:05Z `+
+		`5551234 7 1234`; got != want {
+		t.Errorf("%s failed:\nwant: %s\ngot:  %s", t.Name(), want, got)
+	}
+
+	// Unmarshaling without a suitable Choice registered must fail.
+	der.SetOffset()
+	if err := der.Unmarshal(&out, new(stringChoice)); err == nil {
+		t.Errorf("%s failed: expected error, got nil", t.Name())
+	}
+}
+
+func TestDERPacket_Marshal_subtreeSpecification(t *testing.T) {
+	in := SubtreeSpecification{
+		Base: `ou=People`,
+		ChopSpecification: ChopSpecification{
+			Exclusions: SpecificExclusions{
+				{ChopAfter: `ou=Alumni`},
+				{ChopBefore: `ou=Temp`},
+			},
+			Maximum: 3,
+		},
+	}
+
+	der, _ := srcs.X690().DER()
+	if _, err := der.Marshal(in); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+
+	// base [0], exclusions [1] as SET OF (sorted), minimum DEFAULT 0 omitted, maximum [3]
+	if got, want := hexencs(der.Data()), `3024`+`8009`+hexencs([]byte(`ou=People`))+
+		`a114`+`8007`+hexencs([]byte(`ou=Temp`))+`8109`+hexencs([]byte(`ou=Alumni`))+
+		`830103`; got != want {
+		t.Errorf("%s failed:\nwant: %s\ngot:  %s", t.Name(), want, got)
+	}
+
+	var out SubtreeSpecification
+	if err := der.Unmarshal(&out); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	} else if out.Base != in.Base || out.Maximum != 3 || out.Minimum != 0 ||
+		len(out.Exclusions) != 2 || out.Exclusions[1].ChopAfter != `ou=Alumni` {
+		t.Errorf("%s failed: unexpected result %#v", t.Name(), out)
+	}
+
+	// DEFAULT applies to absent base
+	der, _ = srcs.X690().DER()
+	der.Marshal(SubtreeSpecification{})
+	if got := hexencs(der.Data()); got != `3000` {
+		t.Errorf("%s failed: want 3000, got %s", t.Name(), got)
+	}
+}
+
+func TestDERPacket_Marshal_set(t *testing.T) {
+	type testSet struct {
+		B string `asn1:"tag:1"`
+		A int    `asn1:"tag:0"`
+		C bool   `asn1:"tag:2,optional"`
+	}
+	type testOuter struct {
+		Set testSet `asn1:"set"`
+	}
+
+	der, _ := srcs.X690().DER()
+	if _, err := der.Marshal(testOuter{Set: testSet{B: `b`, A: 1}}); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	} else if got := hexencs(der.Data()); got != `30083106800101810162` {
+		// components sorted by tag
+		t.Errorf("%s failed: unexpected encoding %s", t.Name(), got)
+	}
+
+	var out testOuter
+	if err := der.Unmarshal(&out); err != nil || out.Set.A != 1 || out.Set.B != `b` {
+		t.Errorf("%s failed: %v (%#v)", t.Name(), err, out)
+	}
+
+	// SET components are ordered by tag rather than by encoding,
+	// in agreement with X690.BERToDER.
+	type testTagOrder struct {
+		A int   `asn1:"tag:2"`
+		B []int `asn1:"tag:1"`
+	}
+	type testTagOuter struct {
+		Set testTagOrder `asn1:"set"`
+	}
+
+	der, _ = srcs.X690().DER()
+	if _, err := der.Marshal(testTagOuter{Set: testTagOrder{A: 5, B: []int{1}}}); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+	set := der.Data()[2:]
+	if got := hexencs(set); got != `3108a103020101820105` {
+		t.Errorf("%s failed: unexpected encoding %s", t.Name(), got)
+	} else if canon, err := srcs.X690().BERToDER(set); err != nil {
+		t.Errorf("%s failed: %v", t.Name(), err)
+	} else if hexencs(canon.Data()) != got {
+		t.Errorf("%s failed: BERToDER disagrees: %s", t.Name(), hexencs(canon.Data()))
+	}
+
+	var tagOut testTagOuter
+	if err := der.Unmarshal(&tagOut); err != nil || tagOut.Set.A != 5 || len(tagOut.Set.B) != 1 {
+		t.Errorf("%s failed: %v (%#v)", t.Name(), err, tagOut)
+	}
+}
+
+func TestDERPacket_Unmarshal_lengthOverflow(t *testing.T) {
+	// A long-form length which overflows int must be
+	// rejected rather than cause a panic.
+	type testOctets struct {
+		S []byte
+	}
+
+	der := newDERPacket([]byte{0x30, 0x0a, 0x04, 0x88, 0x80, 0, 0, 0, 0, 0, 0, 0})
+	var out testOctets
+	if err := der.Unmarshal(&out); err == nil {
+		t.Errorf("%s failed: expected error, got nil", t.Name())
+	}
+}
+
+func TestDERPacket_Marshal_types(t *testing.T) {
+	type testTypes struct {
+		U    uint16
+		F    float64
+		B    []byte
+		BI   *big.Int
+		E    Enumerated
+		P    *string
+		I    Integer
+		Bool Boolean
+		PS   string `asn1:"printable"`
+		Skip int    `asn1:"-"`
+		priv int
+	}
+
+	s := `ptr`
+	var b Boolean
+	b.Set(true)
+	i, _ := srcs.RFC4517().Integer(-5)
+	in := testTypes{U: 65535, F: 2.5, B: []byte{1}, BI: big.NewInt(99),
+		E: 3, P: &s, I: i, Bool: b, PS: `ok`, Skip: 1, priv: 1}
+
+	der, _ := srcs.X690().DER()
+	if _, err := der.Marshal(in); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+
+	var out testTypes
+	if err := der.Unmarshal(&out); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	} else if out.U != 65535 || out.F != 2.5 || out.B[0] != 1 || out.BI.Int64() != 99 ||
+		out.E != 3 || *out.P != `ptr` || (*big.Int)(&out.I).Int64() != -5 ||
+		!out.Bool.True() || out.PS != `ok` || out.Skip != 0 || out.priv != 0 {
+		t.Errorf("%s failed: unexpected result %#v", t.Name(), out)
+	}
+}
+
+func TestDERPacket_Marshal_codecov(t *testing.T) {
+	type badTag struct {
+		A int `asn1:"tag:x"`
+	}
+	type nilPtr struct {
+		P *int
+	}
+	type small struct {
+		A int8
+	}
+	type large struct {
+		A int
+	}
+
+	der, _ := srcs.X690().DER()
+	for idx, value := range []any{
+		nil,
+		badTag{},
+		nilPtr{},
+		map[string]int{},
+		struct{ C chan int }{},
+		struct{ E *EmployeeNum }{},
+	} {
+		if _, err := der.Marshal(value); err == nil {
+			t.Errorf("%s[%d] failed: expected error, got nil", t.Name(), idx)
+		}
+	}
+
+	der, _ = srcs.X690().DER()
+	der.Marshal(large{A: 1000})
+	var s small
+	if err := der.Unmarshal(&s); err == nil {
+		t.Errorf("%s failed: expected overflow error, got nil", t.Name())
+	}
+
+	for idx, strukt := range []struct {
+		Hex  string
+		Into any
+	}{
+		{`3000`, small{}},
+		{`3000`, new(small)},
+		{`3000`, new(badTag)},
+		{`3003020101`, new(struct{ A, B int })},
+		{`30050201010500`, new(struct{ A int })},
+		{`3003800101`, new(struct {
+			A int `asn1:"tag:0,explicit"`
+		})},
+		{`3004a0020500`, new(struct {
+			A int `asn1:"tag:0,explicit"`
+		})},
+		{`3004020201ff`, new(struct{ A uint8 })},
+		{`30030201ff`, new(struct{ A uint })},
+		{`30030c01ff`, new(struct {
+			A string `asn1:"utf8"`
+		})},
+		{`3000`, new(struct {
+			A Choice `asn1:"choice"`
+		})},
+		{`3003800101`, new(struct {
+			A struct{} `asn1:"tag:0,set"`
+		})},
+		{`3100`, new(struct {
+			A testDERPerson `asn1:"set"`
+		})},
+		{`300631040201010201`, new(struct {
+			S struct{ A, B int } `asn1:"set"`
+		})},
+		{`3006`, new(struct{ A int })},
+		{`30050403010203`, new(struct{ E EmployeeNum })},
+	} {
+		data, _ := hexdec(strukt.Hex)
+		if err := newDERPacket(data).Unmarshal(strukt.Into); err == nil {
+			t.Errorf("%s[%d] failed: expected error, got nil", t.Name(), idx)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package dirsyn
+
+/*
+asn1_stream.go implements a streaming reader of ITU-T Rec. X.690 TLV
+(tag, length, value) elements over an io.Reader.
+*/
+
+import "io"
+
+/*
+TLVReader reads ITU-T Rec. X.690 TLV elements from an io.Reader, one at a
+time, without buffering the contents of each element in memory. This is
+useful for framing LDAPMessage PDUs off a network connection or capture
+file, or for processing large values such as jpegPhoto or certificates.
+
+Only the definite length form is supported, as required by § 5.1 of RFC
+4511.
+
+Instances of this type are created by way of [X690.TLVReader]. As source
+bytes are read individually, callers reading from unbuffered sources
+(e.g.: a net.Conn) may wish to provide a *bufio.Reader.
+*/
+type TLVReader struct {
+	src     io.Reader
+	max     int
+	tal     TagAndLength
+	header  []byte
+	content *io.LimitedReader
+	buf     [1]byte
+}
+
+/*
+TLVReader returns a new instance of *[TLVReader] reading from src. The
+optional maxSize limits the length of the content of any one element,
+and defaults to 16 MiB.
+*/
+func (r X690) TLVReader(src io.Reader, maxSize ...int) *TLVReader {
+	size := berDefaultMaxSize
+	if len(maxSize) > 0 && maxSize[0] > 0 {
+		size = maxSize[0]
+	}
+
+	return &TLVReader{src: src, max: size}
+}
+
+/*
+Next returns an instance of [TagAndLength] alongside an error following an
+attempt to read the identifier and length octets of the next element. Any
+unread content of the previous element is discarded beforehand.
+
+The error io.EOF is returned only if no further elements are available.
+*/
+func (r *TLVReader) Next() (tal TagAndLength, err error) {
+	if err = r.Skip(); err != nil {
+		return
+	}
+
+	r.header = r.header[:0]
+	r.content = nil
+
+	var b byte
+	if b, err = r.readByte(); err != nil {
+		// io.EOF is only legitimate here
+		return
+	}
+
+	tal.Class = int(b >> 6)
+	tal.IsCompound = b&0x20 != 0
+	tal.Tag = int(b & 0x1f)
+
+	if tal.Tag == 0x1f {
+		tal.Tag = 0
+		for b = 0x80; b&0x80 != 0 && err == nil; {
+			if tal.Tag > int(^uint32(0)>>8) {
+				err = errorTxt("TLV tag number too large")
+			} else if b, err = r.readHeaderByte(); err == nil {
+				tal.Tag = tal.Tag<<7 | int(b&0x7f)
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+
+	if b, err = r.readHeaderByte(); err != nil {
+		return
+	}
+
+	switch {
+	case b == 0x80:
+		err = errorTxt("indefinite lengths are not supported")
+	case b < 0x80:
+		tal.Length = int(b)
+	case b&0x7f > 4:
+		err = errorTxt("TLV length of " + itoa(int(b&0x7f)) + " octets is too large")
+	default:
+		for n := int(b & 0x7f); n > 0 && err == nil; n-- {
+			if b, err = r.readHeaderByte(); err == nil {
+				tal.Length = tal.Length<<8 | int(b)
+			}
+		}
+	}
+
+	if err == nil && tal.Length < 0 {
+		// Four length octets overflow int on 32-bit platforms.
+		err = derLengthErr
+	} else if err == nil && tal.Length > r.max {
+		err = errorTxt("TLV length " + itoa(tal.Length) +
+			" exceeds maximum size of " + itoa(r.max))
+	}
+
+	if err == nil {
+		r.tal = tal
+		r.content = &io.LimitedReader{R: r.src, N: int64(tal.Length)}
+	}
+
+	return
+}
+
+/*
+TagAndLength returns the instance of [TagAndLength] most recently read by
+the [TLVReader.Next] method.
+*/
+func (r TLVReader) TagAndLength() TagAndLength { return r.tal }
+
+/*
+Content returns an io.Reader which yields the unread content octets of
+the current element. An io.ErrUnexpectedEOF error is returned by said
+reader if the source is exhausted before all content has been read.
+*/
+func (r *TLVReader) Content() io.Reader {
+	return tlvContent{r}
+}
+
+/*
+Sub returns a new instance of *[TLVReader] for reading the elements within
+the content of the current (constructed) element, bearing the same maximum
+size as the receiver instance.
+*/
+func (r *TLVReader) Sub() *TLVReader {
+	return &TLVReader{src: r.Content(), max: r.max}
+}
+
+/*
+Skip returns an error following an attempt to discard any unread content
+octets of the current element.
+*/
+func (r *TLVReader) Skip() (err error) {
+	if r.content != nil && r.content.N > 0 {
+		_, err = io.Copy(io.Discard, r.Content())
+	}
+
+	return
+}
+
+/*
+ReadElement returns the complete encoding of the next element, including
+its identifier and length octets, alongside an error. The return value
+is suitable for use with [X690.DecodeDER] or [X690.DecodeBER].
+
+The error io.EOF is returned only if no further elements are available.
+*/
+func (r *TLVReader) ReadElement() (elem []byte, err error) {
+	if _, err = r.Next(); err == nil {
+		elem = make([]byte, len(r.header)+int(r.content.N))
+		copy(elem, r.header)
+		_, err = io.ReadFull(r.Content(), elem[len(r.header):])
+	}
+
+	return
+}
+
+/*
+readByte returns the next source byte alongside an error. The byte is
+appended to the header of the current element.
+*/
+func (r *TLVReader) readByte() (b byte, err error) {
+	if br, ok := r.src.(io.ByteReader); ok {
+		b, err = br.ReadByte()
+	} else if _, err = io.ReadFull(r.src, r.buf[:]); err == nil {
+		b = r.buf[0]
+	}
+
+	if err == nil {
+		r.header = append(r.header, b)
+	}
+
+	return
+}
+
+/*
+readHeaderByte returns the next source byte alongside an error, wherein
+io.EOF is replaced by io.ErrUnexpectedEOF.
+*/
+func (r *TLVReader) readHeaderByte() (b byte, err error) {
+	if b, err = r.readByte(); err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+
+	return
+}
+
+/*
+tlvContent implements io.Reader for the content of the current element
+of a *[TLVReader].
+*/
+type tlvContent struct {
+	r *TLVReader
+}
+
+func (r tlvContent) Read(p []byte) (n int, err error) {
+	lr := r.r.content
+	if lr == nil {
+		return 0, io.EOF
+	}
+
+	if n, err = lr.Read(p); err == io.EOF && lr.N > 0 {
+		err = io.ErrUnexpectedEOF
+	}
+
+	return
+}
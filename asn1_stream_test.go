@@ -0,0 +1,153 @@
+package dirsyn
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"testing"
+)
+
+/*
+This example demonstrates the framing of two (2) consecutive PDUs read
+from an io.Reader, such as a network connection.
+*/
+func ExampleTLVReader_ReadElement() {
+	var r X690
+	data, _ := hexdec(`300c020101` + `600702010304008000` +
+		`30050201024200`)
+
+	tlv := r.TLVReader(bytes.NewReader(data))
+	for {
+		pdu, err := tlv.ReadElement()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Printf("%x\n", pdu)
+	}
+	// Output:
+	// 300c020101600702010304008000
+	// 30050201024200
+}
+
+/*
+This example demonstrates the streaming of the content of a large value
+through an io.Reader, without first buffering the value in memory.
+*/
+func ExampleTLVReader_Content() {
+	var r X690
+	value := bytes.Repeat([]byte{'x'}, 1000)
+	der, _ := r.DER(OctetString(value))
+
+	tlv := r.TLVReader(bytes.NewReader(der.Data()))
+	tal, _ := tlv.Next()
+	n, err := io.Copy(io.Discard, tlv.Content())
+	fmt.Println(TagNames[tal.Tag], tal.Length, n, err)
+	// Output: OCTET STRING 1000 1000 <nil>
+}
+
+func TestTLVReader(t *testing.T) {
+	var r X690
+	// SEQUENCE { INTEGER 1, OCTET STRING "abc", BOOLEAN TRUE }, INTEGER 2
+	data, _ := hexdec(`300b020101040361626301` + `01ff` + `020102`)
+
+	tlv := r.TLVReader(bufio.NewReader(bytes.NewReader(data)))
+	tal, err := tlv.Next()
+	if err != nil || !tal.IsCompound || tal.Tag != tagSequence || tal.Length != 11 {
+		t.Fatalf("%s failed: unexpected header %#v (%v)", t.Name(), tal, err)
+	} else if tlv.TagAndLength() != tal {
+		t.Fatalf("%s failed: TagAndLength mismatch", t.Name())
+	}
+
+	// Descend into the SEQUENCE, skipping the INTEGER and
+	// reading the OCTET STRING.
+	sub := tlv.Sub()
+	if _, err = sub.Next(); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+	if tal, err = sub.Next(); err != nil || tal.Tag != tagOctetString {
+		t.Fatalf("%s failed: unexpected header %#v (%v)", t.Name(), tal, err)
+	}
+	content, _ := io.ReadAll(sub.Content())
+	if string(content) != `abc` {
+		t.Errorf("%s failed: want abc, got %s", t.Name(), content)
+	}
+	if tal, err = sub.Next(); err != nil || tal.Tag != tagBoolean {
+		t.Fatalf("%s failed: unexpected header %#v (%v)", t.Name(), tal, err)
+	}
+	if _, err = sub.Next(); err != io.EOF {
+		t.Errorf("%s failed: want EOF, got %v", t.Name(), err)
+	}
+
+	// The remaining element of the outer stream is read
+	// intact.
+	if elem, err := tlv.ReadElement(); err != nil || fmt.Sprintf("%x", elem) != `020102` {
+		t.Errorf("%s failed: unexpected element %x (%v)", t.Name(), elem, err)
+	}
+	if _, err = tlv.Next(); err != io.EOF {
+		t.Errorf("%s failed: want EOF, got %v", t.Name(), err)
+	}
+	if n, err := tlv.Content().Read(make([]byte, 1)); n != 0 || err != io.EOF {
+		t.Errorf("%s failed: want EOF, got %d, %v", t.Name(), n, err)
+	}
+}
+
+func TestTLVReader_codecov(t *testing.T) {
+	var r X690
+	for idx, strukt := range []struct {
+		Hex string
+		Max int
+		Err error
+	}{
+		{`1f`, 0, io.ErrUnexpectedEOF},
+		{`1fffffffffff7f00`, 0, nil},
+		{`04`, 0, io.ErrUnexpectedEOF},
+		{`0480`, 0, nil},
+		{`048500000000010000`, 0, nil},
+		{`0482`, 0, io.ErrUnexpectedEOF},
+		{`04820100`, 255, nil},
+		{`0405616263`, 0, io.ErrUnexpectedEOF},
+	} {
+		data, _ := hexdec(strukt.Hex)
+		tlv := r.TLVReader(bytes.NewReader(data), strukt.Max)
+		_, err := tlv.ReadElement()
+		if err == nil || (strukt.Err != nil && err != strukt.Err) {
+			t.Errorf("%s[%d] failed: unexpected error %v", t.Name(), idx, err)
+		}
+	}
+
+	// A truncated element whose content is streamed, or
+	// skipped, results in io.ErrUnexpectedEOF.
+	data, _ := hexdec(`1f81000561626364`)
+	tlv := r.TLVReader(iotestReader{bytes.NewReader(data)})
+	if tal, err := tlv.Next(); err != nil || tal.Tag != 128 {
+		t.Fatalf("%s failed: unexpected header %#v (%v)", t.Name(), tal, err)
+	}
+	if _, err := tlv.Next(); err != io.ErrUnexpectedEOF {
+		t.Errorf("%s failed: want %v, got %v", t.Name(), io.ErrUnexpectedEOF, err)
+	}
+}
+
+func TestTLVReader_lengthOverflow(t *testing.T) {
+	// Four length octets overflow int on 32-bit platforms,
+	// which must never yield a negative length.
+	var r X690
+	for idx, raw := range []string{`048480000000`, `0484ffffffff`} {
+		data, _ := hexdec(raw)
+		tlv := r.TLVReader(bytes.NewReader(data), math.MaxInt)
+		if tal, err := tlv.Next(); err == nil && tal.Length < 0 {
+			t.Errorf("%s[%d] failed: negative length %d", t.Name(), idx, tal.Length)
+		}
+	}
+}
+
+// iotestReader hides any io.ByteReader implementation.
+type iotestReader struct {
+	r io.Reader
+}
+
+func (r iotestReader) Read(p []byte) (int, error) { return r.r.Read(p) }
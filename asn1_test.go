@@ -1,8 +1,11 @@
 package dirsyn
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"testing"
+	"time"
 )
 
 type testAttributeList []testAttribute
@@ -309,6 +312,23 @@ func TestTagAndLength_TruncatedLength(t *testing.T) {
 	}
 }
 
+func TestTagAndLength_LengthOverflow(t *testing.T) {
+	// Long-form lengths which overflow int must never
+	// produce a negative length, regardless of mode.
+	for idx, data := range [][]byte{
+		{0x30, 0x88, 0x80, 0, 0, 0, 0, 0, 0, 0, 0x02, 0x01, 0x01},
+		{0x30, 0x88, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		{0x30, 0x89, 0x01, 0, 0, 0, 0, 0, 0, 0, 0},
+	} {
+		for _, mode := range []DERMode{DERLenient, DERStrict} {
+			der := &DERPacket{data: data, mode: mode}
+			if tal, err := der.TagAndLength(); err == nil {
+				t.Errorf("%s[%d] failed: expected error, got length %d", t.Name(), idx, tal.Length)
+			}
+		}
+	}
+}
+
 func TestReadConstructed_DataTruncated(t *testing.T) {
 	// Identifier 0x65:
 	//   - (0x65 >> 6) = 0x1 (Application)
@@ -566,3 +586,286 @@ func ExampleEnumerated() {
 	fmt.Println(names[e])
 	// Output: baseObject
 }
+
+/*
+This example demonstrates the DER encoding of a SET OF, the elements of
+which are sorted by their encodings.
+*/
+func ExampleDERPacket_WriteSetOf() {
+	var srcs Sources
+	der, _ := srcs.X690().DER()
+
+	if _, err := der.WriteSetOf(IA5String(`b`), IA5String(`a`)); err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("%x\n", der.Data())
+
+	var values []IA5String
+	err := der.ReadSetOf(func(sub *DERPacket) (err error) {
+		var ia5 IA5String
+		if err = sub.Read(&ia5); err == nil {
+			values = append(values, ia5)
+		}
+		return
+	})
+	fmt.Println(values, err)
+	// Output:
+	// 3106160161160162
+	// [a b] <nil>
+}
+
+func TestDERPacket_universalTypes(t *testing.T) {
+	var r RFC4512
+	oid1, _ := r.NumericOID(`1.2.840.113549`)
+	oid2, _ := r.NumericOID(`2.999.3`)
+	bmp, _ := srcs.X680().BMPString(`Hi`)
+	when := time.Date(2024, 1, 2, 3, 4, 5, 500000000, time.UTC)
+
+	for idx, strukt := range []struct {
+		Value any
+		Hex   string
+		Read  any
+		Want  string
+	}{
+		{oid1, `06062a864886f70d`, new(NumericOID), `1.2.840.113549`},
+		{oid2, `0603883703`, new(NumericOID), `2.999.3`},
+		{BitString{Bytes: []byte{0xff, 0xf0}, BitLength: 16}, `030300fff0`, new(BitString), `'1111111111110000'B`},
+		{BitString{Bytes: []byte{0xff, 0xff}, BitLength: 12}, `030304fff0`, new(BitString), ``}, // unused bits zeroed
+		{Null{}, `0500`, new(Null), `{}`},
+		{nil, `0500`, new(Null), `{}`},
+		{float64(0), `0900`, new(float64), `0`},
+		{float64(1), `0903800001`, new(float64), `1`},
+		{float32(0.5), `090380ff01`, new(float64), `0.5`},
+		{float64(-1536), `0903c00903`, new(float64), `-1536`},
+		{math.Inf(-1), `090141`, new(float64), `-Inf`},
+		{math.NaN(), `090142`, new(float64), `NaN`},
+		{math.Copysign(0, -1), `090143`, new(float64), `-0`},
+		{UTF8String(`héllo`), `0c0668c3a96c6c6f`, new(UTF8String), `héllo`},
+		{PrintableString(`Test`), `130454657374`, new(PrintableString), `Test`},
+		{PrintableString(``), `1300`, new(PrintableString), ``},
+		{IA5String(`a@b`), `1603614062`, new(IA5String), `a@b`},
+		{TeletexString(`abc`), `1403616263`, new(TeletexString), `abc`},
+		{bmp, `1e0400480069`, new(BMPString), `Hi`},
+		{UniversalString(`A`), `1c0400000041`, new(UniversalString), `A`},
+		{GeneralizedTime(when), `181132303234303130323033303430352e355a`, new(GeneralizedTime), `20240102030405Z`},
+		{UTCTime(when), `170d3234303130323033303430355a`, new(UTCTime), `2401020304Z`},
+	} {
+		der, err := srcs.X690().DER(strukt.Value)
+		if err != nil {
+			t.Errorf("%s[%d] failed: %v", t.Name(), idx, err)
+			continue
+		} else if got := hexencs(der.Data()); got != strukt.Hex {
+			t.Errorf("%s[%d] failed:\nwant: %s\ngot:  %s", t.Name(), idx, strukt.Hex, got)
+			continue
+		}
+
+		if err = der.Read(strukt.Read); err != nil {
+			t.Errorf("%s[%d] failed: %v", t.Name(), idx, err)
+		} else if got := fmt.Sprint(valOf(strukt.Read).Elem().Interface()); got != strukt.Want {
+			t.Errorf("%s[%d] failed:\nwant: %s\ngot:  %s", t.Name(), idx, strukt.Want, got)
+		}
+	}
+}
+
+func TestDERPacket_SequenceOf(t *testing.T) {
+	der, _ := srcs.X690().DER()
+	if _, err := der.WriteSequenceOf(IA5String(`b`), IA5String(`a`)); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	} else if got := hexencs(der.Data()); got != `3006160162160161` {
+		t.Fatalf("%s failed: want order preserved, got %s", t.Name(), got)
+	}
+
+	// SET OF content cannot be read as SEQUENCE OF
+	set, _ := srcs.X690().DER()
+	set.WriteSetOf(Null{})
+	if err := set.ReadSequenceOf(func(sub *DERPacket) error {
+		return sub.Read(new(Null))
+	}); err == nil {
+		t.Errorf("%s failed: expected error, got nil", t.Name())
+	}
+
+	// elements must be consumed by the callback
+	if err := der.ReadSequenceOf(func(sub *DERPacket) error {
+		return nil
+	}); err == nil {
+		t.Errorf("%s failed: expected error, got nil", t.Name())
+	}
+
+	if _, err := der.WriteSetOf(struct{}{}); err == nil {
+		t.Errorf("%s failed: expected error, got nil", t.Name())
+	}
+}
+
+func TestDERPacket_universalTypes_codecov(t *testing.T) {
+	oid, _ := srcs.RFC4512().NumericOID(`1.2`)
+	oid.DotNotation = nil
+
+	for idx, value := range []any{
+		oid,
+		BitString{Bytes: []byte{0xff}, BitLength: 9},
+		UTF8String([]byte{0xff}),
+		PrintableString(`#`),
+		IA5String("\u20ac"),
+		TeletexString("\x01"),
+		BMPString{0x1E, 0x02, 0x00},
+		UniversalString([]byte{0xff}),
+		UTCTime(time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)),
+	} {
+		if _, err := srcs.X690().DER(value); err == nil {
+			t.Errorf("%s[%d] failed: expected error, got nil", t.Name(), idx)
+		}
+	}
+
+	for idx, strukt := range []struct {
+		Hex  string
+		Read any
+	}{
+		{`0600`, new(NumericOID)},
+		{`06028001`, new(NumericOID)},
+		{`060181`, new(NumericOID)},
+		{`0300`, new(BitString)},
+		{`030108`, new(BitString)},
+		{`030201ff`, new(BitString)}, // 1 unused bit, yet the bit is set (permitted here)
+		{`050100`, new(Null)},
+		{`0401ff`, new(Null)},
+		{`2500`, new(Null)},
+		{`0901ff`, new(float64)},
+		{`090244ff`, new(float64)},
+		{`0902b0ff`, new(float64)},
+		{`0903900101`, new(float64)},
+		{`0906033132452d31`, new(float64)},
+		{`090207ff`, new(float64)},
+		{`0c01ff`, new(UTF8String)},
+		{`130123`, new(PrintableString)},
+		{`1601ff`, new(IA5String)},
+		{`140101`, new(TeletexString)},
+		{`1e0100`, new(BMPString)},
+		{`1c0100`, new(UniversalString)},
+		{`1c0400110000`, new(UniversalString)},
+		{`18023230`, new(GeneralizedTime)},
+		{`1700`, new(UTCTime)},
+		{`0400`, new(struct{})},
+	} {
+		data, _ := hexdec(strukt.Hex)
+		der := newDERPacket(data)
+		err := der.Read(strukt.Read)
+		if want := idx == 5 || idx == 12 || idx == 13; want != (err == nil) {
+			t.Errorf("%s[%d] failed: unexpected result %v", t.Name(), idx, err)
+		}
+	}
+}
+
+/*
+This example demonstrates the rejection of a BOOLEAN TRUE value not
+encoded as 0xFF when operating in [DERStrict] mode.
+*/
+func ExampleX690_DecodeDER() {
+	var r X690
+	_, err := r.DecodeDER([]byte{0x01, 0x01, 0x01}, DERStrict)
+	fmt.Println(err)
+	// Output: X.690 § 11.1: BOOLEAN TRUE must be encoded as 0xFF
+}
+
+func TestDERPacket_strict(t *testing.T) {
+	for idx, strukt := range []struct {
+		Hex    string
+		Read   any
+		Clause string
+	}{
+		{`0181010000`, new(Boolean), `10.1`},
+		{`01800000`, new(Boolean), `10.1`},
+		{`01820001ff`, new(Boolean), `10.1`},
+		{`1f0201ff`, new(Boolean), `8.1.2.4.2`},
+		{`02020000`, new(Integer), `8.3.2`},
+		{`0202ff80`, new(Integer), `8.3.2`},
+		{`0200`, new(Integer), `8.3.1`},
+		{`01020000`, new(Boolean), `8.2.1`},
+		{`010101`, new(Boolean), `11.1`},
+		{`030201ff`, new(BitString), `11.2.1`},
+		{`240404020000`, new(OctetString), `10.2`},
+		{`3106020102020101`, nil, `11.6`},
+		{`181132303230303130313030303030302e305a`, new(GeneralizedTime), `11.7`},
+		{`181232303230303130313030303030302e35305a`, new(GeneralizedTime), `11.7`},
+		{`181332303230303130313030303030302b30303030`, new(GeneralizedTime), `11.7`},
+		{`170b323030313031303030305a`, new(UTCTime), `11.8`},
+		{`0a020001`, nil, `8.3.2`},
+		{`3003010101`, nil, `11.1`},
+	} {
+		data, _ := hexdec(strukt.Hex)
+		der := newDERPacket(data)
+		der.SetMode(DERStrict)
+
+		var err error
+		if strukt.Read == nil {
+			err = der.Validate()
+		} else if err = der.Read(strukt.Read); err == nil {
+			// fallback for constructed forms
+			err = der.Validate()
+		}
+
+		var cerr *DERCanonicalError
+		if !errors.As(err, &cerr) || cerr.Clause != strukt.Clause {
+			t.Errorf("%s[%d] failed: want clause %s, got %v",
+				t.Name(), idx, strukt.Clause, err)
+			continue
+		}
+
+		// The lenient mode must not report a canonical error
+		// for any of the above, save for indefinite lengths.
+		lenient := newDERPacket(data)
+		if strukt.Read == nil {
+			continue
+		} else if err = lenient.Read(strukt.Read); errors.As(err, &cerr) && idx != 1 {
+			t.Errorf("%s[%d] failed: unexpected lenient error %v", t.Name(), idx, err)
+		}
+	}
+}
+
+func TestDERPacket_strict_valid(t *testing.T) {
+	var r X690
+	for idx, value := range []any{
+		true,
+		false,
+		-129,
+		128,
+		BitString{Bytes: []byte{0xf0}, BitLength: 4},
+		GeneralizedTime(time.Date(2020, 1, 1, 0, 0, 0, 500000000, time.UTC)),
+		UTCTime(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)),
+		OctetString(make([]byte, 200)),
+	} {
+		der, err := r.DER(value)
+		if err != nil {
+			t.Fatalf("%s[%d] failed: %v", t.Name(), idx, err)
+		}
+		if _, err = r.DecodeDER(der.Data(), DERStrict); err != nil {
+			t.Errorf("%s[%d] failed: %v", t.Name(), idx, err)
+		}
+	}
+
+	// SET OF elements in ascending order, as well as a SET
+	// whose components are ordered by tag.
+	for idx, raw := range []string{
+		`3106020101020102`,
+		`3106800101810100`,
+		`3006020102020101`,
+	} {
+		data, _ := hexdec(raw)
+		if _, err := r.DecodeDER(data, DERStrict); err != nil {
+			t.Errorf("%s[%d] failed: %v", t.Name(), idx, err)
+		}
+	}
+
+	// Strict mode is inherited by nested packets.
+	data, _ := hexdec(`3106020102020101`)
+	der, _ := r.DecodeDER(data)
+	der.SetMode(DERStrict)
+	var i Integer
+	err := der.ReadSetOf(func(sub *DERPacket) error { return sub.Read(&i) })
+	if !errors.Is(err, derSetOfOrderErr) {
+		t.Errorf("%s failed: want %v, got %v", t.Name(), derSetOfOrderErr, err)
+	}
+	if der, _ = r.DER(DERStrict); der.Mode() != DERStrict {
+		t.Errorf("%s failed: mode not set", t.Name())
+	}
+}
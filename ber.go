@@ -0,0 +1,492 @@
+package dirsyn
+
+/*
+ber.go implements a reader for ITU-T Rec. X.690 Basic Encoding Rules, as
+emitted by many LDAP clients and X.500 DSAs, alongside the re-encoding
+of accepted input using the Distinguished Encoding Rules.
+*/
+
+import "sort"
+
+const (
+	berDefaultMaxDepth    = 64
+	berDefaultMaxElements = 1 << 16
+	berDefaultMaxSize     = 1 << 24
+)
+
+/*
+BERLimits contains the limits imposed upon input read by way of the
+[X690.DecodeBER] method. Any field left as zero is replaced with the
+package default shown alongside it.
+*/
+type BERLimits struct {
+	// MaxDepth limits the nesting of constructed
+	// elements. The default is 64.
+	MaxDepth int
+
+	// MaxElements limits the total number of elements,
+	// at any depth, within the input. The default is
+	// 65536.
+	MaxElements int
+
+	// MaxSize limits the size, in bytes, of the input.
+	// The default is 16 MiB.
+	MaxSize int
+}
+
+/*
+BERElement contains a single decoded BER element.
+
+Constructed encodings of UNIVERSAL string types (e.g.: OCTET STRING or
+BIT STRING) are flattened upon decoding, such that Compound is false and
+Content contains the concatenation of all segments. Elements of other
+classes are not flattened, as their underlying type is unknown.
+*/
+type BERElement struct {
+	Class    int
+	Tag      int
+	Compound bool
+
+	// Content contains the content octets of a
+	// primitive element.
+	Content []byte
+
+	// Elements contains the elements within a
+	// constructed element.
+	Elements BERElements
+}
+
+/*
+BERElements contains a sequence of *[BERElement] instances.
+*/
+type BERElements []*BERElement
+
+type berDecoder struct {
+	data   []byte
+	offset int
+	count  int
+	limits BERLimits
+}
+
+/*
+DecodeBER returns an instance of [BERElements] alongside an error following
+an attempt to decode the BER-encoded input data, subject to the optional
+limits.
+
+Indefinite lengths, constructed string encodings, long-form tags and
+non-minimal length encodings are all accepted.
+*/
+func (r X690) DecodeBER(data []byte, limits ...BERLimits) (elems BERElements, err error) {
+	dec := &berDecoder{data: data}
+	if len(limits) > 0 {
+		dec.limits = limits[0]
+	}
+	for _, lim := range []struct {
+		val *int
+		def int
+	}{
+		{&dec.limits.MaxDepth, berDefaultMaxDepth},
+		{&dec.limits.MaxElements, berDefaultMaxElements},
+		{&dec.limits.MaxSize, berDefaultMaxSize},
+	} {
+		if *lim.val <= 0 {
+			*lim.val = lim.def
+		}
+	}
+
+	if len(data) > dec.limits.MaxSize {
+		err = errorTxt("BER input exceeds size limit of " + itoa(dec.limits.MaxSize))
+		return
+	}
+
+	elems, err = dec.elements(0, len(data), false)
+
+	return
+}
+
+/*
+BERToDER returns an instance of *[DERPacket] containing the DER encoding
+of the BER-encoded input data, alongside an error. See [X690.DecodeBER]
+and [BERElements.DER] for details.
+*/
+func (r X690) BERToDER(data []byte, limits ...BERLimits) (der *DERPacket, err error) {
+	var elems BERElements
+	if elems, err = r.DecodeBER(data, limits...); err == nil {
+		var enc []byte
+		if enc, err = elems.DER(); err == nil {
+			der = newDERPacket(enc)
+		}
+	}
+
+	return
+}
+
+/*
+elements returns the elements found at the specified depth, ending either
+at end or, if indefinite is true, at the end-of-contents octets.
+*/
+func (r *berDecoder) elements(depth, end int, indefinite bool) (elems BERElements, err error) {
+	for err == nil {
+		if r.offset >= end {
+			if indefinite {
+				err = errorTxt("missing BER end-of-contents octets")
+			}
+			break
+		} else if indefinite && r.offset+1 < end &&
+			r.data[r.offset] == 0x00 && r.data[r.offset+1] == 0x00 {
+			r.offset += 2
+			break
+		}
+
+		var elem *BERElement
+		if elem, err = r.element(depth, end); err == nil {
+			elems = append(elems, elem)
+		}
+	}
+
+	return
+}
+
+/*
+element returns the next *[BERElement], which must end at or before end.
+*/
+func (r *berDecoder) element(depth, end int) (elem *BERElement, err error) {
+	if r.count++; r.count > r.limits.MaxElements {
+		err = errorTxt("BER element count exceeds limit of " + itoa(r.limits.MaxElements))
+		return
+	} else if depth > r.limits.MaxDepth {
+		err = errorTxt("BER nesting exceeds depth limit of " + itoa(r.limits.MaxDepth))
+		return
+	}
+
+	elem = new(BERElement)
+	if err = r.identifier(elem, end); err != nil {
+		return
+	}
+
+	var (
+		length     int
+		indefinite bool
+	)
+	if length, indefinite, err = r.length(elem, end); err != nil {
+		return
+	}
+
+	if !elem.Compound {
+		if elem.Class == classUniversal && elem.Tag == 0 {
+			err = errorTxt("unexpected BER end-of-contents octets")
+			return
+		}
+		elem.Content = r.data[r.offset : r.offset+length]
+		r.offset += length
+		return
+	}
+
+	stop := r.offset + length
+	if indefinite {
+		stop = end
+	}
+	if elem.Elements, err = r.elements(depth+1, stop, indefinite); err == nil &&
+		elem.Class == classUniversal && derIsStringTag(elem.Tag) {
+		err = berFlatten(elem)
+	}
+
+	return
+}
+
+/*
+identifier reads the identifier octets of the next element into elem.
+*/
+func (r *berDecoder) identifier(elem *BERElement, end int) (err error) {
+	b := r.data[r.offset]
+	r.offset++
+
+	elem.Class = int(b >> 6)
+	elem.Compound = b&0x20 != 0
+	elem.Tag = int(b & 0x1f)
+
+	if elem.Tag == 0x1f {
+		// Long-form tag, for which leading zero
+		// groups are tolerated.
+		elem.Tag = 0
+		for {
+			if r.offset >= end {
+				err = errorTxt("truncated BER tag")
+				break
+			} else if elem.Tag > int(^uint32(0)>>8) {
+				err = errorTxt("BER tag number too large")
+				break
+			}
+			b = r.data[r.offset]
+			r.offset++
+			elem.Tag = elem.Tag<<7 | int(b&0x7f)
+			if b&0x80 == 0 {
+				break
+			}
+		}
+	}
+
+	return
+}
+
+/*
+length returns the length of the next element described by elem, or a
+Boolean value of true if said length is indefinite.
+*/
+func (r *berDecoder) length(elem *BERElement, end int) (length int, indefinite bool, err error) {
+	if r.offset >= end {
+		err = errorTxt("truncated BER length")
+		return
+	}
+
+	b := r.data[r.offset]
+	r.offset++
+
+	switch {
+	case b < 0x80:
+		length = int(b)
+	case b == 0x80:
+		if indefinite = true; !elem.Compound {
+			err = errorTxt("indefinite length not permitted for primitive BER element")
+		}
+		return
+	case b == 0xff:
+		err = errorTxt("reserved BER length octet 0xFF")
+		return
+	default:
+		// Long-form length, for which leading
+		// zero octets are tolerated.
+		for n := int(b & 0x7f); n > 0 && err == nil; n-- {
+			if r.offset >= end {
+				err = errorTxt("truncated BER length")
+			} else if length = length<<8 | int(r.data[r.offset]); length > r.limits.MaxSize {
+				err = errorTxt("BER length exceeds size limit of " + itoa(r.limits.MaxSize))
+			}
+			r.offset++
+		}
+		if err != nil {
+			return
+		}
+	}
+
+	if r.offset+length > end {
+		err = errorTxt("truncated BER element; need " + itoa(length) +
+			" bytes, have " + itoa(end-r.offset))
+	}
+
+	return
+}
+
+/*
+berFlatten replaces the segments of the constructed string element elem
+with the concatenation of their contents per § 8.6.4 and § 8.7.3 of ITU-T
+Rec. X.690.
+*/
+func berFlatten(elem *BERElement) (err error) {
+	segTag := tagOctetString
+	if elem.Tag == tagBitString {
+		segTag = tagBitString
+	}
+
+	var content []byte
+	for i, seg := range elem.Elements {
+		if seg.Class != classUniversal || seg.Tag != segTag || seg.Compound {
+			err = errorTxt("invalid segment within constructed " + TagNames[elem.Tag])
+			return
+		} else if segTag != tagBitString {
+			content = append(content, seg.Content...)
+			continue
+		}
+
+		// Only the final BIT STRING segment may
+		// bear unused bits.
+		last := i == len(elem.Elements)-1
+		if len(seg.Content) == 0 || (!last && seg.Content[0] != 0) {
+			err = errorTxt("invalid segment within constructed " + TagNames[elem.Tag])
+			return
+		} else if i == 0 {
+			content = append(content, seg.Content[0])
+		} else if last {
+			content[0] = seg.Content[0]
+		}
+		content = append(content, seg.Content[1:]...)
+	}
+
+	if segTag == tagBitString && len(content) == 0 {
+		// An empty BIT STRING still bears its
+		// initial (unused bits) octet.
+		content = []byte{0x00}
+	}
+
+	elem.Compound = false
+	elem.Content = content
+	elem.Elements = nil
+
+	return
+}
+
+/*
+DER returns the DER encoding of the receiver instance alongside an error.
+See [BERElement.DER] for details.
+*/
+func (r BERElements) DER() (enc []byte, err error) {
+	for i := 0; i < len(r) && err == nil; i++ {
+		var e []byte
+		if e, err = r[i].DER(); err == nil {
+			enc = append(enc, e...)
+		}
+	}
+
+	return
+}
+
+/*
+DER returns the DER encoding of the receiver instance alongside an error.
+
+Lengths and tags are written in their minimal forms, and the contents of
+UNIVERSAL BOOLEAN, INTEGER, ENUMERATED, BIT STRING, GeneralizedTime and
+UTCTime elements are rewritten in their canonical forms.
+
+The components of a UNIVERSAL SET are sorted by their encodings if all
+bear the same tag (SET OF), else by their tags (SET), as the type of a
+SET is not discernible from its encoding alone.
+*/
+func (r BERElement) DER() (enc []byte, err error) {
+	der := newDERPacket([]byte{})
+
+	if r.Compound {
+		encs := make([][]byte, len(r.Elements))
+		for i := 0; i < len(r.Elements) && err == nil; i++ {
+			encs[i], err = r.Elements[i].DER()
+		}
+		if err != nil {
+			return
+		}
+
+		if r.Class == classUniversal && r.Tag == tagSet {
+			berSortSet(r.Elements, encs)
+		}
+
+		var content []byte
+		for _, e := range encs {
+			content = append(content, e...)
+		}
+		der.WriteTagAndLength(r.Class, true, r.Tag, len(content))
+		enc = append(der.data, content...)
+		return
+	}
+
+	content := r.Content
+	if r.Class == classUniversal {
+		if content, err = berCanonicalContent(r.Tag, content); err != nil {
+			return
+		}
+	}
+	der.WriteTagAndLength(r.Class, false, r.Tag, len(content))
+	enc = append(der.data, content...)
+
+	return
+}
+
+/*
+berSortSet sorts encs, which are the encodings of elems, per § 11.6 of
+ITU-T Rec. X.690 if all elems bear the same tag, else per § 10.3.
+*/
+func berSortSet(elems BERElements, encs [][]byte) {
+	setOf := true
+	for i := 1; i < len(elems) && setOf; i++ {
+		setOf = elems[i].Class == elems[0].Class && elems[i].Tag == elems[0].Tag
+	}
+
+	if setOf {
+		sort.SliceStable(encs, func(i, j int) bool {
+			return string(encs[i]) < string(encs[j])
+		})
+		return
+	}
+
+	idx := make([]int, len(elems))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool {
+		a, b := elems[idx[i]], elems[idx[j]]
+		return a.Class < b.Class || (a.Class == b.Class && a.Tag < b.Tag)
+	})
+
+	sorted := make([][]byte, len(encs))
+	for i, j := range idx {
+		sorted[i] = encs[j]
+	}
+	copy(encs, sorted)
+}
+
+/*
+berCanonicalContent returns the canonical DER form of content, being the
+content octets of a primitive UNIVERSAL element of the specified tag.
+*/
+func berCanonicalContent(tag int, content []byte) (canon []byte, err error) {
+	canon = content
+	switch tag {
+	case tagBoolean:
+		if len(content) != 1 {
+			err = derBooleanLengthErr
+		} else if content[0] != 0x00 {
+			canon = []byte{0xff}
+		}
+	case tagInteger, tagEnum:
+		if len(content) == 0 {
+			err = derIntegerEmptyErr
+			break
+		}
+		for len(canon) > 1 &&
+			((canon[0] == 0x00 && canon[1]&0x80 == 0) ||
+				(canon[0] == 0xff && canon[1]&0x80 != 0)) {
+			canon = canon[1:]
+		}
+	case tagNull:
+		if len(content) != 0 {
+			err = errorTxt("NULL must not contain content octets")
+		}
+	case tagBitString:
+		var bs BitString
+		if err = derReadBitString(&bs, newDERPacket(content), TagAndLength{
+			Tag: tagBitString, Length: len(content)}); err == nil {
+			der := newDERPacket([]byte{})
+			if _, err = derWriteBitString(der, bs); err == nil {
+				canon = der.data[len(der.data)-len(content):]
+			}
+		}
+	case tagGeneralizedTime, tagUTCTime:
+		canon, err = berCanonicalTime(tag, content)
+	}
+
+	return
+}
+
+/*
+berCanonicalTime returns the canonical DER form of content, being the
+content octets of a GeneralizedTime or UTCTime element.
+*/
+func berCanonicalTime(tag int, content []byte) (canon []byte, err error) {
+	der := newDERPacket([]byte{})
+	if tag == tagUTCTime {
+		var utc UTCTime
+		if utc, err = marshalUTCTime(string(content)); err == nil {
+			_, err = derWriteUTCTime(der, utc)
+		}
+	} else {
+		var gt GeneralizedTime
+		if gt, err = marshalGenTime(string(content)); err == nil {
+			derWriteGeneralizedTime(der, gt)
+		}
+	}
+
+	if err == nil {
+		// Time values are always short enough for a
+		// single length octet.
+		canon = der.data[2:]
+	}
+
+	return
+}
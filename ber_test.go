@@ -0,0 +1,119 @@
+package dirsyn
+
+import (
+	"fmt"
+	"testing"
+)
+
+/*
+This example demonstrates the re-encoding of an indefinite-length BER
+SEQUENCE, containing a constructed OCTET STRING and a BOOLEAN TRUE value
+encoded as 0x01, using the Distinguished Encoding Rules.
+*/
+func ExampleX690_BERToDER() {
+	var r X690
+	data, _ := hexdec(`308024800402686504016c0000010101` + `0000`)
+	der, err := r.BERToDER(data)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Printf("%x\n", der.Data())
+	// Output: 3008040368656c0101ff
+}
+
+func TestX690_DecodeBER(t *testing.T) {
+	var r X690
+	for idx, strukt := range []struct {
+		BER string
+		DER string
+	}{
+		{`0101ff`, `0101ff`},
+		{`0181012a`, `0101ff`},     // non-minimal length, TRUE as 0x2a
+		{`0182000100`, `010100`},   // non-minimal length
+		{`1f0101ff`, `0101ff`},     // non-minimal (long-form) tag
+		{`bf80810000`, `bf810000`}, // long-form tag, zero leading group
+		{`02030000ff`, `020200ff`}, // redundant INTEGER padding
+		{`0a02ff80`, `0a0180`},     // redundant ENUMERATED padding
+		{`030201ff`, `030201fe`},   // non-zero unused bits
+		{`2380030300ff00030201ff0000`, `030401ff00fe`},
+		{`2300`, `030100`},                   // empty constructed BIT STRING
+		{`2480248004016100000000`, `040161`}, // nested constructed segments
+		{`3080020102020101` + `0000`, `3006020102020101`},
+		{`3180020102020101` + `0000`, `3106020101020102`}, // SET OF
+		{`3106810101800100`, `3106800100810101`},          // SET
+		{`a080020101` + `0000`, `a003020101`},
+		{`181232303230303130313030303030302e35305a`, `181132303230303130313030303030302e355a`},
+		{`170d3230303130313030303030305a`, `170d3230303130313030303030305a`},
+		{`058100`, `0500`},
+		{``, ``},
+	} {
+		data, _ := hexdec(strukt.BER)
+		der, err := r.BERToDER(data)
+		if err != nil {
+			t.Errorf("%s[%d] failed: %v", t.Name(), idx, err)
+			continue
+		}
+
+		want, _ := hexdec(strukt.DER)
+		if got := der.Data(); string(got) != string(want) {
+			t.Errorf("%s[%d] failed:\nwant: %x\ngot:  %x", t.Name(), idx, want, got)
+		} else if _, err = r.DecodeDER(got, DERStrict); err != nil {
+			t.Errorf("%s[%d] failed: non-canonical result %x: %v", t.Name(), idx, got, err)
+		}
+	}
+}
+
+func TestX690_DecodeBER_limits(t *testing.T) {
+	var r X690
+	deep, _ := hexdec(`30803080308030800000000000000000`)
+	for idx, strukt := range []struct {
+		BER    string
+		Limits BERLimits
+	}{
+		{`3080308030803080` + `0000000000000000`, BERLimits{MaxDepth: 2}},
+		{`3006020101020101`, BERLimits{MaxElements: 2}},
+		{`3006020101020101`, BERLimits{MaxSize: 4}},
+		{`30840000ffff`, BERLimits{MaxSize: 8}},
+	} {
+		data, _ := hexdec(strukt.BER)
+		if _, err := r.DecodeBER(data, strukt.Limits); err == nil {
+			t.Errorf("%s[%d] failed: expected error, got nil", t.Name(), idx)
+		}
+	}
+
+	if elems, err := r.DecodeBER(deep); err != nil || len(elems) != 1 {
+		t.Errorf("%s failed: unexpected result %v (%v)", t.Name(), elems, err)
+	}
+}
+
+func TestX690_DecodeBER_codecov(t *testing.T) {
+	var r X690
+	for idx, raw := range []string{
+		`3080`,           // missing end-of-contents
+		`0000`,           // unexpected end-of-contents
+		`0480`,           // indefinite primitive
+		`01ff`,           // reserved length octet
+		`1f`,             // truncated tag
+		`1fffffffffff7f`, // tag too large
+		`01`,             // truncated length
+		`0182`,           // truncated long-form length
+		`0105ff`,         // truncated content
+		`24030201ff`,     // invalid OCTET STRING segment
+		`2303030101`,     // unused bits without data
+		`2306030101030100`,
+		`230403020000` + `0300`,
+		`0100`,
+		`0200`,
+		`050100`,
+		`030108`,
+		`1801ff`,
+		`1701ff`,
+	} {
+		data, _ := hexdec(raw)
+		if _, err := r.BERToDER(data); err == nil {
+			t.Errorf("%s[%d] failed: expected error, got nil", t.Name(), idx)
+		}
+	}
+}
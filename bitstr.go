@@ -52,7 +52,7 @@ an ASN.1 BIT STRING.
 */
 func (r RFC4517) BitString(x any) (bs BitString, err error) {
 	bs, err = marshalBitString(x)
-	return
+	return bs, errorSyntax(err)
 }
 
 func bitString(x any) (result Boolean) {
@@ -208,3 +208,57 @@ func stripTrailingZeros(bytes []byte, bitLength int) (blen int) {
 
 	return
 }
+
+/*
+derWriteBitString returns an int alongside an error following an attempt
+to write bs as a DER-encoded BIT STRING. As mandated by § 11.2.1 of ITU-T
+Rec. X.690, any unused bits in the final octet are written as zero (0).
+*/
+func derWriteBitString(der *DERPacket, bs BitString) (n int, err error) {
+	nbytes := (bs.BitLength + 7) / 8
+	if bs.BitLength < 0 || nbytes > len(bs.Bytes) {
+		err = errorTxt("BIT STRING length " + itoa(bs.BitLength) +
+			" exceeds available bytes")
+		return
+	}
+
+	unused := nbytes*8 - bs.BitLength
+	content := append([]byte{byte(unused)}, bs.Bytes[:nbytes]...)
+	if unused > 0 {
+		content[nbytes] &= 0xff << uint(unused)
+	}
+
+	n = derWritePrimitive(der, tagBitString, content)
+
+	return
+}
+
+/*
+derReadBitString returns an error following an attempt to read a DER-encoded
+BIT STRING into x.
+*/
+func derReadBitString(x *BitString, der *DERPacket, tal TagAndLength) (err error) {
+	var content []byte
+	if content, err = derReadPrimitive(der, tal, tagBitString); err != nil {
+		return
+	} else if len(content) == 0 {
+		err = errorTxt("BIT STRING has no initial octet")
+		return
+	}
+
+	unused := int(content[0])
+	if unused > 7 || (len(content) == 1 && unused != 0) {
+		err = errorTxt("invalid BIT STRING unused bit count " + itoa(unused))
+		return
+	} else if der.mode == DERStrict && content[len(content)-1]&(1<<unused-1) != 0 {
+		err = derBitStringUnusedErr
+		return
+	}
+
+	*x = BitString{
+		Bytes:     append([]byte{}, content[1:]...),
+		BitLength: (len(content)-1)*8 - unused,
+	}
+
+	return
+}
@@ -110,3 +110,44 @@ func assertBMPString(x any) (enc BMPString, err error) {
 
 	return
 }
+
+/*
+derWriteBMPString returns an int alongside an error following an attempt
+to write bmp as a DER-encoded BMPString. The content octets are the
+big-endian UTF-16 code units already held by the receiver.
+*/
+func derWriteBMPString(der *DERPacket, bmp BMPString) (n int, err error) {
+	var content []byte
+	if len(bmp) > 0 {
+		if len(bmp) < 2 || bmp[0] != 0x1E || len(bmp) != 2+int(bmp[1])*2 {
+			err = errorTxt("malformed BMPString")
+			return
+		}
+		content = bmp[2:]
+	}
+
+	n = derWritePrimitive(der, tagBMPString, content)
+
+	return
+}
+
+/*
+derReadBMPString returns an error following an attempt to read a DER-encoded
+BMPString into x.
+*/
+func derReadBMPString(x *BMPString, der *DERPacket, tal TagAndLength) (err error) {
+	var content []byte
+	if content, err = derReadPrimitive(der, tal, tagBMPString); err != nil {
+		return
+	} else if len(content)%2 != 0 {
+		err = errorTxt("BMPString content length must be even")
+		return
+	} else if len(content)/2 > 255 {
+		err = errorTxt("BMPString too long")
+		return
+	}
+
+	*x = append(BMPString{0x1E, byte(len(content) / 2)}, content...)
+
+	return
+}
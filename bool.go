@@ -60,7 +60,7 @@ From [§ 3.3.3 of RFC 4517]:
 */
 func (r RFC4517) Boolean(x any) (b Boolean, err error) {
 	b, err = assertBoolean(x)
-	return
+	return b, errorSyntax(err)
 }
 
 func boolean(x any) (result Boolean) {
@@ -237,11 +237,19 @@ func derReadBoolean(x *Boolean, der *DERPacket, tal TagAndLength) (err error) {
 	} else if der.offset+tal.Length > len(der.data) {
 		err = errorTxt("insufficient data for BOOLEAN")
 		return
+	} else if tal.Length == 0 || (der.mode == DERStrict && tal.Length != 1) {
+		err = derBooleanLengthErr
+		return
 	}
 
 	value := der.data[der.offset : der.offset+tal.Length]
 	der.offset += tal.Length
 
+	if der.mode == DERStrict && value[0] != 0x0 && value[0] != 0xff {
+		err = derBooleanTrueErr
+		return
+	}
+
 	var b bool
 	if value[len(value)-1] == 0x0 {
 		*x = Boolean{bool: &b}
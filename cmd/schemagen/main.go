@@ -0,0 +1,75 @@
+/*
+Command schemagen generates Go struct types from the object classes found
+within one or more schema files.
+
+Usage:
+
+	schemagen [-pkg name] [-out file] [-classes a,b,...] [-dir path] [file.schema ...]
+
+Definitions are read from all ".schema" files at, or beneath, the -dir path
+as well as any files named on the command line. Standard syntaxes and
+matching rules are pre-loaded. The generated source is written to the -out
+file, or to standard output if unspecified.
+*/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/JesseCoretta/go-dirsyn"
+)
+
+func main() {
+	var (
+		pkg     = flag.String("pkg", "schema", "package name of the generated source")
+		out     = flag.String("out", "", "output file (default: standard output)")
+		classes = flag.String("classes", "", "comma-separated object classes to generate (default: all)")
+		dir     = flag.String("dir", "", "directory of .schema files")
+	)
+	flag.Parse()
+
+	if err := run(*pkg, *out, *classes, *dir, flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, "schemagen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(pkg, out, classes, dir string, files []string) (err error) {
+	var r dirsyn.RFC4512
+	var schema *dirsyn.SubschemaSubentry
+	if schema, err = r.SubschemaSubentry(true); err != nil {
+		return
+	}
+
+	if dir != "" {
+		if err = schema.LoadDirectory(dir); err != nil {
+			return
+		}
+	}
+	if len(files) > 0 {
+		if err = schema.LoadFiles(files...); err != nil {
+			return
+		}
+	}
+
+	opts := dirsyn.GoCodeOptions{Package: pkg}
+	if classes != "" {
+		opts.Classes = strings.Split(classes, ",")
+	}
+
+	var src []byte
+	if src, err = schema.GoCode(opts); err != nil {
+		return
+	}
+
+	if out == "" {
+		_, err = os.Stdout.Write(src)
+	} else {
+		err = os.WriteFile(out, src, 0o644)
+	}
+
+	return
+}
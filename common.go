@@ -1,13 +1,13 @@
 package dirsyn
 
 import (
-	"bufio"
 	"bytes"
 	"encoding/asn1"
 	"encoding/base64"
 	"encoding/binary"
 	"encoding/hex"
 	"errors"
+	"fmt"
 	"math/big"
 	"os"
 	"reflect"
@@ -24,7 +24,9 @@ var (
 	itoa       func(int) string                          = strconv.Itoa
 	cntns      func(string, string) bool                 = strings.Contains
 	erris      func(error, error) bool                   = errors.Is
+	erras      func(error, any) bool                     = errors.As
 	mkerr      func(string) error                        = errors.New
+	errorf     func(string, ...any) error                = fmt.Errorf
 	fields     func(string) []string                     = strings.Fields
 	bfields    func([]byte) [][]byte                     = bytes.Fields
 	trimS      func(string) string                       = strings.TrimSpace
@@ -48,8 +50,10 @@ var (
 	idxany     func(string, string) int                  = strings.IndexAny
 	idxr       func(string, rune) int                    = strings.IndexRune
 	repAll     func(string, string, string) string       = strings.ReplaceAll
+	newRepl    func(...string) *strings.Replacer         = strings.NewReplacer
 	brepAll    func([]byte, []byte, []byte) []byte       = bytes.ReplaceAll
 	puint      func(string, int, int) (uint64, error)    = strconv.ParseUint
+	pfloat     func(string, int) (float64, error)        = strconv.ParseFloat
 	fuint      func(uint64, int) string                  = strconv.FormatUint
 	hexdec     func(string) ([]byte, error)              = hex.DecodeString
 	hexencs    func([]byte) string                       = hex.EncodeToString
@@ -114,24 +118,6 @@ func streq(a, b string) bool {
 	return a == b
 }
 
-func removeBashComments(input []byte) (output []byte) {
-	stripComments := func(line string) string {
-		re := regexp.MustCompile("#.*")
-		return re.ReplaceAllString(line, "")
-	}
-
-	scanner := bufio.NewScanner(bytes.NewReader(input))
-	for scanner.Scan() {
-		line := scanner.Text()
-		strippedLine := stripComments(line)
-		if len(strippedLine) > 0 {
-			output = append(output, []byte(strippedLine+"\n")...)
-		}
-	}
-
-	return
-}
-
 /*
 isAttribute returns a boolean value indicative of whether val
 describes a numeric OID or RFC 4512 descriptor ("descr").
@@ -226,26 +212,6 @@ func newStrBuilder() strings.Builder {
 	return strings.Builder{}
 }
 
-func escapeString(x string) (esc string) {
-	if len(x) > 0 {
-		bld := newStrBuilder()
-		for _, z := range x {
-			if z > maxASCII {
-				for _, c := range []byte(string(z)) {
-					bld.WriteString(`\`)
-					bld.WriteString(fuint(uint64(c), 16))
-				}
-			} else {
-				bld.WriteRune(z)
-			}
-		}
-
-		esc = bld.String()
-	}
-
-	return
-}
-
 func hexEncode(x any) string {
 	var r string
 	switch tv := x.(type) {
@@ -494,7 +460,8 @@ WHSP characters condensed into single space characters.
 The input value may be a string or []byte instance.
 
 WHSP is qualified through space or TAB chars (ASCII #32
-and #9 respectively).
+and #9 respectively). All other octets are written as-is,
+thus multi-byte UTF-8 sequences are preserved.
 */
 func condenseWHSP(input any) (a string) {
 	// remove leading and trailing
@@ -513,24 +480,25 @@ func condenseWHSP(input any) (a string) {
 	b = repAll(b, string(rune(10)), string(rune(32)))
 
 	var last bool
+	bld := newStrBuilder()
 	for i := 0; i < len(b); i++ {
-		c := rune(b[i])
+		c := b[i]
 		switch c {
 		// match space (32) or tab (9)
-		case rune(9), rune(10), rune(32):
+		case 9, 10, 32:
 			if !last {
 				last = true
-				a += string(rune(32))
+				bld.WriteByte(32)
 			}
 		default:
 			if last {
 				last = false
 			}
-			a += string(c)
+			bld.WriteByte(c)
 		}
 	}
 
-	a = trimS(a)
+	a = trimS(bld.String())
 	return
 }
 
@@ -5,6 +5,23 @@ import (
 	"testing"
 )
 
+func TestCondenseWHSP(t *testing.T) {
+	for idx, strukt := range []struct {
+		Input any
+		Want  string
+	}{
+		{`this has spaces`, `this has spaces`},
+		{"  this\t\thas \n spaces  ", `this has spaces`},
+		{[]byte(`Lučić  Coretta`), `Lučić Coretta`},
+		{`функция   함수`, `функция 함수`},
+		{rune(33), ``},
+	} {
+		if got := condenseWHSP(strukt.Input); got != strukt.Want {
+			t.Errorf("%s[%d] failed:\nwant: %q\ngot:  %q", t.Name(), idx, strukt.Want, got)
+		}
+	}
+}
+
 func TestMisc_codecov(t *testing.T) {
 
 	b64dec([]byte{0x0, 0x1, 0x2, 0xff})
@@ -43,7 +43,8 @@ valid -- should be verified periodically in lieu of significant world events.
 [ISO 3166]: https://www.iso.org/iso-3166-country-codes.html
 */
 func (r RFC4517) CountryString(x any) (CountryString, error) {
-	return marshalCountryString(x)
+	val, err := marshalCountryString(x)
+	return val, errorSyntax(err)
 }
 
 func marshalCountryString(x any) (cs CountryString, err error) {
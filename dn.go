@@ -352,6 +352,8 @@ func marshalDistinguishedName(x any) (dn DistinguishedName, err error) {
 	var _dn *DistinguishedName
 	if _dn, err = parseDN(raw); err == nil {
 		dn = DistinguishedName(*_dn)
+	} else {
+		err = errorResult(ResultInvalidDNSyntax, err)
 	}
 
 	return
@@ -361,7 +363,8 @@ func marshalDistinguishedName(x any) (dn DistinguishedName, err error) {
 DistinguishedName is a wrapping alias for [RFC4514.DN].
 */
 func (r RFC4517) DistinguishedName(x any) (dn DistinguishedName, err error) {
-	return marshalDistinguishedName(x)
+	dn, err = marshalDistinguishedName(x)
+	return dn, errorSyntax(err)
 }
 
 func dN(x any) (result Boolean) {
@@ -402,7 +405,8 @@ NameAndOptionalUID returns an instance of [NameAndOptionalUID] alongside
 an error.
 */
 func (r RFC4517) NameAndOptionalUID(x any) (NameAndOptionalUID, error) {
-	return marshalNameAndOptionalUID(x)
+	val, err := marshalNameAndOptionalUID(x)
+	return val, errorSyntax(err)
 }
 
 func nameAndOptionalUID(x any) (result Boolean) {
@@ -467,6 +471,9 @@ func marshalNameAndOptionalUID(x any) (nou NameAndOptionalUID, err error) {
 
 // parseDN returns a distinguishedName or an error.  The
 // function respects https://tools.ietf.org/html/rfc4514
+//
+// Any error returned is a *ParseError positioned at the
+// offending attribute type or value.
 func parseDN(str string) (*DistinguishedName, error) {
 	var dn = &DistinguishedName{RDNs: make([]*RelativeDistinguishedName, 0)}
 	if trimS(str) == "" {
@@ -503,6 +510,8 @@ func parseDN(str string) (*DistinguishedName, error) {
 		case char == '=' && len(attr.Type) == 0:
 			if err = attr.setType(str[startPos:i]); err == nil {
 				startPos = i + 1
+			} else {
+				err = newParseError(str, startPos, `attribute type`, err)
 			}
 		case isDNDelim(char):
 			startPos, err = checkDNDelim(str, char, attr, startPos, i, appendAttributesToRDN)
@@ -512,11 +521,14 @@ func parseDN(str string) (*DistinguishedName, error) {
 	if err != nil {
 		return dn, err
 	} else if len(attr.Type) == 0 {
-		return dn, errorTxt("DN ended with incomplete type, value pair")
+		return dn, newParseError(str, len(str), `'='`,
+			errorTxt("DN ended with incomplete type, value pair"))
 	}
 
 	if err = attr.setValue(str[startPos:]); err == nil {
 		appendAttributesToRDN(true)
+	} else {
+		err = newParseError(str, startPos, `attribute value`, err)
 	}
 
 	return dn, err
@@ -524,11 +536,13 @@ func parseDN(str string) (*DistinguishedName, error) {
 
 func checkDNDelim(str string, char byte, attr *AttributeTypeAndValue, s, i int, funk func(bool)) (startPos int, err error) {
 	if len(attr.Type) == 0 {
-		err = errorTxt("incomplete type, value pair")
+		err = newParseError(str, i, `'='`, errorTxt("incomplete type, value pair"))
 	} else if err = attr.setValue(str[s:i]); err == nil {
 		startPos = i + 1
 		last := char == ',' || char == ';'
 		funk(last)
+	} else {
+		err = newParseError(str, s, `attribute value`, err)
 	}
 
 	return
@@ -70,7 +70,8 @@ The following input types are accepted:
   - [BMPString]
 */
 func (r RFC4517) DirectoryString(x any) (DirectoryString, error) {
-	return marshalDirectoryString(x)
+	val, err := marshalDirectoryString(x)
+	return val, errorSyntax(err)
 }
 
 func directoryString(x any) (result Boolean) {
@@ -83,6 +83,12 @@ func derReadEnumerated(x *Enumerated, der *DERPacket,
 		content := der.data[der.offset : der.offset+tal.Length]
 		der.offset += tal.Length
 
+		if der.mode == DERStrict {
+			if err = derCheckInteger(content); err != nil {
+				return
+			}
+		}
+
 		// Reconstruct a DER blob for an INTEGER: asn1.Unmarshal expects tag 0x02.
 		tmp := make([]byte, tal.Length+2)
 		tmp[0] = 0x02             // INTEGER tag
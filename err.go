@@ -43,6 +43,242 @@ func errorASN1ConstructedTagClass(wantTAL, gotTAL TagAndLength) error {
 		", compound:" + bool2str(gotTAL.IsCompound))
 }
 
+/*
+ParseError describes a problem encountered at a specific position while
+parsing the string representation of a [Filter], [DistinguishedName],
+[SubtreeSpecification], schema definition or ACI component.
+
+Instances are returned by the relevant parsers by reference, and may be
+extracted from a returned error using [errors.As].
+*/
+type ParseError struct {
+	// Offset contains the zero-based byte offset of the
+	// problem within the input.
+	Offset int
+
+	// Line and Column contain the one-based line and column
+	// numbers of Offset. Columns are counted in runes.
+	Line, Column int
+
+	// Expected describes the class of token which was expected
+	// at Offset, such as "')'" or "attribute type". It may be
+	// zero if no particular token was expected.
+	Expected string
+
+	// Snippet contains an excerpt of the input in the vicinity
+	// of Offset.
+	Snippet string
+
+	// Err contains the underlying cause, if known.
+	Err error
+}
+
+/*
+Error returns the string representation of the receiver instance.
+*/
+func (r *ParseError) Error() string {
+	msg := `Parse error`
+	if r.Err != nil {
+		msg = r.Err.Error()
+	}
+
+	msg += ` at line ` + itoa(r.Line) + `, column ` + itoa(r.Column)
+	if r.Expected != "" {
+		msg += `: expected ` + r.Expected
+	}
+	if r.Snippet != "" {
+		msg += ` near "` + r.Snippet + `"`
+	}
+
+	return msg
+}
+
+/*
+Unwrap returns the underlying cause of the receiver instance.
+*/
+func (r *ParseError) Unwrap() error { return r.Err }
+
+/*
+newParseError returns a new *ParseError describing a problem at offset
+within input. Offsets beyond either end of input are clamped.
+*/
+func newParseError(input string, offset int, expected string, cause error) *ParseError {
+	if offset < 0 {
+		offset = 0
+	} else if offset > len(input) {
+		offset = len(input)
+	}
+
+	head := input[:offset]
+	line := strcnt(head, "\n")
+	if idx := strlidx(head, "\n"); idx != -1 {
+		head = head[idx+1:]
+	}
+
+	return &ParseError{
+		Offset:   offset,
+		Line:     line + 1,
+		Column:   runeCnt(head) + 1,
+		Expected: expected,
+		Snippet:  parseErrorSnippet(input, offset),
+		Err:      cause,
+	}
+}
+
+/*
+parseErrorSnippet returns an excerpt of input spanning a few characters
+before, and rather more characters after, offset. Whitespace is condensed
+so that multi-line input produces a single-line excerpt.
+*/
+func parseErrorSnippet(input string, offset int) string {
+	start, end := offset-8, offset+24
+	if start < 0 {
+		start = 0
+	}
+	if end > len(input) {
+		end = len(input)
+	}
+
+	// Do not split multibyte characters.
+	for start > 0 && !runeBgn(input[start]) {
+		start--
+	}
+	for end < len(input) && !runeBgn(input[end]) {
+		end++
+	}
+
+	return condenseWHSP(input[start:end])
+}
+
+/*
+parseErrorAt returns err as a *ParseError relative to input. If err
+already is (or wraps) a *ParseError, which is relative to the portion of
+input beginning at offset, its position is rebased upon input. Otherwise,
+err is wrapped as the cause of a new *ParseError at offset.
+
+A nil error is returned as is.
+*/
+func parseErrorAt(err error, input string, offset int, expected string) error {
+	if err == nil {
+		return nil
+	}
+
+	var pe *ParseError
+	if erras(err, &pe) {
+		return rebaseParseError(err, input, offset)
+	}
+
+	return newParseError(input, offset, expected, err)
+}
+
+/*
+rebaseParseError returns err with its position rebased upon input if err
+is (or wraps) a *ParseError relative to the portion of input beginning at
+offset. Any other error is returned as is.
+*/
+func rebaseParseError(err error, input string, offset int) error {
+	var pe *ParseError
+	if erras(err, &pe) {
+		err = newParseError(input, offset+pe.Offset, pe.Expected, pe.Err)
+	}
+
+	return err
+}
+
+/*
+trimSOffset returns the result of trimming leading and trailing WHSP from
+x alongside the offset at which the trimmed result begins within x.
+*/
+func trimSOffset(x string) (trimmed string, offset int) {
+	if trimmed = trimS(x); len(trimmed) > 0 {
+		offset = stridx(x, trimmed)
+	}
+
+	return
+}
+
+/*
+DERCanonicalError describes a violation of the canonical encoding rules
+of [ITU-T Rec. X.690] encountered by a *[DERPacket] operating in strict
+mode (see [DERStrict]) or by [DERPacket.Validate].
+
+Instances may be extracted from a returned error using [errors.As]. Each
+distinct violation is identified by its Clause and Reason.
+
+[ITU-T Rec. X.690]: https://www.itu.int/rec/T-REC-X.690
+*/
+type DERCanonicalError struct {
+	// Clause contains the number of the violated clause
+	// of ITU-T Rec. X.690, e.g.: "11.1".
+	Clause string
+
+	// Reason contains a brief description of the violation.
+	Reason string
+}
+
+/*
+Error returns the string representation of the receiver instance.
+*/
+func (r *DERCanonicalError) Error() string {
+	return `X.690 § ` + r.Clause + `: ` + r.Reason
+}
+
+var (
+	derIndefiniteLengthErr  *DERCanonicalError = &DERCanonicalError{`10.1`, `indefinite length form not permitted`}
+	derNonMinimalLengthErr  *DERCanonicalError = &DERCanonicalError{`10.1`, `length not encoded in the minimum number of octets`}
+	derNonMinimalTagErr     *DERCanonicalError = &DERCanonicalError{`8.1.2.4.2`, `tag number not encoded in the minimum number of octets`}
+	derConstructedStringErr *DERCanonicalError = &DERCanonicalError{`10.2`, `constructed form not permitted for string types`}
+	derBooleanLengthErr     *DERCanonicalError = &DERCanonicalError{`8.2.1`, `BOOLEAN must consist of a single content octet`}
+	derBooleanTrueErr       *DERCanonicalError = &DERCanonicalError{`11.1`, `BOOLEAN TRUE must be encoded as 0xFF`}
+	derIntegerEmptyErr      *DERCanonicalError = &DERCanonicalError{`8.3.1`, `INTEGER must consist of one or more content octets`}
+	derIntegerPaddingErr    *DERCanonicalError = &DERCanonicalError{`8.3.2`, `INTEGER contains redundant leading octets`}
+	derBitStringUnusedErr   *DERCanonicalError = &DERCanonicalError{`11.2.1`, `BIT STRING unused bits must be zero`}
+	derSetOfOrderErr        *DERCanonicalError = &DERCanonicalError{`11.6`, `SET OF elements not in ascending order`}
+	derGeneralizedTimeErr   *DERCanonicalError = &DERCanonicalError{`11.7`, `GeneralizedTime not in canonical form`}
+	derUTCTimeErr           *DERCanonicalError = &DERCanonicalError{`11.8`, `UTCTime not in canonical form`}
+)
+
+/*
+resultError associates an underlying error with the [ResultCode] most
+appropriate for conveying it, as returned by [RFC4511.ErrorResultCode].
+The string representation of the underlying error is not altered.
+*/
+type resultError struct {
+	code ResultCode
+	err  error
+}
+
+/*
+errorResult returns err wrapped within a *resultError bearing code. A
+nil error, or one which already bears a [ResultCode], is returned as is.
+*/
+func errorResult(code ResultCode, err error) error {
+	var re *resultError
+	if err == nil || erras(err, &re) {
+		return err
+	}
+
+	return &resultError{code: code, err: err}
+}
+
+/*
+errorSyntax returns err wrapped within a *resultError bearing the code
+[ResultInvalidAttributeSyntax], as with [errorResult].
+*/
+func errorSyntax(err error) error {
+	return errorResult(ResultInvalidAttributeSyntax, err)
+}
+
+/*
+Error returns the string representation of the underlying error.
+*/
+func (r *resultError) Error() string { return r.err.Error() }
+
+/*
+Unwrap returns the underlying error of the receiver instance.
+*/
+func (r *resultError) Unwrap() error { return r.err }
+
 var (
 	nilBEREncodeErr   error = mkerr("Cannot BER encode nil instance")
 	unknownBERPacket  error = mkerr("Unidentified BER packet; cannot process")
@@ -52,6 +288,16 @@ var (
 	invalidMR         error = mkerr("Invalid or incompatible matching rule")
 	nilInstanceErr    error = mkerr("Nil instance error")
 	nilInputErr       error = mkerr("Nil input error")
+	unknownATErr      error = mkerr("Unknown attribute type")
+	unknownMRErr      error = mkerr("Unknown matching rule")
+	noEqualityMRErr   error = mkerr("No EQUALITY matching rule in force")
+	noOrderingMRErr   error = mkerr("No ORDERING matching rule in force")
+	noSubstringMRErr  error = mkerr("No SUBSTR matching rule in force")
+	inapplicableMRErr error = mkerr("Matching rule not applicable to attribute type")
+	badAssertionErr   error = mkerr("Assertion value violates matching rule syntax")
+	unbalancedErr     error = mkerr("Unbalanced parenthetical encapsulation")
+	unterminatedErr   error = mkerr("Unterminated quoted value")
+	derLengthErr      error = mkerr("Encoded length exceeds the maximum supported size")
 	errNotExist       error = os.ErrNotExist
 )
 
@@ -0,0 +1,98 @@
+package dirsyn
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+/*
+This example demonstrates the means for extracting the position of a
+problem within a malformed [Filter] by way of [errors.As].
+*/
+func ExampleParseError() {
+	var r RFC4515
+	_, err := r.Filter(`(&(objectClass=person)
+	(|(cn=Jesse)(sn=Cor*)(!(x-bogus_attr=1))))`)
+
+	var perr *ParseError
+	if errors.As(err, &perr) {
+		fmt.Printf("line %d, column %d: %s\n", perr.Line, perr.Column, perr.Expected)
+	}
+	// Output: line 2, column 26: attribute description
+}
+
+func TestParseError(t *testing.T) {
+	var (
+		r4515 RFC4515
+		r4514 RFC4514
+		r3672 RFC3672
+		aci   NetscapeACIv3
+	)
+
+	for idx, strukt := range []struct {
+		Parse    func(string) error
+		Input    string
+		Offset   int
+		Line     int
+		Column   int
+		Expected string
+	}{
+		{func(x string) (err error) { _, err = r4515.Filter(x); return }, `(&(cn=a)(sn=b)`, 14, 1, 15, `')'`},
+		{func(x string) (err error) { _, err = r4515.Filter(x); return }, `(&(cn=a))(sn=b))`, 15, 1, 16, `'('`},
+		{func(x string) (err error) { _, err = r4515.Filter(x); return }, `(&(cn=a)x(sn=b))`, 8, 1, 9, `'('`},
+		{func(x string) (err error) { _, err = r4515.Filter(x); return }, `(&(cn=a)((sn=b)))`, 9, 1, 10, `filter component`},
+		{func(x string) (err error) { _, err = r4515.Filter(x); return }, `  (|(cn=a)(!(s_n=b)))`, 13, 1, 14, `attribute description`},
+		{func(x string) (err error) { _, err = r4515.Filter(x); return }, `(|(cn=a)(sn))`, 11, 1, 12, `'='`},
+		{func(x string) (err error) { _, err = r4515.Filter(x); return }, "(&(cn=a)\n(sn=b**))", 13, 2, 5, `substring assertion`},
+		{func(x string) (err error) { _, err = r4514.DistinguishedName(x); return }, `cn=Jesse,o\zz=People,dc=example`, 9, 1, 10, `attribute type`},
+		{func(x string) (err error) { _, err = r4514.DistinguishedName(x); return }, `cn=Jesse,ou`, 11, 1, 12, `'='`},
+		{func(x string) (err error) { _, err = r4514.DistinguishedName(x); return }, `cn=Jesse,,dc=example`, 9, 1, 10, `'='`},
+		{func(x string) (err error) { _, err = r3672.SubtreeSpecification(x); return }, `{ base "ou=People }`, 7, 1, 8, `closing quote`},
+		{func(x string) (err error) { _, err = r3672.SubtreeSpecification(x); return }, `{ base "ou=People"`, 18, 1, 19, `'}'`},
+		{func(x string) (err error) { _, err = marshalAttributeType(x); return }, "attributeTypes: ( 1.2.3.4\n\tNAME 'fake'\n\tBOGUS )", 40, 3, 2, `definition keyword or extension`},
+		{func(x string) (err error) { _, err = marshalAttributeType(x); return }, `( 1.2.3.4 NAME 'fake )`, 15, 1, 16, `closing quote`},
+		{func(x string) (err error) { _, err = marshalAttributeType(x); return }, `( 1.2.3.4 SYNTAX 1.2.3{x} )`, 17, 1, 18, `numeric OID with optional {bound}`},
+		{func(x string) (err error) { _, err = marshalAttributeType(x); return }, `( 1.2.3.4 SINGLE-VALUE COLLECTIVE )`, 23, 1, 24, ``},
+		{func(x string) (err error) { _, err = aci.TargetRuleItem(x); return }, `(target!"ldap:///anyone")`, 8, 1, 9, `'='`},
+		{func(x string) (err error) { _, err = aci.TargetRuleItem(x); return }, `(targetattr="cn"|"sn")`, 17, 1, 18, `'|'`},
+		{func(x string) (err error) { _, err = aci.BindRule(x); return }, `userdn="ldap:///anyone`, 7, 1, 8, `closing quote`},
+		{func(x string) (err error) { _, err = aci.BindRule(x); return }, `userdn=""`, 7, 1, 8, `bind rule value`},
+		{func(x string) (err error) { _, err = aci.Instruction(x); return }, `(targetattr="*")(version 3.0; acl "Anyone"; allow(read,search) userdn="ldap:///anyone;)`, 70, 1, 71, `closing quote`},
+	} {
+		err := strukt.Parse(strukt.Input)
+
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Errorf("%s[%d] failed: expected *ParseError, got %T (%v)", t.Name(), idx, err, err)
+		} else if perr.Offset != strukt.Offset || perr.Line != strukt.Line ||
+			perr.Column != strukt.Column || perr.Expected != strukt.Expected {
+			t.Errorf("%s[%d] failed:\nwant: %d (%d:%d) %s\ngot:  %d (%d:%d) %s [%v]",
+				t.Name(), idx, strukt.Offset, strukt.Line, strukt.Column, strukt.Expected,
+				perr.Offset, perr.Line, perr.Column, perr.Expected, perr)
+		}
+	}
+}
+
+func TestParseError_codecov(t *testing.T) {
+	perr := newParseError(`čččččččččččč`, 4, ``, nil)
+	if perr.Offset != 4 || perr.Column != 3 || perr.Unwrap() != nil {
+		t.Errorf("%s failed: unexpected position %#v", t.Name(), perr)
+	} else if want := `Parse error at line 1, column 3 near "čččččččččččč"`; perr.Error() != want {
+		t.Errorf("%s failed:\nwant: %s\ngot:  %s", t.Name(), want, perr)
+	}
+
+	if perr = newParseError(`abc`, -1, ``, nil); perr.Offset != 0 {
+		t.Errorf("%s failed: offset not clamped: %d", t.Name(), perr.Offset)
+	} else if perr = newParseError(`abc`, 10, ``, nil); perr.Offset != 3 {
+		t.Errorf("%s failed: offset not clamped: %d", t.Name(), perr.Offset)
+	}
+
+	if err := parseErrorAt(nil, `abc`, 1, ``); err != nil {
+		t.Errorf("%s failed: expected nil, got %v", t.Name(), err)
+	} else if err = rebaseParseError(invalidFilterErr, `abc`, 1); err != invalidFilterErr {
+		t.Errorf("%s failed: unexpected rebase of %v", t.Name(), err)
+	} else if err = parseErrorAt(invalidFilterErr, `abc`, 1, `x`); !errors.Is(err, invalidFilterErr) {
+		t.Errorf("%s failed: cause not wrapped: %v", t.Name(), err)
+	}
+}
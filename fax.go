@@ -94,7 +94,8 @@ Input x should be the ASN.1 encoded bytes of the receiver instance in
 string or []byte form.
 */
 func (r RFC4517) Fax(x any) (Fax, error) {
-	return marshalFax(x)
+	val, err := marshalFax(x)
+	return val, errorSyntax(err)
 }
 
 func fax(x any) (result Boolean) {
@@ -50,8 +50,26 @@ func marshalFilter(x any) (filter Filter, err error) {
 	if filter, err = parseSubFilter(x); filter == nil {
 		// just to avoid panics in the event
 		// the user does not check errors.
+		// Positioned parse errors are preserved.
+		var perr *ParseError
 		filter = invalidFilter{}
-		err = invalidFilterErr
+		if !erras(err, &perr) {
+			err = invalidFilterErr
+		}
+	}
+
+	return
+}
+
+/*
+assertFilter returns x if it is a [Filter] qualifier instance, else the
+[Filter] produced through the marshaling of x.
+*/
+func assertFilter(x any) (filter Filter, err error) {
+	if f, ok := x.(Filter); ok {
+		filter = f
+	} else {
+		filter, err = marshalFilter(x)
 	}
 
 	return
@@ -577,16 +595,38 @@ func (r AssertionValue) Unescaped() string {
 	return u
 }
 
+/*
+Escaped returns the receiver value escaped per [§ 3 of RFC 4515]. The
+asterisk, parentheses, backslash, NUL and all non-ASCII characters are
+escaped, while escape sequences already present (e.g.: "\2a") are
+preserved as-is. For example, "Lučić (*)" is returned as
+"Lu\c4\8di\c4\87 \28\2a\29".
+
+[§ 3 of RFC 4515]: https://datatracker.ietf.org/doc/html/rfc4515#section-3
+*/
 func (r AssertionValue) Escaped() (esc string) {
 	if len(r) > 0 {
-		esc = escapeString(string(r))
+		s := string(r)
+		bld := newStrBuilder()
+		var last int
+		for i := 0; i+2 < len(s); i++ {
+			if s[i] == '\\' && isHex(rune(s[i+1])) && isHex(rune(s[i+2])) {
+				bld.WriteString(string(escapeFilterValue(s[last:i], false)))
+				bld.WriteString(s[i : i+3])
+				i += 2
+				last = i + 1
+			}
+		}
+		bld.WriteString(string(escapeFilterValue(s[last:], false)))
+		esc = bld.String()
 	}
 
 	return
 }
 
 /*
-Set assigns x to the receiver instance.
+Set assigns x, a raw string or []byte value, to the receiver instance
+in escaped form. See [AssertionValue.Escaped] for details.
 */
 func (r *AssertionValue) Set(x any) {
 	var s string
@@ -599,7 +639,10 @@ func (r *AssertionValue) Set(x any) {
 		return
 	}
 
-	*r = AssertionValue(escapeString(s))
+	// Backslashes within x are literal, and must not
+	// be mistaken for existing escape sequences.
+	esc := AssertionValue(repAll(s, `\`, `\5c`))
+	*r = AssertionValue(esc.Escaped())
 }
 
 /*
@@ -1043,11 +1086,21 @@ func parseSubFilter(x any) (filter Filter, err error) {
 		return
 	}
 
-	if input = trimS(input); input == "" {
+	// retain the offset of the trimmed input within the
+	// raw input, used to position any parse errors.
+	raw := input
+	var lead int
+	if input, lead = trimSOffset(input); input == "" {
 		filter = FilterPresent{Desc: AttributeDescription("objectClass")}
 		return
-	} else if cntns(input, `((`) || !checkParenBalanced(input) {
-		err = endOfFilterErr
+	}
+
+	if idx := stridx(input, `((`); idx != -1 {
+		err = newParseError(raw, lead+idx+1, `filter component`, endOfFilterErr)
+		filter = invalidFilter{}
+		return
+	} else if !checkParenBalanced(input) {
+		err = rebaseParseError(checkFilterParens(input), raw, lead)
 		filter = invalidFilter{}
 		return
 	}
@@ -1063,27 +1116,39 @@ func parseSubFilter(x any) (filter Filter, err error) {
 		filter, err = parseItemFilter(input)
 	}
 
+	err = parseErrorAt(err, raw, lead, `filter`)
+
 	return
 }
 
-func parseFilterAnd(input string) (Filter, error) {
-	return parseComplexFilter(input[2:len(input)-1], "&")
+func parseFilterAnd(input string) (filter Filter, err error) {
+	if filter, err = parseComplexFilter(input[2:len(input)-1], "&"); err != nil {
+		err = rebaseParseError(err, input, 2)
+	}
+
+	return
 }
 
-func parseFilterOr(input string) (Filter, error) {
-	return parseComplexFilter(input[2:len(input)-1], "|")
+func parseFilterOr(input string) (filter Filter, err error) {
+	if filter, err = parseComplexFilter(input[2:len(input)-1], "|"); err != nil {
+		err = rebaseParseError(err, input, 2)
+	}
+
+	return
 }
 
 func parseFilterNot(input string) (filter Filter, err error) {
 	filter = invalidFilter{}
 	if len(input) < 8 {
-		err = invalidFilterErr
+		err = newParseError(input, 2, `filter`, invalidFilterErr)
 		return
 	}
 
 	var subRef Filter
 	if subRef, err = parseSubFilter(input[2 : len(input)-1]); err == nil {
 		filter = FilterNot{subRef}
+	} else {
+		err = rebaseParseError(err, input, 2)
 	}
 
 	return
@@ -1091,11 +1156,15 @@ func parseFilterNot(input string) (filter Filter, err error) {
 
 func parseComplexFilter(input, prefix string) (Filter, error) {
 	var refs []Filter
-	parts := splitFilterParts(input)
-	for _, part := range parts {
+	parts, offsets, err := splitFilterParts(input)
+	if err != nil {
+		return nil, err
+	}
+
+	for idx, part := range parts {
 		subRef, err := parseSubFilter(part)
 		if err != nil {
-			return nil, err
+			return nil, rebaseParseError(err, input, offsets[idx])
 		}
 		refs = append(refs, subRef)
 	}
@@ -1109,7 +1178,7 @@ func parseItemFilter(input string) (filter Filter, err error) {
 	filter = invalidFilter{}
 	idx := stridx(input, "=")
 	if idx == -1 {
-		err = invalidFilterErr
+		err = newParseError(input, len(trimR(input, `)`)), `'='`, invalidFilterErr)
 		return
 	}
 
@@ -1119,9 +1188,14 @@ func parseItemFilter(input string) (filter Filter, err error) {
 
 	// Verify parenthetical encapsulation is balanced
 	if err = checkParenEncaps(pre, after); err != nil {
+		err = newParseError(input, len(input), `')'`, err)
 		return
 	}
 
+	// offsets of the description and value, used
+	// to position any parse errors.
+	dpos, vpos := len(pre)-len(trimL(pre, `(`)), idx+1
+
 	// Now that we've verified them, parenthetical
 	// encapsulators will just get in the way, so
 	// let's strip them off. They will reappear
@@ -1158,6 +1232,8 @@ func parseItemFilter(input string) (filter Filter, err error) {
 			filter = FilterSubstrings{
 				Type:       AttributeDescription(pre),
 				Substrings: ssa}
+		} else {
+			err = newParseError(input, vpos, `substring assertion`, err)
 		}
 	} else if cntns(pre, ":") {
 		filter, err = parseExtensibleMatch(pre, after)
@@ -1174,6 +1250,13 @@ func parseItemFilter(input string) (filter Filter, err error) {
 		filter = invalidFilter{}
 	}
 
+	// Any errors not already positioned concern the
+	// attribute description or matching rule.
+	var perr *ParseError
+	if err != nil && !erras(err, &perr) {
+		err = newParseError(input, dpos, `attribute description`, err)
+	}
+
 	return
 }
 
@@ -1229,11 +1312,16 @@ func parseExtensibleMatch(a, b string) (filter Filter, err error) {
 /*
 BER returns the BER encoding of the receiver instance alongside an error.
 
+A zero length receiver is encoded as an empty SET, which represents the
+absolute true filter "(&)" per [RFC 4526].
+
 To decode the return *[ber.Packet], pass it to [RFC4515.Filter] as the
 input value.
+
+[RFC 4526]: https://datatracker.ietf.org/doc/html/rfc4526
 */
 func (r FilterAnd) BER() (packet *ber.Packet, err error) {
-	if r.IsZero() || r.Len() == 0 {
+	if r.IsZero() {
 		err = nilBEREncodeErr
 		return
 	}
@@ -1258,11 +1346,16 @@ func (r FilterAnd) BER() (packet *ber.Packet, err error) {
 /*
 BER returns the BER encoding of the receiver instance alongside an error.
 
+A zero length receiver is encoded as an empty SET, which represents the
+absolute false filter "(|)" per [RFC 4526].
+
 To decode the return *[ber.Packet], pass it to [RFC4515.Filter] as the
 input value.
+
+[RFC 4526]: https://datatracker.ietf.org/doc/html/rfc4526
 */
 func (r FilterOr) BER() (packet *ber.Packet, err error) {
-	if r.IsZero() || r.Len() == 0 {
+	if r.IsZero() {
 		err = nilBEREncodeErr
 		return
 	}
@@ -1776,27 +1869,69 @@ func checkFilterOIDs(t, m string) (err error) {
 	return
 }
 
-func splitFilterParts(input string) []string {
-	var parts []string
-	currentPart := newStrBuilder()
-	depth := 0
-	for _, char := range input {
-		switch char {
+/*
+checkFilterParens returns a *ParseError positioned at the first unmatched
+parenthesis within input, if any.
+*/
+func checkFilterParens(input string) (err error) {
+	var opens []int
+	for i := 0; i < len(input) && err == nil; i++ {
+		switch input[i] {
+		case '\\':
+			i++ // skip escaped octet
 		case '(':
-			if depth == 0 && currentPart.Len() > 0 {
-				parts = append(parts, currentPart.String())
-				currentPart.Reset()
+			opens = append(opens, i)
+		case ')':
+			if len(opens) == 0 {
+				err = newParseError(input, i, `'('`, unbalancedErr)
+			} else {
+				opens = opens[:len(opens)-1]
+			}
+		}
+	}
+
+	if err == nil && len(opens) > 0 {
+		err = newParseError(input, len(input), `')'`, endOfFilterErr)
+	}
+
+	return
+}
+
+/*
+splitFilterParts returns the parenthetical sub-filters found within input
+alongside their respective byte offsets. A *ParseError is returned if the
+parentheses are unbalanced, or if any non-whitespace characters appear
+between sub-filters.
+*/
+func splitFilterParts(input string) (parts []string, offsets []int, err error) {
+	var start int
+	depth := 0
+	for i := 0; i < len(input); i++ {
+		switch char := input[i]; {
+		case char == '(':
+			if depth == 0 {
+				start = i
 			}
 			depth++
-		case ')':
-			depth--
+		case char == ')':
+			if depth--; depth < 0 {
+				err = newParseError(input, i, `'('`, unbalancedErr)
+				return
+			} else if depth == 0 {
+				parts = append(parts, input[start:i+1])
+				offsets = append(offsets, start)
+			}
+		case depth == 0 && !isSpace(rune(char)):
+			err = newParseError(input, i, `'('`, invalidFilterErr)
+			return
 		}
-		currentPart.WriteRune(char)
 	}
-	if currentPart.Len() > 0 {
-		parts = append(parts, currentPart.String())
+
+	if depth > 0 {
+		err = newParseError(input, len(input), `')'`, endOfFilterErr)
 	}
-	return parts
+
+	return
 }
 
 func unmarshalFilterBER(packet *ber.Packet) (filter Filter, err error) {
@@ -1857,7 +1992,9 @@ func unmarshalSetFilterBER(packet *ber.Packet) (filter Filter, err error) {
 	var filters []Filter
 	and := packet.Description == "and"
 
-	if lct == 0 || packet.Description == "invalid" || !(and || packet.Description == "or") {
+	// Note that a zero length SET is permitted, as this
+	// represents absolute true or false per RFC 4526.
+	if packet.Description == "invalid" || !(and || packet.Description == "or") {
 		err = emptyFilterSetErr
 		return
 	}
@@ -0,0 +1,539 @@
+package dirsyn
+
+/*
+filter_canon.go contains Filter canonicalization and simplification methods.
+*/
+
+import (
+	"sort"
+)
+
+/*
+CanonicalFilter returns a canonical instance of [Filter] alongside an error
+following an attempt to normalize and simplify x.
+
+The input value x may be an existing [Filter] qualifier instance, or any
+value accepted by [RFC4515.Filter].
+
+Canonicalization consists of the following operations:
+
+  - Nested [FilterAnd] and [FilterOr] instances are flattened
+  - Duplicate operands are removed
+  - Operands are sorted in a deterministic manner
+  - [FilterNot] instances are pushed inward through [FilterAnd] and [FilterOr] instances per De Morgan's laws
+  - Double negation is removed
+  - Absolute true "(&)" and absolute false "(|)" instances are folded per [RFC 4526]
+  - [AttributeDescription] types are lowercased and [AttributeOption] instances are lowercased and sorted
+  - [AssertionValue] instances are re-escaped in a consistent manner
+
+If a non-nil *[SubschemaSubentry] instance is provided, attribute types and
+matching rules known to it are resolved to their respective numeric OIDs, and
+assertion values are normalized according to the effective matching rule, e.g.:
+"caseIgnoreMatch" values are lowercased and have insignificant spaces removed.
+
+Note that all of the above transformations are safe under the three-valued
+logic described in [§ 4.5.1.7 of RFC 4511], thus the return instance shall
+always evaluate in the same manner as the input instance.  Item filters are
+never negated in any way other than by way of [FilterNot] encapsulation.
+
+The string and BER encodings of the return instance are suitable for use as
+cache keys, in that cosmetically different -- yet equivalent -- input values
+shall produce identical canonical output.
+
+[RFC 4526]: https://datatracker.ietf.org/doc/html/rfc4526
+[§ 4.5.1.7 of RFC 4511]: https://datatracker.ietf.org/doc/html/rfc4511#section-4.5.1.7
+*/
+func (r RFC4515) CanonicalFilter(x any, schema ...*SubschemaSubentry) (filter Filter, err error) {
+	filter = invalidFilter{}
+
+	var in Filter
+	if in, err = assertFilter(x); err != nil {
+		return
+	}
+
+	var sch *SubschemaSubentry
+	if len(schema) > 0 {
+		sch = schema[0]
+	}
+
+	var out Filter
+	if out, err = canonicalFilter(in, false, sch); err == nil {
+		filter = out
+	}
+
+	return
+}
+
+/*
+canonicalFilter is the recursive handler for [RFC4515.CanonicalFilter].
+The neg input value indicates whether the filter is to be negated.
+*/
+func canonicalFilter(filter Filter, neg bool, schema *SubschemaSubentry) (out Filter, err error) {
+	switch tv := filter.(type) {
+	case FilterNot:
+		if tv.IsZero() {
+			err = invalidFilterErr
+			break
+		}
+		// Double negation cancels out, regardless
+		// of the three-valued logic in force.
+		out, err = canonicalFilter(tv.Filter, !neg, schema)
+	case FilterAnd:
+		// De Morgan: !(&(a)(b)) == (|(!(a))(!(b)))
+		out, err = canonicalFilterSet([]Filter(tv), !neg, neg, schema)
+	case FilterOr:
+		// De Morgan: !(|(a)(b)) == (&(!(a))(!(b)))
+		out, err = canonicalFilterSet([]Filter(tv), neg, neg, schema)
+	case nil, invalidFilter:
+		err = invalidFilterErr
+	default:
+		if out, err = canonicalItemFilter(filter, schema); err == nil && neg {
+			out = FilterNot{out}
+		}
+	}
+
+	return
+}
+
+/*
+canonicalFilterSet flattens, folds, deduplicates and sorts the input slices
+of [Filter]. If and is true, a [FilterAnd] is produced, else a [FilterOr].
+*/
+func canonicalFilterSet(filters []Filter, and, neg bool, schema *SubschemaSubentry) (out Filter, err error) {
+	var (
+		terms []Filter
+		seen  map[string]bool = make(map[string]bool)
+		fold  bool
+	)
+
+	for i := 0; i < len(filters) && !fold && err == nil; i++ {
+		var sub Filter
+		if sub, err = canonicalFilter(filters[i], neg, schema); err != nil {
+			break
+		}
+
+		var kids []Filter
+		switch tv := sub.(type) {
+		case FilterAnd:
+			if !and {
+				if len(tv) == 0 {
+					// absolute true within an OR
+					fold = true
+					break
+				}
+				kids = []Filter{tv}
+			} else {
+				kids = []Filter(tv) // flatten; (&) is a no-op
+			}
+		case FilterOr:
+			if and {
+				if len(tv) == 0 {
+					// absolute false within an AND
+					fold = true
+					break
+				}
+				kids = []Filter{tv}
+			} else {
+				kids = []Filter(tv) // flatten; (|) is a no-op
+			}
+		default:
+			kids = []Filter{tv}
+		}
+
+		for _, kid := range kids {
+			if key := kid.String(); !seen[key] {
+				seen[key] = true
+				terms = append(terms, kid)
+			}
+		}
+	}
+
+	if err != nil {
+		return
+	}
+
+	if fold {
+		// An AND containing absolute false is absolute false,
+		// and an OR containing absolute true is absolute true.
+		if and {
+			out = FilterOr{}
+		} else {
+			out = FilterAnd{}
+		}
+		return
+	}
+
+	sort.SliceStable(terms, func(i, j int) bool {
+		return terms[i].String() < terms[j].String()
+	})
+
+	if len(terms) == 1 {
+		out = terms[0]
+	} else if and {
+		out = FilterAnd(terms)
+	} else {
+		out = FilterOr(terms)
+	}
+
+	return
+}
+
+/*
+canonicalItemFilter returns the canonical form of the input item filter.
+*/
+func canonicalItemFilter(filter Filter, schema *SubschemaSubentry) (out Filter, err error) {
+	out = invalidFilter{}
+
+	if filter.IsZero() {
+		err = invalidFilterErr
+		return
+	}
+
+	switch tv := filter.(type) {
+	case FilterPresent:
+		desc, _ := canonicalAttributeDescription(tv.Desc, schema)
+		out = FilterPresent{Desc: desc}
+	case FilterEqualityMatch:
+		desc, at := canonicalAttributeDescription(tv.Desc, schema)
+		var val AssertionValue
+		if val, err = canonicalFilterValue(tv.Value, filterEqualityRule(at)); err == nil {
+			out = FilterEqualityMatch{Desc: desc, Value: val}
+		}
+	case FilterApproximateMatch:
+		desc, at := canonicalAttributeDescription(tv.Desc, schema)
+		var val AssertionValue
+		if val, err = canonicalFilterValue(tv.Value, filterEqualityRule(at)); err == nil {
+			out = FilterApproximateMatch{Desc: desc, Value: val}
+		}
+	case FilterGreaterOrEqual:
+		desc, at := canonicalAttributeDescription(tv.Desc, schema)
+		var val AssertionValue
+		if val, err = canonicalFilterValue(tv.Value, filterOrderingRule(at)); err == nil {
+			out = FilterGreaterOrEqual{Desc: desc, Value: val}
+		}
+	case FilterLessOrEqual:
+		desc, at := canonicalAttributeDescription(tv.Desc, schema)
+		var val AssertionValue
+		if val, err = canonicalFilterValue(tv.Value, filterOrderingRule(at)); err == nil {
+			out = FilterLessOrEqual{Desc: desc, Value: val}
+		}
+	case FilterSubstrings:
+		desc, at := canonicalAttributeDescription(tv.Type, schema)
+		var ssa SubstringAssertion
+		if ssa, err = canonicalSubstringAssertion(tv.Substrings, filterSubstringRule(at)); err == nil {
+			if ssa.IsZero() {
+				// All components were insignificant, e.g.:
+				// (telephoneNumber=* - *), which is merely
+				// a presence assertion in disguise.
+				out = FilterPresent{Desc: desc}
+			} else {
+				out = FilterSubstrings{Type: desc, Substrings: ssa}
+			}
+		}
+	case FilterExtensibleMatch:
+		out, err = canonicalExtensibleMatch(tv, schema)
+	default:
+		err = invalidFilterErr
+	}
+
+	return
+}
+
+func canonicalExtensibleMatch(filter FilterExtensibleMatch, schema *SubschemaSubentry) (out Filter, err error) {
+	out = invalidFilter{}
+
+	var (
+		at   *AttributeType
+		mr   *MatchingRule
+		_out FilterExtensibleMatch = FilterExtensibleMatch{
+			DNAttributes: filter.DNAttributes,
+		}
+	)
+
+	if len(filter.Type) > 0 {
+		_out.Type, at = canonicalAttributeDescription(filter.Type, schema)
+	}
+
+	if id := filter.MatchingRule.String(); len(id) > 0 {
+		_out.MatchingRule = MatchingRuleID(lc(id))
+		if schema != nil {
+			var idx int
+			if mr, idx = schema.MatchingRule(id); idx != -1 {
+				_out.MatchingRule = MatchingRuleID(mr.NumericOID)
+			} else {
+				mr = nil
+			}
+		}
+	} else {
+		mr = filterEqualityRule(at)
+	}
+
+	if _out.MatchValue, err = canonicalFilterValue(filter.MatchValue, mr); err == nil {
+		out = _out
+	}
+
+	return
+}
+
+/*
+canonicalAttributeDescription returns the canonical form of the input
+[AttributeDescription] alongside the resolved *[AttributeType], if the
+schema is non-nil and the type is known to it.
+
+The attribute type is lowercased, or replaced with its numeric OID if
+it was resolved. Attribute options are lowercased, deduplicated and
+sorted, as their order is not significant per § 2.5 of RFC 4512.
+*/
+func canonicalAttributeDescription(desc AttributeDescription, schema *SubschemaSubentry) (out AttributeDescription, at *AttributeType) {
+	typ := lc(desc.Type())
+	if schema != nil {
+		if def, idx := schema.AttributeType(typ); idx != -1 {
+			at = def
+			typ = def.NumericOID
+		}
+	}
+
+	var opts []string
+	seen := make(map[string]bool)
+	for _, opt := range desc.Options() {
+		if o := lc(opt.String()); !seen[o] {
+			seen[o] = true
+			opts = append(opts, o)
+		}
+	}
+	sort.Strings(opts)
+
+	out = AttributeDescription(join(append([]string{typ}, opts...), `;`))
+
+	return
+}
+
+func filterEqualityRule(at *AttributeType) (mr *MatchingRule) {
+	if at != nil {
+		mr = at.EffectiveEquality()
+	}
+
+	return
+}
+
+func filterOrderingRule(at *AttributeType) (mr *MatchingRule) {
+	if at != nil {
+		if mr = at.EffectiveOrdering(); mr == nil {
+			mr = at.EffectiveEquality()
+		}
+	}
+
+	return
+}
+
+func filterSubstringRule(at *AttributeType) (mr *MatchingRule) {
+	if at != nil {
+		if mr = at.EffectiveSubstring(); mr == nil {
+			mr = at.EffectiveEquality()
+		}
+	}
+
+	return
+}
+
+/*
+canonicalSubstringAssertion returns the canonical form of the input
+[SubstringAssertion]. Empty "any" components are discarded.
+*/
+func canonicalSubstringAssertion(ssa SubstringAssertion, mr *MatchingRule) (out SubstringAssertion, err error) {
+	if ssa.IsZero() {
+		err = invalidFilterErr
+		return
+	}
+
+	if len(ssa.Initial) > 0 {
+		if out.Initial, err = canonicalSubstringValue(ssa.Initial, mr); err != nil {
+			return
+		}
+	}
+
+	if len(ssa.Final) > 0 {
+		if out.Final, err = canonicalSubstringValue(ssa.Final, mr); err != nil {
+			return
+		}
+	}
+
+	var anys []string
+	for _, a := range split(string(ssa.Any), `*`) {
+		if len(a) == 0 {
+			continue
+		}
+		var av AssertionValue
+		if av, err = canonicalSubstringValue(AssertionValue(a), mr); err != nil {
+			return
+		} else if len(av) > 0 {
+			anys = append(anys, string(av))
+		}
+	}
+	out.Any = AssertionValue(join(anys, `*`))
+
+	return
+}
+
+func canonicalSubstringValue(val AssertionValue, mr *MatchingRule) (out AssertionValue, err error) {
+	var raw string
+	if raw, err = unescapeFilterValue(val); err == nil {
+		if mr != nil {
+			if prep, found := substringValuePreparers[lc(mr.Identifier())]; found {
+				raw = prep(raw)
+			}
+		}
+		out = escapeFilterValue(raw, false)
+	}
+
+	return
+}
+
+/*
+canonicalFilterValue returns the canonical form of the input [AssertionValue],
+normalized per the input *[MatchingRule] (if non-nil and recognized).
+*/
+func canonicalFilterValue(val AssertionValue, mr *MatchingRule) (out AssertionValue, err error) {
+	var raw string
+	if raw, err = unescapeFilterValue(val); err == nil {
+		if mr != nil {
+			if prep, found := assertionValuePreparers[lc(mr.Identifier())]; found {
+				raw = prep(raw)
+			}
+		}
+		out = escapeFilterValue(raw, false)
+	}
+
+	return
+}
+
+/*
+unescapeFilterValue returns the raw string form of the input escaped
+[AssertionValue] alongside an error, which is only non-nil if a bogus
+escape sequence is encountered.
+*/
+func unescapeFilterValue(val AssertionValue) (raw string, err error) {
+	bld := newStrBuilder()
+	for i := 0; i < len(val); i++ {
+		if val[i] != '\\' {
+			bld.WriteByte(val[i])
+			continue
+		}
+
+		if i+2 >= len(val) || !isHex(rune(val[i+1])) || !isHex(rune(val[i+2])) {
+			err = invalidFilterErr
+			return
+		}
+
+		b, _ := hexdec(string(val[i+1 : i+3]))
+		bld.Write(b)
+		i += 2
+	}
+	raw = bld.String()
+
+	return
+}
+
+/*
+escapeFilterValue returns an [AssertionValue] bearing the escaped form
+of raw. Per § 3 of RFC 4515, the asterisk, parentheses, backslash and
+NUL characters are always escaped, as are all other control characters.
+
+If utf8 is false, all non-ASCII octets are also escaped so as to produce
+a consistent (ASCII-only) representation. Otherwise valid multi-byte
+UTF-8 sequences are left intact, and only invalid octets are escaped.
+*/
+func escapeFilterValue(raw string, utf8 bool) AssertionValue {
+	const hexchars = `0123456789abcdef`
+
+	bld := newStrBuilder()
+	for len(raw) > 0 {
+		c := raw[0]
+		size := 1
+		if utf8 && rune(c) > maxASCII {
+			_, size = decRune([]byte(raw))
+		}
+
+		switch {
+		case size > 1:
+			bld.WriteString(raw[:size])
+		case c == '*', c == '(', c == ')', c == '\\', c < 0x20, c == 0x7f, rune(c) > maxASCII:
+			bld.WriteByte('\\')
+			bld.WriteByte(hexchars[c>>4])
+			bld.WriteByte(hexchars[c&0xf])
+		default:
+			bld.WriteByte(c)
+		}
+		raw = raw[size:]
+	}
+
+	return AssertionValue(bld.String())
+}
+
+func prepareCaseIgnoreValue(x string) string { return lc(condenseWHSP(x)) }
+func prepareCaseExactValue(x string) string  { return condenseWHSP(x) }
+
+func prepareNumericStringValue(x string) string {
+	x, _, _ = prepareNumericStringAssertion(x, ``)
+	return x
+}
+
+func prepareTelephoneNumberValue(x string) string {
+	x, _, _ = prepareTelephoneNumberAssertion(x, ``)
+	return x
+}
+
+func prepareIntegerValue(x string) string {
+	if i, ok := newBigInt(0).SetString(trimS(x), 10); ok {
+		x = i.String()
+	}
+
+	return x
+}
+
+func prepareBooleanValue(x string) string { return uc(trimS(x)) }
+func prepareLowerValue(x string) string   { return lc(trimS(x)) }
+
+/*
+assertionValuePreparers contains functions used to normalize assertion
+values per the equality or ordering matching rule in force. Keys are the
+lowercased names of matching rules.
+*/
+var assertionValuePreparers map[string]func(string) string = map[string]func(string) string{
+	`caseignorematch`:                     prepareCaseIgnoreValue,
+	`caseignoreorderingmatch`:             prepareCaseIgnoreValue,
+	`caseignoreia5match`:                  prepareCaseIgnoreValue,
+	`caseignorelistmatch`:                 prepareCaseIgnoreValue,
+	`directorystringfirstcomponentmatch`:  prepareCaseIgnoreValue,
+	`wordmatch`:                           prepareCaseIgnoreValue,
+	`keywordmatch`:                        prepareCaseIgnoreValue,
+	`caseexactmatch`:                      prepareCaseExactValue,
+	`caseexactorderingmatch`:              prepareCaseExactValue,
+	`caseexactia5match`:                   prepareCaseExactValue,
+	`numericstringmatch`:                  prepareNumericStringValue,
+	`numericstringorderingmatch`:          prepareNumericStringValue,
+	`telephonenumbermatch`:                prepareTelephoneNumberValue,
+	`integermatch`:                        prepareIntegerValue,
+	`integerorderingmatch`:                prepareIntegerValue,
+	`integerfirstcomponentmatch`:          prepareIntegerValue,
+	`booleanmatch`:                        prepareBooleanValue,
+	`objectidentifiermatch`:               prepareLowerValue,
+	`objectidentifierfirstcomponentmatch`: prepareLowerValue,
+	`uuidmatch`:                           prepareLowerValue,
+	`uuidorderingmatch`:                   prepareLowerValue,
+}
+
+/*
+substringValuePreparers contains functions used to normalize substring
+assertion components per the substrings matching rule in force.  Unlike
+the equality counterparts, insignificant spaces are left intact, as the
+boundaries of each component are significant.
+*/
+var substringValuePreparers map[string]func(string) string = map[string]func(string) string{
+	`caseignorematch`:                lc,
+	`caseignoresubstringsmatch`:      lc,
+	`caseignoreia5substringsmatch`:   lc,
+	`caseignorelistsubstringsmatch`:  lc,
+	`numericstringsubstringsmatch`:   prepareNumericStringValue,
+	`telephonenumbersubstringsmatch`: prepareTelephoneNumberValue,
+}
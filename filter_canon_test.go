@@ -0,0 +1,158 @@
+package dirsyn
+
+import (
+	"fmt"
+	"testing"
+)
+
+/*
+This example demonstrates the means for producing a canonical [Filter]
+suitable for use as a cache key.
+*/
+func ExampleRFC4515_CanonicalFilter() {
+	var r RFC4515
+	f, err := r.CanonicalFilter(`(&(|(SN=Lučić)(&))(!(|(objectClass=Person)(cn=Jesse))))`)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Println(f)
+	// Output: (&(!(cn=Jesse))(!(objectclass=Person)))
+}
+
+/*
+This example demonstrates the means for producing a canonical [Filter]
+with the aid of a *[SubschemaSubentry] instance, which allows attribute
+types to be resolved and assertion values to be normalized.
+*/
+func ExampleRFC4515_CanonicalFilter_withSchema() {
+	var r RFC4515
+	f, err := r.CanonicalFilter(`(|(commonName=  Jesse   Coretta )(CN=jesse coretta))`, exampleSchema)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Println(f)
+	// Output: (2.5.4.3=jesse coretta)
+}
+
+func TestRFC4515_CanonicalFilter(t *testing.T) {
+	var r RFC4515
+
+	for idx, strukt := range []struct {
+		Input  string
+		Schema bool
+		Want   string
+	}{
+		{`(cn=Jesse)`, false, `(cn=Jesse)`},
+		{`(CN;Lang-EN;binary=Jesse)`, false, `(cn;binary;lang-en=Jesse)`},
+		{`(&(b=2)(a=1)(b=2))`, false, `(&(a=1)(b=2))`},
+		{`(&(a=1)(&(b=2)(&(c=3))))`, false, `(&(a=1)(b=2)(c=3))`},
+		{`(|(a=1)(|(b=2)(c=3)))`, false, `(|(a=1)(b=2)(c=3))`},
+		{`(!(!(a=1)))`, false, `(a=1)`},
+		{`(!(&(a=1)(b=2)))`, false, `(|(!(a=1))(!(b=2)))`},
+		{`(!(|(a=1)(!(b=2))))`, false, `(&(!(a=1))(b=2))`},
+		{`(&(a=1)(&))`, false, `(a=1)`},
+		{`(&(a=1)(|))`, false, `(|)`},
+		{`(|(a=1)(&))`, false, `(&)`},
+		{`(|(a=1)(|))`, false, `(a=1)`},
+		{`(!(&(&)))`, false, `(|)`},
+		{`(!(|(|)))`, false, `(&)`},
+		{`(&)`, false, `(&)`},
+		{`(a=\2A\28)`, false, `(a=\2a\28)`},
+		{`(a=Lučić)`, false, `(a=Lu\c4\8di\c4\87)`},
+		{`(cn=*Jesse*Coretta*)`, false, `(cn=*Jesse*Coretta*)`},
+		{`(cn:dn:CaseExactMatch:=Jesse)`, false, `(cn:dn:caseexactmatch:=Jesse)`},
+		{`(cn=JESSE)`, true, `(2.5.4.3=jesse)`},
+		{`(cn>=JESSE)`, true, `(2.5.4.3>=jesse)`},
+		{`(cn=JE*S*SE)`, true, `(2.5.4.3=je*s*se)`},
+		{`(governingStructureRule=+0001)`, true, `(2.5.21.10=1)`},
+		{`(cn:caseExactMatch:=Jesse  Coretta)`, true, `(2.5.4.3:2.5.13.5:=Jesse Coretta)`},
+		{`(unknownType=Value)`, true, `(unknowntype=Value)`},
+	} {
+		var f Filter
+		var err error
+		if strukt.Schema {
+			f, err = r.CanonicalFilter(strukt.Input, exampleSchema)
+		} else {
+			f, err = r.CanonicalFilter(strukt.Input)
+		}
+
+		if err != nil {
+			t.Errorf("%s[%d] failed: %v", t.Name(), idx, err)
+			continue
+		} else if got := f.String(); got != strukt.Want {
+			t.Errorf("%s[%d] failed:\nwant: %s\ngot:  %s",
+				t.Name(), idx, strukt.Want, got)
+			continue
+		}
+
+		// Canonicalization must be idempotent, and the
+		// BER encoding must survive a round trip.
+		var f2 Filter
+		if f2, err = r.CanonicalFilter(f); err != nil || f2.String() != f.String() {
+			t.Errorf("%s[%d] idempotency failed: %v [%s]", t.Name(), idx, err, f2)
+			continue
+		}
+
+		pkt, err := f.BER()
+		if err != nil {
+			t.Errorf("%s[%d] BER encoding failed: %v", t.Name(), idx, err)
+			continue
+		}
+
+		if f2, err = r.Filter(pkt); err != nil || f2.String() != f.String() {
+			t.Errorf("%s[%d] BER decoding failed: %v [%s]", t.Name(), idx, err, f2)
+		}
+	}
+}
+
+func TestRFC4515_CanonicalFilter_codecov(t *testing.T) {
+	var r RFC4515
+
+	// Cosmetically different, but equivalent, filters
+	// must produce identical canonical representations.
+	a, _ := r.CanonicalFilter(`(&(objectClass=person)(|(sn=Smith)(SN=smith)))`, exampleSchema)
+	b, _ := r.CanonicalFilter(`(&(|(sn=SMITH))(objectclass=PERSON))`, exampleSchema)
+	if a.String() != b.String() {
+		t.Errorf("%s failed: mismatched canonical filters:\n%s\n%s",
+			t.Name(), a, b)
+	}
+
+	for _, bogus := range []any{
+		invalidFilter{},
+		FilterNot{},
+		FilterAnd{invalidFilter{}},
+		FilterEqualityMatch{},
+		FilterEqualityMatch{Desc: AttributeDescription(`cn`), Value: AssertionValue(`bad\zz`)},
+		FilterSubstrings{Type: AttributeDescription(`cn`)},
+		FilterSubstrings{Type: AttributeDescription(`cn`), Substrings: SubstringAssertion{Initial: AssertionValue(`\`)}},
+		FilterSubstrings{Type: AttributeDescription(`cn`), Substrings: SubstringAssertion{Final: AssertionValue(`\`)}},
+		FilterSubstrings{Type: AttributeDescription(`cn`), Substrings: SubstringAssertion{Any: AssertionValue(`\`)}},
+		FilterExtensibleMatch{Type: AttributeDescription(`cn`), MatchValue: AssertionValue(`\`)},
+		`(cn=bogus`,
+	} {
+		if _, err := r.CanonicalFilter(bogus); err == nil {
+			t.Errorf("%s failed: expected error for %#v, got nil", t.Name(), bogus)
+		}
+	}
+
+	if f, _ := r.CanonicalFilter(FilterNot{FilterAnd{}}); f.String() != `(|)` {
+		t.Errorf("%s failed: want (|), got %s", t.Name(), f)
+	}
+
+	f, _ := r.CanonicalFilter(`(telephoneNumber=* - *)`, exampleSchema)
+	if f.Choice() != `present` {
+		t.Errorf("%s failed: want present, got %s", t.Name(), f.Choice())
+	}
+
+	for _, val := range []string{`1 2 3`, `+1-555-1212`, `x`, ` true `, `ABC`} {
+		prepareNumericStringValue(val)
+		prepareTelephoneNumberValue(val)
+		prepareIntegerValue(val)
+		prepareBooleanValue(val)
+		prepareLowerValue(val)
+	}
+}
@@ -0,0 +1,839 @@
+package dirsyn
+
+/*
+filter_compile.go contains Filter compilation methods, which produce
+reusable predicates for evaluation against many entries.
+*/
+
+import (
+	"math/big"
+	"sort"
+	"time"
+)
+
+/*
+FilterEntry implements a simple entry abstraction for use with instances
+of [CompiledFilter]. Keys are lowercased attribute descriptions, which
+may bear options (e.g.: "cn;lang-en"), and values are raw (unescaped)
+attribute values.
+
+Keys are expected to be lowercase. Use of the [FilterEntry.Set] method
+guarantees this.
+*/
+type FilterEntry map[string][]string
+
+/*
+Set assigns the input values to the receiver under the lowercased form
+of desc, replacing any values previously present.
+*/
+func (r FilterEntry) Set(desc string, values ...string) {
+	if r != nil {
+		r[lc(desc)] = values
+	}
+}
+
+/*
+CompiledFilter implements a reusable predicate produced through the
+compilation of a [Filter] by way of [RFC4515.CompileFilter].
+
+Instances of this type are immutable once produced, and are safe for
+concurrent use by multiple goroutines.
+*/
+type CompiledFilter struct {
+	filter Filter
+	root   filterNode
+}
+
+/*
+CompileFilter returns an instance of [CompiledFilter] alongside an error
+following an attempt to compile x with the aid of schema.
+
+The input value x may be an existing [Filter] qualifier instance, or any
+value accepted by [RFC4515.Filter]. A non-nil *[SubschemaSubentry] is
+required, as all attribute types and matching rules are resolved ahead
+of time.
+
+Compilation involves the following:
+
+  - Canonicalization per [RFC4515.CanonicalFilter]
+  - Resolution of attribute types, including all subordinate types, and matching rules
+  - Normalization of assertion values per the matching rule in force
+  - Pre-splitting of substring assertion components
+  - Reordering of [FilterAnd] and [FilterOr] operands such that the least costly are evaluated first
+
+Items which cannot be evaluated, such as those bearing an unknown attribute
+type or an assertion value which violates the syntax of the matching rule
+in force, are compiled into constant UNDEFINED results, per [§ 4.5.1.7 of
+RFC 4511].
+
+Note that the dnAttributes field of [FilterExtensibleMatch] is not honored,
+as [FilterEntry] does not convey a distinguished name.
+
+[§ 4.5.1.7 of RFC 4511]: https://datatracker.ietf.org/doc/html/rfc4511#section-4.5.1.7
+*/
+func (r RFC4515) CompileFilter(x any, schema *SubschemaSubentry) (cf CompiledFilter, err error) {
+	if schema == nil {
+		err = nilInstanceErr
+		return
+	}
+
+	var f Filter
+	if f, err = r.CanonicalFilter(x, schema); err == nil {
+		cmp := filterCompiler{schema: schema}
+		cf = CompiledFilter{
+			filter: f,
+			root:   cmp.compile(f),
+		}
+	}
+
+	return
+}
+
+/*
+Match returns a Boolean value indicative of whether the receiver matched
+entry. An UNDEFINED result, as described in [CompiledFilter.Evaluate], is
+treated as false.
+*/
+func (r CompiledFilter) Match(entry FilterEntry) bool {
+	return !r.IsZero() && r.root.eval(entry) == filterYes
+}
+
+/*
+Evaluate returns an instance of [Boolean] following an evaluation of entry
+using the receiver instance. The return value is TRUE, FALSE or UNDEFINED
+(zero) per the three-valued logic of [§ 4.5.1.7 of RFC 4511].
+
+[§ 4.5.1.7 of RFC 4511]: https://datatracker.ietf.org/doc/html/rfc4511#section-4.5.1.7
+*/
+func (r CompiledFilter) Evaluate(entry FilterEntry) (result Boolean) {
+	if !r.IsZero() {
+		result = r.root.eval(entry).boolean()
+	}
+
+	return
+}
+
+/*
+Filter returns the canonical [Filter] from which the receiver was compiled.
+*/
+func (r CompiledFilter) Filter() (f Filter) {
+	f = invalidFilter{}
+	if !r.IsZero() {
+		f = r.filter
+	}
+
+	return
+}
+
+/*
+String returns the string representation of the canonical [Filter] from
+which the receiver was compiled.
+*/
+func (r CompiledFilter) String() (s string) {
+	if !r.IsZero() {
+		s = r.filter.String()
+	}
+
+	return
+}
+
+/*
+IsZero returns a Boolean value indicative of a nil receiver state.
+*/
+func (r CompiledFilter) IsZero() bool { return r.root == nil }
+
+/*
+filterNode is a private interface qualified by all compiled filter
+components.
+*/
+type filterNode interface {
+	eval(FilterEntry) filterTruth
+	cost() int
+}
+
+/*
+filterCompiler produces filterNode instances from canonical filters.
+*/
+type filterCompiler struct {
+	schema *SubschemaSubentry
+}
+
+func (r filterCompiler) compile(f Filter) (node filterNode) {
+	switch tv := f.(type) {
+	case FilterAnd:
+		node = r.compileSet(tv, true)
+	case FilterOr:
+		node = r.compileSet(tv, false)
+	case FilterNot:
+		node = filterNotNode{r.compile(tv.Filter)}
+	case FilterPresent:
+		node = filterPresentNode{r.attribute(tv.Desc, false)}
+	case FilterEqualityMatch:
+		node = r.compileEquality(tv.Desc, tv.Value)
+	case FilterApproximateMatch:
+		// No approximate matching algorithm is in use, thus
+		// equality matching is used in its place, as is the
+		// custom among directory implementations.
+		node = r.compileEquality(tv.Desc, tv.Value)
+	case FilterGreaterOrEqual:
+		node = r.compileOrdering(tv.Desc, tv.Value, true)
+	case FilterLessOrEqual:
+		node = r.compileOrdering(tv.Desc, tv.Value, false)
+	case FilterSubstrings:
+		node = r.compileSubstrings(tv)
+	case FilterExtensibleMatch:
+		node = r.compileExtensible(tv)
+	default:
+		node = filterConstNode(filterUnknown)
+	}
+
+	return
+}
+
+func (r filterCompiler) compileSet(filters []Filter, and bool) filterNode {
+	nodes := make([]filterNode, len(filters))
+	for i := 0; i < len(filters); i++ {
+		nodes[i] = r.compile(filters[i])
+	}
+
+	// Cheapest operands first, so as to maximize
+	// the benefit of short-circuit evaluation.
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodes[i].cost() < nodes[j].cost()
+	})
+
+	return filterSetNode{and: and, nodes: nodes}
+}
+
+func (r filterCompiler) compileEquality(desc AttributeDescription, value AssertionValue) filterNode {
+	attr := r.attribute(desc, true)
+	if attr.at == nil {
+		return filterConstNode(filterUnknown)
+	}
+
+	if vm, ok := r.valueMatcher(filterEqualityRule(attr.at), value); ok {
+		return filterEqualityNode{attr: attr, vm: vm}
+	}
+
+	return filterConstNode(filterUnknown)
+}
+
+func (r filterCompiler) compileOrdering(desc AttributeDescription, value AssertionValue, ge bool) filterNode {
+	attr := r.attribute(desc, true)
+	if attr.at == nil {
+		return filterConstNode(filterUnknown)
+	}
+
+	mr := attr.at.EffectiveOrdering()
+	if mr == nil {
+		return filterConstNode(filterUnknown)
+	}
+
+	raw, _ := unescapeFilterValue(value)
+	if !r.verifyAssertion(mr, raw) {
+		return filterConstNode(filterUnknown)
+	}
+
+	node := filterOrderingNode{attr: attr, ge: ge, value: raw}
+	switch lc(mr.Identifier()) {
+	case `integerorderingmatch`:
+		i, ok := newBigInt(0).SetString(raw, 10)
+		if !ok {
+			return filterConstNode(filterUnknown)
+		}
+		node.kind = filterOrderInteger
+		node.bint = i
+		if i.IsInt64() {
+			node.i64, node.small = i.Int64(), true
+		}
+	case `generalizedtimeorderingmatch`:
+		gt, err := marshalGenTime(raw)
+		if err != nil {
+			return filterConstNode(filterUnknown)
+		}
+		node.kind = filterOrderTime
+		node.time = gt.Cast()
+	default:
+		prep, found := assertionValuePreparers[lc(mr.Identifier())]
+		if !found {
+			return filterConstNode(filterUnknown)
+		}
+		node.kind = filterOrderString
+		node.prep = prep
+	}
+
+	return node
+}
+
+func (r filterCompiler) compileSubstrings(f FilterSubstrings) filterNode {
+	attr := r.attribute(f.Type, true)
+	if attr.at == nil {
+		return filterConstNode(filterUnknown)
+	}
+
+	mr := attr.at.EffectiveSubstring()
+	if mr == nil {
+		return filterConstNode(filterUnknown)
+	}
+
+	node := filterSubstringsNode{
+		attr: attr,
+		any:  splitSubstringAny(f.Substrings.Any),
+		prep: func(x string) string { return x },
+	}
+	node.initial, _ = unescapeFilterValue(f.Substrings.Initial)
+	node.final, _ = unescapeFilterValue(f.Substrings.Final)
+	if prep, found := substringValuePreparers[lc(mr.Identifier())]; found {
+		node.prep = prep
+	}
+
+	return node
+}
+
+func (r filterCompiler) compileExtensible(f FilterExtensibleMatch) filterNode {
+	var (
+		attr filterAttribute
+		mr   *MatchingRule
+	)
+
+	if len(f.Type) > 0 {
+		if attr = r.attribute(f.Type, true); attr.at == nil {
+			return filterConstNode(filterUnknown)
+		}
+	}
+
+	if id := f.MatchingRule.String(); len(id) > 0 {
+		var idx int
+		if mr, idx = r.schema.MatchingRule(id); idx == -1 {
+			return filterConstNode(filterUnknown)
+		}
+	} else if mr = filterEqualityRule(attr.at); mr == nil {
+		return filterConstNode(filterUnknown)
+	}
+
+	applies, ok := r.ruleApplies(attr.at, mr)
+	if !ok {
+		return filterConstNode(filterUnknown)
+	} else if attr.at == nil {
+		attr.types = applies
+	}
+
+	if vm, ok := r.valueMatcher(mr, f.MatchValue); ok {
+		return filterEqualityNode{attr: attr, vm: vm, extensible: true}
+	}
+
+	return filterConstNode(filterUnknown)
+}
+
+/*
+valueMatcher returns a filterValueMatcher instance for use in equality
+assertions per mr, alongside a Boolean value indicative of success.
+*/
+func (r filterCompiler) valueMatcher(mr *MatchingRule, value AssertionValue) (vm filterValueMatcher, ok bool) {
+	if mr == nil {
+		return
+	}
+
+	raw, _ := unescapeFilterValue(value)
+	if !r.verifyAssertion(mr, raw) {
+		return
+	}
+
+	// Only rules of the EQUALITY kind are suitable.
+	if funk, found := matchingRuleAssertions[mr.NumericOID]; found {
+		eq, isEq := funk.(EqualityRuleAssertion)
+		if !isEq {
+			return
+		}
+		vm.funk = eq
+	}
+
+	vm.value = raw
+	switch name := lc(mr.Identifier()); name {
+	case `caseignorematch`, `caseignoreia5match`:
+		vm.condense, vm.fold = true, true
+	case `caseexactmatch`, `caseexactia5match`:
+		vm.condense = true
+	default:
+		// A preparer is cheaper than the rule, if available.
+		if prep, found := assertionValuePreparers[name]; found {
+			vm.prep = prep
+		}
+	}
+	ok = vm.condense || vm.prep != nil || vm.funk != nil
+
+	return
+}
+
+/*
+ruleApplies returns a Boolean value indicative of whether mr may be used
+in an extensible match involving at, alongside the names of all types
+to which mr applies if at is nil.
+
+Per § 4.5.1.7.7 of RFC 4511, the rule must be applicable to the type in
+question. When no type was specified, the matchingRuleUse definition
+alone conveys which types apply. A type's own EQUALITY rule is always
+applicable.
+*/
+func (r filterCompiler) ruleApplies(at *AttributeType, mr *MatchingRule) (applies map[string]struct{}, ok bool) {
+	if eq := filterEqualityRule(at); eq != nil && eq.NumericOID == mr.NumericOID {
+		ok = true
+	} else if mru, idx := r.schema.MatchingRuleUse(mr.NumericOID); idx != -1 {
+		applies = r.applicable(mru)
+		if ok = at == nil; !ok {
+			_, ok = applies[lc(at.NumericOID)]
+		}
+	}
+
+	return
+}
+
+/*
+verifyAssertion returns false only if the raw assertion value was found
+to violate the assertion syntax of mr. Syntaxes which cannot be verified
+do not result in failure.
+*/
+func (r filterCompiler) verifyAssertion(mr *MatchingRule, raw string) bool {
+	if syn, idx := r.schema.LDAPSyntax(mr.Syntax); idx != -1 {
+		return !syn.Verify(raw).False()
+	}
+
+	return true
+}
+
+/*
+attribute returns a filterAttribute instance describing desc, which is
+expected to be in canonical form. If known is true, unknown types are
+not permitted and the returned instance bears a nil *[AttributeType].
+*/
+func (r filterCompiler) attribute(desc AttributeDescription, known bool) (attr filterAttribute) {
+	for _, opt := range desc.Options() {
+		attr.opts = append(attr.opts, lc(opt.String()))
+	}
+
+	if at, idx := r.schema.AttributeType(desc.Type()); idx != -1 {
+		attr.at = at
+		attr.types = make(map[string]struct{})
+		r.typeNames(at, attr.types)
+	} else if !known {
+		attr.types = map[string]struct{}{lc(desc.Type()): {}}
+	}
+
+	return
+}
+
+/*
+typeNames populates names with the lowercased names and numeric OID of
+at, as well as those of all of its subordinate types, recursively.
+*/
+func (r filterCompiler) typeNames(at *AttributeType, names map[string]struct{}) {
+	if _, seen := names[lc(at.NumericOID)]; seen {
+		return
+	}
+
+	names[lc(at.NumericOID)] = struct{}{}
+	for _, name := range at.Name {
+		names[lc(name)] = struct{}{}
+	}
+
+	subs := at.SubordinateTypes()
+	for i := 0; i < subs.Len(); i++ {
+		r.typeNames(subs.Index(i), names)
+	}
+}
+
+/*
+applicable returns the lowercased names and numeric OIDs of all types
+(and their subordinate types) to which the input matchingRuleUse applies.
+*/
+func (r filterCompiler) applicable(mru *MatchingRuleUse) (names map[string]struct{}) {
+	names = make(map[string]struct{})
+	for _, term := range mru.Applies {
+		if at, idx := r.schema.AttributeType(term); idx != -1 {
+			r.typeNames(at, names)
+		}
+	}
+
+	return
+}
+
+/*
+filterAttribute describes the set of attribute descriptions within a
+[FilterEntry] to which a compiled item filter applies.
+*/
+type filterAttribute struct {
+	at    *AttributeType
+	types map[string]struct{}
+	opts  []string
+}
+
+/*
+matches returns a Boolean value indicative of whether the lowercased
+[FilterEntry] key applies to the receiver instance. The type must be
+among those resolved during compilation, and all required options must
+be present.
+*/
+func (r filterAttribute) matches(key string) bool {
+	base := key
+	if i := idxr(key, ';'); i != -1 {
+		base = key[:i]
+	}
+
+	if _, found := r.types[base]; !found {
+		return false
+	}
+
+	for _, opt := range r.opts {
+		if !hasKeyOption(key[len(base):], opt) {
+			return false
+		}
+	}
+
+	return true
+}
+
+/*
+hasKeyOption returns a Boolean value indicative of whether the ";"
+delimited options string contains opt.
+*/
+func hasKeyOption(options, opt string) bool {
+	for len(options) > 0 {
+		options = options[1:] // skip ';'
+		end := idxr(options, ';')
+		if end == -1 {
+			end = len(options)
+		}
+		if options[:end] == opt {
+			return true
+		}
+		options = options[end:]
+	}
+
+	return false
+}
+
+/*
+filterConstNode implements a constant filterNode, used for absolute
+true/false and undefined items.
+*/
+type filterConstNode filterTruth
+
+func (r filterConstNode) eval(_ FilterEntry) filterTruth { return filterTruth(r) }
+func (r filterConstNode) cost() int                      { return 0 }
+
+/*
+filterSetNode implements the AND and OR filterNode, with short-circuit
+evaluation.
+*/
+type filterSetNode struct {
+	and   bool
+	nodes []filterNode
+}
+
+func (r filterSetNode) eval(entry FilterEntry) filterTruth {
+	// Absolute true (AND) or absolute false (OR) per RFC 4526
+	// when no nodes are present.
+	stop, result := filterNo, filterYes
+	if !r.and {
+		stop, result = filterYes, filterNo
+	}
+
+	for _, node := range r.nodes {
+		switch t := node.eval(entry); t {
+		case stop:
+			return stop
+		case filterUnknown:
+			result = filterUnknown
+		}
+	}
+
+	return result
+}
+
+func (r filterSetNode) cost() (c int) {
+	for _, node := range r.nodes {
+		c += node.cost()
+	}
+
+	return
+}
+
+/*
+filterNotNode implements the NOT filterNode. UNDEFINED results remain
+UNDEFINED.
+*/
+type filterNotNode struct {
+	node filterNode
+}
+
+func (r filterNotNode) eval(entry FilterEntry) (t filterTruth) {
+	switch t = r.node.eval(entry); t {
+	case filterYes:
+		t = filterNo
+	case filterNo:
+		t = filterYes
+	}
+
+	return
+}
+
+func (r filterNotNode) cost() int { return r.node.cost() }
+
+/*
+filterPresentNode implements the presence filterNode.
+*/
+type filterPresentNode struct {
+	attr filterAttribute
+}
+
+func (r filterPresentNode) eval(entry FilterEntry) filterTruth {
+	for key, vals := range entry {
+		if len(vals) > 0 && r.attr.matches(key) {
+			return filterYes
+		}
+	}
+
+	return filterNo
+}
+
+func (r filterPresentNode) cost() int { return 1 }
+
+/*
+filterValueMatcher performs an equality comparison of an attribute value
+with a pre-normalized assertion value.
+*/
+type filterValueMatcher struct {
+	value    string
+	condense bool
+	fold     bool
+	prep     func(string) string
+	funk     EqualityRuleAssertion
+}
+
+func (r filterValueMatcher) match(val string) (t filterTruth) {
+	if r.condense {
+		if eq, ok := condensedEqual(val, r.value, r.fold); ok {
+			t = filterNo
+			if eq {
+				t = filterYes
+			}
+			return
+		}
+
+		// Fallback for non-ASCII and unusual whitespace.
+		cond := condenseWHSP(val)
+		if r.fold {
+			cond = lc(cond)
+		}
+		t = filterNo
+		if cond == r.value {
+			t = filterYes
+		}
+	} else if r.prep != nil {
+		t = filterNo
+		if r.prep(val) == r.value {
+			t = filterYes
+		}
+	} else if result, err := r.funk(val, r.value); err == nil {
+		if result.True() {
+			t = filterYes
+		} else if result.False() {
+			t = filterNo
+		}
+	}
+
+	return
+}
+
+/*
+condensedEqual compares val to the pre-condensed (and, if fold is true,
+pre-lowercased) assertion without allocation. The second return value
+is false if val contains non-ASCII octets or whitespace other than space,
+tab or newline, in which case the comparison must be made the slow way.
+*/
+func condensedEqual(val, assert string, fold bool) (eq, ok bool) {
+	var j int
+	var pending bool
+	for i := 0; i < len(val); i++ {
+		c := val[i]
+		switch {
+		case rune(c) > maxASCII, c == '\r', c == '\v', c == '\f':
+			return
+		case c == ' ', c == '\t', c == '\n':
+			pending = j > 0
+			continue
+		}
+
+		if pending {
+			if j >= len(assert) || assert[j] != ' ' {
+				return false, true
+			}
+			j++
+			pending = false
+		}
+
+		if fold && 'A' <= c && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+
+		if j >= len(assert) || assert[j] != c {
+			return false, true
+		}
+		j++
+	}
+
+	return j == len(assert), true
+}
+
+/*
+filterEqualityNode implements the equality, approximate and extensible
+match filterNode.
+*/
+type filterEqualityNode struct {
+	attr       filterAttribute
+	vm         filterValueMatcher
+	extensible bool
+}
+
+func (r filterEqualityNode) eval(entry FilterEntry) filterTruth {
+	result := filterNo
+	for key, vals := range entry {
+		if !r.attr.matches(key) {
+			continue
+		}
+		for _, val := range vals {
+			switch r.vm.match(val) {
+			case filterYes:
+				return filterYes
+			case filterUnknown:
+				result = filterUnknown
+			}
+		}
+	}
+
+	return result
+}
+
+func (r filterEqualityNode) cost() (c int) {
+	if c = 2; r.extensible {
+		c = 6
+	}
+
+	return
+}
+
+const (
+	filterOrderString uint8 = iota
+	filterOrderInteger
+	filterOrderTime
+)
+
+/*
+filterOrderingNode implements the greaterOrEqual and lessOrEqual
+filterNode.
+*/
+type filterOrderingNode struct {
+	attr  filterAttribute
+	ge    bool
+	kind  uint8
+	value string
+	prep  func(string) string
+	bint  *big.Int
+	i64   int64
+	small bool
+	time  time.Time
+}
+
+func (r filterOrderingNode) eval(entry FilterEntry) filterTruth {
+	result := filterNo
+	for key, vals := range entry {
+		if !r.attr.matches(key) {
+			continue
+		}
+		for _, val := range vals {
+			cmp, ok := r.compare(val)
+			if !ok {
+				result = filterUnknown
+			} else if (r.ge && cmp >= 0) || (!r.ge && cmp <= 0) {
+				return filterYes
+			}
+		}
+	}
+
+	return result
+}
+
+/*
+compare returns the comparison of val with the assertion value (-1, 0
+or 1), alongside a Boolean value indicative of comparability.
+*/
+func (r filterOrderingNode) compare(val string) (cmp int, ok bool) {
+	switch r.kind {
+	case filterOrderInteger:
+		if r.small {
+			var i int
+			var err error
+			if i, err = atoi(val); err == nil {
+				ok = true
+				switch x := int64(i); {
+				case x < r.i64:
+					cmp = -1
+				case x > r.i64:
+					cmp = 1
+				}
+				return
+			}
+		}
+		var i *big.Int
+		if i, ok = newBigInt(0).SetString(trimS(val), 10); ok {
+			cmp = i.Cmp(r.bint)
+		}
+	case filterOrderTime:
+		if gt, err := marshalGenTime(val); err == nil {
+			cmp, ok = gt.Cast().Compare(r.time), true
+		}
+	default:
+		switch v := r.prep(val); {
+		case v < r.value:
+			cmp = -1
+		case v > r.value:
+			cmp = 1
+		}
+		ok = true
+	}
+
+	return
+}
+
+func (r filterOrderingNode) cost() int { return 3 }
+
+/*
+filterSubstringsNode implements the substrings filterNode, with all
+components pre-split and pre-normalized.
+*/
+type filterSubstringsNode struct {
+	attr    filterAttribute
+	initial string
+	any     []string
+	final   string
+	prep    func(string) string
+}
+
+func (r filterSubstringsNode) eval(entry FilterEntry) filterTruth {
+	for key, vals := range entry {
+		if !r.attr.matches(key) {
+			continue
+		}
+		for _, val := range vals {
+			v := r.prep(val)
+			if len(v) >= len(r.initial)+len(r.final) &&
+				substringPatternMatch(v, r.initial, r.any, r.final) {
+				return filterYes
+			}
+		}
+	}
+
+	return filterNo
+}
+
+func (r filterSubstringsNode) cost() int { return 4 + len(r.any) }
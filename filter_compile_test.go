@@ -0,0 +1,183 @@
+package dirsyn
+
+import (
+	"fmt"
+	"testing"
+)
+
+/*
+This example demonstrates the means for compiling a [Filter] into a
+reusable predicate, which is then used to evaluate an entry.
+*/
+func ExampleRFC4515_CompileFilter() {
+	var r RFC4515
+	cf, err := r.CompileFilter(`(&(objectClass=person)(|(sn=Coretta)(cn=Jess*)))`, exampleSchema)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	entry := make(FilterEntry)
+	entry.Set(`objectClass`, `top`, `person`)
+	entry.Set(`cn`, `Jesse Coretta`)
+
+	fmt.Println(cf.Match(entry))
+	// Output: true
+}
+
+/*
+This example demonstrates the three-valued evaluation of an entry by way
+of an instance of [CompiledFilter].
+*/
+func ExampleCompiledFilter_Evaluate() {
+	var r RFC4515
+	cf, err := r.CompileFilter(`(!(unknownType=value))`, exampleSchema)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Println(cf.Evaluate(FilterEntry{`cn`: {`Jesse`}}))
+	// Output: UNDEFINED
+}
+
+func TestRFC4515_CompileFilter(t *testing.T) {
+	var r RFC4515
+	defer registerFilterTestType(t)()
+
+	entry := make(FilterEntry)
+	entry.Set(`objectClass`, `top`, `person`)
+	entry.Set(`cn`, `Jesse Coretta`)
+	entry.Set(`cn;lang-en`, `Jess`)
+	entry.Set(`sn`, `Coretta`)
+	entry.Set(`telephoneNumber`, `+1 555 1212`)
+	entry.Set(`modifyTimestamp`, `20240601000000Z`)
+	entry.Set(`filterTestCount`, `42`)
+
+	for idx, strukt := range []struct {
+		Input string
+		Want  string
+	}{
+		{`(cn=jesse   CORETTA)`, `TRUE`},
+		{`(cn~=jesse coretta)`, `TRUE`},
+		{`(name=Jesse Coretta)`, `TRUE`},
+		{`(commonName=Jess)`, `TRUE`},
+		{`(cn;lang-en=Jesse Coretta)`, `FALSE`},
+		{`(cn;LANG-EN=jess)`, `TRUE`},
+		{`(sn=Smith)`, `FALSE`},
+		{`(objectClass=PERSON)`, `TRUE`},
+		{`(telephoneNumber=+15551212)`, `TRUE`},
+		{`(cn=*)`, `TRUE`},
+		{`(mail=*)`, `FALSE`},
+		{`(bogusType=*)`, `FALSE`},
+		{`(bogusType=x)`, `UNDEFINED`},
+		{`(!(bogusType=x))`, `UNDEFINED`},
+		{`(|(bogusType=x)(sn=Coretta))`, `TRUE`},
+		{`(&(bogusType=x)(sn=Smith))`, `FALSE`},
+		{`(&(bogusType=x)(sn=Coretta))`, `UNDEFINED`},
+		{`(cn=Jes*Cor*)`, `TRUE`},
+		{`(cn=*TTA)`, `TRUE`},
+		{`(cn=*xyz*)`, `FALSE`},
+		{`(modifyTimestamp=2024*)`, `UNDEFINED`},
+		{`(filterTestCount=4*)`, `UNDEFINED`},
+		{`(cn>=a)`, `UNDEFINED`},
+		{`(modifyTimestamp>=20240101000000Z)`, `TRUE`},
+		{`(modifyTimestamp<=20240101000000Z)`, `FALSE`},
+		{`(filterTestCount=042)`, `TRUE`},
+		{`(filterTestCount>=10)`, `TRUE`},
+		{`(filterTestCount<=10)`, `FALSE`},
+		{`(filterTestCount>=99999999999999999999999)`, `FALSE`},
+		{`(filterTestCount>=abc)`, `UNDEFINED`},
+		{`(cn:=jesse coretta)`, `TRUE`},
+		{`(cn:caseIgnoreMatch:=JESSE CORETTA)`, `TRUE`},
+		{`(cn:caseExactMatch:=Jesse Coretta)`, `UNDEFINED`},
+		{`(:caseIgnoreMatch:=coretta)`, `TRUE`},
+		{`(:bogusRule:=x)`, `UNDEFINED`},
+		{`(&)`, `TRUE`},
+		{`(|)`, `FALSE`},
+	} {
+		cf, err := r.CompileFilter(strukt.Input, exampleSchema)
+		if err != nil {
+			t.Errorf("%s[%d] failed: %v", t.Name(), idx, err)
+			continue
+		}
+
+		if got := cf.Evaluate(entry).String(); got != strukt.Want {
+			t.Errorf("%s[%d] failed: %s\nwant: %s\ngot:  %s",
+				t.Name(), idx, strukt.Input, strukt.Want, got)
+		} else if cf.Match(entry) != (strukt.Want == `TRUE`) {
+			t.Errorf("%s[%d] failed: %s: Match and Evaluate disagree",
+				t.Name(), idx, strukt.Input)
+		}
+	}
+}
+
+func TestCompiledFilter_codecov(t *testing.T) {
+	var r RFC4515
+
+	if _, err := r.CompileFilter(`(cn=*)`, nil); err == nil {
+		t.Errorf("%s failed: expected error, got nil", t.Name())
+	}
+	if _, err := r.CompileFilter(`(cn=bogus`, exampleSchema); err == nil {
+		t.Errorf("%s failed: expected error, got nil", t.Name())
+	}
+
+	var cf CompiledFilter
+	if !cf.IsZero() || cf.Match(nil) || !cf.Evaluate(nil).IsZero() ||
+		cf.String() != `` || cf.Filter().String() != `` {
+		t.Errorf("%s failed: bogus zero state", t.Name())
+	}
+
+	var entry FilterEntry
+	entry.Set(`cn`, `ignored`)
+
+	cf, _ = r.CompileFilter(`(|(cn=Lu*)(cn=lučić))`, exampleSchema)
+	if cf.String() != `(|(2.5.4.3=lu*)(2.5.4.3=lu\c4\8di\c4\87))` ||
+		cf.Filter().Choice() != `or` {
+		t.Errorf("%s failed: unexpected filter %s", t.Name(), cf)
+	}
+	if !cf.Match(FilterEntry{`cn`: {`LUČIĆ`}}) {
+		t.Errorf("%s failed: non-ASCII match failed", t.Name())
+	}
+
+	for _, strukt := range []struct {
+		Value, Assert string
+		Fold, Eq, OK  bool
+	}{
+		{`  Jesse  Coretta `, `jesse coretta`, true, true, true},
+		{`Jesse Coretta`, `jesse coretta`, false, false, true},
+		{`Jesse`, `jesse coretta`, true, false, true},
+		{`Jesse Coretta`, `jesse`, true, false, true},
+		{`Jesse Coretta`, `jessecoretta`, true, false, true},
+		{"Jesse\rCoretta", `jesse coretta`, true, false, false},
+	} {
+		if eq, ok := condensedEqual(strukt.Value, strukt.Assert, strukt.Fold); eq != strukt.Eq || ok != strukt.OK {
+			t.Errorf("%s failed: %q vs. %q: want %t/%t, got %t/%t", t.Name(),
+				strukt.Value, strukt.Assert, strukt.Eq, strukt.OK, eq, ok)
+		}
+	}
+
+	if hasKeyOption(`;lang-en;binary`, `lang-fr`) || !hasKeyOption(`;lang-en;binary`, `binary`) {
+		t.Errorf("%s failed: option lookup error", t.Name())
+	}
+
+	var vm filterValueMatcher
+	vm.funk = EqualityRuleAssertion(integerMatch)
+	vm.value = `1`
+	vm.match(`x`)
+	vm.match(`2`)
+	vm.match(`1`)
+
+	var cmp filterCompiler = filterCompiler{schema: exampleSchema}
+	cmp.valueMatcher(nil, AssertionValue(`x`))
+	if mr, idx := exampleSchema.MatchingRule(`caseIgnoreSubstringsMatch`); idx != -1 {
+		cmp.valueMatcher(mr, AssertionValue(`x`))
+	}
+	cmp.compile(invalidFilter{})
+	cmp.compileExtensible(FilterExtensibleMatch{Type: AttributeDescription(`bogus`)})
+	cmp.compileExtensible(FilterExtensibleMatch{MatchValue: AssertionValue(`x`)})
+	cmp.compileSubstrings(FilterSubstrings{Type: AttributeDescription(`bogus`)})
+	cmp.compileOrdering(AttributeDescription(`bogus`), AssertionValue(`x`), true)
+	cmp.compileOrdering(AttributeDescription(`modifyTimestamp`), AssertionValue(`x`), true)
+	cmp.compileOrdering(AttributeDescription(`uid`), AssertionValue(`x`), true)
+}
@@ -0,0 +1,420 @@
+package dirsyn
+
+/*
+filter_json.go contains JSON encoding and decoding methods for instances
+of Filter.
+*/
+
+import (
+	"encoding/json"
+)
+
+/*
+FilterJSON returns an instance of [Filter] alongside an error following
+an attempt to decode the JSON representation of a filter, as produced
+through the MarshalJSON method of any [Filter] qualifier type.
+
+Each JSON object bears a single key, which is the string CHOICE name of
+the [Filter] in question (see the Choice method of [Filter]). For example:
+
+	{"and":[
+	  {"equalityMatch":{"attributeDesc":"objectClass","assertionValue":"person"}},
+	  {"or":[
+	    {"substrings":{"type":"cn","substrings":[{"initial":"Jes"},{"any":"s"},{"final":"e"}]}},
+	    {"not":{"present":"sn"}},
+	    {"extensibleMatch":{"matchingRule":"caseExactMatch","type":"cn","matchValue":"Jesse","dnAttributes":false}}
+	  ]}
+	]}
+
+The greaterOrEqual, lessOrEqual and approxMatch CHOICEs bear the same form
+as equalityMatch.
+
+Assertion values are conveyed in their raw (unescaped) form. Values which
+are not valid UTF-8 are conveyed as base64 by way of an object, such as:
+
+	{"equalityMatch":{"attributeDesc":"objectGUID","assertionValue":{"base64":"AQID"}}}
+
+Each item is subjected to the same validation as that performed by the
+string parser, thus an error is returned for any JSON representation of
+a filter which could not be expressed as a string.
+*/
+func (r RFC4515) FilterJSON(data []byte) (filter Filter, err error) {
+	var raw json.RawMessage = data
+	if filter, err = unmarshalFilterJSON(raw); err != nil {
+		filter = invalidFilter{}
+	}
+
+	return
+}
+
+/*
+filterJSONItem is the JSON representation of an AttributeValueAssertion.
+*/
+type filterJSONItem struct {
+	Desc  string          `json:"attributeDesc"`
+	Value json.RawMessage `json:"assertionValue"`
+}
+
+/*
+filterJSONSubstrings is the JSON representation of a SubstringFilter.
+*/
+type filterJSONSubstrings struct {
+	Type       string                       `json:"type"`
+	Substrings []map[string]json.RawMessage `json:"substrings"`
+}
+
+/*
+filterJSONExtensible is the JSON representation of a MatchingRuleAssertion.
+*/
+type filterJSONExtensible struct {
+	MatchingRule string          `json:"matchingRule,omitempty"`
+	Type         string          `json:"type,omitempty"`
+	MatchValue   json.RawMessage `json:"matchValue"`
+	DNAttributes bool            `json:"dnAttributes"`
+}
+
+/*
+filterJSONBinary is the JSON representation of an assertion value which
+is not valid UTF-8.
+*/
+type filterJSONBinary struct {
+	Base64 []byte `json:"base64"`
+}
+
+func filterJSONChoice(choice string, value any) ([]byte, error) {
+	return json.Marshal(map[string]any{choice: value})
+}
+
+/*
+marshalFilterJSONValue returns the JSON representation of the input
+escaped [AssertionValue].
+*/
+func marshalFilterJSONValue(val AssertionValue) (data json.RawMessage, err error) {
+	var raw string
+	if raw, err = unescapeFilterValue(val); err == nil {
+		if utf8OK(raw) {
+			data, err = json.Marshal(raw)
+		} else {
+			data, err = json.Marshal(filterJSONBinary{Base64: []byte(raw)})
+		}
+	}
+
+	return
+}
+
+/*
+unmarshalFilterJSONValue returns the escaped [AssertionValue] described
+by the input JSON value, which is either a string or a base64 object.
+*/
+func unmarshalFilterJSONValue(data json.RawMessage) (val AssertionValue, err error) {
+	var raw string
+	if err = json.Unmarshal(data, &raw); err != nil {
+		var bin filterJSONBinary
+		if err = json.Unmarshal(data, &bin); err != nil {
+			return
+		}
+		raw = string(bin.Base64)
+	}
+
+	val = escapeFilterValue(raw, true)
+
+	return
+}
+
+/*
+MarshalJSON returns the JSON representation of the receiver instance
+alongside an error. See [RFC4515.FilterJSON] for details.
+*/
+func (r FilterAnd) MarshalJSON() ([]byte, error) {
+	return filterJSONChoice(r.Choice(), append([]Filter{}, r...))
+}
+
+/*
+MarshalJSON returns the JSON representation of the receiver instance
+alongside an error. See [RFC4515.FilterJSON] for details.
+*/
+func (r FilterOr) MarshalJSON() ([]byte, error) {
+	return filterJSONChoice(r.Choice(), append([]Filter{}, r...))
+}
+
+/*
+MarshalJSON returns the JSON representation of the receiver instance
+alongside an error. See [RFC4515.FilterJSON] for details.
+*/
+func (r FilterNot) MarshalJSON() ([]byte, error) {
+	if r.Filter == nil {
+		return nil, nilInstanceErr
+	}
+	return filterJSONChoice(r.Choice(), r.Filter)
+}
+
+/*
+MarshalJSON returns the JSON representation of the receiver instance
+alongside an error. See [RFC4515.FilterJSON] for details.
+*/
+func (r FilterPresent) MarshalJSON() ([]byte, error) {
+	return filterJSONChoice(r.Choice(), r.Desc.String())
+}
+
+/*
+MarshalJSON returns the JSON representation of the receiver instance
+alongside an error. See [RFC4515.FilterJSON] for details.
+*/
+func (r FilterEqualityMatch) MarshalJSON() ([]byte, error) {
+	return marshalFilterJSONItem(r.Choice(), r.Desc, r.Value)
+}
+
+/*
+MarshalJSON returns the JSON representation of the receiver instance
+alongside an error. See [RFC4515.FilterJSON] for details.
+*/
+func (r FilterGreaterOrEqual) MarshalJSON() ([]byte, error) {
+	return marshalFilterJSONItem(r.Choice(), r.Desc, r.Value)
+}
+
+/*
+MarshalJSON returns the JSON representation of the receiver instance
+alongside an error. See [RFC4515.FilterJSON] for details.
+*/
+func (r FilterLessOrEqual) MarshalJSON() ([]byte, error) {
+	return marshalFilterJSONItem(r.Choice(), r.Desc, r.Value)
+}
+
+/*
+MarshalJSON returns the JSON representation of the receiver instance
+alongside an error. See [RFC4515.FilterJSON] for details.
+*/
+func (r FilterApproximateMatch) MarshalJSON() ([]byte, error) {
+	return marshalFilterJSONItem(r.Choice(), r.Desc, r.Value)
+}
+
+func marshalFilterJSONItem(choice string, desc AttributeDescription, val AssertionValue) (data []byte, err error) {
+	item := filterJSONItem{Desc: desc.String()}
+	if item.Value, err = marshalFilterJSONValue(val); err == nil {
+		data, err = filterJSONChoice(choice, item)
+	}
+
+	return
+}
+
+/*
+MarshalJSON returns the JSON representation of the receiver instance
+alongside an error. See [RFC4515.FilterJSON] for details.
+*/
+func (r FilterSubstrings) MarshalJSON() (data []byte, err error) {
+	sub := filterJSONSubstrings{Type: r.Type.String()}
+	add := func(kind string, val AssertionValue) {
+		if err == nil {
+			var v json.RawMessage
+			if v, err = marshalFilterJSONValue(val); err == nil {
+				sub.Substrings = append(sub.Substrings, map[string]json.RawMessage{kind: v})
+			}
+		}
+	}
+
+	if len(r.Substrings.Initial) > 0 {
+		add(`initial`, r.Substrings.Initial)
+	}
+	for _, a := range split(string(r.Substrings.Any), `*`) {
+		if len(a) > 0 {
+			add(`any`, AssertionValue(a))
+		}
+	}
+	if len(r.Substrings.Final) > 0 {
+		add(`final`, r.Substrings.Final)
+	}
+
+	if err == nil {
+		data, err = filterJSONChoice(r.Choice(), sub)
+	}
+
+	return
+}
+
+/*
+MarshalJSON returns the JSON representation of the receiver instance
+alongside an error. See [RFC4515.FilterJSON] for details.
+*/
+func (r FilterExtensibleMatch) MarshalJSON() (data []byte, err error) {
+	ext := filterJSONExtensible{
+		MatchingRule: r.MatchingRule.String(),
+		Type:         r.Type.String(),
+		DNAttributes: r.DNAttributes,
+	}
+	if ext.MatchValue, err = marshalFilterJSONValue(r.MatchValue); err == nil {
+		data, err = filterJSONChoice(r.Choice(), ext)
+	}
+
+	return
+}
+
+/*
+MarshalJSON returns an error, as invalid filters cannot be represented.
+*/
+func (r invalidFilter) MarshalJSON() ([]byte, error) {
+	return nil, invalidFilterErr
+}
+
+func unmarshalFilterJSON(data json.RawMessage) (filter Filter, err error) {
+	var obj map[string]json.RawMessage
+	if err = json.Unmarshal(data, &obj); err != nil {
+		return
+	} else if len(obj) != 1 {
+		err = errorTxt("Filter JSON object must contain exactly one CHOICE")
+		return
+	}
+
+	for choice, body := range obj {
+		switch choice {
+		case `and`, `or`:
+			filter, err = unmarshalFilterJSONSet(choice, body)
+		case `not`:
+			var sub Filter
+			if sub, err = unmarshalFilterJSON(body); err == nil {
+				filter = FilterNot{sub}
+			}
+		case `present`:
+			var desc string
+			if err = json.Unmarshal(body, &desc); err == nil {
+				filter, err = checkFilterJSONItem(FilterPresent{Desc: AttributeDescription(desc)})
+			}
+		case `equalityMatch`, `greaterOrEqual`, `lessOrEqual`, `approxMatch`:
+			filter, err = unmarshalFilterJSONItem(choice, body)
+		case `substrings`:
+			filter, err = unmarshalFilterJSONSubstrings(body)
+		case `extensibleMatch`:
+			filter, err = unmarshalFilterJSONExtensible(body)
+		default:
+			err = errorTxt("Unknown filter JSON CHOICE " + choice)
+		}
+	}
+
+	return
+}
+
+func unmarshalFilterJSONSet(choice string, data json.RawMessage) (filter Filter, err error) {
+	var raws []json.RawMessage
+	if err = json.Unmarshal(data, &raws); err != nil {
+		return
+	}
+
+	set := make([]Filter, len(raws))
+	for i := 0; i < len(raws); i++ {
+		if set[i], err = unmarshalFilterJSON(raws[i]); err != nil {
+			return
+		}
+	}
+
+	if choice == `and` {
+		filter = FilterAnd(set)
+	} else {
+		filter = FilterOr(set)
+	}
+
+	return
+}
+
+func unmarshalFilterJSONItem(choice string, data json.RawMessage) (filter Filter, err error) {
+	var item filterJSONItem
+	if err = json.Unmarshal(data, &item); err != nil {
+		return
+	}
+
+	var val AssertionValue
+	if val, err = unmarshalFilterJSONValue(item.Value); err != nil {
+		return
+	}
+
+	desc := AttributeDescription(item.Desc)
+	switch choice {
+	case `equalityMatch`:
+		filter = FilterEqualityMatch{Desc: desc, Value: val}
+	case `greaterOrEqual`:
+		filter = FilterGreaterOrEqual{Desc: desc, Value: val}
+	case `lessOrEqual`:
+		filter = FilterLessOrEqual{Desc: desc, Value: val}
+	default:
+		filter = FilterApproximateMatch{Desc: desc, Value: val}
+	}
+
+	return checkFilterJSONItem(filter)
+}
+
+func unmarshalFilterJSONSubstrings(data json.RawMessage) (filter Filter, err error) {
+	var sub filterJSONSubstrings
+	if err = json.Unmarshal(data, &sub); err != nil {
+		return
+	}
+
+	// Per § 4.5.1 of RFC 4511, there shall be at most one
+	// initial component, which must be first, and at most
+	// one final component, which must be last.
+	var ssa SubstringAssertion
+	var anys []string
+	for i, comp := range sub.Substrings {
+		for kind, raw := range comp {
+			var val AssertionValue
+			if val, err = unmarshalFilterJSONValue(raw); err != nil {
+				return
+			}
+
+			switch {
+			case len(comp) != 1:
+				err = invalidFilterErr
+			case kind == `initial` && i == 0:
+				ssa.Initial = val
+			case kind == `final` && i == len(sub.Substrings)-1:
+				ssa.Final = val
+			case kind == `any`:
+				anys = append(anys, string(val))
+			default:
+				err = errorTxt("Misplaced or unknown substrings component " + kind)
+			}
+
+			if err != nil {
+				return
+			}
+		}
+	}
+	ssa.Any = AssertionValue(join(anys, `*`))
+
+	return checkFilterJSONItem(FilterSubstrings{Type: AttributeDescription(sub.Type), Substrings: ssa})
+}
+
+func unmarshalFilterJSONExtensible(data json.RawMessage) (filter Filter, err error) {
+	var ext filterJSONExtensible
+	if err = json.Unmarshal(data, &ext); err != nil {
+		return
+	}
+
+	var val AssertionValue
+	if val, err = unmarshalFilterJSONValue(ext.MatchValue); err == nil {
+		filter, err = checkFilterJSONItem(FilterExtensibleMatch{
+			MatchingRule: MatchingRuleID(ext.MatchingRule),
+			Type:         AttributeDescription(ext.Type),
+			MatchValue:   val,
+			DNAttributes: ext.DNAttributes,
+		})
+	}
+
+	return
+}
+
+/*
+checkFilterJSONItem subjects the input item filter to the validation of
+the string parser, returning an error if the string representation of
+the filter does not parse back into a filter of the same CHOICE.
+*/
+func checkFilterJSONItem(filter Filter) (Filter, error) {
+	parsed, err := parseSubFilter(filter.String())
+	if err == nil && (parsed == nil || parsed.Choice() != filter.Choice() ||
+		parsed.String() != filter.String()) {
+		err = invalidFilterErr
+	}
+
+	if err != nil {
+		filter = nil
+	}
+
+	return filter, err
+}
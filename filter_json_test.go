@@ -0,0 +1,140 @@
+package dirsyn
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+/*
+This example demonstrates the means for producing the JSON representation
+of a [Filter].
+*/
+func ExampleFilterAnd_MarshalJSON() {
+	var r RFC4515
+	f, _ := r.Filter(`(&(objectClass=person)(|(cn=Jes*e)(!(sn=*))))`)
+
+	data, err := json.Marshal(f)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Println(string(data))
+	// Output: {"and":[{"equalityMatch":{"attributeDesc":"objectClass","assertionValue":"person"}},{"or":[{"substrings":{"type":"cn","substrings":[{"initial":"Jes"},{"final":"e"}]}},{"not":{"present":"sn"}}]}]}
+}
+
+/*
+This example demonstrates the means for decoding the JSON representation
+of a [Filter].
+*/
+func ExampleRFC4515_FilterJSON() {
+	var r RFC4515
+	f, err := r.FilterJSON([]byte(`{"or":[
+		{"equalityMatch":{"attributeDesc":"cn","assertionValue":"Jesse (*)"}},
+		{"equalityMatch":{"attributeDesc":"objectGUID","assertionValue":{"base64":"/wAB"}}}
+	]}`))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Println(f)
+	// Output: (|(cn=Jesse \28\2a\29)(objectGUID=\ff\00\01))
+}
+
+func TestRFC4515_FilterJSON(t *testing.T) {
+	var r RFC4515
+
+	for idx, input := range []string{
+		`(cn=Jesse)`,
+		`(cn=)`,
+		`(cn~=Jesse)`,
+		`(n>=5)`,
+		`(n<=5)`,
+		`(cn=*)`,
+		`(cn;lang-en=Jesse)`,
+		`(sn=Lučić)`,
+		`(cn=\2a\28\29\5c)`,
+		`(objectGUID=\ff\00\01)`,
+		`(cn=Jes*)`,
+		`(cn=*sse)`,
+		`(cn=*e*s*)`,
+		`(cn=J*e*s*e)`,
+		`(cn:caseExactMatch:=Jesse)`,
+		`(cn:dn:caseExactMatch:=Jesse)`,
+		`(:caseExactMatch:=Jesse)`,
+		`(&(a=1)(|(b=2)(!(c=3))))`,
+		`(&)`,
+		`(|)`,
+	} {
+		f, err := r.Filter(input)
+		if err != nil {
+			t.Errorf("%s[%d] failed: %v", t.Name(), idx, err)
+			continue
+		}
+
+		var data []byte
+		if data, err = json.Marshal(f); err != nil {
+			t.Errorf("%s[%d] marshal failed: %v", t.Name(), idx, err)
+			continue
+		}
+
+		var f2 Filter
+		if f2, err = r.FilterJSON(data); err != nil {
+			t.Errorf("%s[%d] unmarshal failed: %v [%s]", t.Name(), idx, err, data)
+		} else if f2.String() != f.String() {
+			t.Errorf("%s[%d] failed:\nwant: %s\ngot:  %s", t.Name(), idx, f, f2)
+		}
+	}
+}
+
+func TestRFC4515_FilterJSON_codecov(t *testing.T) {
+	var r RFC4515
+
+	for _, bogus := range []string{
+		`bogus`,
+		`{}`,
+		`{"and":[],"or":[]}`,
+		`{"xor":[]}`,
+		`{"and":{}}`,
+		`{"and":[{"bogus":1}]}`,
+		`{"not":{"bogus":1}}`,
+		`{"present":1}`,
+		`{"present":"c n"}`,
+		`{"equalityMatch":[]}`,
+		`{"equalityMatch":{"attributeDesc":"cn","assertionValue":1}}`,
+		`{"substrings":[]}`,
+		`{"substrings":{"type":"cn","substrings":[]}}`,
+		`{"substrings":{"type":"cn","substrings":[{"any":1}]}}`,
+		`{"substrings":{"type":"cn","substrings":[{"any":"a"},{"initial":"b"}]}}`,
+		`{"substrings":{"type":"cn","substrings":[{"any":"a","final":"b"}]}}`,
+		`{"substrings":{"type":"cn","substrings":[{"any":"a"},{"any":""},{"any":"b"}]}}`,
+		`{"extensibleMatch":[]}`,
+		`{"extensibleMatch":{"matchValue":1}}`,
+		`{"extensibleMatch":{"matchValue":"x"}}`,
+	} {
+		if _, err := r.FilterJSON([]byte(bogus)); err == nil {
+			t.Errorf("%s failed: expected error for %s, got nil", t.Name(), bogus)
+		}
+	}
+
+	for _, bogus := range []Filter{
+		invalidFilter{},
+		FilterNot{},
+		FilterAnd{invalidFilter{}},
+		FilterEqualityMatch{Desc: AttributeDescription(`cn`), Value: AssertionValue(`\zz`)},
+		FilterSubstrings{Type: AttributeDescription(`cn`), Substrings: SubstringAssertion{Initial: AssertionValue(`\zz`)}},
+		FilterExtensibleMatch{MatchValue: AssertionValue(`\zz`)},
+	} {
+		if _, err := json.Marshal(bogus); err == nil {
+			t.Errorf("%s failed: expected error for %#v, got nil", t.Name(), bogus)
+		}
+	}
+
+	if av := escapeFilterValue("a\xffč", true); string(av) != `a\ffč` {
+		t.Errorf("%s failed: unexpected escape %s", t.Name(), av)
+	} else if av = escapeFilterValue("a\tč*", false); string(av) != `a\09\c4\8d\2a` {
+		t.Errorf("%s failed: unexpected escape %s", t.Name(), av)
+	}
+}
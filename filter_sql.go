@@ -0,0 +1,531 @@
+package dirsyn
+
+/*
+filter_sql.go contains methods for the translation of Filter instances
+into SQL predicates.
+*/
+
+import (
+	"sort"
+	"time"
+)
+
+/*
+SQLFilterMapping describes the relational layout to which a [Filter] is
+translated by way of [RFC4515.SQLFilter].
+
+Two layouts are supported. In the column layout, each attribute type is
+mapped to a (single-valued) column through the Columns map. In the entry
+attribute value (EAV) layout, used if EAV is non-nil, each attribute value
+is stored as a row within a separate table.
+
+All table and column names are written into the resulting predicate as-is,
+and are therefore expected to originate from trusted configuration rather
+than user input. Assertion values are never written into the predicate,
+and are always conveyed through bind parameters.
+*/
+type SQLFilterMapping struct {
+	// Columns maps attribute types to columns in the column layout.
+	// Keys are matched without regard for case. If Schema is non-nil,
+	// a key may be any name or the numeric OID of the type in question.
+	Columns map[string]string
+
+	// EAV, if non-nil, enables the entry attribute value layout. The
+	// Columns map is not used in this case.
+	EAV *SQLFilterEAV
+
+	// Schema, if non-nil, is used to resolve attribute types (and any
+	// subordinate types) and the effective matching rules thereof. Case
+	// folding is applied to those types whose effective EQUALITY rule is
+	// of the caseIgnore family. If nil, all comparisons are case-exact,
+	// and all range comparisons are textual.
+	Schema *SubschemaSubentry
+
+	// Placeholder returns the bind parameter placeholder for the Nth
+	// (1-based) parameter. If nil, PostgreSQL-style placeholders
+	// (e.g.: "$1") are used.
+	Placeholder func(int) string
+}
+
+/*
+SQLFilterEAV describes an entry attribute value (EAV) table layout, in
+which each row contains an entry identifier, a lowercased attribute type
+name and a single value. For example:
+
+	CREATE TABLE attrs (entry_id BIGINT, name TEXT, value TEXT);
+*/
+type SQLFilterEAV struct {
+	// Table is the name of the EAV table, e.g.: "attrs".
+	Table string
+
+	// EntryColumn is the column of Table which references the entry,
+	// e.g.: "entry_id".
+	EntryColumn string
+
+	// NameColumn is the column of Table which contains the lowercased
+	// attribute type name, e.g.: "name".
+	NameColumn string
+
+	// ValueColumn is the column of Table which contains the attribute
+	// value, e.g.: "value".
+	ValueColumn string
+
+	// EntryIDColumn is the qualified column of the outer query which
+	// identifies the entry, e.g.: "entries.id".
+	EntryIDColumn string
+}
+
+/*
+SQLFilter returns a parameterized SQL predicate, suitable for use within
+a WHERE clause, alongside the bind parameter values and an error following
+an attempt to translate x per mapping.
+
+The input value x may be an existing [Filter] qualifier instance, or any
+value accepted by [RFC4515.Filter].
+
+Translation proceeds as follows:
+
+  - [FilterAnd] and [FilterOr] become AND and OR expressions; the absolute true and false filters of [RFC 4526] become TRUE and FALSE
+  - [FilterNot] becomes NOT
+  - [FilterPresent] becomes IS NOT NULL (or EXISTS in the EAV layout)
+  - [FilterEqualityMatch] and [FilterApproximateMatch] become equality comparisons, per the effective EQUALITY rule of the type (see below)
+  - [FilterGreaterOrEqual] and [FilterLessOrEqual] become range comparisons, per the effective ORDERING rule of the type (see below)
+  - [FilterSubstrings] becomes a LIKE pattern, in which the "%", "_" and "!" characters are escaped by way of "ESCAPE '!'", which is portable across dialects
+  - [FilterExtensibleMatch] becomes an equality comparison if an attribute type is present, the dnAttributes field is not set and the matching rule (if any) is one of the caseExact or caseIgnore equality rules
+
+Equality comparisons honor the effective EQUALITY rule of the attribute
+type if it is resolved through the Schema:
+
+  - caseIgnoreMatch (and the like): textual comparison, case folded
+  - integerMatch: numeric comparison, in which the column is cast to NUMERIC, and the bound value is an int64 (or a decimal string if larger)
+  - numericStringMatch: textual comparison, in which spaces are removed from both the column and the bound value
+  - all others: textual comparison
+
+Range comparisons honor the effective ORDERING rule of the attribute type
+if it is resolved through the Schema:
+
+  - caseIgnoreOrderingMatch, caseExactOrderingMatch, numericStringOrderingMatch and octetStringOrderingMatch: textual comparison, case folded if appropriate
+  - integerOrderingMatch: numeric comparison, in which the column is cast to NUMERIC, and the bound value is an int64 (or a decimal string if larger)
+  - generalizedTimeOrderingMatch: the bound value is a UTC time.Time, and the mapped column must be of a timestamp type; not supported in the EAV layout
+
+Each item predicate is constructed such that it never evaluates to NULL,
+thus NOT behaves in accordance with LDAP, in which "(!(cn=x))" matches an
+entry with no cn attribute.
+
+An error is returned if an attribute type is not mapped, if an attribute
+description bears an [AttributeOption], if a range comparison involves a
+resolved type lacking a supported ORDERING rule, or if a
+[FilterExtensibleMatch] cannot be translated.
+
+[RFC 4526]: https://datatracker.ietf.org/doc/html/rfc4526
+*/
+func (r RFC4515) SQLFilter(x any, mapping SQLFilterMapping) (where string, args []any, err error) {
+	var f Filter
+	if f, err = assertFilter(x); err != nil {
+		return
+	}
+
+	if mapping.Placeholder == nil {
+		mapping.Placeholder = func(n int) string { return `$` + itoa(n) }
+	}
+
+	b := &sqlFilterBuilder{
+		mapping:  mapping,
+		compiler: filterCompiler{schema: mapping.Schema},
+	}
+
+	if where, err = b.translate(f); err == nil {
+		args = b.args
+	} else {
+		where = ``
+	}
+
+	return
+}
+
+/*
+sqlFilterBuilder maintains the state of a single translation.
+*/
+type sqlFilterBuilder struct {
+	mapping  SQLFilterMapping
+	compiler filterCompiler
+	args     []any
+}
+
+/*
+bind appends value to the bind parameters, and returns its placeholder.
+*/
+func (r *sqlFilterBuilder) bind(value any) string {
+	r.args = append(r.args, value)
+	return r.mapping.Placeholder(len(r.args))
+}
+
+func (r *sqlFilterBuilder) translate(f Filter) (sql string, err error) {
+	switch tv := f.(type) {
+	case FilterAnd:
+		sql, err = r.translateSet(tv, ` AND `, `TRUE`)
+	case FilterOr:
+		sql, err = r.translateSet(tv, ` OR `, `FALSE`)
+	case FilterNot:
+		if sql, err = r.translate(tv.Filter); err == nil {
+			sql = `NOT ` + sql
+		}
+	case FilterPresent:
+		sql, err = r.item(tv.Desc, nil, ``, nil, sqlColumnAsIs)
+	case FilterEqualityMatch:
+		sql, err = r.comparison(tv.Desc, tv.Value, `=`, ``)
+	case FilterApproximateMatch:
+		sql, err = r.comparison(tv.Desc, tv.Value, `=`, ``)
+	case FilterGreaterOrEqual:
+		sql, err = r.ordering(tv.Desc, tv.Value, `>=`)
+	case FilterLessOrEqual:
+		sql, err = r.ordering(tv.Desc, tv.Value, `<=`)
+	case FilterSubstrings:
+		sql, err = r.substrings(tv)
+	case FilterExtensibleMatch:
+		sql, err = r.extensible(tv)
+	default:
+		err = invalidFilterErr
+	}
+
+	return
+}
+
+func (r *sqlFilterBuilder) translateSet(set []Filter, op, empty string) (sql string, err error) {
+	if len(set) == 0 {
+		return empty, nil
+	}
+
+	var parts []string
+	for i := 0; i < len(set); i++ {
+		var part string
+		if part, err = r.translate(set[i]); err != nil {
+			return
+		}
+		parts = append(parts, part)
+	}
+	sql = `(` + join(parts, op) + `)`
+
+	return
+}
+
+/*
+comparison returns a predicate comparing the column of desc to value by
+way of op. Case folding is governed by rule, which is the name of the
+matching rule in force, or the effective EQUALITY rule of the type if
+zero.
+*/
+func (r *sqlFilterBuilder) comparison(desc AttributeDescription, value AssertionValue, op, rule string) (sql string, err error) {
+	var raw string
+	if raw, err = unescapeFilterValue(value); err != nil {
+		return
+	}
+
+	at := r.attributeType(desc)
+	if mr := filterEqualityRule(at); len(rule) == 0 && mr != nil {
+		rule = mr.Identifier()
+	}
+
+	var bound any = raw
+	form := sqlColumnAsIs
+	switch {
+	case sqlFoldCase(rule):
+		bound = lc(raw)
+		form = sqlColumnFolded
+	case streqf(rule, `integerMatch`):
+		if bound, err = sqlInteger(desc, raw); err != nil {
+			return
+		}
+		form = sqlColumnNumeric
+	case streqf(rule, `numericStringMatch`):
+		bound = repAll(raw, ` `, ``)
+		form = sqlColumnNoSpace
+	}
+
+	return r.item(desc, at, op, bound, form)
+}
+
+/*
+ordering returns a range predicate comparing the column of desc to value
+by way of op, per the effective ORDERING rule of the type. If the type is
+not resolved, the values are compared as text.
+*/
+func (r *sqlFilterBuilder) ordering(desc AttributeDescription, value AssertionValue, op string) (sql string, err error) {
+	at := r.attributeType(desc)
+	if at == nil {
+		return r.comparison(desc, value, op, ``)
+	}
+
+	mr := at.EffectiveOrdering()
+	if mr == nil {
+		err = errorTxt("No ORDERING matching rule for attribute type " + desc.Type())
+		return
+	}
+
+	var raw string
+	if raw, err = unescapeFilterValue(value); err != nil {
+		return
+	}
+
+	switch lc(mr.Identifier()) {
+	case `caseignoreorderingmatch`:
+		sql, err = r.item(desc, at, op, lc(raw), sqlColumnFolded)
+	case `caseexactorderingmatch`, `numericstringorderingmatch`, `octetstringorderingmatch`:
+		sql, err = r.item(desc, at, op, raw, sqlColumnAsIs)
+	case `integerorderingmatch`:
+		var bound any
+		if bound, err = sqlInteger(desc, raw); err == nil {
+			sql, err = r.item(desc, at, op, bound, sqlColumnNumeric)
+		}
+	case `generalizedtimeorderingmatch`:
+		if r.mapping.EAV != nil {
+			err = errorTxt("SQL translation of generalizedTimeOrderingMatch not supported in the EAV layout")
+			return
+		}
+
+		var gt GeneralizedTime
+		if gt, err = marshalGenTime(raw); err != nil {
+			return
+		}
+		sql, err = r.item(desc, at, op, time.Time(gt).UTC(), sqlColumnAsIs)
+	default:
+		err = errorTxt("SQL translation of ordering rule " + mr.Identifier() + " not supported")
+	}
+
+	return
+}
+
+func (r *sqlFilterBuilder) substrings(f FilterSubstrings) (sql string, err error) {
+	// The "any" components are split prior to unescaping, lest
+	// an escaped asterisk be mistaken for a delimiter.
+	comps := []AssertionValue{f.Substrings.Initial}
+	for _, a := range split(string(f.Substrings.Any), `*`) {
+		comps = append(comps, AssertionValue(a))
+	}
+	comps = append(comps, f.Substrings.Final)
+
+	bld := newStrBuilder()
+	for i, comp := range comps {
+		var raw string
+		if raw, err = unescapeFilterValue(comp); err != nil {
+			return
+		}
+		bld.WriteString(sqlLikeEscape(raw))
+		if i < len(comps)-1 && (i == 0 || len(raw) > 0) {
+			bld.WriteByte('%')
+		}
+	}
+
+	at := r.attributeType(f.Type)
+	form := sqlColumnAsIs
+	if mr := filterSubstringRule(at); mr != nil && sqlFoldCase(mr.Identifier()) {
+		form = sqlColumnFolded
+	}
+
+	pattern := bld.String()
+	if form == sqlColumnFolded {
+		pattern = lc(pattern)
+	}
+
+	return r.item(f.Type, at, `LIKE`, pattern, form)
+}
+
+func (r *sqlFilterBuilder) extensible(f FilterExtensibleMatch) (sql string, err error) {
+	if len(f.Type) == 0 || f.DNAttributes {
+		err = errorTxt("SQL translation of extensibleMatch requires a type and no dnAttributes")
+		return
+	}
+
+	var rule string
+	if id := f.MatchingRule.String(); len(id) > 0 {
+		switch lc(id) {
+		case `caseexactmatch`, `2.5.13.5`, `caseexactia5match`, `1.3.6.1.4.1.1466.109.114.1`:
+			rule = `caseExactMatch`
+		case `caseignorematch`, `2.5.13.2`, `caseignoreia5match`, `1.3.6.1.4.1.1466.109.114.2`:
+			rule = `caseIgnoreMatch`
+		default:
+			err = errorTxt("SQL translation of matching rule " + id + " not supported")
+			return
+		}
+	}
+
+	return r.comparison(f.Type, f.MatchValue, `=`, rule)
+}
+
+/*
+item returns the predicate for a single item. If op is zero, a presence
+predicate is produced. Otherwise the value of the relevant column, in the
+given form, is compared to the bound value by way of op.
+
+Values are bound in the order in which their placeholders appear, thus
+positional placeholders (e.g.: "?") remain viable.
+*/
+func (r *sqlFilterBuilder) item(desc AttributeDescription, at *AttributeType, op string, value any, form sqlColumnForm) (sql string, err error) {
+	if len(desc.Options()) > 0 {
+		err = errorTxt("SQL translation of attribute options not supported: " + desc.String())
+		return
+	}
+
+	if at == nil {
+		at = r.attributeType(desc)
+	}
+
+	if eav := r.mapping.EAV; eav != nil {
+		sql = `EXISTS (SELECT 1 FROM ` + eav.Table + ` WHERE ` +
+			eav.Table + `.` + eav.EntryColumn + ` = ` + eav.EntryIDColumn + ` AND ` +
+			eav.Table + `.` + eav.NameColumn + ` IN (` + join(r.eavNames(desc, at), `, `) + `)`
+		if len(op) > 0 {
+			sql += ` AND ` + form.column(eav.Table+`.`+eav.ValueColumn) + ` ` + op + ` ` + r.rhs(op, value)
+		}
+		sql += `)`
+		return
+	}
+
+	var col string
+	if col, err = r.column(desc, at); err != nil {
+		return
+	}
+
+	sql = col + ` IS NOT NULL`
+	if len(op) > 0 {
+		// The IS NOT NULL qualifier assures the item never
+		// evaluates to NULL, thus NOT behaves as expected.
+		sql = `(` + sql + ` AND ` + form.column(col) + ` ` + op + ` ` + r.rhs(op, value) + `)`
+	}
+
+	return
+}
+
+/*
+rhs binds value and returns the right-hand side of a comparison by way
+of op. The "!" escape character is used for LIKE patterns, as it bears
+no special meaning within string literals in any common dialect, unlike
+the backslash (e.g.: MySQL).
+*/
+func (r *sqlFilterBuilder) rhs(op string, value any) (rhs string) {
+	if rhs = r.bind(value); op == `LIKE` {
+		rhs += ` ESCAPE '!'`
+	}
+
+	return
+}
+
+/*
+column returns the column mapped to the type described by desc.
+*/
+func (r *sqlFilterBuilder) column(desc AttributeDescription, at *AttributeType) (col string, err error) {
+	terms := []string{desc.Type()}
+	if at != nil {
+		terms = append(append(terms, at.NumericOID), at.Name...)
+	}
+
+	for _, term := range terms {
+		for k, v := range r.mapping.Columns {
+			if streqf(k, term) {
+				return v, nil
+			}
+		}
+	}
+
+	err = errorTxt("No SQL column mapped for attribute type " + desc.Type())
+
+	return
+}
+
+/*
+eavNames returns the bind placeholders for the lowercased names of the
+type described by desc and, if resolved, all of its subordinate types.
+*/
+func (r *sqlFilterBuilder) eavNames(desc AttributeDescription, at *AttributeType) (phs []string) {
+	if at == nil {
+		return []string{r.bind(lc(desc.Type()))}
+	}
+
+	names := make(map[string]struct{})
+	r.compiler.typeNames(at, names)
+
+	var sorted []string
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	for _, name := range sorted {
+		phs = append(phs, r.bind(name))
+	}
+
+	return
+}
+
+func (r *sqlFilterBuilder) attributeType(desc AttributeDescription) (at *AttributeType) {
+	if r.mapping.Schema != nil {
+		var idx int
+		if at, idx = r.mapping.Schema.AttributeType(desc.Type()); idx == -1 {
+			at = nil
+		}
+	}
+
+	return
+}
+
+/*
+sqlInteger returns the bound form of the INTEGER assertion value raw,
+which is an int64, or a decimal string if larger.
+*/
+func sqlInteger(desc AttributeDescription, raw string) (bound any, err error) {
+	i, ok := newBigInt(0).SetString(raw, 10)
+	if !ok {
+		err = errorTxt("Invalid INTEGER assertion value for " + desc.Type())
+		return
+	}
+
+	if bound = i.String(); i.IsInt64() {
+		bound = i.Int64()
+	}
+
+	return
+}
+
+/*
+sqlFoldCase returns a Boolean value indicative of whether rule is of the
+caseIgnore family, and thus warrants case folding.
+*/
+func sqlFoldCase(rule string) bool {
+	return hasPfx(lc(rule), `caseignore`)
+}
+
+/*
+sqlColumnForm describes the form in which a column is compared.
+*/
+type sqlColumnForm uint8
+
+const (
+	sqlColumnAsIs    sqlColumnForm = iota // the column as-is
+	sqlColumnFolded                       // lower(column)
+	sqlColumnNumeric                      // CAST(column AS NUMERIC)
+	sqlColumnNoSpace                      // replace(column, ' ', '')
+)
+
+/*
+column returns col in the form described by the receiver instance.
+*/
+func (r sqlColumnForm) column(col string) string {
+	switch r {
+	case sqlColumnFolded:
+		col = `lower(` + col + `)`
+	case sqlColumnNumeric:
+		col = `CAST(` + col + ` AS NUMERIC)`
+	case sqlColumnNoSpace:
+		col = `replace(` + col + `, ' ', '')`
+	}
+
+	return col
+}
+
+/*
+sqlLikeEscape escapes the LIKE wildcard characters, as well as the "!"
+escape character itself, within x.
+*/
+func sqlLikeEscape(x string) string {
+	return newRepl(`!`, `!!`, `%`, `!%`, `_`, `!_`).Replace(x)
+}
@@ -0,0 +1,177 @@
+package dirsyn
+
+import (
+	"fmt"
+	"testing"
+)
+
+/*
+This example demonstrates the means for translating a [Filter] into a
+parameterized SQL predicate against a column layout.
+*/
+func ExampleRFC4515_SQLFilter() {
+	var r RFC4515
+	where, args, err := r.SQLFilter(`(&(commonName=Jes*)(!(mail=*))(uid=jcoretta))`,
+		SQLFilterMapping{
+			Columns: map[string]string{`cn`: `full_name`, `mail`: `email`, `uid`: `login`},
+			Schema:  exampleSchema,
+		})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Println(where)
+	fmt.Println(args)
+	// Output:
+	// ((full_name IS NOT NULL AND lower(full_name) LIKE $1 ESCAPE '!') AND NOT email IS NOT NULL AND (login IS NOT NULL AND lower(login) = $2))
+	// [jes% jcoretta]
+}
+
+/*
+This example demonstrates the means for translating a [Filter] into a
+parameterized SQL predicate against an entry attribute value (EAV) table
+layout.
+*/
+func ExampleRFC4515_SQLFilter_eav() {
+	var r RFC4515
+	where, args, err := r.SQLFilter(`(sn=Coretta)`,
+		SQLFilterMapping{
+			EAV: &SQLFilterEAV{
+				Table:         `attrs`,
+				EntryColumn:   `entry_id`,
+				NameColumn:    `name`,
+				ValueColumn:   `value`,
+				EntryIDColumn: `entries.id`,
+			},
+			Placeholder: func(int) string { return `?` },
+		})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Println(where)
+	fmt.Println(args)
+	// Output:
+	// EXISTS (SELECT 1 FROM attrs WHERE attrs.entry_id = entries.id AND attrs.name IN (?) AND attrs.value = ?)
+	// [sn Coretta]
+}
+
+func TestRFC4515_SQLFilter(t *testing.T) {
+	var r RFC4515
+	defer registerFilterTestType(t)()
+
+	columns := SQLFilterMapping{
+		Columns: map[string]string{
+			`cn`:              `cn`,
+			`filterTestCount`: `count`,
+			`x-exact`:         `exact`,
+			`modifyTimestamp`: `modified`,
+			`dnQualifier`:     `qualifier`,
+			`x121Address`:     `x121`,
+		},
+	}
+	schemaColumns := columns
+	schemaColumns.Schema = exampleSchema
+
+	eav := SQLFilterMapping{
+		EAV: &SQLFilterEAV{
+			Table:         `a`,
+			EntryColumn:   `e`,
+			NameColumn:    `n`,
+			ValueColumn:   `v`,
+			EntryIDColumn: `x.id`,
+		},
+		Schema: exampleSchema,
+	}
+
+	for idx, strukt := range []struct {
+		Input   string
+		Mapping SQLFilterMapping
+		Where   string
+		Args    string
+	}{
+		{`(cn=Jesse)`, columns, `(cn IS NOT NULL AND cn = $1)`, `[Jesse]`},
+		{`(cn=Jesse)`, schemaColumns, `(cn IS NOT NULL AND lower(cn) = $1)`, `[jesse]`},
+		{`(cn~=Jesse)`, schemaColumns, `(cn IS NOT NULL AND lower(cn) = $1)`, `[jesse]`},
+		{`(2.5.4.3=Jesse)`, schemaColumns, `(cn IS NOT NULL AND lower(cn) = $1)`, `[jesse]`},
+		{`(cn:caseExactMatch:=Jesse)`, schemaColumns, `(cn IS NOT NULL AND cn = $1)`, `[Jesse]`},
+		{`(x-exact:caseIgnoreMatch:=Jesse)`, columns, `(exact IS NOT NULL AND lower(exact) = $1)`, `[jesse]`},
+		{`(cn=*)`, columns, `cn IS NOT NULL`, `[]`},
+		{`(cn=\2a%_!\5c*)`, columns, `(cn IS NOT NULL AND cn LIKE $1 ESCAPE '!')`, `[*!%!_!!\%]`},
+		{`(cn=*a\2ab*c*)`, columns, `(cn IS NOT NULL AND cn LIKE $1 ESCAPE '!')`, `[%a*b%c%]`},
+		{`(cn=a*b)`, columns, `(cn IS NOT NULL AND cn LIKE $1 ESCAPE '!')`, `[a%b]`},
+		{`(filterTestCount>=5)`, columns, `(count IS NOT NULL AND count >= $1)`, `[5]`},
+		{`(filterTestCount=010)`, columns, `(count IS NOT NULL AND count = $1)`, `[010]`},
+		{`(filterTestCount=010)`, schemaColumns, `(count IS NOT NULL AND CAST(count AS NUMERIC) = $1)`, `[10]`},
+		{`(filterTestCount~=-0)`, schemaColumns, `(count IS NOT NULL AND CAST(count AS NUMERIC) = $1)`, `[0]`},
+		{`(x121Address=1 23 45)`, schemaColumns, `(x121 IS NOT NULL AND replace(x121, ' ', '') = $1)`, `[12345]`},
+		{`(x121Address=012)`, eav, `EXISTS (SELECT 1 FROM a WHERE a.e = x.id AND a.n IN ($1, $2) AND replace(a.v, ' ', '') = $3)`, `[2.5.4.24 x121address 012]`},
+		{`(filterTestCount>=5)`, schemaColumns, `(count IS NOT NULL AND CAST(count AS NUMERIC) >= $1)`, `[5]`},
+		{`(filterTestCount<=-10)`, schemaColumns, `(count IS NOT NULL AND CAST(count AS NUMERIC) <= $1)`, `[-10]`},
+		{`(filterTestCount<=99999999999999999999)`, schemaColumns, `(count IS NOT NULL AND CAST(count AS NUMERIC) <= $1)`, `[99999999999999999999]`},
+		{`(modifyTimestamp>=20240101013000.5+0130)`, schemaColumns, `(modified IS NOT NULL AND modified >= $1)`, `[2024-01-01 00:00:00.5 +0000 UTC]`},
+		{`(dnQualifier<=ABC)`, schemaColumns, `(qualifier IS NOT NULL AND lower(qualifier) <= $1)`, `[abc]`},
+		{`(|(cn=a)(!(cn=b)))`, columns, `((cn IS NOT NULL AND cn = $1) OR NOT (cn IS NOT NULL AND cn = $2))`, `[a b]`},
+		{`(&)`, columns, `TRUE`, `[]`},
+		{`(|)`, columns, `FALSE`, `[]`},
+		{`(cn=*)`, eav, `EXISTS (SELECT 1 FROM a WHERE a.e = x.id AND a.n IN ($1, $2, $3))`, `[2.5.4.3 cn commonname]`},
+		{`(filterTestCount>=5)`, eav, `EXISTS (SELECT 1 FROM a WHERE a.e = x.id AND a.n IN ($1, $2) AND CAST(a.v AS NUMERIC) >= $3)`, `[1.3.6.1.4.1.56521.999.88.1 filtertestcount 5]`},
+		{`(cn=Je*)`, eav, `EXISTS (SELECT 1 FROM a WHERE a.e = x.id AND a.n IN ($1, $2, $3) AND lower(a.v) LIKE $4 ESCAPE '!')`, `[2.5.4.3 cn commonname je%]`},
+	} {
+		where, args, err := r.SQLFilter(strukt.Input, strukt.Mapping)
+		if err != nil {
+			t.Errorf("%s[%d] failed: %v", t.Name(), idx, err)
+		} else if where != strukt.Where || fmt.Sprint(args) != strukt.Args {
+			t.Errorf("%s[%d] failed: %s\nwant: %s %s\ngot:  %s %v",
+				t.Name(), idx, strukt.Input, strukt.Where, strukt.Args, where, args)
+		}
+	}
+}
+
+func TestRFC4515_SQLFilter_codecov(t *testing.T) {
+	var r RFC4515
+	mapping := SQLFilterMapping{Columns: map[string]string{`cn`: `cn`}}
+
+	for _, bogus := range []any{
+		`(cn=bogus`,
+		`(sn=x)`,
+		`(cn;lang-en=x)`,
+		`(:caseExactMatch:=x)`,
+		`(cn:dn:=x)`,
+		`(cn:bogusMatch:=x)`,
+		`(&(cn=x)(sn=y))`,
+		`(!(sn=y))`,
+		invalidFilter{},
+		FilterEqualityMatch{Desc: AttributeDescription(`cn`), Value: AssertionValue(`\zz`)},
+		FilterSubstrings{Type: AttributeDescription(`cn`), Substrings: SubstringAssertion{Any: AssertionValue(`\zz`)}},
+	} {
+		if where, _, err := r.SQLFilter(bogus, mapping); err == nil || where != `` {
+			t.Errorf("%s failed: expected error for %v, got nil", t.Name(), bogus)
+		}
+	}
+
+	// range comparisons per the ORDERING rule of resolved types
+	defer registerFilterTestType(t)()
+	mapping.Columns[`filterTestCount`] = `count`
+	mapping.Columns[`modifyTimestamp`] = `modified`
+	mapping.Schema = exampleSchema
+	eav := SQLFilterMapping{EAV: &SQLFilterEAV{Table: `a`}, Schema: exampleSchema}
+
+	for _, strukt := range []struct {
+		Input   string
+		Mapping SQLFilterMapping
+	}{
+		{`(cn>=a)`, mapping},
+		{`(filterTestCount>=abc)`, mapping},
+		{`(filterTestCount=abc)`, mapping},
+		{`(filterTestCount>=\zz)`, mapping},
+		{`(modifyTimestamp<=bogus)`, mapping},
+		{`(modifyTimestamp<=20240101000000Z)`, eav},
+	} {
+		if where, _, err := r.SQLFilter(strukt.Input, strukt.Mapping); err == nil || where != `` {
+			t.Errorf("%s failed: expected error for %s, got nil", t.Name(), strukt.Input)
+		}
+	}
+}
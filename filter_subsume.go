@@ -0,0 +1,722 @@
+package dirsyn
+
+/*
+filter_subsume.go contains Filter containment (subsumption) and
+satisfiability methods.
+*/
+
+import (
+	"math/big"
+)
+
+/*
+FilterSubsumes returns a [Boolean] instance alongside an error following
+an attempt to determine whether every entry matched by [Filter] a is also
+matched by [Filter] b. In other words, whether the result set of a is
+contained within the result set of b.
+
+The input values a and b may be existing [Filter] qualifier instances, or
+any values accepted by [RFC4515.Filter].
+
+The return [Boolean] is TRUE if containment was proven, FALSE if it was
+disproven and UNDEFINED (zero) if neither could be determined.  The check
+is conservative, in that TRUE and FALSE are only returned when certain.
+
+The following are considered during evaluation:
+
+  - Equality, presence and approximate assertions
+  - Substring prefix ("initial"), suffix ("final") and "any" containment, by way of the SUBSTR rule in force (requires a *[SubschemaSubentry])
+  - Ordering ranges on integer and generalizedTime types, by way of the ORDERING rule in force (requires a *[SubschemaSubentry])
+  - Attribute subtyping and [AttributeOption] containment (e.g.: "(cn;lang-en=x)" implies "(cn=*)")
+  - Boolean composition through [FilterAnd], [FilterOr] and [FilterNot]
+  - Unsatisfiable filters, which are contained within any filter
+
+Note that evaluation is performed in terms of the filters themselves, and
+does not consider DIT content rules, object class requirements or actual
+DIT content.  Assertion values are presumed valid per their respective
+matching rules.
+
+If a non-nil *[SubschemaSubentry] is provided, attribute types and values
+are resolved and normalized as described in [RFC4515.CanonicalFilter],
+and the three-valued logic of [§ 4.5.1.7 of RFC 4511] is honored where
+negated items are concerned.
+
+[§ 4.5.1.7 of RFC 4511]: https://datatracker.ietf.org/doc/html/rfc4511#section-4.5.1.7
+*/
+func (r RFC4515) FilterSubsumes(a, b any, schema ...*SubschemaSubentry) (result Boolean, err error) {
+	var fa, fb Filter
+	if fa, err = r.CanonicalFilter(a, schema...); err != nil {
+		return
+	} else if fb, err = r.CanonicalFilter(b, schema...); err != nil {
+		return
+	}
+
+	rsn := newFilterReasoner(schema...)
+	result = rsn.implies(fa, fb).boolean()
+
+	return
+}
+
+/*
+FilterSatisfiable returns a [Boolean] instance alongside an error following
+an attempt to determine whether [Filter] x could ever match an entry.
+
+The input value x may be an existing [Filter] qualifier instance, or any
+value accepted by [RFC4515.Filter].
+
+The return [Boolean] is FALSE if x was proven unsatisfiable, such as with
+"(&(a=1)(!(a=*)))", TRUE if x was proven satisfiable and UNDEFINED (zero)
+if neither could be determined.
+
+See [RFC4515.FilterSubsumes] for details on the evaluation process.
+*/
+func (r RFC4515) FilterSatisfiable(x any, schema ...*SubschemaSubentry) (result Boolean, err error) {
+	var f Filter
+	if f, err = r.CanonicalFilter(x, schema...); err != nil {
+		return
+	}
+
+	rsn := newFilterReasoner(schema...)
+	if rsn.unsatisfiable(f) {
+		result.Set(false)
+	} else if rsn.satisfiable(f) {
+		result.Set(true)
+	}
+
+	return
+}
+
+/*
+filterTruth is a private three-state type used during filter reasoning.
+*/
+type filterTruth uint8
+
+const (
+	filterUnknown filterTruth = iota
+	filterYes
+	filterNo
+)
+
+func (r filterTruth) boolean() (b Boolean) {
+	switch r {
+	case filterYes:
+		b.Set(true)
+	case filterNo:
+		b.Set(false)
+	}
+
+	return
+}
+
+/*
+filterReasoner performs containment and satisfiability analysis upon
+canonical [Filter] instances, optionally with the aid of a schema.
+*/
+type filterReasoner struct {
+	schema *SubschemaSubentry
+}
+
+func newFilterReasoner(schema ...*SubschemaSubentry) (rsn filterReasoner) {
+	if len(schema) > 0 {
+		rsn.schema = schema[0]
+	}
+
+	return
+}
+
+/*
+implies returns filterYes if every entry matching a also matches b.
+*/
+func (r filterReasoner) implies(a, b Filter) (truth filterTruth) {
+	if r.unsatisfiable(a) {
+		// Vacuously true.
+		return filterYes
+	} else if a.String() == b.String() {
+		return filterYes
+	} else if r.unsatisfiable(b) {
+		if r.satisfiable(a) {
+			truth = filterNo
+		}
+		return
+	}
+
+	switch tv := b.(type) {
+	case FilterAnd:
+		// a must imply every operand of b.
+		truth = filterYes
+		for i := 0; i < len(tv) && truth != filterNo; i++ {
+			if t := r.implies(a, tv[i]); t != filterYes {
+				truth = t
+			}
+		}
+		return
+	}
+
+	switch tv := a.(type) {
+	case FilterOr:
+		// Every operand of a must imply b.
+		truth = filterYes
+		for i := 0; i < len(tv) && truth != filterNo; i++ {
+			if r.unsatisfiable(tv[i]) {
+				continue
+			} else if t := r.implies(tv[i], b); t != filterYes {
+				truth = t
+			}
+		}
+		return
+	case FilterAnd:
+		// Any single operand of a implying b will suffice.
+		for i := 0; i < len(tv); i++ {
+			if r.implies(tv[i], b) == filterYes {
+				return filterYes
+			}
+		}
+	}
+
+	if or, isOr := b.(FilterOr); isOr {
+		// a implying any single operand of b will suffice.
+		for i := 0; i < len(or); i++ {
+			if r.implies(a, or[i]) == filterYes {
+				return filterYes
+			}
+		}
+	} else if truth = r.literalImplies(a, b); truth != filterUnknown {
+		return
+	}
+
+	if r.counterexample(a, b) {
+		truth = filterNo
+	}
+
+	return
+}
+
+/*
+literalImplies handles containment between two literals, which are item
+filters or negated item filters.
+*/
+func (r filterReasoner) literalImplies(a, b Filter) (truth filterTruth) {
+	pa, nega, oka := filterLiteral(a)
+	pb, negb, okb := filterLiteral(b)
+	if !oka || !okb {
+		return
+	}
+
+	if !nega && !negb {
+		truth = r.atomImplies(pa, pb)
+	} else if nega && negb {
+		// Contraposition: if pb implies pa, then !pa implies !pb,
+		// but only if pb can never evaluate as UNDEFINED.
+		if r.definite(pb) && r.atomImplies(pb, pa) == filterYes {
+			truth = filterYes
+		}
+	}
+
+	return
+}
+
+/*
+atomImplies returns filterYes if item filter a implies item filter b.
+*/
+func (r filterReasoner) atomImplies(a, b Filter) (truth filterTruth) {
+	da, db := filterAtomDesc(a), filterAtomDesc(b)
+	if len(da) == 0 || len(db) == 0 {
+		return
+	}
+
+	if _, isPresent := b.(FilterPresent); isPresent {
+		if ext, isExt := a.(FilterExtensibleMatch); isExt && ext.DNAttributes {
+			// Could match on a DN attribute, as opposed
+			// to an attribute of the entry itself.
+			return
+		} else if r.descImplies(da, db, false) {
+			truth = filterYes
+		}
+		return
+	}
+
+	if !r.descImplies(da, db, true) {
+		return
+	}
+
+	switch b.(type) {
+	case FilterSubstrings, FilterGreaterOrEqual, FilterLessOrEqual:
+		// Without a SUBSTR or ORDERING rule in force, b
+		// evaluates as UNDEFINED (and never TRUE) per
+		// § 4.5.1.7 of RFC 4511.
+		if !r.definite(b) {
+			return
+		}
+	}
+
+	switch tb := b.(type) {
+	case FilterEqualityMatch:
+		if ta, ok := a.(FilterEqualityMatch); ok && string(ta.Value) == string(tb.Value) {
+			truth = filterYes
+		}
+	case FilterApproximateMatch:
+		if ta, ok := a.(FilterApproximateMatch); ok && string(ta.Value) == string(tb.Value) {
+			truth = filterYes
+		}
+	case FilterSubstrings:
+		truth = r.substringImplies(a, tb)
+	case FilterGreaterOrEqual:
+		switch ta := a.(type) {
+		case FilterEqualityMatch:
+			truth = r.orderingImplies(da, ta.Value, tb.Value, true)
+		case FilterGreaterOrEqual:
+			truth = r.orderingImplies(da, ta.Value, tb.Value, true)
+		}
+	case FilterLessOrEqual:
+		switch ta := a.(type) {
+		case FilterEqualityMatch:
+			truth = r.orderingImplies(da, ta.Value, tb.Value, false)
+		case FilterLessOrEqual:
+			truth = r.orderingImplies(da, ta.Value, tb.Value, false)
+		}
+	}
+
+	return
+}
+
+/*
+substringImplies returns filterYes if a (an equality or substrings filter)
+implies the substrings filter b.
+*/
+func (r filterReasoner) substringImplies(a Filter, b FilterSubstrings) (truth filterTruth) {
+	binit, _ := unescapeFilterValue(b.Substrings.Initial)
+	bfinal, _ := unescapeFilterValue(b.Substrings.Final)
+	bany := splitSubstringAny(b.Substrings.Any)
+
+	switch ta := a.(type) {
+	case FilterEqualityMatch:
+		val, _ := unescapeFilterValue(ta.Value)
+		if substringPatternMatch(val, binit, bany, bfinal) {
+			truth = filterYes
+		}
+	case FilterSubstrings:
+		ainit, _ := unescapeFilterValue(ta.Substrings.Initial)
+		afinal, _ := unescapeFilterValue(ta.Substrings.Final)
+		aany := splitSubstringAny(ta.Substrings.Any)
+
+		if hasPfx(ainit, binit) && hasSfx(afinal, bfinal) && isSubsequence(bany, aany) {
+			truth = filterYes
+		}
+	}
+
+	return
+}
+
+/*
+orderingImplies returns filterYes if a value of (at least) va implies
+a value greater than or equal to vb (if ge is true), or if a value of
+(at most) va implies a value less than or equal to vb (if ge is false).
+*/
+func (r filterReasoner) orderingImplies(desc AttributeDescription, va, vb AssertionValue, ge bool) (truth filterTruth) {
+	if cmp, ok := r.compareOrdered(desc, va, vb); ok {
+		if (ge && cmp >= 0) || (!ge && cmp <= 0) {
+			truth = filterYes
+		}
+	}
+
+	return
+}
+
+/*
+compareOrdered compares a and b per the ORDERING rule of the attribute
+type described by desc. Only integer and generalizedTime ordering rules
+are supported.
+*/
+func (r filterReasoner) compareOrdered(desc AttributeDescription, a, b AssertionValue) (cmp int, ok bool) {
+	at := r.attributeType(desc)
+	if at == nil {
+		return
+	}
+
+	mr := at.EffectiveOrdering()
+	if mr == nil {
+		return
+	}
+
+	x, _ := unescapeFilterValue(a)
+	y, _ := unescapeFilterValue(b)
+
+	switch lc(mr.Identifier()) {
+	case `integerorderingmatch`:
+		i, iok := new(big.Int).SetString(x, 10)
+		j, jok := new(big.Int).SetString(y, 10)
+		if ok = iok && jok; ok {
+			cmp = i.Cmp(j)
+		}
+	case `generalizedtimeorderingmatch`:
+		i, ierr := marshalGenTime(x)
+		j, jerr := marshalGenTime(y)
+		if ok = ierr == nil && jerr == nil; ok {
+			cmp = i.Cast().Compare(j.Cast())
+		}
+	}
+
+	return
+}
+
+/*
+unsatisfiable returns a Boolean value indicative of whether the input
+canonical filter was proven to never match any entry.
+*/
+func (r filterReasoner) unsatisfiable(f Filter) (unsat bool) {
+	switch tv := f.(type) {
+	case FilterOr:
+		// Absolute false, or all operands unsatisfiable.
+		unsat = true
+		for i := 0; i < len(tv) && unsat; i++ {
+			unsat = r.unsatisfiable(tv[i])
+		}
+	case FilterAnd:
+		unsat = r.contradictory(tv)
+	}
+
+	return
+}
+
+/*
+contradictory returns a Boolean value indicative of whether the input
+conjunction contains operands that cannot be TRUE simultaneously.
+*/
+func (r filterReasoner) contradictory(and FilterAnd) bool {
+	for i := 0; i < len(and); i++ {
+		if r.unsatisfiable(and[i]) {
+			return true
+		}
+
+		// If and[i] is (!x), any other operand implying
+		// x makes the conjunction unsatisfiable.
+		if not, isNot := and[i].(FilterNot); isNot {
+			for j := 0; j < len(and); j++ {
+				if j != i && r.implies(and[j], not.Filter) == filterYes {
+					return true
+				}
+			}
+		}
+	}
+
+	return r.singleValueConflict(and)
+}
+
+/*
+singleValueConflict returns a Boolean value indicative of whether the
+input conjunction asserts two different values of a single-valued type.
+*/
+func (r filterReasoner) singleValueConflict(and FilterAnd) bool {
+	values := make(map[string]string)
+	for i := 0; i < len(and); i++ {
+		eq, isEq := and[i].(FilterEqualityMatch)
+		if !isEq {
+			continue
+		}
+
+		at := r.attributeType(eq.Desc)
+		if at == nil || !at.Single || !r.normalizable(at) {
+			continue
+		}
+
+		key := eq.Desc.String()
+		if val, found := values[key]; found && val != string(eq.Value) {
+			return true
+		}
+		values[key] = string(eq.Value)
+	}
+
+	return false
+}
+
+/*
+satisfiable returns a Boolean value indicative of whether the input
+canonical filter was proven to match at least one conceivable entry.
+Only absolute true and conjunctions of non-contradictory, non-negated
+item filters are considered.
+*/
+func (r filterReasoner) satisfiable(f Filter) bool {
+	var terms []Filter
+	switch tv := f.(type) {
+	case FilterAnd:
+		terms = []Filter(tv)
+	case FilterOr, FilterNot:
+		return false
+	default:
+		terms = []Filter{tv}
+	}
+
+	types := make(map[string]int)
+	for i := 0; i < len(terms); i++ {
+		switch terms[i].(type) {
+		case FilterPresent, FilterEqualityMatch, FilterSubstrings,
+			FilterGreaterOrEqual, FilterLessOrEqual:
+		default:
+			return false
+		}
+
+		if r.schema != nil {
+			at := r.attributeType(filterAtomDesc(terms[i]))
+			if at == nil {
+				return false
+			}
+
+			// Multiple assertions upon a single-valued type
+			// could render otherwise innocuous ranges to be
+			// contradictory, so we won't vouch for them.
+			if types[at.NumericOID]++; at.Single && types[at.NumericOID] > 1 {
+				return false
+			}
+		}
+	}
+
+	return !r.contradictory(FilterAnd(terms))
+}
+
+/*
+counterexample returns a Boolean value indicative of whether a witness
+entry, which matches a but not b, can be conceived.
+*/
+func (r filterReasoner) counterexample(a, b Filter) bool {
+	if !r.satisfiable(a) {
+		return false
+	} else if r.unsatisfiable(b) {
+		return true
+	}
+
+	atom, _, ok := filterLiteral(b)
+	if !ok || !r.satisfiable(atom) {
+		return false
+	}
+
+	// The attribute type asserted by b must be demonstrably
+	// unrelated to all of those asserted by a. In such a case,
+	// a minimal witness for a either lacks the attribute (if b
+	// is positive), or may possess a value satisfying atom (if
+	// b is negated).
+	db := filterAtomDesc(atom)
+	var terms []Filter = []Filter{a}
+	if and, isAnd := a.(FilterAnd); isAnd {
+		terms = []Filter(and)
+	}
+
+	for i := 0; i < len(terms); i++ {
+		if !r.unrelated(filterAtomDesc(terms[i]), db) {
+			return false
+		}
+	}
+
+	return true
+}
+
+/*
+definite returns a Boolean value indicative of whether the input item
+filter can never evaluate as UNDEFINED.
+*/
+func (r filterReasoner) definite(f Filter) (def bool) {
+	switch tv := f.(type) {
+	case FilterPresent:
+		def = true
+	case FilterEqualityMatch, FilterApproximateMatch:
+		if at := r.attributeType(filterAtomDesc(tv)); at != nil {
+			def = at.EffectiveEquality() != nil
+		}
+	case FilterSubstrings:
+		if at := r.attributeType(filterAtomDesc(tv)); at != nil {
+			def = at.EffectiveSubstring() != nil
+		}
+	case FilterGreaterOrEqual, FilterLessOrEqual:
+		if at := r.attributeType(filterAtomDesc(tv)); at != nil {
+			def = at.EffectiveOrdering() != nil
+		}
+	}
+
+	return
+}
+
+/*
+normalizable returns a Boolean value indicative of whether the EQUALITY
+rule of the input type is one whose canonical values are comparable.
+*/
+func (r filterReasoner) normalizable(at *AttributeType) (ok bool) {
+	if mr := at.EffectiveEquality(); mr != nil {
+		_, ok = assertionValuePreparers[lc(mr.Identifier())]
+	}
+
+	return
+}
+
+/*
+attributeType returns the *[AttributeType] described by desc, or nil if
+no schema is in use or the type is unknown.
+*/
+func (r filterReasoner) attributeType(desc AttributeDescription) (at *AttributeType) {
+	if r.schema != nil && len(desc) > 0 {
+		if def, idx := r.schema.AttributeType(desc.Type()); idx != -1 {
+			at = def
+		}
+	}
+
+	return
+}
+
+/*
+descImplies returns a Boolean value indicative of whether a value of the
+type described by a is also a value of the type described by b. This is
+the case if both share a type (or b is a supertype of a, in which case
+strict must be false) and the options of b are a subset of those of a.
+*/
+func (r filterReasoner) descImplies(a, b AttributeDescription, strict bool) bool {
+	if !optionsSubset(b.Options(), a.Options()) {
+		return false
+	} else if streqf(a.Type(), b.Type()) {
+		return true
+	} else if strict {
+		return false
+	}
+
+	if at := r.attributeType(a); at != nil {
+		if bt := r.attributeType(b); bt != nil {
+			chain := at.SuperChain()
+			for i := 0; i < chain.Len(); i++ {
+				if chain.Index(i).NumericOID == bt.NumericOID {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+/*
+unrelated returns a Boolean value indicative of whether the types
+described by a and b are known to be distinct and not related through
+super typing. A schema is required, as aliases cannot be otherwise
+resolved.
+*/
+func (r filterReasoner) unrelated(a, b AttributeDescription) bool {
+	at, bt := r.attributeType(a), r.attributeType(b)
+	if at == nil || bt == nil || at.NumericOID == bt.NumericOID {
+		return false
+	}
+
+	return !r.descImplies(a, b, false) &&
+		!r.descImplies(b, a, false) &&
+		!r.descImplies(AttributeDescription(a.Type()), AttributeDescription(b.Type()), false) &&
+		!r.descImplies(AttributeDescription(b.Type()), AttributeDescription(a.Type()), false)
+}
+
+/*
+filterLiteral returns the item filter within f, which is either an item
+filter or a negated item filter.
+*/
+func filterLiteral(f Filter) (atom Filter, neg, ok bool) {
+	switch tv := f.(type) {
+	case FilterAnd, FilterOr, invalidFilter:
+	case FilterNot:
+		switch tv.Filter.(type) {
+		case FilterAnd, FilterOr, FilterNot, invalidFilter:
+		default:
+			atom, neg, ok = tv.Filter, true, true
+		}
+	default:
+		atom, ok = tv, true
+	}
+
+	return
+}
+
+/*
+filterAtomDesc returns the [AttributeDescription] asserted by the input
+item filter.
+*/
+func filterAtomDesc(f Filter) (desc AttributeDescription) {
+	switch tv := f.(type) {
+	case FilterPresent:
+		desc = tv.Desc
+	case FilterEqualityMatch:
+		desc = tv.Desc
+	case FilterApproximateMatch:
+		desc = tv.Desc
+	case FilterGreaterOrEqual:
+		desc = tv.Desc
+	case FilterLessOrEqual:
+		desc = tv.Desc
+	case FilterSubstrings:
+		desc = tv.Type
+	case FilterExtensibleMatch:
+		desc = tv.Type
+	}
+
+	return
+}
+
+func optionsSubset(sub, super []AttributeOption) bool {
+	for i := 0; i < len(sub); i++ {
+		var found bool
+		for j := 0; j < len(super) && !found; j++ {
+			found = streqf(sub[i].String(), super[j].String())
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+/*
+splitSubstringAny returns the unescaped "any" components of the input
+canonical [AssertionValue].
+*/
+func splitSubstringAny(any AssertionValue) (comps []string) {
+	for _, c := range split(string(any), `*`) {
+		if len(c) > 0 {
+			raw, _ := unescapeFilterValue(AssertionValue(c))
+			comps = append(comps, raw)
+		}
+	}
+
+	return
+}
+
+/*
+substringPatternMatch returns a Boolean value indicative of whether val
+matches the substring pattern described by initial, any and final.
+*/
+func substringPatternMatch(val, initial string, any []string, final string) bool {
+	if !hasPfx(val, initial) {
+		return false
+	}
+	val = val[len(initial):]
+
+	for _, c := range any {
+		idx := stridx(val, c)
+		if idx == -1 {
+			return false
+		}
+		val = val[idx+len(c):]
+	}
+
+	return hasSfx(val, final)
+}
+
+/*
+isSubsequence returns a Boolean value indicative of whether all of the
+slices in sub appear, in order, within super.
+*/
+func isSubsequence(sub, super []string) bool {
+	var j int
+	for i := 0; i < len(sub); i++ {
+		for j < len(super) && super[j] != sub[i] {
+			j++
+		}
+		if j == len(super) {
+			return false
+		}
+		j++
+	}
+
+	return true
+}
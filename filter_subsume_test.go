@@ -0,0 +1,226 @@
+package dirsyn
+
+import (
+	"fmt"
+	"testing"
+)
+
+/*
+This example demonstrates the means for determining whether all entries
+matched by one [Filter] are also matched by another.
+*/
+func ExampleRFC4515_FilterSubsumes() {
+	var r RFC4515
+	result, err := r.FilterSubsumes(
+		`(&(objectClass=person)(cn=Jesse*))`,
+		`(|(cn=Jes*)(sn=Coretta))`,
+		exampleSchema)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Println(result)
+	// Output: TRUE
+}
+
+/*
+This example demonstrates the means for determining whether a [Filter]
+range is contained within another by way of the ORDERING rule in force.
+*/
+func ExampleRFC4515_FilterSubsumes_ordering() {
+	var r RFC4515
+	result, err := r.FilterSubsumes(
+		`(modifyTimestamp>=20240101000000Z)`,
+		`(modifyTimestamp>=20230101000000Z)`,
+		exampleSchema)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Println(result)
+	// Output: TRUE
+}
+
+/*
+This example demonstrates the means for identifying an unsatisfiable
+[Filter].
+*/
+func ExampleRFC4515_FilterSatisfiable() {
+	var r RFC4515
+	result, err := r.FilterSatisfiable(`(&(a=1)(!(a=*)))`)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Println(result)
+	// Output: FALSE
+}
+
+/*
+filterTestType is a temporary integer-ordered attribute type, as none
+are present within the example schema.
+*/
+const filterTestType = `( 1.3.6.1.4.1.56521.999.88.1
+        NAME 'filterTestCount'
+        EQUALITY integerMatch
+        ORDERING integerOrderingMatch
+        SYNTAX 1.3.6.1.4.1.1466.115.121.1.27 )`
+
+func registerFilterTestType(t *testing.T) (cleanup func()) {
+	if err := exampleSchema.RegisterAttributeType(filterTestType); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+
+	return func() {
+		if err := exampleSchema.UnregisterAttributeType(filterTestType); err != nil {
+			t.Errorf("%s failed: %v", t.Name(), err)
+		}
+	}
+}
+
+func TestRFC4515_FilterSubsumes(t *testing.T) {
+	var r RFC4515
+	defer registerFilterTestType(t)()
+
+	for idx, strukt := range []struct {
+		A, B   string
+		Schema bool
+		Want   string
+	}{
+		{`(cn=Jesse)`, `(cn=Jesse)`, false, `TRUE`},
+		{`(cn=Jesse)`, `(cn=*)`, false, `TRUE`},
+		{`(cn;lang-en=Jesse)`, `(cn=*)`, false, `TRUE`},
+		{`(cn=*)`, `(cn;lang-en=*)`, false, `UNDEFINED`},
+		{`(cn=Jesse)`, `(commonName=*)`, false, `UNDEFINED`},
+		{`(cn=Jesse)`, `(commonName=*)`, true, `TRUE`},
+		{`(cn=Jesse)`, `(name=*)`, true, `TRUE`},
+		{`(cn=Jesse)`, `(CN=jesse)`, true, `TRUE`},
+		{`(cn=Jesse)`, `(cn=Je*)`, true, `TRUE`},
+		{`(cn=Jesse)`, `(cn=Je*)`, false, `UNDEFINED`},
+		{`(objectClass=person)`, `(objectClass=per*)`, false, `UNDEFINED`},
+		{`(objectClass=person)`, `(objectClass=per*)`, true, `UNDEFINED`},
+		{`(objectClass=per*)`, `(objectClass=pe*)`, true, `UNDEFINED`},
+		{`(cn=Jesse)`, `(cn=*ss*)`, true, `TRUE`},
+		{`(cn=Jesse)`, `(cn=*x*)`, true, `UNDEFINED`},
+		{`(cn=Jesse*Coretta)`, `(cn=Je*tta)`, true, `TRUE`},
+		{`(cn=Jesse*a*b*Coretta)`, `(cn=Je*b*)`, true, `TRUE`},
+		{`(cn=Je*)`, `(cn=Jesse*)`, true, `UNDEFINED`},
+		{`(&(cn=Jesse)(sn=Coretta))`, `(cn=Jesse)`, false, `TRUE`},
+		{`(cn=Jesse)`, `(&(cn=Jesse)(sn=Coretta))`, false, `UNDEFINED`},
+		{`(cn=Jesse)`, `(|(cn=Jesse)(sn=Coretta))`, false, `TRUE`},
+		{`(|(cn=Jesse)(cn=John))`, `(cn=*)`, false, `TRUE`},
+		{`(|(cn=Jesse)(sn=Coretta))`, `(cn=*)`, false, `UNDEFINED`},
+		{`(&(a=1)(!(a=*)))`, `(b=2)`, false, `TRUE`},
+		{`(cn=Jesse)`, `(&(a=1)(!(a=*)))`, false, `FALSE`},
+		{`(cn=Jesse)`, `(|)`, false, `FALSE`},
+		{`(cn=Jesse)`, `(&)`, false, `TRUE`},
+		{`(cn=Jesse)`, `(sn=*)`, false, `UNDEFINED`},
+		{`(cn=Jesse)`, `(sn=*)`, true, `FALSE`},
+		{`(cn=Jesse)`, `(!(sn=Coretta))`, true, `FALSE`},
+		{`(!(cn=*))`, `(!(cn=Jesse))`, false, `UNDEFINED`},
+		{`(!(cn=*))`, `(!(cn=Jesse))`, true, `TRUE`},
+		{`(!(cn=Jesse))`, `(!(cn=*))`, true, `UNDEFINED`},
+		{`(filterTestCount=10)`, `(filterTestCount>=5)`, true, `TRUE`},
+		{`(filterTestCount>=10)`, `(filterTestCount>=5)`, true, `TRUE`},
+		{`(filterTestCount>=5)`, `(filterTestCount>=10)`, true, `UNDEFINED`},
+		{`(filterTestCount<=5)`, `(filterTestCount<=10)`, true, `TRUE`},
+		{`(filterTestCount=5)`, `(filterTestCount<=10)`, true, `TRUE`},
+		{`(filterTestCount<=5)`, `(filterTestCount<=10)`, false, `UNDEFINED`},
+		{`(modifyTimestamp<=20230101000000Z)`, `(modifyTimestamp<=20240101000000Z)`, true, `TRUE`},
+		{`(cn>=b)`, `(cn>=a)`, true, `UNDEFINED`},
+	} {
+		var result Boolean
+		var err error
+		if strukt.Schema {
+			result, err = r.FilterSubsumes(strukt.A, strukt.B, exampleSchema)
+		} else {
+			result, err = r.FilterSubsumes(strukt.A, strukt.B)
+		}
+
+		if err != nil {
+			t.Errorf("%s[%d] failed: %v", t.Name(), idx, err)
+		} else if got := result.String(); got != strukt.Want {
+			t.Errorf("%s[%d] failed: %s vs. %s\nwant: %s\ngot:  %s",
+				t.Name(), idx, strukt.A, strukt.B, strukt.Want, got)
+		}
+	}
+}
+
+func TestRFC4515_FilterSatisfiable(t *testing.T) {
+	var r RFC4515
+	defer registerFilterTestType(t)()
+
+	for idx, strukt := range []struct {
+		Input  string
+		Schema bool
+		Want   string
+	}{
+		{`(&(a=1)(!(a=*)))`, false, `FALSE`},
+		{`(&(cn=Jesse)(!(cn=Jesse)))`, false, `FALSE`},
+		{`(&(cn=Jesse)(|(sn=x)(&(a=1)(!(a=*)))))`, false, `UNDEFINED`},
+		{`(|(&(a=1)(!(a=*)))(&(b=1)(!(b=*))))`, false, `FALSE`},
+		{`(&(cn=Jesse)(!(name=*)))`, true, `FALSE`},
+		{`(&(cn=Jesse)(sn=Coretta))`, false, `TRUE`},
+		{`(&(cn=Jesse)(sn=Coretta))`, true, `TRUE`},
+		{`(&(governingStructureRule=1)(governingStructureRule=2))`, true, `FALSE`},
+		{`(&(cn=Jesse)(!(sn=Coretta)))`, false, `UNDEFINED`},
+		{`(|)`, false, `FALSE`},
+		{`(&)`, false, `TRUE`},
+	} {
+		var result Boolean
+		var err error
+		if strukt.Schema {
+			result, err = r.FilterSatisfiable(strukt.Input, exampleSchema)
+		} else {
+			result, err = r.FilterSatisfiable(strukt.Input)
+		}
+
+		if err != nil {
+			t.Errorf("%s[%d] failed: %v", t.Name(), idx, err)
+		} else if got := result.String(); got != strukt.Want {
+			t.Errorf("%s[%d] failed: %s\nwant: %s\ngot:  %s",
+				t.Name(), idx, strukt.Input, strukt.Want, got)
+		}
+	}
+}
+
+func TestRFC4515_FilterSubsumes_codecov(t *testing.T) {
+	var r RFC4515
+
+	if _, err := r.FilterSubsumes(`(cn=bogus`, `(cn=*)`); err == nil {
+		t.Errorf("%s failed: expected error, got nil", t.Name())
+	}
+	if _, err := r.FilterSubsumes(`(cn=*)`, `(cn=bogus`); err == nil {
+		t.Errorf("%s failed: expected error, got nil", t.Name())
+	}
+	if _, err := r.FilterSatisfiable(`(cn=bogus`); err == nil {
+		t.Errorf("%s failed: expected error, got nil", t.Name())
+	}
+
+	var rsn filterReasoner
+	rsn.atomImplies(FilterPresent{}, FilterPresent{})
+	rsn.atomImplies(FilterExtensibleMatch{
+		Type:         AttributeDescription(`cn`),
+		DNAttributes: true,
+	}, FilterPresent{Desc: AttributeDescription(`cn`)})
+	rsn.definite(FilterEqualityMatch{})
+	filterLiteral(FilterNot{FilterAnd{}})
+	filterAtomDesc(FilterApproximateMatch{})
+	filterAtomDesc(FilterExtensibleMatch{})
+
+	defer registerFilterTestType(t)()
+	rsn = newFilterReasoner(exampleSchema)
+	for _, f := range []string{`(cn=x)`, `(cn~=x)`, `(cn=x*)`, `(cn>=x)`, `(createTimestamp<=x)`} {
+		filter, _ := marshalFilter(f)
+		rsn.definite(filter)
+	}
+	rsn.compareOrdered(AttributeDescription(`filterTestCount`),
+		AssertionValue(`x`), AssertionValue(`1`))
+	rsn.compareOrdered(AttributeDescription(`cn`),
+		AssertionValue(`x`), AssertionValue(`1`))
+	rsn.compareOrdered(AttributeDescription(`bogus`),
+		AssertionValue(`x`), AssertionValue(`1`))
+}
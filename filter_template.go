@@ -0,0 +1,290 @@
+package dirsyn
+
+/*
+filter_template.go contains parameterized Filter template methods and
+types.
+*/
+
+/*
+FilterTemplate implements a parameterized [Filter] skeleton, in which
+assertion values may bear named or positional placeholders, such as:
+
+	(&(objectClass=person)(uid={0})(mail={email}))
+
+Instances of this type are produced by [RFC4515.FilterTemplate], and are
+safe for concurrent use by multiple goroutines.
+*/
+type FilterTemplate struct {
+	text     string
+	skeleton Filter
+	names    []string
+	tokens   map[string]string // name -> sentinel
+}
+
+/*
+filterPlaceholderPrefix is the prefix of the sentinel values substituted
+in place of placeholders prior to parsing.
+*/
+const filterPlaceholderPrefix = `dirsynTemplateValue`
+
+/*
+FilterTemplate returns an instance of [FilterTemplate] alongside an error
+following an attempt to parse x.
+
+Placeholders are of the form "{name}", where name is composed of ASCII
+letters, digits, hyphens, underscores and periods (e.g.: "{0}", "{email}").
+Braces which do not form a placeholder are left intact. A literal brace
+may also be expressed as "\7b" or "\7d".
+
+Placeholders are only permitted within assertion values, including the
+individual components of a substring assertion. For example:
+
+	(&(cn={first}*{last})(|(uid={0})(mail={0}@*)))
+
+The template is parsed exactly once. Values are later supplied by way of
+the [FilterTemplate.Bind] or [FilterTemplate.BindArgs] methods, which
+escape each value per [§ 3 of RFC 4515] and write it directly into the
+relevant [AssertionValue]. User input never passes through the filter
+parser, and thus can never alter the structure of the [Filter]. This
+includes asterisks, which are always treated literally.
+
+[§ 3 of RFC 4515]: https://datatracker.ietf.org/doc/html/rfc4515#section-3
+*/
+func (r RFC4515) FilterTemplate(x string) (tmpl FilterTemplate, err error) {
+	if cntns(x, filterPlaceholderPrefix) {
+		err = errorTxt("Filter template contains reserved sequence " + filterPlaceholderPrefix)
+		return
+	}
+
+	tmpl.text = x
+	tmpl.tokens = make(map[string]string)
+
+	bld := newStrBuilder()
+	for i := 0; i < len(x); i++ {
+		name, ok := filterPlaceholderName(x[i:])
+		if !ok {
+			bld.WriteByte(x[i])
+			continue
+		}
+
+		token, seen := tmpl.tokens[name]
+		if !seen {
+			token = filterPlaceholderPrefix + itoa(len(tmpl.names)) + `z`
+			tmpl.tokens[name] = token
+			tmpl.names = append(tmpl.names, name)
+		}
+
+		bld.WriteString(token)
+		i += len(name) + 1
+	}
+
+	if tmpl.skeleton, err = marshalFilter(bld.String()); err != nil {
+		tmpl = FilterTemplate{}
+		return
+	} else if err = checkFilterTemplate(tmpl.skeleton); err != nil {
+		tmpl = FilterTemplate{}
+	}
+
+	return
+}
+
+/*
+filterPlaceholderName returns the name of the placeholder at the start
+of x, alongside a Boolean value indicative of success.
+*/
+func filterPlaceholderName(x string) (name string, ok bool) {
+	if len(x) < 3 || x[0] != '{' {
+		return
+	}
+
+	for i := 1; i < len(x); i++ {
+		switch c := rune(x[i]); {
+		case c == '}':
+			if i > 1 {
+				name, ok = x[1:i], true
+			}
+			return
+		case isAlnum(c), c == '-', c == '_', c == '.':
+		default:
+			return
+		}
+	}
+
+	return
+}
+
+/*
+checkFilterTemplate returns an error if any placeholder was found outside
+of an assertion value within f.
+*/
+func checkFilterTemplate(f Filter) (err error) {
+	var misplaced bool
+	switch tv := f.(type) {
+	case FilterAnd:
+		for i := 0; i < len(tv) && err == nil; i++ {
+			err = checkFilterTemplate(tv[i])
+		}
+		return
+	case FilterOr:
+		for i := 0; i < len(tv) && err == nil; i++ {
+			err = checkFilterTemplate(tv[i])
+		}
+		return
+	case FilterNot:
+		return checkFilterTemplate(tv.Filter)
+	case FilterExtensibleMatch:
+		misplaced = cntns(string(tv.Type), filterPlaceholderPrefix) ||
+			cntns(string(tv.MatchingRule), filterPlaceholderPrefix)
+	default:
+		misplaced = cntns(string(filterAtomDesc(f)), filterPlaceholderPrefix)
+	}
+
+	if misplaced {
+		err = errorTxt("Filter template placeholders are only permitted within assertion values")
+	}
+
+	return
+}
+
+/*
+Placeholders returns the names of all placeholders present within the
+receiver instance, in order of first appearance.
+*/
+func (r FilterTemplate) Placeholders() (names []string) {
+	names = append(names, r.names...)
+	return
+}
+
+/*
+String returns the original template text of the receiver instance.
+*/
+func (r FilterTemplate) String() string { return r.text }
+
+/*
+IsZero returns a Boolean value indicative of a nil receiver state.
+*/
+func (r FilterTemplate) IsZero() bool { return r.skeleton == nil }
+
+/*
+Bind returns an instance of [Filter] alongside an error following the
+assignment of values to the placeholders of the receiver instance. Keys
+are placeholder names, and positional placeholders (e.g.: "{0}") are
+keyed by their decimal string form (e.g.: "0").
+
+An error is returned if a value was not provided for every placeholder.
+Values for which no placeholder exists are ignored.
+
+An error is also returned if the components of a substring assertion all
+become empty following the assignment of values, as the assertion would
+otherwise be widened into a presence check (e.g.: "(uid={0}*)" becoming
+"(uid=*)"), which matches any entry bearing the type.
+*/
+func (r FilterTemplate) Bind(values map[string]string) (filter Filter, err error) {
+	filter = invalidFilter{}
+	if r.IsZero() {
+		err = nilInstanceErr
+		return
+	}
+
+	var pairs []string
+	for _, name := range r.names {
+		value, found := values[name]
+		if !found {
+			err = errorTxt("Missing value for filter template placeholder {" + name + "}")
+			return
+		}
+		var av AssertionValue
+		av.Set(value)
+		pairs = append(pairs, r.tokens[name], string(av))
+	}
+
+	// A Replacer performs all substitutions in a single pass,
+	// thus placeholder-like content within one value is never
+	// subject to substitution by another.
+	var bound Filter
+	if bound, err = bindFilterTemplate(r.skeleton, newRepl(pairs...)); err == nil {
+		filter = bound
+	}
+
+	return
+}
+
+/*
+BindArgs is a convenience wrapper for [FilterTemplate.Bind], in which
+values are assigned to positional placeholders, such that the first
+value is assigned to "{0}", the second to "{1}" and so on.
+*/
+func (r FilterTemplate) BindArgs(values ...string) (Filter, error) {
+	m := make(map[string]string, len(values))
+	for i, value := range values {
+		m[itoa(i)] = value
+	}
+
+	return r.Bind(m)
+}
+
+/*
+bindFilterTemplate returns a copy of f in which all assertion values
+have been subjected to repl, alongside an error.
+*/
+func bindFilterTemplate(f Filter, repl interface{ Replace(string) string }) (out Filter, err error) {
+	val := func(v AssertionValue) AssertionValue {
+		return AssertionValue(repl.Replace(string(v)))
+	}
+
+	switch tv := f.(type) {
+	case FilterAnd:
+		and := make(FilterAnd, len(tv))
+		for i := 0; i < len(tv) && err == nil; i++ {
+			and[i], err = bindFilterTemplate(tv[i], repl)
+		}
+		out = and
+	case FilterOr:
+		or := make(FilterOr, len(tv))
+		for i := 0; i < len(tv) && err == nil; i++ {
+			or[i], err = bindFilterTemplate(tv[i], repl)
+		}
+		out = or
+	case FilterNot:
+		var not Filter
+		not, err = bindFilterTemplate(tv.Filter, repl)
+		out = FilterNot{not}
+	case FilterEqualityMatch:
+		out = FilterEqualityMatch{Desc: tv.Desc, Value: val(tv.Value)}
+	case FilterApproximateMatch:
+		out = FilterApproximateMatch{Desc: tv.Desc, Value: val(tv.Value)}
+	case FilterGreaterOrEqual:
+		out = FilterGreaterOrEqual{Desc: tv.Desc, Value: val(tv.Value)}
+	case FilterLessOrEqual:
+		out = FilterLessOrEqual{Desc: tv.Desc, Value: val(tv.Value)}
+	case FilterExtensibleMatch:
+		tv.MatchValue = val(tv.MatchValue)
+		out = tv
+	case FilterSubstrings:
+		// Components emptied by way of binding are dropped,
+		// as consecutive asterisks are not permitted.
+		var anys []string
+		for _, a := range split(string(tv.Substrings.Any), `*`) {
+			if a = repl.Replace(a); len(a) > 0 {
+				anys = append(anys, a)
+			}
+		}
+		ssa := SubstringAssertion{
+			Initial: val(tv.Substrings.Initial),
+			Any:     AssertionValue(join(anys, `*`)),
+			Final:   val(tv.Substrings.Final),
+		}
+		if ssa.IsZero() {
+			// Never widen a bound assertion into
+			// a presence check.
+			err = errorTxt("Filter template values empty all substring components for '" +
+				tv.Type.String() + "'")
+		} else {
+			out = FilterSubstrings{Type: tv.Type, Substrings: ssa}
+		}
+	default:
+		out = f
+	}
+
+	return
+}
@@ -0,0 +1,143 @@
+package dirsyn
+
+import (
+	"fmt"
+	"testing"
+)
+
+/*
+This example demonstrates the means for safely producing a [Filter] from
+untrusted user input by way of an instance of [FilterTemplate].
+*/
+func ExampleRFC4515_FilterTemplate() {
+	var r RFC4515
+	tmpl, err := r.FilterTemplate(`(&(objectClass=person)(uid={0})(mail={email}))`)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	f, err := tmpl.Bind(map[string]string{
+		`0`:     `*)(uid=*`,
+		`email`: `jesse@example.com`,
+	})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Println(f)
+	// Output: (&(objectClass=person)(uid=\2a\29\28uid=\2a)(mail=jesse@example.com))
+}
+
+/*
+This example demonstrates the use of positional placeholders within the
+components of a substring assertion.
+*/
+func ExampleFilterTemplate_BindArgs() {
+	var r RFC4515
+	tmpl, _ := r.FilterTemplate(`(cn={0}*{1})`)
+
+	f, err := tmpl.BindArgs(`Je*`, `Coretta`)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Println(f)
+	// Output: (cn=Je\2a*Coretta)
+}
+
+func TestFilterTemplate(t *testing.T) {
+	var r RFC4515
+
+	for idx, strukt := range []struct {
+		Template string
+		Values   map[string]string
+		Want     string
+	}{
+		{`(cn={name})`, map[string]string{`name`: `Jesse`}, `(cn=Jesse)`},
+		{`(cn=Mr. {name} Jr.)`, map[string]string{`name`: `Jesse`}, `(cn=Mr. Jesse Jr.)`},
+		{`(cn={name})`, map[string]string{`name`: `*`}, `(cn=\2a)`},
+		{`(cn={name})`, map[string]string{`name`: `Lučić`}, `(cn=Lu\c4\8di\c4\87)`},
+		{`(cn={name})`, map[string]string{`name`: `a\b`}, `(cn=a\5cb)`},
+		{`(cn={name})`, map[string]string{`name`: `a\2ab`}, `(cn=a\5c2ab)`},
+		{`(cn={name})`, map[string]string{`name`: "(a\x00)"}, `(cn=\28a\00\29)`},
+		{`(cn={name})`, map[string]string{`name`: ``}, `(cn=)`},
+		{`(|(uid={0})(mail={0}@*))`, map[string]string{`0`: `jesse`}, `(|(uid=jesse)(mail=jesse@*))`},
+		{`(cn=*{x}*)`, map[string]string{`x`: `a*b`}, `(cn=*a\2ab*)`},
+		{`(cn=*a*{x}*b*)`, map[string]string{`x`: ``}, `(cn=*a*b*)`},
+		{`(!(n>={x}))`, map[string]string{`x`: `5`}, `(!(n>=5))`},
+		{`(n<={x})`, map[string]string{`x`: `5`}, `(n<=5)`},
+		{`(givenName~={x})`, map[string]string{`x`: `Jessi`}, `(givenName~=Jessi)`},
+		{`(cn:caseExactMatch:={x})`, map[string]string{`x`: `Jesse`}, `(cn:caseExactMatch:=Jesse)`},
+		{`(cn={x}{y})`, map[string]string{`x`: `{y}`, `y`: `!`}, `(cn={y}!)`},
+		{`(cn={ x })`, nil, `(cn={ x })`},
+		{`(cn=*)`, nil, `(cn=*)`},
+	} {
+		tmpl, err := r.FilterTemplate(strukt.Template)
+		if err != nil {
+			t.Errorf("%s[%d] failed: %v", t.Name(), idx, err)
+			continue
+		} else if tmpl.String() != strukt.Template {
+			t.Errorf("%s[%d] failed: template text mismatch", t.Name(), idx)
+		}
+
+		f, err := tmpl.Bind(strukt.Values)
+		if err != nil {
+			t.Errorf("%s[%d] failed: %v", t.Name(), idx, err)
+		} else if got := f.String(); got != strukt.Want {
+			t.Errorf("%s[%d] failed:\nwant: %s\ngot:  %s",
+				t.Name(), idx, strukt.Want, got)
+		} else if _, err = f.BER(); err != nil {
+			t.Errorf("%s[%d] BER encoding failed: %v", t.Name(), idx, err)
+		}
+	}
+}
+
+func TestFilterTemplate_codecov(t *testing.T) {
+	var r RFC4515
+
+	for _, bogus := range []string{
+		`({attr}=x)`,
+		`(cn:{rule}:=x)`,
+		`(cn={0}`,
+		`(cn=` + filterPlaceholderPrefix + `)`,
+	} {
+		if _, err := r.FilterTemplate(bogus); err == nil {
+			t.Errorf("%s failed: expected error for %s, got nil", t.Name(), bogus)
+		}
+	}
+
+	var tmpl FilterTemplate
+	if !tmpl.IsZero() {
+		t.Errorf("%s failed: bogus zero state", t.Name())
+	} else if _, err := tmpl.Bind(nil); err == nil {
+		t.Errorf("%s failed: expected error, got nil", t.Name())
+	}
+
+	tmpl, _ = r.FilterTemplate(`(&(uid={0})(mail={1})(uid={0}))`)
+	if names := tmpl.Placeholders(); fmt.Sprint(names) != `[0 1]` {
+		t.Errorf("%s failed: unexpected placeholders %v", t.Name(), names)
+	}
+	if _, err := tmpl.BindArgs(`jesse`); err == nil {
+		t.Errorf("%s failed: expected error, got nil", t.Name())
+	}
+
+	// substring assertions are never widened into presence checks
+	for idx, bogus := range []string{
+		`(cn=*{x}*)`,
+		`(uid={0}*)`,
+		`(cn=*{x}*{x}*)`,
+		`(&(objectClass=person)(!(|(sn=x)(cn={0}*))))`,
+	} {
+		tmpl, _ = r.FilterTemplate(bogus)
+		if f, err := tmpl.Bind(map[string]string{`0`: ``, `x`: ``}); err == nil {
+			t.Errorf("%s[%d] failed: expected error, got %s", t.Name(), idx, f)
+		} else if _, isInvalid := f.(invalidFilter); !isInvalid {
+			t.Errorf("%s[%d] failed: unexpected filter %s", t.Name(), idx, f)
+		}
+	}
+
+	bindFilterTemplate(FilterPresent{Desc: AttributeDescription(`cn`)}, newRepl())
+}
@@ -27,6 +27,33 @@ func ExampleAssertionValue_Set() {
 	// Output: Lu\c4\8di\c4\87 / Lučić
 }
 
+func TestAssertionValue_Escaped(t *testing.T) {
+	for idx, strukt := range []struct {
+		Value AssertionValue
+		Want  string
+	}{
+		{AssertionValue(``), ``},
+		{AssertionValue(`Jesse`), `Jesse`},
+		{AssertionValue(`Lučić (*)`), `Lu\c4\8di\c4\87 \28\2a\29`},
+		{AssertionValue("a\x00b"), `a\00b`},
+		{AssertionValue(`a\2ab\5C`), `a\2ab\5C`},
+		{AssertionValue(`a\b`), `a\5cb`},
+		{AssertionValue(`a\2`), `a\5c2`},
+		{AssertionValue(`\zz\`), `\5czz\5c`},
+	} {
+		if got := strukt.Value.Escaped(); got != strukt.Want {
+			t.Errorf("%s[%d] failed:\nwant: %s\ngot:  %s", t.Name(), idx, strukt.Want, got)
+		} else if again := AssertionValue(got).Escaped(); again != got {
+			t.Errorf("%s[%d] failed: not idempotent: %s", t.Name(), idx, again)
+		}
+	}
+
+	var av AssertionValue
+	if av.Set(`a\2a*`); string(av) != `a\5c2a\2a` {
+		t.Errorf("%s failed: unexpected Set result %s", t.Name(), av)
+	}
+}
+
 /*
 This example demonstrates the means for accessing the BER encoding of
 an instance of [Filter].
@@ -121,7 +148,7 @@ func TestFilter(t *testing.T) {
 		{
 			Input:  `(objectClass=`,
 			Output: ``,
-			Error:  `Unexpected end of filter`,
+			Error:  `Unexpected end of filter at line 1, column 14: expected ')' near "ctClass="`,
 			Choice: `invalid`,
 		},
 		{
@@ -755,3 +782,121 @@ func BenchmarkFilterParse(b *testing.B) {
 		_, _ = r.Filter(pkt)
 	}
 }
+
+var benchmarkFilterEntry FilterEntry = FilterEntry{
+	`objectclass`:     {`top`, `person`, `organizationalPerson`, `inetOrgPerson`},
+	`cn`:              {`Jesse Coretta`},
+	`sn`:              {`Coretta`},
+	`givenname`:       {`Jesse`},
+	`mail`:            {`jesse@example.com`},
+	`telephonenumber`: {`+1 555 555 1212`},
+	`description`:     {`Some  Person`},
+	`modifytimestamp`: {`20240601000000Z`},
+}
+
+const benchmarkCompiledFilter = `(&(objectClass=person)(|(sn=Smith)(cn=jes*cor*))(!(description=some other person))(modifyTimestamp>=20240101000000Z))`
+
+func BenchmarkFilterCompiledMatch(b *testing.B) {
+	var r RFC4515
+	cf, err := r.CompileFilter(benchmarkCompiledFilter, exampleSchema)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if !cf.Match(benchmarkFilterEntry) {
+			b.Fatal("unexpected mismatch")
+		}
+	}
+}
+
+func BenchmarkFilterUncompiledMatch(b *testing.B) {
+	var r RFC4515
+	f, err := r.Filter(benchmarkCompiledFilter)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if !benchmarkTreeMatch(f, benchmarkFilterEntry) {
+			b.Fatal("unexpected mismatch")
+		}
+	}
+}
+
+/*
+benchmarkTreeMatch walks the parsed Filter tree, resolving the attribute
+type and matching rule of each item and invoking the rule's assertion
+function for every value, which is the work a CompiledFilter performs
+only once.
+*/
+func benchmarkTreeMatch(f Filter, entry FilterEntry) bool {
+	assert := func(desc AttributeDescription, value string, kind string, op byte) bool {
+		at, idx := exampleSchema.AttributeType(desc.Type())
+		if idx == -1 {
+			return false
+		}
+
+		var mr *MatchingRule
+		switch kind {
+		case `EQUALITY`:
+			mr = at.EffectiveEquality()
+		case `ORDERING`:
+			mr = at.EffectiveOrdering()
+		case `SUBSTR`:
+			mr = at.EffectiveSubstring()
+		}
+		if mr == nil {
+			return false
+		}
+
+		for _, val := range entry[lc(at.Identifier())] {
+			var result Boolean
+			switch funk := matchingRuleAssertions[mr.NumericOID].(type) {
+			case EqualityRuleAssertion:
+				result, _ = funk(val, value)
+			case SubstringsRuleAssertion:
+				result, _ = funk(val, value)
+			case OrderingRuleAssertion:
+				// ordering rules take the assertion first
+				result, _ = funk(value, val, op)
+			}
+			if result.True() {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	switch tv := f.(type) {
+	case FilterAnd:
+		for _, sub := range tv {
+			if !benchmarkTreeMatch(sub, entry) {
+				return false
+			}
+		}
+		return true
+	case FilterOr:
+		for _, sub := range tv {
+			if benchmarkTreeMatch(sub, entry) {
+				return true
+			}
+		}
+		return false
+	case FilterNot:
+		return !benchmarkTreeMatch(tv.Filter, entry)
+	case FilterEqualityMatch:
+		return assert(tv.Desc, string(tv.Value), `EQUALITY`, 0)
+	case FilterGreaterOrEqual:
+		return assert(tv.Desc, string(tv.Value), `ORDERING`, GreaterOrEqual)
+	case FilterLessOrEqual:
+		return assert(tv.Desc, string(tv.Value), `ORDERING`, LessOrEqual)
+	case FilterSubstrings:
+		return assert(tv.Type, tv.Substrings.String(), `SUBSTR`, 0)
+	}
+
+	return false
+}
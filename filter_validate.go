@@ -0,0 +1,259 @@
+package dirsyn
+
+/*
+filter_validate.go contains Filter validation methods, which check a
+Filter against the definitions of a schema.
+*/
+
+/*
+substringAssertionOID is the numeric OID of the Substring Assertion syntax.
+*/
+const substringAssertionOID = `1.3.6.1.4.1.1466.115.121.1.58`
+
+/*
+substringEscaper escapes the characters significant to a single component
+of a Substring Assertion value, per § 3.3.30 of RFC 4517.
+*/
+var substringEscaper = newRepl(`\`, `\5C`, `*`, `\2A`)
+
+/*
+FilterProblem describes a single problem found within a [Filter] during
+validation by way of [RFC4515.ValidateFilter].
+*/
+type FilterProblem struct {
+	// Filter contains the offending sub-filter.
+	Filter Filter
+
+	// Path contains the indices leading from the outermost
+	// Filter to the offending sub-filter. Each index refers
+	// to an operand of a FilterAnd or FilterOr instance; a
+	// FilterNot instance contributes an index of zero. The
+	// Path of the outermost Filter is empty.
+	Path []int
+
+	// Err describes the nature of the problem.
+	Err error
+}
+
+/*
+Error returns the string representation of the receiver instance, which
+includes the offending sub-filter.
+*/
+func (r FilterProblem) Error() (s string) {
+	if r.Err != nil {
+		s = r.Err.Error()
+		if r.Filter != nil {
+			s += `: ` + r.Filter.String()
+		}
+	}
+
+	return
+}
+
+/*
+Unwrap returns the underlying error of the receiver instance.
+*/
+func (r FilterProblem) Unwrap() error { return r.Err }
+
+/*
+ValidateFilter returns slices of [FilterProblem] alongside an error
+following an attempt to validate x against schema.
+
+The input value x may be an existing [Filter] qualifier instance, or any
+value accepted by [RFC4515.Filter]. The error is only non-nil if x could
+not be parsed, or if schema is nil.
+
+The following are reported:
+
+  - Unknown attribute types
+  - Equality and approximate assertions upon types with no EQUALITY rule
+  - Ordering assertions upon types with no ORDERING rule
+  - Substring assertions upon types with no SUBSTR rule
+  - Extensible matches bearing an unknown matching rule, or a rule which is not applicable to the type per the relevant [MatchingRuleUse]
+  - Assertion values which violate the assertion syntax of the matching rule in force (e.g.: a non-numeric value for integerMatch)
+
+Problems are returned in the order in which they appear within x. Each
+of the above would cause the item in question to evaluate as UNDEFINED
+per [§ 4.5.1.7 of RFC 4511].
+
+[§ 4.5.1.7 of RFC 4511]: https://datatracker.ietf.org/doc/html/rfc4511#section-4.5.1.7
+*/
+func (r RFC4515) ValidateFilter(x any, schema *SubschemaSubentry) (problems []FilterProblem, err error) {
+	if schema == nil {
+		err = nilInstanceErr
+		return
+	}
+
+	var f Filter
+	if f, err = assertFilter(x); err != nil {
+		return
+	}
+
+	v := filterValidator{filterCompiler: filterCompiler{schema: schema}}
+	v.validate(f, nil)
+	problems = v.problems
+
+	return
+}
+
+/*
+filterValidator accumulates problems found during the walk of a [Filter].
+*/
+type filterValidator struct {
+	filterCompiler
+	problems []FilterProblem
+}
+
+func (r *filterValidator) report(f Filter, path []int, err error) {
+	r.problems = append(r.problems, FilterProblem{
+		Filter: f,
+		Path:   append([]int{}, path...),
+		Err:    err,
+	})
+}
+
+func (r *filterValidator) validate(f Filter, path []int) {
+	switch tv := f.(type) {
+	case FilterAnd:
+		for i := 0; i < len(tv); i++ {
+			r.validate(tv[i], append(path, i))
+		}
+	case FilterOr:
+		for i := 0; i < len(tv); i++ {
+			r.validate(tv[i], append(path, i))
+		}
+	case FilterNot:
+		r.validate(tv.Filter, append(path, 0))
+	case FilterPresent:
+		r.attributeType(f, path, tv.Desc)
+	case FilterEqualityMatch:
+		r.validateEquality(f, path, tv.Desc, tv.Value)
+	case FilterApproximateMatch:
+		r.validateEquality(f, path, tv.Desc, tv.Value)
+	case FilterGreaterOrEqual:
+		r.validateOrdering(f, path, tv.Desc, tv.Value)
+	case FilterLessOrEqual:
+		r.validateOrdering(f, path, tv.Desc, tv.Value)
+	case FilterSubstrings:
+		r.validateSubstrings(tv, path)
+	case FilterExtensibleMatch:
+		r.validateExtensible(tv, path)
+	default:
+		r.report(f, path, invalidFilterErr)
+	}
+}
+
+/*
+attributeType returns the *[AttributeType] described by desc, or nil
+following the report of a problem.
+*/
+func (r *filterValidator) attributeType(f Filter, path []int, desc AttributeDescription) (at *AttributeType) {
+	var idx int
+	if at, idx = r.schema.AttributeType(desc.Type()); idx == -1 {
+		at = nil
+		r.report(f, path, unknownATErr)
+	}
+
+	return
+}
+
+func (r *filterValidator) validateEquality(f Filter, path []int, desc AttributeDescription, value AssertionValue) {
+	if at := r.attributeType(f, path, desc); at != nil {
+		if mr := at.EffectiveEquality(); mr == nil {
+			r.report(f, path, noEqualityMRErr)
+		} else {
+			r.validateAssertion(f, path, mr, value)
+		}
+	}
+}
+
+func (r *filterValidator) validateOrdering(f Filter, path []int, desc AttributeDescription, value AssertionValue) {
+	if at := r.attributeType(f, path, desc); at != nil {
+		if mr := at.EffectiveOrdering(); mr == nil {
+			r.report(f, path, noOrderingMRErr)
+		} else {
+			r.validateAssertion(f, path, mr, value)
+		}
+	}
+}
+
+/*
+validateSubstrings verifies each "initial", "any" and "final" component of
+f against the assertion syntax of the SUBSTR rule in force. Components are
+verified as individual values of the Substring Assertion syntax, if used
+by the rule, as described in [§ 3.3.30 of RFC 4517].
+
+[§ 3.3.30 of RFC 4517]: https://datatracker.ietf.org/doc/html/rfc4517#section-3.3.30
+*/
+func (r *filterValidator) validateSubstrings(f FilterSubstrings, path []int) {
+	at := r.attributeType(f, path, f.Type)
+	if at == nil {
+		return
+	}
+
+	mr := at.EffectiveSubstring()
+	if mr == nil {
+		r.report(f, path, noSubstringMRErr)
+		return
+	}
+
+	comps := []AssertionValue{f.Substrings.Initial}
+	for _, a := range split(string(f.Substrings.Any), `*`) {
+		comps = append(comps, AssertionValue(a))
+	}
+	comps = append(comps, f.Substrings.Final)
+
+	for _, comp := range comps {
+		raw, err := unescapeFilterValue(comp)
+		if err != nil {
+			r.report(f, path, err)
+			return
+		} else if len(raw) == 0 {
+			continue
+		}
+
+		if mr.Syntax == substringAssertionOID {
+			raw = `*` + substringEscaper.Replace(raw) + `*`
+		}
+
+		if !r.verifyAssertion(mr, raw) {
+			r.report(f, path, errorf("%w %s", badAssertionErr, mr.Identifier()))
+			return
+		}
+	}
+}
+
+func (r *filterValidator) validateExtensible(f FilterExtensibleMatch, path []int) {
+	var at *AttributeType
+	if len(f.Type) > 0 {
+		if at = r.attributeType(f, path, f.Type); at == nil {
+			return
+		}
+	}
+
+	var mr *MatchingRule
+	if id := f.MatchingRule.String(); len(id) > 0 {
+		var idx int
+		if mr, idx = r.schema.MatchingRule(id); idx == -1 {
+			r.report(f, path, unknownMRErr)
+			return
+		}
+	} else if mr = filterEqualityRule(at); mr == nil {
+		r.report(f, path, noEqualityMRErr)
+		return
+	}
+
+	if _, ok := r.ruleApplies(at, mr); !ok {
+		r.report(f, path, inapplicableMRErr)
+	} else {
+		r.validateAssertion(f, path, mr, f.MatchValue)
+	}
+}
+
+func (r *filterValidator) validateAssertion(f Filter, path []int, mr *MatchingRule, value AssertionValue) {
+	if raw, err := unescapeFilterValue(value); err != nil {
+		r.report(f, path, err)
+	} else if !r.verifyAssertion(mr, raw) {
+		r.report(f, path, errorf("%w %s", badAssertionErr, mr.Identifier()))
+	}
+}
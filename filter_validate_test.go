@@ -0,0 +1,113 @@
+package dirsyn
+
+import (
+	"fmt"
+	"testing"
+)
+
+/*
+This example demonstrates the means for validating a [Filter] against
+a *[SubschemaSubentry] instance prior to its use in a search.
+*/
+func ExampleRFC4515_ValidateFilter() {
+	var r RFC4515
+	problems, err := r.ValidateFilter(`(&(cn=Jesse)(|(bogusType=x)(governingStructureRule=abc)))`, exampleSchema)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	for _, problem := range problems {
+		fmt.Println(problem.Path, problem)
+	}
+	// Output:
+	// [1 0] Unknown attribute type: (bogusType=x)
+	// [1 1] Assertion value violates matching rule syntax integerMatch: (governingStructureRule=abc)
+}
+
+func TestRFC4515_ValidateFilter(t *testing.T) {
+	var r RFC4515
+
+	for idx, strukt := range []struct {
+		Input string
+		Want  []string
+	}{
+		{`(cn=Jesse)`, nil},
+		{`(&(objectClass=person)(|(cn=Jess*)(!(sn=Coretta))))`, nil},
+		{`(bogusType=*)`, []string{`[] Unknown attribute type: (bogusType=*)`}},
+		{`(!(bogusType~=x))`, []string{`[0] Unknown attribute type: (bogusType~=x)`}},
+		{`(cn>=x)`, []string{`[] No ORDERING matching rule in force: (cn>=x)`}},
+		{`(modifyTimestamp>=20240101000000Z)`, nil},
+		{`(modifyTimestamp<=bogus)`, []string{`[] Assertion value violates matching rule syntax generalizedTimeOrderingMatch: (modifyTimestamp<=bogus)`}},
+		{`(modifyTimestamp=2024*)`, []string{`[] No SUBSTR matching rule in force: (modifyTimestamp=2024*)`}},
+		{`(cn=Je\2as*\5c*se)`, nil},
+		{`(cn=\00*)`, []string{`[] Assertion value violates matching rule syntax caseIgnoreSubstringsMatch: (cn=\00*)`}},
+		{`(cn=*a*\00*)`, []string{`[] Assertion value violates matching rule syntax caseIgnoreSubstringsMatch: (cn=*a*\00*)`}},
+		{`(governingStructureRule=1)`, nil},
+		{`(cn:caseIgnoreMatch:=Jesse)`, nil},
+		{`(cn:caseExactMatch:=Jesse)`, []string{`[] Matching rule not applicable to attribute type: (cn:caseExactMatch:=Jesse)`}},
+		{`(:caseIgnoreMatch:=Jesse)`, nil},
+		{`(:bogusMatch:=Jesse)`, []string{`[] Unknown matching rule: (:bogusMatch:=Jesse)`}},
+		{`(bogusType:=Jesse)`, []string{`[] Unknown attribute type: (bogusType:=Jesse)`}},
+		{`(|(a=1)(b=2))`, []string{
+			`[0] Unknown attribute type: (a=1)`,
+			`[1] Unknown attribute type: (b=2)`,
+		}},
+	} {
+		problems, err := r.ValidateFilter(strukt.Input, exampleSchema)
+		if err != nil {
+			t.Errorf("%s[%d] failed: %v", t.Name(), idx, err)
+			continue
+		}
+
+		var got []string
+		for _, problem := range problems {
+			got = append(got, fmt.Sprintf("%v %s", problem.Path, problem))
+		}
+
+		if fmt.Sprint(got) != fmt.Sprint(strukt.Want) {
+			t.Errorf("%s[%d] failed: %s\nwant: %v\ngot:  %v",
+				t.Name(), idx, strukt.Input, strukt.Want, got)
+		}
+	}
+}
+
+func TestRFC4515_ValidateFilter_codecov(t *testing.T) {
+	var r RFC4515
+
+	if _, err := r.ValidateFilter(`(cn=*)`, nil); err == nil {
+		t.Errorf("%s failed: expected error, got nil", t.Name())
+	}
+	if _, err := r.ValidateFilter(`(cn=bogus`, exampleSchema); err == nil {
+		t.Errorf("%s failed: expected error, got nil", t.Name())
+	}
+
+	problems, _ := r.ValidateFilter(FilterAnd{
+		invalidFilter{},
+		FilterEqualityMatch{Desc: AttributeDescription(`cn`), Value: AssertionValue(`bad\zz`)},
+		FilterExtensibleMatch{MatchValue: AssertionValue(`x`)},
+	}, exampleSchema)
+	if len(problems) != 3 {
+		t.Errorf("%s failed: want 3 problems, got %d", t.Name(), len(problems))
+	}
+
+	// syntax violations must remain identifiable
+	problems, _ = r.ValidateFilter(`(|(governingStructureRule=abc)(cn=*\00))`, exampleSchema)
+	if len(problems) != 2 {
+		t.Errorf("%s failed: want 2 problems, got %d", t.Name(), len(problems))
+	}
+	for idx, problem := range problems {
+		if !erris(problem, badAssertionErr) {
+			t.Errorf("%s[%d] failed: %v is not %v", t.Name(), idx, problem, badAssertionErr)
+		}
+	}
+
+	var fp FilterProblem
+	if fp.Error() != `` || fp.Unwrap() != nil {
+		t.Errorf("%s failed: bogus zero state", t.Name())
+	}
+	fp.Err = invalidFilterErr
+	if fp.Error() != invalidFilterErr.Error() || !erris(fp, invalidFilterErr) {
+		t.Errorf("%s failed: unexpected error %s", t.Name(), fp)
+	}
+}
@@ -0,0 +1,218 @@
+package dirsyn
+
+/*
+filter_walk.go contains Filter traversal and rewrite methods.
+*/
+
+/*
+FilterRewriteFunc is the signature of a closure used to inspect, and
+optionally replace, a [Filter] during a call of [RFC4515.RewriteFilter].
+
+The filter input value is the [Filter] currently being visited, and the
+path input value contains the indices leading from the outermost [Filter]
+to the current [Filter], as described in [FilterProblem]. The path slices
+are only valid for the duration of the call and must be copied if they
+are to be retained.
+
+The returned [Filter] replaces the input filter. Returning the input filter
+unmodified leaves it intact, while returning nil removes it. A non-nil
+error aborts the rewrite.
+*/
+type FilterRewriteFunc func(filter Filter, path []int) (Filter, error)
+
+/*
+WalkFilter performs a depth-first traversal of x, which may be an existing
+[Filter] qualifier instance or any value accepted by [RFC4515.Filter].
+
+The pre closure, if non-nil, is called for each [Filter] prior to its
+operands being visited. If pre returns false, the operands of the filter
+in question are not visited, nor is post called for it.
+
+The post closure, if non-nil, is called for each [Filter] after all of
+its operands have been visited.
+
+The path input value of each closure is described in [FilterRewriteFunc].
+
+An error is only returned if x could not be marshaled into a [Filter].
+*/
+func (r RFC4515) WalkFilter(x any, pre func(Filter, []int) bool, post func(Filter, []int)) (err error) {
+	var f Filter
+	if f, err = assertFilter(x); err == nil {
+		walkFilter(f, nil, pre, post)
+	}
+
+	return
+}
+
+func walkFilter(f Filter, path []int, pre func(Filter, []int) bool, post func(Filter, []int)) {
+	if pre != nil && !pre(f, path) {
+		return
+	}
+
+	switch tv := f.(type) {
+	case FilterAnd:
+		for i := 0; i < len(tv); i++ {
+			walkFilter(tv[i], append(path, i), pre, post)
+		}
+	case FilterOr:
+		for i := 0; i < len(tv); i++ {
+			walkFilter(tv[i], append(path, i), pre, post)
+		}
+	case FilterNot:
+		walkFilter(tv.Filter, append(path, 0), pre, post)
+	}
+
+	if post != nil {
+		post(f, path)
+	}
+}
+
+/*
+RewriteFilter returns a rewritten copy of x alongside an error following
+a depth-first traversal involving the pre and post closures, either of
+which may be nil. The input value x may be an existing [Filter] qualifier
+instance or any value accepted by [RFC4515.Filter]. It is not modified.
+
+The pre closure is called for each [Filter] prior to its operands being
+visited. When a replacement is returned, the operands of the replacement
+are visited in its place.
+
+The post closure is called for each [Filter] after its operands have been
+visited (and possibly rewritten). This is well-suited for bottom-up tasks,
+such as the replacement of an item filter with an expansion, as the items
+of the expansion are not themselves visited.
+
+A [Filter] removed from a [FilterNot] instance causes the [FilterNot] to be
+removed as well. Removal of all operands of a [FilterAnd] or [FilterOr]
+results in the absolute true or absolute false filter respectively, as
+described in [RFC 4526]. An error is returned if the outermost [Filter]
+is removed.
+
+See also [RFC4515.RenameFilterAttributes].
+
+[RFC 4526]: https://datatracker.ietf.org/doc/html/rfc4526
+*/
+func (r RFC4515) RewriteFilter(x any, pre, post FilterRewriteFunc) (filter Filter, err error) {
+	filter = invalidFilter{}
+
+	var in, out Filter
+	if in, err = assertFilter(x); err != nil {
+		return
+	} else if out, err = rewriteFilter(in, nil, pre, post); err == nil {
+		if out == nil {
+			err = errorTxt("Outermost filter removed during rewrite")
+		} else {
+			filter = out
+		}
+	}
+
+	return
+}
+
+func rewriteFilter(f Filter, path []int, pre, post FilterRewriteFunc) (out Filter, err error) {
+	out = f
+	if pre != nil {
+		if out, err = pre(out, path); err != nil || out == nil {
+			return
+		}
+	}
+
+	switch tv := out.(type) {
+	case FilterAnd:
+		var and FilterAnd
+		if and, err = rewriteFilterSet(tv, path, pre, post); err == nil {
+			out = and
+		}
+	case FilterOr:
+		var or FilterAnd
+		if or, err = rewriteFilterSet(tv, path, pre, post); err == nil {
+			out = FilterOr(or)
+		}
+	case FilterNot:
+		var sub Filter
+		if sub, err = rewriteFilter(tv.Filter, append(path, 0), pre, post); err == nil {
+			if out = nil; sub != nil {
+				out = FilterNot{sub}
+			}
+		}
+	}
+
+	if err == nil && out != nil && post != nil {
+		out, err = post(out, path)
+	}
+
+	return
+}
+
+func rewriteFilterSet(set []Filter, path []int, pre, post FilterRewriteFunc) (out FilterAnd, err error) {
+	out = make(FilterAnd, 0, len(set))
+	for i := 0; i < len(set); i++ {
+		var sub Filter
+		if sub, err = rewriteFilter(set[i], append(path, i), pre, post); err != nil {
+			return
+		} else if sub != nil {
+			out = append(out, sub)
+		}
+	}
+
+	return
+}
+
+/*
+RenameFilterAttributes returns a copy of x alongside an error following
+the renaming of attribute types per names, in which keys are the types
+to be renamed and values are the new types. Keys are matched without
+regard for case. [AttributeOption] values, such as language tags, are
+preserved. For example, given a names map of {"cn": "displayName"}:
+
+	(&(CN;lang-en=Jesse)(cn:caseExactMatch:=Jesse))
+
+... becomes:
+
+	(&(displayName;lang-en=Jesse)(displayName:caseExactMatch:=Jesse))
+
+The input value x may be an existing [Filter] qualifier instance or any
+value accepted by [RFC4515.Filter]. It is not modified.
+*/
+func (r RFC4515) RenameFilterAttributes(x any, names map[string]string) (Filter, error) {
+	lnames := make(map[string]string, len(names))
+	for k, v := range names {
+		lnames[lc(k)] = v
+	}
+
+	rename := func(desc AttributeDescription) AttributeDescription {
+		if typ, found := lnames[lc(desc.Type())]; found {
+			desc = AttributeDescription(typ + string(desc[len(desc.Type()):]))
+		}
+		return desc
+	}
+
+	return r.RewriteFilter(x, nil, func(f Filter, _ []int) (Filter, error) {
+		switch tv := f.(type) {
+		case FilterPresent:
+			tv.Desc = rename(tv.Desc)
+			f = tv
+		case FilterEqualityMatch:
+			tv.Desc = rename(tv.Desc)
+			f = tv
+		case FilterApproximateMatch:
+			tv.Desc = rename(tv.Desc)
+			f = tv
+		case FilterGreaterOrEqual:
+			tv.Desc = rename(tv.Desc)
+			f = tv
+		case FilterLessOrEqual:
+			tv.Desc = rename(tv.Desc)
+			f = tv
+		case FilterSubstrings:
+			tv.Type = rename(tv.Type)
+			f = tv
+		case FilterExtensibleMatch:
+			if len(tv.Type) > 0 {
+				tv.Type = rename(tv.Type)
+			}
+			f = tv
+		}
+		return f, nil
+	})
+}
@@ -0,0 +1,165 @@
+package dirsyn
+
+import (
+	"fmt"
+	"testing"
+)
+
+/*
+This example demonstrates the means for collecting all attribute types
+asserted within a [Filter] through a traversal.
+*/
+func ExampleRFC4515_WalkFilter() {
+	var r RFC4515
+	var types []string
+	err := r.WalkFilter(`(&(objectClass=person)(|(cn=Jesse)(!(sn=Coretta))))`,
+		func(f Filter, path []int) bool {
+			if desc := filterAtomDesc(f); len(desc) > 0 {
+				types = append(types, fmt.Sprintf("%s%v", desc, path))
+			}
+			return true
+		}, nil)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Println(types)
+	// Output: [objectClass[0] cn[1 0] sn[1 1 0]]
+}
+
+/*
+This example demonstrates the means for replacing an item filter with
+an expansion, while injecting a constraint at the outermost level.
+*/
+func ExampleRFC4515_RewriteFilter() {
+	var r RFC4515
+	f, err := r.RewriteFilter(`(&(objectClass=person)(memberOf=cn=admins))`, nil,
+		func(f Filter, path []int) (Filter, error) {
+			if eq, ok := f.(FilterEqualityMatch); ok && streqf(eq.Desc.String(), `memberOf`) {
+				return r.Filter(`(|(memberOf=cn=admins)(memberOf=cn=operators))`)
+			} else if len(path) == 0 {
+				return FilterAnd{f, FilterEqualityMatch{
+					Desc:  AttributeDescription(`tenant`),
+					Value: AssertionValue(`acme`)}}, nil
+			}
+			return f, nil
+		})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Println(f)
+	// Output: (&(&(objectClass=person)(|(memberOf=cn=admins)(memberOf=cn=operators)))(tenant=acme))
+}
+
+/*
+This example demonstrates the means for renaming attribute types within
+a [Filter], such as when translating between backends.
+*/
+func ExampleRFC4515_RenameFilterAttributes() {
+	var r RFC4515
+	f, err := r.RenameFilterAttributes(`(&(CN;lang-en=Jesse)(cn:caseExactMatch:=Jesse)(sn=*))`,
+		map[string]string{`cn`: `displayName`, `sn`: `surname`})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Println(f)
+	// Output: (&(displayName;lang-en=Jesse)(displayName:caseExactMatch:=Jesse)(surname=*))
+}
+
+func TestRFC4515_WalkFilter(t *testing.T) {
+	var r RFC4515
+
+	var pre, post []string
+	err := r.WalkFilter(`(&(a=1)(!(|(b=2)(c=3))))`,
+		func(f Filter, _ []int) bool {
+			pre = append(pre, f.Choice())
+			return f.Choice() != `or`
+		},
+		func(f Filter, _ []int) {
+			post = append(post, f.Choice())
+		})
+
+	if err != nil {
+		t.Errorf("%s failed: %v", t.Name(), err)
+	} else if got := fmt.Sprint(pre); got != `[and equalityMatch not or]` {
+		t.Errorf("%s failed: unexpected pre-order %s", t.Name(), got)
+	} else if got = fmt.Sprint(post); got != `[equalityMatch not and]` {
+		t.Errorf("%s failed: unexpected post-order %s", t.Name(), got)
+	}
+
+	if err = r.WalkFilter(`(a=1`, nil, nil); err == nil {
+		t.Errorf("%s failed: expected error, got nil", t.Name())
+	}
+}
+
+func TestRFC4515_RewriteFilter(t *testing.T) {
+	var r RFC4515
+
+	drop := func(typ string) FilterRewriteFunc {
+		return func(f Filter, _ []int) (Filter, error) {
+			if streqf(filterAtomDesc(f).Type(), typ) {
+				return nil, nil
+			}
+			return f, nil
+		}
+	}
+
+	for idx, strukt := range []struct {
+		Input string
+		Pre   FilterRewriteFunc
+		Post  FilterRewriteFunc
+		Want  string
+	}{
+		{`(&(a=1)(b=2))`, drop(`a`), nil, `(&(b=2))`},
+		{`(&(a=1)(b=2))`, nil, drop(`b`), `(&(a=1))`},
+		{`(&(a=1)(!(b=2)))`, drop(`b`), nil, `(&(a=1))`},
+		{`(&(a=1)(a=2))`, drop(`a`), nil, `(&)`},
+		{`(|(a=1)(a=2))`, nil, drop(`a`), `(|)`},
+		{`(|(a=1)(b=2))`, nil, nil, `(|(a=1)(b=2))`},
+	} {
+		f, err := r.RewriteFilter(strukt.Input, strukt.Pre, strukt.Post)
+		if err != nil {
+			t.Errorf("%s[%d] failed: %v", t.Name(), idx, err)
+		} else if got := f.String(); got != strukt.Want {
+			t.Errorf("%s[%d] failed:\nwant: %s\ngot:  %s",
+				t.Name(), idx, strukt.Want, got)
+		}
+	}
+}
+
+func TestRFC4515_RewriteFilter_codecov(t *testing.T) {
+	var r RFC4515
+
+	if _, err := r.RewriteFilter(`(a=1`, nil, nil); err == nil {
+		t.Errorf("%s failed: expected error, got nil", t.Name())
+	}
+
+	if _, err := r.RewriteFilter(`(a=1)`, func(Filter, []int) (Filter, error) {
+		return nil, nil
+	}, nil); err == nil {
+		t.Errorf("%s failed: expected error, got nil", t.Name())
+	}
+
+	bogus := func(f Filter, path []int) (Filter, error) {
+		if len(path) > 1 {
+			return f, invalidFilterErr
+		}
+		return f, nil
+	}
+	for _, input := range []string{`(&(|(a=1)))`, `(|(&(a=1)))`, `(!(&(a=1)))`} {
+		if _, err := r.RewriteFilter(input, bogus, nil); err == nil {
+			t.Errorf("%s failed: expected error for %s, got nil", t.Name(), input)
+		}
+	}
+
+	f, _ := r.RenameFilterAttributes(`(&(a>=1)(a<=2)(a~=3)(a=x*)(:caseExactMatch:=4))`,
+		map[string]string{`A`: `b`})
+	if want := `(&(b>=1)(b<=2)(b~=3)(b=x*)(:caseExactMatch:=4))`; f.String() != want {
+		t.Errorf("%s failed:\nwant: %s\ngot:  %s", t.Name(), want, f)
+	}
+}
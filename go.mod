@@ -8,4 +8,4 @@ require (
 	github.com/google/uuid v1.6.0
 )
 
-require github.com/JesseCoretta/go-shifty v1.0.1 // indirect
+require github.com/JesseCoretta/go-shifty v1.0.1
@@ -0,0 +1,550 @@
+package dirsyn
+
+/*
+gser.go implements the Generic String Encoding Rules (GSER) of RFC 3641.
+*/
+
+/*
+GSERKind describes the form of a [GSERValue].
+*/
+type GSERKind uint8
+
+/*
+GSERKind constants define the forms of [GSERValue] produced by the parser
+of [RFC3641.GSERValue]. Note that GSER does not distinguish SET from
+SEQUENCE, nor SET OF from SEQUENCE OF.
+*/
+const (
+	GSERInvalid     GSERKind = iota // invalid or uninitialized value
+	GSERInteger                     // INTEGER, e.g.: -5
+	GSERBoolean                     // BOOLEAN, e.g.: TRUE
+	GSERNull                        // NULL
+	GSERString                      // string types, e.g.: "cn=Jesse"
+	GSERBitString                   // BIT STRING, e.g.: '0101'B
+	GSEROctetString                 // OCTET STRING, e.g.: '0A'H
+	GSEROID                         // numeric OBJECT IDENTIFIER, e.g.: 2.5.4.3
+	GSERIdentifier                  // ENUMERATED, named number or descriptor, e.g.: cn
+	GSERSequence                    // SEQUENCE or SET, e.g.: { base "", minimum 1 }
+	GSERSequenceOf                  // SEQUENCE OF or SET OF, e.g.: { 1, 2 }
+	GSERChoice                      // CHOICE, e.g.: item:2.5.6.6
+)
+
+/*
+String returns the string representation of the receiver instance.
+*/
+func (r GSERKind) String() (s string) {
+	switch r {
+	case GSERInteger:
+		s = `INTEGER`
+	case GSERBoolean:
+		s = `BOOLEAN`
+	case GSERNull:
+		s = `NULL`
+	case GSERString:
+		s = `string`
+	case GSERBitString:
+		s = `BIT STRING`
+	case GSEROctetString:
+		s = `OCTET STRING`
+	case GSEROID:
+		s = `OBJECT IDENTIFIER`
+	case GSERIdentifier:
+		s = `identifier`
+	case GSERSequence:
+		s = `SEQUENCE`
+	case GSERSequenceOf:
+		s = `SEQUENCE OF`
+	case GSERChoice:
+		s = `CHOICE`
+	default:
+		s = `invalid`
+	}
+
+	return
+}
+
+/*
+GSERValue implements a single value encoded per the Generic String Encoding
+Rules of [RFC 3641]. Instances are returned by [RFC3641.GSERValue] and
+[RFC3641.Marshal], and may be read into Go values by way of the
+[GSERValue.Unmarshal] method.
+
+Hand-written parsers of GSER-based syntaxes may also traverse instances of
+this type directly, e.g.: by way of [GSERValue.Component].
+
+[RFC 3641]: https://datatracker.ietf.org/doc/html/rfc3641
+*/
+type GSERValue struct {
+	// Kind contains the form of the value.
+	Kind GSERKind
+
+	// Text contains the content of a scalar value, namely
+	// the decimal digits of an INTEGER, "TRUE" or "FALSE",
+	// the unescaped content of a string, the binary digits
+	// of a BIT STRING, the hexadecimal digits of an OCTET
+	// STRING, a dotted OBJECT IDENTIFIER or an identifier.
+	Text string
+
+	// Components contains the named components of a
+	// SEQUENCE, or the sole chosen alternative of a
+	// CHOICE.
+	Components []GSERComponent
+
+	// Elements contains the elements of a SEQUENCE OF.
+	Elements []GSERValue
+}
+
+/*
+GSERComponent implements a single named component of a SEQUENCE, or the
+chosen alternative of a CHOICE, within a [GSERValue].
+*/
+type GSERComponent struct {
+	Name  string
+	Value GSERValue
+}
+
+/*
+gserMaxDepth defines the maximum nesting of SEQUENCE, SEQUENCE OF and
+CHOICE values honored by the parser.
+*/
+const gserMaxDepth = 64
+
+/*
+GSERValue returns an instance of [GSERValue] alongside an error following
+an attempt to parse x, which may be a string or []byte instance bearing a
+single value encoded per [RFC 3641]. Leading and trailing whitespace is
+ignored.
+
+Any error returned is a *[ParseError] positioned at the offending token.
+
+[RFC 3641]: https://datatracker.ietf.org/doc/html/rfc3641
+*/
+func (r RFC3641) GSERValue(x any) (value GSERValue, err error) {
+	var raw string
+	switch tv := x.(type) {
+	case string:
+		raw = tv
+	case []byte:
+		raw = string(tv)
+	case GSERValue:
+		value = tv
+		return
+	default:
+		err = errorBadType("GSER value")
+		return
+	}
+
+	p := &gserParser{input: raw}
+	p.space()
+	if value, err = p.value(); err == nil {
+		if p.space(); p.pos < len(p.input) {
+			err = p.error(`end of input`, errorTxt("Unexpected trailing GSER content"))
+		}
+	}
+
+	if err != nil {
+		value = GSERValue{}
+	}
+
+	return
+}
+
+/*
+gserParser contains the state of an [RFC3641.GSERValue] operation.
+*/
+type gserParser struct {
+	input string
+	pos   int
+	depth int
+}
+
+func (r *gserParser) error(expected string, cause error) error {
+	return newParseError(r.input, r.pos, expected, cause)
+}
+
+/*
+space advances past any whitespace, returning a Boolean value indicative
+of whether any was found.
+*/
+func (r *gserParser) space() (found bool) {
+	for r.pos < len(r.input) && isWHSP(rune(r.input[r.pos])) {
+		r.pos++
+		found = true
+	}
+
+	return
+}
+
+/*
+peek returns the character at the current position, or zero if none
+remain.
+*/
+func (r *gserParser) peek() (c byte) {
+	if r.pos < len(r.input) {
+		c = r.input[r.pos]
+	}
+
+	return
+}
+
+func (r *gserParser) value() (value GSERValue, err error) {
+	switch c := r.peek(); {
+	case c == '{':
+		value, err = r.braced()
+	case c == '"':
+		value, err = r.str()
+	case c == '\'':
+		value, err = r.quoted()
+	case c == '-' || isDigit(rune(c)):
+		value, err = r.number()
+	case isAlpha(rune(c)):
+		value, err = r.word()
+	case c == 0:
+		err = r.error(`value`, errorTxt("Unexpected end of GSER value"))
+	default:
+		err = r.error(`value`, errorTxt("Unexpected character '"+string(c)+"'"))
+	}
+
+	return
+}
+
+/*
+nest increments the depth of the receiver, returning an error if the
+maximum depth would be exceeded.
+*/
+func (r *gserParser) nest() (err error) {
+	if r.depth++; r.depth > gserMaxDepth {
+		err = r.error(``, errorTxt("GSER value exceeds maximum depth of "+itoa(gserMaxDepth)))
+	}
+
+	return
+}
+
+/*
+braced parses a SEQUENCE or SEQUENCE OF value. A SEQUENCE is identified by
+a leading identifier followed by whitespace and a value.
+*/
+func (r *gserParser) braced() (value GSERValue, err error) {
+	if err = r.nest(); err != nil {
+		return
+	}
+	defer func() { r.depth-- }()
+
+	r.pos++
+	r.space()
+	value.Kind = GSERSequence
+	if r.peek() == '}' {
+		r.pos++
+		return
+	}
+
+	if !r.named() {
+		value.Kind = GSERSequenceOf
+	}
+
+	for {
+		if value.Kind == GSERSequence {
+			var comp GSERComponent
+			if comp, err = r.component(value.Components); err != nil {
+				return
+			}
+			value.Components = append(value.Components, comp)
+		} else {
+			var elem GSERValue
+			if elem, err = r.value(); err != nil {
+				return
+			}
+			value.Elements = append(value.Elements, elem)
+		}
+
+		r.space()
+		switch r.peek() {
+		case ',':
+			r.pos++
+			r.space()
+		case '}':
+			r.pos++
+			return
+		default:
+			err = r.error(`',' or '}'`, unbalancedErr)
+			return
+		}
+	}
+}
+
+/*
+named returns a Boolean value indicative of whether the current position
+begins a NamedValue, without advancing the receiver.
+*/
+func (r *gserParser) named() (is bool) {
+	start := r.pos
+	if id := r.identifier(); id != `` {
+		is = r.space() && r.peek() != ',' && r.peek() != '}' && r.peek() != 0
+	}
+	r.pos = start
+
+	return
+}
+
+/*
+identifier advances past, and returns, an identifier beginning with a
+lowercase letter. Zero is returned if none were found.
+*/
+func (r *gserParser) identifier() (id string) {
+	start := r.pos
+	if isLAlpha(rune(r.peek())) {
+		for r.pos < len(r.input) && gserKeychar(r.input[r.pos]) {
+			r.pos++
+		}
+	}
+
+	return r.input[start:r.pos]
+}
+
+func gserKeychar(c byte) bool {
+	return isAlnum(rune(c)) || c == '-'
+}
+
+/*
+component parses a NamedValue. Names already present within seen are
+rejected.
+*/
+func (r *gserParser) component(seen []GSERComponent) (comp GSERComponent, err error) {
+	start := r.pos
+	if comp.Name = r.identifier(); comp.Name == `` {
+		err = r.error(`identifier`, errorTxt("Missing GSER component name"))
+		return
+	}
+
+	for i := 0; i < len(seen); i++ {
+		if seen[i].Name == comp.Name {
+			r.pos = start
+			err = r.error(``, errorTxt("Duplicate GSER component '"+comp.Name+"'"))
+			return
+		}
+	}
+
+	if !r.space() {
+		err = r.error(`' '`, errorTxt("Missing space following GSER component name"))
+		return
+	}
+
+	comp.Value, err = r.value()
+
+	return
+}
+
+/*
+str parses a quoted string value, within which quotation marks are
+escaped by doubling.
+*/
+func (r *gserParser) str() (value GSERValue, err error) {
+	start := r.pos
+	bld := newStrBuilder()
+	for r.pos++; r.pos < len(r.input); r.pos++ {
+		if c := r.input[r.pos]; c != '"' {
+			bld.WriteByte(c)
+		} else if r.pos+1 < len(r.input) && r.input[r.pos+1] == '"' {
+			bld.WriteByte(c)
+			r.pos++
+		} else {
+			r.pos++
+			value = GSERValue{Kind: GSERString, Text: bld.String()}
+			return
+		}
+	}
+
+	r.pos = start
+	err = r.error(`closing quote`, unterminatedErr)
+
+	return
+}
+
+/*
+quoted parses a BIT STRING (bstring) or OCTET STRING (hstring) value.
+*/
+func (r *gserParser) quoted() (value GSERValue, err error) {
+	start := r.pos
+	end := idxr(r.input[start+1:], '\'')
+	if end == -1 {
+		err = r.error(`closing quote`, unterminatedErr)
+		return
+	}
+
+	digits := r.input[start+1 : start+1+end]
+	r.pos = start + end + 2
+
+	switch r.peek() {
+	case 'B':
+		value = GSERValue{Kind: GSERBitString, Text: digits}
+		for i := 0; i < len(digits) && err == nil; i++ {
+			if digits[i] != '0' && digits[i] != '1' {
+				r.pos = start + 1 + i
+				err = r.error(`binary digit`, errorTxt("Invalid GSER BIT STRING"))
+			}
+		}
+	case 'H':
+		value = GSERValue{Kind: GSEROctetString, Text: uc(digits)}
+		for i := 0; i < len(digits) && err == nil; i++ {
+			if !isHex(rune(digits[i])) {
+				r.pos = start + 1 + i
+				err = r.error(`hexadecimal digit`, errorTxt("Invalid GSER OCTET STRING"))
+			}
+		}
+		if err == nil && len(digits)%2 != 0 {
+			err = r.error(`hexadecimal digit`, errorTxt("Odd number of digits in GSER OCTET STRING"))
+		}
+	default:
+		err = r.error(`'B' or 'H'`, errorTxt("Invalid GSER quoted value"))
+	}
+	r.pos++
+
+	return
+}
+
+/*
+number parses an INTEGER, or a numeric OBJECT IDENTIFIER.
+*/
+func (r *gserParser) number() (value GSERValue, err error) {
+	start := r.pos
+	negative := r.peek() == '-'
+	if negative {
+		r.pos++
+	}
+
+	var arcs int
+	for {
+		arc := r.pos
+		for r.pos < len(r.input) && isDigit(rune(r.input[r.pos])) {
+			r.pos++
+		}
+
+		if digits := r.input[arc:r.pos]; len(digits) == 0 ||
+			(len(digits) > 1 && digits[0] == '0') ||
+			(negative && digits == `0`) {
+			r.pos = arc
+			err = r.error(`number`, errorTxt("Invalid GSER number"))
+			return
+		}
+
+		arcs++
+		if r.peek() != '.' || negative {
+			break
+		}
+		r.pos++
+	}
+
+	value = GSERValue{Kind: GSERInteger, Text: r.input[start:r.pos]}
+	if arcs > 1 {
+		value.Kind = GSEROID
+	}
+
+	return
+}
+
+/*
+word parses a BOOLEAN, NULL, identifier or descriptor, or a CHOICE value
+should the word be an identifier followed immediately by a colon.
+*/
+func (r *gserParser) word() (value GSERValue, err error) {
+	start := r.pos
+	for r.pos < len(r.input) && gserKeychar(r.input[r.pos]) {
+		r.pos++
+	}
+
+	text := r.input[start:r.pos]
+	if r.peek() == ':' {
+		if !isLAlpha(rune(text[0])) {
+			r.pos = start
+			err = r.error(`identifier`, errorTxt("Invalid GSER CHOICE identifier"))
+			return
+		} else if err = r.nest(); err != nil {
+			return
+		}
+		defer func() { r.depth-- }()
+
+		r.pos++
+		var alt GSERValue
+		if alt, err = r.value(); err == nil {
+			value = GSERValue{Kind: GSERChoice, Components: []GSERComponent{{Name: text, Value: alt}}}
+		}
+		return
+	}
+
+	switch text {
+	case `TRUE`, `FALSE`:
+		value = GSERValue{Kind: GSERBoolean, Text: text}
+	case `NULL`:
+		value = GSERValue{Kind: GSERNull, Text: text}
+	default:
+		value = GSERValue{Kind: GSERIdentifier, Text: text}
+	}
+
+	return
+}
+
+/*
+String returns the GSER encoding of the receiver instance. Zero is returned
+if the receiver is invalid.
+*/
+func (r GSERValue) String() (s string) {
+	switch r.Kind {
+	case GSERInteger, GSERBoolean, GSEROID, GSERIdentifier:
+		s = r.Text
+	case GSERNull:
+		s = `NULL`
+	case GSERString:
+		s = `"` + repAll(r.Text, `"`, `""`) + `"`
+	case GSERBitString:
+		s = `'` + r.Text + `'B`
+	case GSEROctetString:
+		s = `'` + uc(r.Text) + `'H`
+	case GSERSequence:
+		var comps []string
+		for _, comp := range r.Components {
+			comps = append(comps, comp.Name+` `+comp.Value.String())
+		}
+		s = gserBraces(comps)
+	case GSERSequenceOf:
+		var elems []string
+		for _, elem := range r.Elements {
+			elems = append(elems, elem.String())
+		}
+		s = gserBraces(elems)
+	case GSERChoice:
+		if len(r.Components) == 1 {
+			s = r.Components[0].Name + `:` + r.Components[0].Value.String()
+		}
+	}
+
+	return
+}
+
+func gserBraces(items []string) (s string) {
+	s = `{ }`
+	if len(items) > 0 {
+		s = `{ ` + join(items, `, `) + ` }`
+	}
+
+	return
+}
+
+/*
+Component returns the value of the SEQUENCE component, or CHOICE
+alternative, named by name alongside a Boolean value indicative of
+whether it was found.
+*/
+func (r GSERValue) Component(name string) (value GSERValue, found bool) {
+	for i := 0; i < len(r.Components) && !found; i++ {
+		if found = r.Components[i].Name == name; found {
+			value = r.Components[i].Value
+		}
+	}
+
+	return
+}
+
+/*
+IsZero returns a Boolean value indicative of a nil receiver state.
+*/
+func (r GSERValue) IsZero() bool { return r.Kind == GSERInvalid }
@@ -0,0 +1,676 @@
+package dirsyn
+
+/*
+gser_marshal.go implements struct tag driven GSER marshaling and
+unmarshaling by way of reflection.
+*/
+
+import (
+	"encoding/asn1"
+	"math/big"
+	"reflect"
+	"strconv"
+)
+
+/*
+GSERMarshaler is implemented by types which produce their own GSER value,
+for use with [RFC3641.Marshal].
+*/
+type GSERMarshaler interface {
+	MarshalGSER() (GSERValue, error)
+}
+
+/*
+GSERUnmarshaler is implemented by types which read their own GSER value,
+for use with [GSERValue.Unmarshal].
+*/
+type GSERUnmarshaler interface {
+	UnmarshalGSER(GSERValue) error
+}
+
+/*
+gserFieldParams contains the parsed contents of a "gser" struct tag.
+*/
+type gserFieldParams struct {
+	name       string  // component identifier
+	optional   bool    // OPTIONAL
+	choice     bool    // CHOICE
+	identifier bool    // unquoted strings
+	dflt       *string // DEFAULT
+}
+
+/*
+parseGSERFieldParams returns an instance of gserFieldParams following an
+attempt to parse the contents of a "gser" struct tag. The first item of
+str is the component identifier; if zero, the identifier is derived from
+field by lowering its first character. Unrecognized keywords are ignored.
+*/
+func parseGSERFieldParams(field, str string) (p gserFieldParams) {
+	parts := split(str, `,`)
+	if p.name = trimS(parts[0]); p.name == `` {
+		p.name = lc(field[:1]) + field[1:]
+	}
+
+	for _, part := range parts[1:] {
+		switch part = trimS(part); {
+		case hasPfx(part, `default:`):
+			dflt := part[8:]
+			p.dflt = &dflt
+		case part == `optional`:
+			p.optional = true
+		case part == `choice`:
+			p.choice = true
+		case part == `identifier`, part == `oid`:
+			p.identifier = true
+		}
+	}
+
+	return
+}
+
+/*
+elem returns the gserFieldParams for elements of a SEQUENCE OF, which
+inherit only the CHOICE and identifier qualities of the receiver.
+*/
+func (r gserFieldParams) elem() gserFieldParams {
+	return gserFieldParams{choice: r.choice, identifier: r.identifier}
+}
+
+var (
+	gserValueType       reflect.Type = typeOf(GSERValue{})
+	gserMarshalerType   reflect.Type = typeOf((*GSERMarshaler)(nil)).Elem()
+	gserUnmarshalerType reflect.Type = typeOf((*GSERUnmarshaler)(nil)).Elem()
+	gserBooleanType     reflect.Type = typeOf(Boolean{})
+	gserIntegerType     reflect.Type = typeOf(Integer{})
+	gserBitStringType   reflect.Type = typeOf(BitString{})
+	gserNumericOIDType  reflect.Type = typeOf(NumericOID{})
+	gserDescriptorType  reflect.Type = typeOf(Descriptor(``))
+	gserASN1OIDType     reflect.Type = typeOf(asn1.ObjectIdentifier{})
+	gserRefinementType  reflect.Type = typeOf((*Refinement)(nil)).Elem()
+)
+
+/*
+gserField describes a single struct field subject to GSER marshaling.
+*/
+type gserField struct {
+	value reflect.Value
+	p     gserFieldParams
+}
+
+/*
+gserFields returns the fields of struct v. Anonymous struct fields bearing
+no "gser" tag are flattened, thereby implementing "COMPONENTS OF".
+Unexported fields and those tagged `gser:"-"` are skipped.
+*/
+func gserFields(v reflect.Value) (fields []gserField) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, tagged := f.Tag.Lookup(`gser`)
+		if f.Anonymous && !tagged && f.Type.Kind() == reflect.Struct {
+			// the exported fields of unexported embedded
+			// types are promoted, and thus remain settable.
+			fields = append(fields, gserFields(v.Field(i))...)
+			continue
+		} else if !f.IsExported() || tag == `-` {
+			continue
+		}
+
+		fields = append(fields, gserField{
+			value: v.Field(i),
+			p:     parseGSERFieldParams(f.Name, tag),
+		})
+	}
+
+	return
+}
+
+/*
+Marshal returns an instance of [GSERValue] alongside an error following
+an attempt to encode x, which may be any of the following:
+
+  - bool, [Boolean]: BOOLEAN
+  - Go integers, *big.Int, [Integer]: INTEGER
+  - string kinds: string, or an unquoted identifier if so tagged
+  - [NumericOID], asn1.ObjectIdentifier: OBJECT IDENTIFIER
+  - [Descriptor]: identifier
+  - [BitString], asn1.BitString: BIT STRING
+  - []byte kinds, e.g.: [OctetString]: OCTET STRING
+  - structs: SEQUENCE (or CHOICE if so tagged)
+  - other slices and arrays: SEQUENCE OF
+  - pointers to, and interfaces bearing, any of the above
+  - [GSERValue] instances, which are used as is
+
+Note that ENUMERATED values, such as [Enumerated], are written as INTEGER
+values, as the names of their alternatives are not known.
+
+The encoding of each struct field may be altered by way of a "gser" struct
+tag, the first item of which is the identifier of the component. If zero,
+the identifier is derived from the name of the field by lowering its first
+character. The following comma-delimited keywords are also honored:
+
+  - optional: the field is OPTIONAL, and is omitted if zero
+  - default:V: the field is omitted if equal to V
+  - choice: the field is a struct implementing a CHOICE, the alternatives
+    of which are its fields, exactly one (1) of which must be non-zero
+  - identifier, oid: strings are written unquoted, e.g.: as an
+    OBJECT IDENTIFIER or ENUMERATED identifier
+
+The keywords of fields of a slice type apply to each of its elements, such
+that a SET OF CHOICE may be described. Anonymous struct fields bearing no
+"gser" tag are written as though their fields were those of the outer
+struct ("COMPONENTS OF"). Types which implement [GSERMarshaler] write their
+own value.
+*/
+func (r RFC3641) Marshal(x any) (GSERValue, error) {
+	return gserMarshalValue(valOf(x), gserFieldParams{})
+}
+
+func gserMarshalValue(v reflect.Value, p gserFieldParams) (value GSERValue, err error) {
+	if !v.IsValid() {
+		err = errorBadType("GSER marshal")
+		return
+	}
+
+	t := v.Type()
+	if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+		err = errorTxt("cannot GSER encode nil " + t.String())
+		return
+	} else if t == gserValueType {
+		value = v.Interface().(GSERValue)
+		return
+	} else if t.Implements(gserMarshalerType) {
+		value, err = v.Interface().(GSERMarshaler).MarshalGSER()
+		return
+	} else if v.CanAddr() && v.Addr().Type().Implements(gserMarshalerType) {
+		value, err = v.Addr().Interface().(GSERMarshaler).MarshalGSER()
+		return
+	}
+
+	if value, err = gserMarshalSpecial(v); err != nil || value.Kind != GSERInvalid {
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		value, err = gserMarshalValue(v.Elem(), p)
+	case reflect.Bool:
+		value = gserBoolean(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value = GSERValue{Kind: GSERInteger, Text: fmtInt(v.Int(), 10)}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value = GSERValue{Kind: GSERInteger, Text: fmtUint(v.Uint(), 10)}
+	case reflect.String:
+		value, err = gserMarshalString(v.String(), p)
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			bts := make([]byte, v.Len())
+			reflect.Copy(valOf(bts), v)
+			value = GSERValue{Kind: GSEROctetString, Text: uc(hexencs(bts))}
+			break
+		}
+
+		value.Kind = GSERSequenceOf
+		for i := 0; i < v.Len() && err == nil; i++ {
+			var elem GSERValue
+			if elem, err = gserMarshalValue(v.Index(i), p.elem()); err == nil {
+				value.Elements = append(value.Elements, elem)
+			}
+		}
+	case reflect.Struct:
+		if p.choice {
+			value, err = gserMarshalChoice(v)
+		} else {
+			value, err = gserMarshalSequence(v)
+		}
+	default:
+		err = errorBadType("GSER marshal " + t.String())
+	}
+
+	if err != nil {
+		value = GSERValue{}
+	}
+
+	return
+}
+
+/*
+gserMarshalSpecial returns the GSER value of v if it is one of the types
+requiring special handling. An invalid value is returned otherwise.
+*/
+func gserMarshalSpecial(v reflect.Value) (value GSERValue, err error) {
+	switch tv := v.Interface().(type) {
+	case Boolean:
+		if tv.IsZero() {
+			err = errorTxt("cannot GSER encode undefined BOOLEAN")
+		} else {
+			value = gserBoolean(tv.True())
+		}
+	case Integer:
+		value = GSERValue{Kind: GSERInteger, Text: tv.Cast().String()}
+	case *big.Int:
+		value = GSERValue{Kind: GSERInteger, Text: tv.String()}
+	case BitString:
+		value = gserBitString(asn1.BitString(tv))
+	case asn1.BitString:
+		value = gserBitString(tv)
+	case NumericOID:
+		if tv.DotNotation == nil {
+			err = errorTxt("cannot GSER encode zero OBJECT IDENTIFIER")
+		} else {
+			value = GSERValue{Kind: GSEROID, Text: tv.String()}
+		}
+	case asn1.ObjectIdentifier:
+		value = GSERValue{Kind: GSEROID, Text: tv.String()}
+	case Descriptor:
+		value, err = gserMarshalString(string(tv), gserFieldParams{identifier: true})
+	}
+
+	return
+}
+
+func gserBoolean(b bool) (value GSERValue) {
+	value = GSERValue{Kind: GSERBoolean, Text: `FALSE`}
+	if b {
+		value.Text = `TRUE`
+	}
+
+	return
+}
+
+func gserBitString(bs asn1.BitString) GSERValue {
+	bld := newStrBuilder()
+	for i := 0; i < bs.BitLength; i++ {
+		bld.WriteByte(byte('0' + bs.At(i)))
+	}
+
+	return GSERValue{Kind: GSERBitString, Text: bld.String()}
+}
+
+/*
+gserMarshalString returns str as a string value or, if p so specifies,
+as an unquoted OBJECT IDENTIFIER or identifier, alongside an error.
+*/
+func gserMarshalString(str string, p gserFieldParams) (value GSERValue, err error) {
+	value = GSERValue{Kind: GSERString, Text: str}
+	if p.identifier {
+		var r RFC3641
+		if value, err = r.GSERValue(str); err != nil ||
+			(value.Kind != GSEROID && value.Kind != GSERIdentifier) {
+			value = GSERValue{}
+			err = errorTxt("invalid GSER identifier '" + str + "'")
+		}
+	}
+
+	return
+}
+
+func gserMarshalSequence(v reflect.Value) (value GSERValue, err error) {
+	value.Kind = GSERSequence
+	for _, field := range gserFields(v) {
+		if gserOmitField(field.value, field.p) {
+			continue
+		}
+
+		var comp GSERValue
+		if comp, err = gserMarshalValue(field.value, field.p); err != nil {
+			err = errorTxt(field.p.name + `: ` + err.Error())
+			return
+		}
+		value.Components = append(value.Components, GSERComponent{field.p.name, comp})
+	}
+
+	return
+}
+
+/*
+gserMarshalChoice returns the CHOICE value of struct v, the sole non-zero
+field of which is the chosen alternative.
+*/
+func gserMarshalChoice(v reflect.Value) (value GSERValue, err error) {
+	var chosen *gserField
+	for _, field := range gserFields(v) {
+		if field.value.IsZero() {
+			continue
+		} else if chosen != nil {
+			err = errorTxt("multiple GSER CHOICE alternatives present in " + v.Type().String())
+			return
+		}
+		chosen = &field
+	}
+
+	if chosen == nil {
+		err = errorTxt("no GSER CHOICE alternative present in " + v.Type().String())
+		return
+	}
+
+	var alt GSERValue
+	if alt, err = gserMarshalValue(chosen.value, chosen.p); err == nil {
+		value = GSERValue{Kind: GSERChoice, Components: []GSERComponent{{chosen.p.name, alt}}}
+	}
+
+	return
+}
+
+/*
+gserOmitField returns a Boolean value indicative of whether v should be
+omitted from the value of its struct, either due to being equal to its
+DEFAULT or to being a zero OPTIONAL value.
+*/
+func gserOmitField(v reflect.Value, p gserFieldParams) bool {
+	if p.dflt != nil {
+		return derIsDefault(v, *p.dflt)
+	}
+
+	return p.optional && v.IsZero()
+}
+
+/*
+Unmarshal returns an error following an attempt to read the receiver
+instance into x, which must be a non-nil pointer to any type supported by
+[RFC3641.Marshal]. See [RFC3641.Marshal] for the "gser" struct tag keywords
+honored.
+
+Absent OPTIONAL components are zeroed, while absent DEFAULT components are
+set to their DEFAULT value. Components which do not correspond to a field
+of the destination struct are rejected. Empty interface values receive the
+[GSERValue] itself, while [Refinement] values receive the alternative of the
+CHOICE. Types which implement [GSERUnmarshaler] read their own value.
+*/
+func (r GSERValue) Unmarshal(x any) (err error) {
+	v := valOf(x)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		err = errorBadType("GSER unmarshal")
+		return
+	}
+
+	return gserUnmarshalValue(r, v.Elem(), gserFieldParams{})
+}
+
+func gserUnmarshalValue(g GSERValue, v reflect.Value, p gserFieldParams) (err error) {
+	t := v.Type()
+	if t == gserValueType {
+		v.Set(valOf(g))
+		return
+	} else if u := gserUnmarshalerValue(v); u.IsValid() {
+		err = u.Interface().(GSERUnmarshaler).UnmarshalGSER(g)
+		return
+	}
+
+	var handled bool
+	if handled, err = gserUnmarshalSpecial(g, v); handled {
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		elem := reflect.New(t.Elem())
+		if err = gserUnmarshalValue(g, elem.Elem(), p); err == nil {
+			v.Set(elem)
+		}
+	case reflect.Interface:
+		if t.NumMethod() != 0 {
+			err = errorBadType("GSER unmarshal " + t.String())
+		} else {
+			v.Set(valOf(g))
+		}
+	case reflect.Bool:
+		if err = gserExpect(g, t, GSERBoolean); err == nil {
+			v.SetBool(g.Text == `TRUE`)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if err = gserExpect(g, t, GSERInteger); err == nil {
+			var i int64
+			if i, err = strconv.ParseInt(g.Text, 10, t.Bits()); err == nil {
+				v.SetInt(i)
+			}
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if err = gserExpect(g, t, GSERInteger); err == nil {
+			var i uint64
+			if i, err = puint(g.Text, 10, t.Bits()); err == nil {
+				v.SetUint(i)
+			}
+		}
+	case reflect.String:
+		kinds := []GSERKind{GSERString}
+		if p.identifier {
+			kinds = []GSERKind{GSEROID, GSERIdentifier}
+		}
+		if err = gserExpect(g, t, kinds...); err == nil {
+			v.SetString(g.Text)
+		}
+	case reflect.Slice, reflect.Array:
+		err = gserUnmarshalList(g, v, p)
+	case reflect.Struct:
+		if p.choice {
+			err = gserUnmarshalChoice(g, v)
+		} else {
+			err = gserUnmarshalSequence(g, v)
+		}
+	default:
+		err = errorBadType("GSER unmarshal " + t.String())
+	}
+
+	return
+}
+
+/*
+gserUnmarshalerValue returns v, or its address, as a [GSERUnmarshaler]
+value. An invalid value is returned if neither qualifies.
+*/
+func gserUnmarshalerValue(v reflect.Value) (u reflect.Value) {
+	if v.Kind() == reflect.Ptr && v.Type().Implements(gserUnmarshalerType) {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		u = v
+	} else if v.CanAddr() && v.Addr().Type().Implements(gserUnmarshalerType) {
+		u = v.Addr()
+	}
+
+	return
+}
+
+/*
+gserExpect returns an error if the kind of g is not among kinds.
+*/
+func gserExpect(g GSERValue, t reflect.Type, kinds ...GSERKind) (err error) {
+	for _, kind := range kinds {
+		if g.Kind == kind {
+			return
+		}
+	}
+
+	return errorTxt("cannot assign GSER " + g.Kind.String() + " to " + t.String())
+}
+
+/*
+gserUnmarshalSpecial reads g into v if v is one of the types requiring
+special handling, returning a Boolean value indicative of whether v was
+handled alongside an error.
+*/
+func gserUnmarshalSpecial(g GSERValue, v reflect.Value) (handled bool, err error) {
+	handled = true
+	switch t := v.Type(); t {
+	case gserBooleanType:
+		if err = gserExpect(g, t, GSERBoolean); err == nil {
+			var b Boolean
+			b.Set(g.Text == `TRUE`)
+			v.Set(valOf(b))
+		}
+	case gserIntegerType, derBigIntType:
+		if err = gserExpect(g, t, GSERInteger); err == nil {
+			i, _ := newBigInt(0).SetString(g.Text, 10)
+			if t == derBigIntType {
+				v.Set(valOf(i))
+			} else {
+				v.Set(valOf(Integer(*i)))
+			}
+		}
+	case gserBitStringType, derASN1BitStringType:
+		if err = gserExpect(g, t, GSERBitString); err == nil {
+			bs := asn1.BitString{Bytes: make([]byte, (len(g.Text)+7)/8), BitLength: len(g.Text)}
+			for i := 0; i < len(g.Text); i++ {
+				if g.Text[i] == '1' {
+					bs.Bytes[i/8] |= 0x80 >> uint(i%8)
+				}
+			}
+			v.Set(valOf(bs).Convert(t))
+		}
+	case gserNumericOIDType:
+		var noid NumericOID
+		if err = gserExpect(g, t, GSEROID); err == nil {
+			if noid, err = marshalNumericOID(g.Text); err == nil {
+				v.Set(valOf(noid))
+			}
+		}
+	case gserASN1OIDType:
+		if err = gserExpect(g, t, GSEROID); err == nil {
+			var oid asn1.ObjectIdentifier
+			for _, arc := range split(g.Text, `.`) {
+				var i int
+				if i, err = atoi(arc); err != nil {
+					return
+				}
+				oid = append(oid, i)
+			}
+			v.Set(valOf(oid))
+		}
+	case gserDescriptorType:
+		if err = gserExpect(g, t, GSERIdentifier); err == nil {
+			v.SetString(g.Text)
+		}
+	case gserRefinementType:
+		var ref Refinement
+		if ref, err = unmarshalRefinementGSER(g); err == nil {
+			v.Set(valOf(&ref).Elem())
+		}
+	default:
+		handled = false
+	}
+
+	return
+}
+
+/*
+gserUnmarshalList reads a SEQUENCE OF, or an OCTET STRING, into slice or
+array v.
+*/
+func gserUnmarshalList(g GSERValue, v reflect.Value, p gserFieldParams) (err error) {
+	t := v.Type()
+	if t.Elem().Kind() == reflect.Uint8 {
+		var bts []byte
+		if err = gserExpect(g, t, GSEROctetString); err != nil {
+			return
+		} else if bts, err = hexdec(g.Text); err != nil {
+			return
+		}
+
+		if t.Kind() == reflect.Array {
+			if len(bts) != t.Len() {
+				err = errorBadLength(t.String(), len(bts))
+				return
+			}
+			reflect.Copy(v, valOf(bts))
+		} else {
+			v.SetBytes(bts)
+		}
+		return
+	}
+
+	// An empty value is indistinguishable from an empty SEQUENCE.
+	if g.Kind == GSERSequence && len(g.Components) == 0 {
+		g = GSERValue{Kind: GSERSequenceOf}
+	}
+
+	if err = gserExpect(g, t, GSERSequenceOf); err != nil {
+		return
+	}
+
+	list := v
+	if t.Kind() == reflect.Array {
+		if len(g.Elements) != t.Len() {
+			err = errorBadLength(t.String(), len(g.Elements))
+			return
+		}
+	} else {
+		list = reflect.MakeSlice(t, len(g.Elements), len(g.Elements))
+	}
+
+	for i := 0; i < len(g.Elements) && err == nil; i++ {
+		err = gserUnmarshalValue(g.Elements[i], list.Index(i), p.elem())
+	}
+
+	if err == nil && t.Kind() == reflect.Slice {
+		v.Set(list)
+	}
+
+	return
+}
+
+func gserUnmarshalSequence(g GSERValue, v reflect.Value) (err error) {
+	// An empty value is indistinguishable from an empty SEQUENCE OF.
+	if g.Kind == GSERSequenceOf && len(g.Elements) == 0 {
+		g = GSERValue{Kind: GSERSequence}
+	}
+
+	if err = gserExpect(g, v.Type(), GSERSequence); err != nil {
+		return
+	}
+
+	fields := gserFields(v)
+	for _, comp := range g.Components {
+		var known bool
+		for i := 0; i < len(fields) && !known; i++ {
+			known = fields[i].p.name == comp.Name
+		}
+		if !known {
+			err = errorTxt("unknown GSER component '" + comp.Name + "' for " + v.Type().String())
+			return
+		}
+	}
+
+	for _, field := range fields {
+		comp, found := g.Component(field.p.name)
+		if found {
+			if err = gserUnmarshalValue(comp, field.value, field.p); err != nil {
+				err = errorTxt(field.p.name + `: ` + err.Error())
+				return
+			}
+		} else if field.p.optional || field.p.dflt != nil {
+			derSetDefault(field.value, field.p.dflt)
+		} else {
+			err = errorTxt("missing GSER component '" + field.p.name + "' for " + v.Type().String())
+			return
+		}
+	}
+
+	return
+}
+
+/*
+gserUnmarshalChoice reads a CHOICE into struct v, setting the field which
+corresponds to the chosen alternative and zeroing all others.
+*/
+func gserUnmarshalChoice(g GSERValue, v reflect.Value) (err error) {
+	if err = gserExpect(g, v.Type(), GSERChoice); err != nil {
+		return
+	} else if len(g.Components) != 1 {
+		err = errorTxt("GSER CHOICE must bear exactly one alternative")
+		return
+	}
+
+	v.Set(reflect.Zero(v.Type()))
+	alt := g.Components[0]
+	for _, field := range gserFields(v) {
+		if field.p.name == alt.Name {
+			err = gserUnmarshalValue(alt.Value, field.value, field.p)
+			return
+		}
+	}
+
+	err = errorTxt("unknown GSER CHOICE alternative '" + alt.Name + "' for " + v.Type().String())
+
+	return
+}
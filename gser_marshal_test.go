@@ -0,0 +1,391 @@
+package dirsyn
+
+import (
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+/*
+gserTestRefinement implements the Refinement CHOICE of RFC 3672 by way
+of "gser" struct tags.
+*/
+type gserTestRefinement struct {
+	Item string               `gser:"item,oid"`
+	And  []gserTestRefinement `gser:"and,choice"`
+	Or   []gserTestRefinement `gser:"or,choice"`
+	Not  *gserTestRefinement  `gser:"not,choice"`
+}
+
+type gserTestExclusion struct {
+	ChopBefore string
+	ChopAfter  string
+}
+
+/*
+gserTestSubtree implements the SubtreeSpecification SEQUENCE of RFC 3672
+by way of "gser" struct tags.
+*/
+type gserTestSubtree struct {
+	Base       string              `gser:"base,default:"`
+	Exclusions []gserTestExclusion `gser:"specificExclusions,optional,choice"`
+	Minimum    int                 `gser:"minimum,default:0"`
+	Maximum    int                 `gser:"maximum,optional"`
+	Filter     *gserTestRefinement `gser:"specificationFilter,optional,choice"`
+}
+
+/*
+This example demonstrates the decoding of a GSER value into a struct by
+way of "gser" struct tags, and the subsequent re-encoding thereof.
+*/
+func ExampleGSERValue_Unmarshal() {
+	type Refinement struct {
+		Item string       `gser:"item,oid"`
+		And  []Refinement `gser:"and,choice"`
+		Not  *Refinement  `gser:"not,choice"`
+	}
+
+	type SubtreeSpecification struct {
+		Base    string      `gser:"base,default:"`
+		Minimum int         `gser:"minimum,default:0"`
+		Filter  *Refinement `gser:"specificationFilter,optional,choice"`
+	}
+
+	var r RFC3641
+	value, _ := r.GSERValue(`{ base "ou=People", specificationFilter and:{ item:person, not:item:2.5.6.6 } }`)
+
+	var ss SubtreeSpecification
+	if err := value.Unmarshal(&ss); err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(ss.Base, ss.Filter.And[1].Not.Item)
+
+	ss.Minimum = 2
+	value, _ = r.Marshal(ss)
+	fmt.Println(value)
+	// Output:
+	// ou=People 2.5.6.6
+	// { base "ou=People", minimum 2, specificationFilter and:{ item:person, not:item:2.5.6.6 } }
+}
+
+func TestRFC3641_Marshal(t *testing.T) {
+	type primitives struct {
+		Bool       bool
+		Int        int8
+		Uint       uint16
+		Str        string
+		Bytes      []byte
+		Array      [2]byte
+		Octets     OctetString
+		Big        *big.Int
+		Integer    Integer
+		Boolean    Boolean
+		Bits       BitString
+		ASN1Bits   asn1.BitString
+		OID        NumericOID
+		ASN1OID    asn1.ObjectIdentifier
+		Descr      Descriptor
+		Enum       Enumerated
+		Names      []string `gser:"names,identifier"`
+		Counts     [2]int
+		Raw        GSERValue
+		Any        any
+		Skipped    string `gser:"-"`
+		unexported string
+	}
+
+	var r RFC3641
+	noid, _ := marshalNumericOID(`2.5.4.3`)
+	var b Boolean
+	b.Set(true)
+
+	for idx, strukt := range []struct {
+		Value any
+		Want  string
+	}{
+		{gserTestSubtree{}, `{ }`},
+		{gserTestSubtree{Base: `ou=People`, Minimum: 1, Maximum: 3}, `{ base "ou=People", minimum 1, maximum 3 }`},
+		{gserTestSubtree{
+			Exclusions: []gserTestExclusion{{ChopBefore: `cn=x`}, {ChopAfter: `cn=y`}},
+			Filter: &gserTestRefinement{Or: []gserTestRefinement{
+				{Item: `cn`},
+				{Not: &gserTestRefinement{Item: `1.3.6.1.4.1.56521`}},
+			}},
+		}, `{ specificExclusions { chopBefore:"cn=x", chopAfter:"cn=y" }, specificationFilter or:{ item:cn, not:item:1.3.6.1.4.1.56521 } }`},
+		{primitives{
+			Bool:     true,
+			Int:      -8,
+			Uint:     65535,
+			Str:      `say "hi"`,
+			Bytes:    []byte{0x0a, 0xff},
+			Array:    [2]byte{1, 2},
+			Octets:   OctetString{},
+			Big:      newBigInt(-1),
+			Integer:  Integer(*newBigInt(12345)),
+			Boolean:  b,
+			Bits:     BitString{Bytes: []byte{0xa0}, BitLength: 3},
+			ASN1Bits: asn1.BitString{Bytes: []byte{0x0f}, BitLength: 8},
+			OID:      noid,
+			ASN1OID:  asn1.ObjectIdentifier{1, 2, 3},
+			Descr:    Descriptor(`commonName`),
+			Enum:     Enumerated(2),
+			Names:    []string{`cn`, `2.5.4.4`},
+			Counts:   [2]int{1, 2},
+			Raw:      GSERValue{Kind: GSERNull},
+			Any:      GSERValue{Kind: GSERIdentifier, Text: `x`},
+		}, `{ bool TRUE, int -8, uint 65535, str "say ""hi""", bytes '0AFF'H, array '0102'H, octets ''H, ` +
+			`big -1, integer 12345, boolean TRUE, bits '101'B, aSN1Bits '00001111'B, oID 2.5.4.3, ` +
+			`aSN1OID 1.2.3, descr commonName, enum 2, names { cn, 2.5.4.4 }, counts { 1, 2 }, raw NULL, any x }`},
+	} {
+		value, err := r.Marshal(strukt.Value)
+		if err != nil {
+			t.Errorf("%s[%d] failed: %v", t.Name(), idx, err)
+			continue
+		} else if got := value.String(); got != strukt.Want {
+			t.Errorf("%s[%d] failed:\nwant: %s\ngot:  %s", t.Name(), idx, strukt.Want, got)
+			continue
+		}
+
+		// ensure the value survives a round trip through its
+		// string representation
+		parsed, err := r.GSERValue(value.String())
+		if err != nil {
+			t.Errorf("%s[%d] failed: %v", t.Name(), idx, err)
+			continue
+		}
+
+		dest := reflect.New(reflect.TypeOf(strukt.Value))
+		if err = parsed.Unmarshal(dest.Interface()); err != nil {
+			t.Errorf("%s[%d] failed: %v", t.Name(), idx, err)
+		} else if again, _ := r.Marshal(dest.Elem().Interface()); again.String() != strukt.Want {
+			t.Errorf("%s[%d] failed:\nwant: %s\ngot:  %s", t.Name(), idx, strukt.Want, again)
+		}
+	}
+}
+
+func TestRFC3641_MarshalSubtreeSpecification(t *testing.T) {
+	var r RFC3641
+
+	for idx, strukt := range []struct {
+		Value SubtreeSpecification
+		Want  string
+	}{
+		{SubtreeSpecification{}, `{ }`},
+		{SubtreeSpecification{
+			Base: LocalName(`ou=People`),
+			ChopSpecification: ChopSpecification{
+				Exclusions: SpecificExclusions{
+					{ChopBefore: LocalName(`cn=x`)},
+					{ChopAfter: LocalName(`cn=y`)},
+				},
+				Minimum: 1,
+				Maximum: 3,
+			},
+			SpecificationFilter: RefinementAnd{
+				RefinementItem(`person`),
+				RefinementNot{RefinementItem(`2.5.6.6`)},
+			},
+		}, `{ base "ou=People", specificExclusions { chopBefore:"cn=x", chopAfter:"cn=y" }, ` +
+			`minimum 1, maximum 3, specificationFilter and:{ item:person, not:item:2.5.6.6 } }`},
+		{SubtreeSpecification{
+			SpecificationFilter: RefinementOr{RefinementItem(`1.3.6.1.4.1.56521`), RefinementAnd{}},
+		}, `{ specificationFilter or:{ item:1.3.6.1.4.1.56521, and:{ } } }`},
+	} {
+		value, err := r.Marshal(strukt.Value)
+		if err != nil {
+			t.Errorf("%s[%d] failed: %v", t.Name(), idx, err)
+			continue
+		} else if got := value.String(); got != strukt.Want {
+			t.Errorf("%s[%d] failed:\nwant: %s\ngot:  %s", t.Name(), idx, strukt.Want, got)
+			continue
+		}
+
+		parsed, err := r.GSERValue(value.String())
+		if err != nil {
+			t.Errorf("%s[%d] failed: %v", t.Name(), idx, err)
+			continue
+		}
+
+		var ss SubtreeSpecification
+		if err = parsed.Unmarshal(&ss); err != nil {
+			t.Errorf("%s[%d] failed: %v", t.Name(), idx, err)
+		} else if !reflect.DeepEqual(ss, strukt.Value) {
+			t.Errorf("%s[%d] failed:\nwant: %#v\ngot:  %#v", t.Name(), idx, strukt.Value, ss)
+		}
+	}
+
+	// refinement failures
+	for idx, x := range []any{
+		SubtreeSpecification{SpecificationFilter: RefinementItem(`not valid`)},
+		SubtreeSpecification{SpecificationFilter: RefinementNot{}},
+		SubtreeSpecification{SpecificationFilter: RefinementAnd{RefinementNot{}}},
+	} {
+		if _, err := r.Marshal(x); err == nil {
+			t.Errorf("%s[%d] failed: expected error, got nil", t.Name(), idx)
+		}
+	}
+
+	for idx, raw := range []string{
+		`{ specificationFilter person }`,
+		`{ specificationFilter item:"person" }`,
+		`{ specificationFilter and:person }`,
+		`{ specificationFilter and:{ person } }`,
+		`{ specificationFilter not:person }`,
+		`{ specificationFilter xor:{ } }`,
+	} {
+		var ss SubtreeSpecification
+		if value, err := r.GSERValue(raw); err != nil {
+			t.Errorf("%s[%d] failed: %v", t.Name(), idx, err)
+		} else if err = value.Unmarshal(&ss); err == nil {
+			t.Errorf("%s[%d] failed: expected error, got nil", t.Name(), idx)
+		}
+	}
+}
+
+/*
+gserTestHook implements GSERMarshaler and GSERUnmarshaler, representing
+itself as an uppercased identifier.
+*/
+type gserTestHook struct{ name string }
+
+func (r gserTestHook) MarshalGSER() (GSERValue, error) {
+	if r.name == `` {
+		return GSERValue{}, errorTxt("zero hook")
+	}
+	return GSERValue{Kind: GSERIdentifier, Text: uc(r.name)}, nil
+}
+
+func (r *gserTestHook) UnmarshalGSER(value GSERValue) error {
+	r.name = lc(value.Text)
+	return nil
+}
+
+func TestGSERMarshal_codecov(t *testing.T) {
+	var r RFC3641
+
+	type hooked struct {
+		Hook gserTestHook
+		Ptr  *gserTestHook `gser:"ptr,optional"`
+	}
+
+	value, err := r.Marshal(hooked{Hook: gserTestHook{`x`}, Ptr: &gserTestHook{`y`}})
+	if err != nil || value.String() != `{ hook X, ptr Y }` {
+		t.Errorf("%s failed: unexpected result %s (%v)", t.Name(), value, err)
+	}
+
+	var h hooked
+	if err = value.Unmarshal(&h); err != nil || h.Hook.name != `x` || h.Ptr.name != `y` {
+		t.Errorf("%s failed: unexpected result %#v (%v)", t.Name(), h, err)
+	}
+
+	// untagged anonymous struct fields are flattened ("COMPONENTS OF")
+	type components struct {
+		gserTestExclusion
+		Extra int
+	}
+
+	value, err = r.Marshal(components{gserTestExclusion{`cn=x`, `cn=y`}, 1})
+	if err != nil || value.String() != `{ chopBefore "cn=x", chopAfter "cn=y", extra 1 }` {
+		t.Errorf("%s failed: unexpected result %s (%v)", t.Name(), value, err)
+	}
+
+	var c components
+	if err = value.Unmarshal(&c); err != nil || c.ChopAfter != `cn=y` || c.Extra != 1 {
+		t.Errorf("%s failed: unexpected result %#v (%v)", t.Name(), c, err)
+	}
+
+	// marshal failures
+	for idx, x := range []any{
+		nil,
+		(*int)(nil),
+		map[string]int{},
+		3.14,
+		Boolean{},
+		NumericOID{},
+		Descriptor(`not valid`),
+		struct {
+			S string `gser:"s,identifier"`
+		}{`"quoted"`},
+		struct {
+			S []any
+		}{[]any{nil}},
+		gserTestHook{},
+		struct {
+			C gserTestRefinement `gser:"c,choice"`
+		}{},
+		struct {
+			C gserTestRefinement `gser:"c,choice"`
+		}{gserTestRefinement{Item: `cn`, Not: &gserTestRefinement{}}},
+		struct {
+			C gserTestRefinement `gser:"c,choice"`
+		}{gserTestRefinement{Not: &gserTestRefinement{}}},
+	} {
+		if _, err := r.Marshal(x); err == nil {
+			t.Errorf("%s[%d] failed: expected error, got nil", t.Name(), idx)
+		}
+	}
+
+	// unmarshal failures
+	for idx, strukt := range []struct {
+		Input string
+		Dest  any
+	}{
+		{`1`, new(bool)},
+		{`TRUE`, new(int)},
+		{`300`, new(int8)},
+		{`-1`, new(uint)},
+		{`cn`, new(string)},
+		{`"x"`, new([]byte)},
+		{`'0102'H`, new([3]byte)},
+		{`{ 1 }`, new([2]int)},
+		{`{ "x" }`, new([]int)},
+		{`1`, new([]int)},
+		{`1`, new(Boolean)},
+		{`"x"`, new(*big.Int)},
+		{`'00'H`, new(BitString)},
+		{`cn`, new(NumericOID)},
+		{`cn`, new(asn1.ObjectIdentifier)},
+		{`2.5.4.3`, new(Descriptor)},
+		{`1`, new(fmt.Stringer)},
+		{`1`, new(float64)},
+		{`{ 1 }`, new(gserTestSubtree)},
+		{`{ bogus 1 }`, new(gserTestSubtree)},
+		{`{ minimum "1" }`, new(gserTestSubtree)},
+		{`{ specificationFilter item:cn }`, new(struct{ Required string })},
+		{`{ specificationFilter bogus:cn }`, new(gserTestSubtree)},
+		{`{ specificationFilter { } }`, new(gserTestSubtree)},
+	} {
+		value, err := r.GSERValue(strukt.Input)
+		if err != nil {
+			t.Errorf("%s[%d] failed: %v", t.Name(), idx, err)
+		} else if err = value.Unmarshal(strukt.Dest); err == nil {
+			t.Errorf("%s[%d] failed: expected error, got nil", t.Name(), idx)
+		}
+	}
+
+	if err = (GSERValue{Kind: GSERChoice}).Unmarshal(&gserTestSubtree{}); err == nil {
+		t.Errorf("%s failed: expected error, got nil", t.Name())
+	} else if err = gserUnmarshalChoice(GSERValue{Kind: GSERChoice}, valOf(&gserTestRefinement{}).Elem()); err == nil {
+		t.Errorf("%s failed: expected error, got nil", t.Name())
+	} else if err = value.Unmarshal(nil); err == nil {
+		t.Errorf("%s failed: expected error, got nil", t.Name())
+	}
+
+	// empty values are accepted by both structs and slices
+	var (
+		empty, _ = r.GSERValue(`{ }`)
+		list     []int
+		arr      [0]int
+		ss       gserTestSubtree
+	)
+	if err = empty.Unmarshal(&list); err != nil {
+		t.Errorf("%s failed: %v", t.Name(), err)
+	} else if err = empty.Unmarshal(&arr); err != nil {
+		t.Errorf("%s failed: %v", t.Name(), err)
+	} else if err = (GSERValue{Kind: GSERSequenceOf}).Unmarshal(&ss); err != nil {
+		t.Errorf("%s failed: %v", t.Name(), err)
+	}
+}
@@ -0,0 +1,151 @@
+package dirsyn
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+/*
+This example demonstrates the parsing of a GSER value and the traversal
+of its components.
+*/
+func ExampleRFC3641_GSERValue() {
+	var r RFC3641
+	value, err := r.GSERValue(`{ base "ou=People", minimum 1, specificationFilter not:item:2.5.6.6 }`)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	filter, _ := value.Component(`specificationFilter`)
+	fmt.Println(filter.Kind, filter.Components[0].Name, filter)
+	// Output: CHOICE not not:item:2.5.6.6
+}
+
+func TestRFC3641_GSERValue(t *testing.T) {
+	var r RFC3641
+	for idx, strukt := range []struct {
+		Input string
+		Kind  GSERKind
+		Want  string // expected re-encoding
+	}{
+		{`0`, GSERInteger, `0`},
+		{`-42`, GSERInteger, `-42`},
+		{`TRUE`, GSERBoolean, `TRUE`},
+		{`FALSE`, GSERBoolean, `FALSE`},
+		{`NULL`, GSERNull, `NULL`},
+		{`""`, GSERString, `""`},
+		{`"say ""hi"""`, GSERString, `"say ""hi"""`},
+		{`'0101'B`, GSERBitString, `'0101'B`},
+		{`''B`, GSERBitString, `''B`},
+		{`'0a1F'H`, GSEROctetString, `'0A1F'H`},
+		{`2.5.4.3`, GSEROID, `2.5.4.3`},
+		{`cn`, GSERIdentifier, `cn`},
+		{`id-at-commonName`, GSERIdentifier, `id-at-commonName`},
+		{`{}`, GSERSequence, `{ }`},
+		{`  { }  `, GSERSequence, `{ }`},
+		{`{ a 1, b "x" }`, GSERSequence, `{ a 1, b "x" }`},
+		{`{a  {c TRUE},b '00'H}`, GSERSequence, `{ a { c TRUE }, b '00'H }`},
+		{`{ 1, 2, 3 }`, GSERSequenceOf, `{ 1, 2, 3 }`},
+		{`{ red, green }`, GSERSequenceOf, `{ red, green }`},
+		{`{ { 1 }, { } }`, GSERSequenceOf, `{ { 1 }, { } }`},
+		{`item:2.5.6.6`, GSERChoice, `item:2.5.6.6`},
+		{`and:{item:cn,not:item:sn}`, GSERChoice, `and:{ item:cn, not:item:sn }`},
+		{`{ x y:{ 1 } }`, GSERSequence, `{ x y:{ 1 } }`},
+	} {
+		value, err := r.GSERValue(strukt.Input)
+		if err != nil {
+			t.Errorf("%s[%d] failed: %v", t.Name(), idx, err)
+		} else if value.Kind != strukt.Kind {
+			t.Errorf("%s[%d] failed: want %s, got %s", t.Name(), idx, strukt.Kind, value.Kind)
+		} else if got := value.String(); got != strukt.Want {
+			t.Errorf("%s[%d] failed:\nwant: %s\ngot:  %s", t.Name(), idx, strukt.Want, got)
+		} else if again, err := r.GSERValue([]byte(got)); err != nil || again.String() != got {
+			t.Errorf("%s[%d] failed: re-encoding did not round trip: %v", t.Name(), idx, err)
+		}
+	}
+}
+
+func TestRFC3641_GSERValue_errors(t *testing.T) {
+	var r RFC3641
+	for idx, strukt := range []struct {
+		Input    string
+		Offset   int
+		Expected string
+	}{
+		{``, 0, `value`},
+		{`   `, 3, `value`},
+		{`?`, 0, `value`},
+		{`1 2`, 2, `end of input`},
+		{`"abc`, 0, `closing quote`},
+		{`'0102'B`, 4, `binary digit`},
+		{`'0G'H`, 2, `hexadecimal digit`},
+		{`'0A0'H`, 5, `hexadecimal digit`},
+		{`'01'X`, 4, `'B' or 'H'`},
+		{`'01`, 0, `closing quote`},
+		{`01`, 0, `number`},
+		{`-0`, 1, `number`},
+		{`-`, 1, `number`},
+		{`1.`, 2, `number`},
+		{`1.02`, 2, `number`},
+		{`Item:1`, 0, `identifier`},
+		{`{ 1, 2`, 6, `',' or '}'`},
+		{`{ 1 2 }`, 4, `',' or '}'`},
+		{`{ a 1, 2 }`, 7, `identifier`},
+		{`{ a 1, b:1 }`, 8, `' '`},
+		{`{ a 1, a 2 }`, 7, ``},
+		{`{ a 1, }`, 7, `identifier`},
+	} {
+		_, err := r.GSERValue(strukt.Input)
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Errorf("%s[%d] failed: expected *ParseError, got %T (%v)", t.Name(), idx, err, err)
+		} else if perr.Offset != strukt.Offset || perr.Expected != strukt.Expected {
+			t.Errorf("%s[%d] failed: want offset %d (%s), got %d (%s)", t.Name(), idx,
+				strukt.Offset, strukt.Expected, perr.Offset, perr.Expected)
+		}
+	}
+}
+
+func TestGSERValue_codecov(t *testing.T) {
+	var r RFC3641
+
+	// nesting beyond the maximum depth is rejected
+	deep := ``
+	for i := 0; i <= gserMaxDepth; i++ {
+		deep += `not:`
+	}
+	if _, err := r.GSERValue(deep + `item:cn`); err == nil {
+		t.Errorf("%s failed: expected depth error, got nil", t.Name())
+	}
+
+	deep = ``
+	for i := 0; i <= gserMaxDepth; i++ {
+		deep = `{ ` + deep + ` }`
+	}
+	if _, err := r.GSERValue(deep); err == nil {
+		t.Errorf("%s failed: expected depth error, got nil", t.Name())
+	}
+
+	if _, err := r.GSERValue(3.14); err == nil {
+		t.Errorf("%s failed: expected error, got nil", t.Name())
+	} else if value, err := r.GSERValue(GSERValue{Kind: GSERNull}); err != nil || value.String() != `NULL` {
+		t.Errorf("%s failed: unexpected result %s (%v)", t.Name(), value, err)
+	}
+
+	var zero GSERValue
+	if !zero.IsZero() || zero.String() != `` {
+		t.Errorf("%s failed: unexpected zero value state", t.Name())
+	} else if _, found := zero.Component(`x`); found {
+		t.Errorf("%s failed: unexpected component found", t.Name())
+	} else if bogus := (GSERValue{Kind: GSERChoice}); bogus.String() != `` {
+		t.Errorf("%s failed: unexpected choice encoding %s", t.Name(), bogus)
+	}
+
+	for kind := GSERInvalid; kind <= GSERChoice+1; kind++ {
+		if kind.String() == `` {
+			t.Errorf("%s failed: zero name for kind %d", t.Name(), kind)
+		}
+	}
+}
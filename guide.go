@@ -48,7 +48,8 @@ type EnhancedGuide struct {
 EnhancedGuide returns an instance of [EnhancedGuide] alongside an error.
 */
 func (r RFC4517) EnhancedGuide(x any) (EnhancedGuide, error) {
-	return marshalEnhancedGuide(x)
+	val, err := marshalEnhancedGuide(x)
+	return val, errorSyntax(err)
 }
 
 func enhancedGuide(x any) (result Boolean) {
@@ -169,7 +170,8 @@ type Guide struct {
 Guide returns an instance of [Guide] alongside an error.
 */
 func (r RFC4517) Guide(x any) (Guide, error) {
-	return marshalGuide(x)
+	val, err := marshalGuide(x)
+	return val, errorSyntax(err)
 }
 
 func guide(x any) (result Boolean) {
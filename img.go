@@ -34,7 +34,7 @@ See also [§ 3.3.17 of RFC 4517].
 [§ 3.3.17 of RFC 4517]: https://datatracker.ietf.org/doc/html/rfc4517#section-3.3.17
 */
 func (r RFC4517) JPEG(x any) error {
-	return marshalJPEG(x)
+	return errorSyntax(marshalJPEG(x))
 }
 
 func jPEG(x any) (result Boolean) {
@@ -41,7 +41,8 @@ x may be any Go primitive number, *[big.Int], string or []byte.  If x
 is a []byte instance, it is assumed to be ASN.1 DER encoded bytes.
 */
 func (r RFC4517) Integer(x any) (Integer, error) {
-	return marshalInteger(x)
+	val, err := marshalInteger(x)
+	return val, errorSyntax(err)
 }
 
 func marshalInteger(x any) (i Integer, err error) {
@@ -188,6 +189,12 @@ func derReadInteger(x *Integer, der *DERPacket, tal TagAndLength) (err error) {
 	encodedValue := der.data[der.offset : der.offset+tal.Length]
 	der.offset += tal.Length
 
+	if der.mode == DERStrict {
+		if err = derCheckInteger(encodedValue); err != nil {
+			return
+		}
+	}
+
 	// Convert to big.Int.
 	i := newBigInt(0)
 	i.SetBytes(encodedValue)
@@ -212,6 +219,23 @@ func derReadInteger(x *Integer, der *DERPacket, tal TagAndLength) (err error) {
 	return nil
 }
 
+/*
+derCheckInteger returns an error if content is not the minimal encoding
+of an INTEGER (or ENUMERATED) value per § 8.3 of ITU-T Rec. X.690, i.e.:
+it is empty, or its first nine (9) bits are all zero or all one.
+*/
+func derCheckInteger(content []byte) (err error) {
+	if len(content) == 0 {
+		err = derIntegerEmptyErr
+	} else if len(content) > 1 &&
+		((content[0] == 0x00 && content[1]&0x80 == 0) ||
+			(content[0] == 0xff && content[1]&0x80 != 0)) {
+		err = derIntegerPaddingErr
+	}
+
+	return
+}
+
 func assertInt(x any) (i *big.Int, err error) {
 	switch tv := x.(type) {
 	case int:
@@ -26,7 +26,8 @@ IA5String returns an instance of [IA5String] alongside an error following
 an analysis of x in the context of an IA5 String.
 */
 func (r RFC4517) IA5String(x any) (ia5 IA5String, err error) {
-	return marshalIA5String(x)
+	ia5, err = marshalIA5String(x)
+	return ia5, errorSyntax(err)
 }
 
 func iA5String(x any) (result Boolean) {
@@ -121,3 +122,36 @@ func caseIgnoreIA5SubstringsMatch(a, b any) (result Boolean, err error) {
 
 	return
 }
+
+/*
+derWriteIA5String returns an int alongside an error following an attempt
+to write ia5 as a DER-encoded IA5String.
+*/
+func derWriteIA5String(der *DERPacket, ia5 IA5String) (n int, err error) {
+	if len(ia5) > 0 {
+		err = checkIA5String(string(ia5))
+	}
+	if err == nil {
+		n = derWritePrimitive(der, tagIA5String, []byte(ia5))
+	}
+
+	return
+}
+
+/*
+derReadIA5String returns an error following an attempt to read a DER-encoded
+IA5String into x.
+*/
+func derReadIA5String(x *IA5String, der *DERPacket, tal TagAndLength) (err error) {
+	var content []byte
+	if content, err = derReadPrimitive(der, tal, tagIA5String); err == nil {
+		if len(content) > 0 {
+			err = checkIA5String(string(content))
+		}
+		if err == nil {
+			*x = IA5String(content)
+		}
+	}
+
+	return
+}
@@ -0,0 +1,600 @@
+package dirsyn
+
+/*
+ldap.go implements the LDAPMessage envelope, LDAPResult and Controls of
+RFC 4511. See ldap_ops.go for the protocol operations.
+*/
+
+/*
+ldapMaxInt is the largest value permitted for RFC 4511 MessageID and
+other INTEGER (0 .. maxInt) values.
+*/
+const ldapMaxInt = 1<<31 - 1
+
+/*
+LDAPMessage implements the LDAPMessage envelope of [§ 4.1.1 of RFC 4511].
+
+	LDAPMessage ::= SEQUENCE {
+	     messageID       MessageID,
+	     protocolOp      CHOICE { ... },
+	     controls       [0] Controls OPTIONAL }
+
+Instances of this type may be encoded and decoded by way of the DER-based
+[DERPacket.Marshal] and [DERPacket.Unmarshal] methods, as well as by way
+of the [LDAPMessage.DER] and [RFC4511.LDAPMessage] methods. The decoder
+accepts the BER subset described in § 5.1 of RFC 4511, i.e.: definite
+lengths and primitive OCTET STRINGs.
+
+[§ 4.1.1 of RFC 4511]: https://datatracker.ietf.org/doc/html/rfc4511#section-4.1.1
+*/
+type LDAPMessage struct {
+	MessageID  int
+	ProtocolOp ProtocolOp
+	Controls   Controls
+}
+
+/*
+ProtocolOp is implemented by the protocol operations which may be conveyed
+within an instance of [LDAPMessage], e.g.: [BindRequest], [SearchRequest]
+or [ExtendedResponse].
+*/
+type ProtocolOp interface {
+	// Tag returns the APPLICATION tag of the operation.
+	Tag() int
+
+	// Identifier returns the ASN.1 identifier of the
+	// operation's CHOICE alternative, e.g.: "bindRequest".
+	Identifier() string
+
+	// writeOp writes the complete operation element.
+	writeOp(*DERPacket) error
+}
+
+/*
+LDAPOID implements the LDAPOID type of [§ 4.1.2 of RFC 4511], being the
+textual form of a numeric OID.
+
+[§ 4.1.2 of RFC 4511]: https://datatracker.ietf.org/doc/html/rfc4511#section-4.1.2
+*/
+type LDAPOID LDAPString
+
+/*
+String returns the string representation of the receiver instance.
+*/
+func (r LDAPOID) String() string { return string(r) }
+
+/*
+LDAPResult implements the LDAPResult type of [§ 4.1.9 of RFC 4511], which
+is the basis of most response operations.
+
+	LDAPResult ::= SEQUENCE {
+	     resultCode         ENUMERATED { ... },
+	     matchedDN          LDAPDN,
+	     diagnosticMessage  LDAPString,
+	     referral           [3] Referral OPTIONAL }
+
+A nil Referral is absent from the encoding.
+
+[§ 4.1.9 of RFC 4511]: https://datatracker.ietf.org/doc/html/rfc4511#section-4.1.9
+*/
+type LDAPResult struct {
+	ResultCode        ResultCode
+	MatchedDN         LDAPDN
+	DiagnosticMessage LDAPString
+	Referral          Referral
+}
+
+/*
+Referral implements the Referral type of [§ 4.1.10 of RFC 4511].
+
+	Referral ::= SEQUENCE SIZE (1..MAX) OF uri URI
+
+[§ 4.1.10 of RFC 4511]: https://datatracker.ietf.org/doc/html/rfc4511#section-4.1.10
+*/
+type Referral []LDAPString
+
+/*
+Control implements the Control type of [§ 4.1.11 of RFC 4511].
+
+	Control ::= SEQUENCE {
+	     controlType             LDAPOID,
+	     criticality             BOOLEAN DEFAULT FALSE,
+	     controlValue            OCTET STRING OPTIONAL }
+
+A nil Value is absent from the encoding.
+
+[§ 4.1.11 of RFC 4511]: https://datatracker.ietf.org/doc/html/rfc4511#section-4.1.11
+*/
+type Control struct {
+	Type        LDAPOID
+	Criticality bool
+	Value       OctetString
+}
+
+/*
+Controls implements the Controls type of [§ 4.1.11 of RFC 4511].
+
+	Controls ::= SEQUENCE OF control Control
+*/
+type Controls []Control
+
+/*
+PartialAttribute implements the PartialAttribute type of [§ 4.1.7 of RFC
+4511]. The same type is used for the Attribute type, which differs only in
+that at least one value must be present.
+
+	PartialAttribute ::= SEQUENCE {
+	     type       AttributeDescription,
+	     vals       SET OF value AttributeValue }
+
+[§ 4.1.7 of RFC 4511]: https://datatracker.ietf.org/doc/html/rfc4511#section-4.1.7
+*/
+type PartialAttribute struct {
+	Type   AttributeDescription
+	Values []OctetString
+}
+
+/*
+LDAPMessage returns an instance of [LDAPMessage] alongside an error
+following an attempt to decode x, which may be a []byte containing a
+single encoded LDAPMessage, or a *[DERPacket] from which the next
+LDAPMessage is read.
+*/
+func (r RFC4511) LDAPMessage(x any) (msg LDAPMessage, err error) {
+	switch tv := x.(type) {
+	case []byte:
+		der := newDERPacket(tv)
+		if err = msg.UnmarshalDER(der); err == nil && der.HasMoreData() {
+			err = errorTxt("trailing data following LDAPMessage")
+		}
+	case *DERPacket:
+		if tv == nil {
+			err = nilInstanceErr
+		} else {
+			err = msg.UnmarshalDER(tv)
+		}
+	default:
+		err = errorBadType("LDAPMessage")
+	}
+
+	return
+}
+
+/*
+DER returns an instance of *[DERPacket] containing the encoding of the
+receiver instance alongside an error.
+*/
+func (r LDAPMessage) DER() (der *DERPacket, err error) {
+	der = newDERPacket([]byte{})
+	if _, err = r.MarshalDER(der); err == nil {
+		der.SetOffset()
+	}
+
+	return
+}
+
+/*
+MarshalDER returns an int alongside an error following an attempt to write
+the receiver instance into der. This method satisfies the [DERMarshaler]
+interface.
+*/
+func (r LDAPMessage) MarshalDER(der *DERPacket) (n int, err error) {
+	if r.MessageID < 0 || r.MessageID > ldapMaxInt {
+		err = errorTxt("LDAPMessage messageID out of range: " + itoa(r.MessageID))
+		return
+	} else if r.ProtocolOp == nil {
+		err = errorTxt("LDAPMessage protocolOp is nil")
+		return
+	}
+
+	return der.WriteConstructed(classUniversal, tagSequence, func(sub *DERPacket) (err error) {
+		ldapWriteInt(sub, classUniversal, tagInteger, r.MessageID)
+		if err = r.ProtocolOp.writeOp(sub); err == nil && r.Controls != nil {
+			_, err = sub.WriteConstructed(classContextSpecific, 0, r.Controls.write)
+		}
+		return
+	})
+}
+
+/*
+UnmarshalDER returns an error following an attempt to read the next
+LDAPMessage from der into the receiver instance. This method satisfies
+the [DERUnmarshaler] interface.
+*/
+func (r *LDAPMessage) UnmarshalDER(der *DERPacket) error {
+	var msg LDAPMessage
+	err := der.ReadConstructed(classUniversal, tagSequence, func(sub *DERPacket) (err error) {
+		if msg.MessageID, err = ldapReadInt(sub, classUniversal, tagInteger); err != nil {
+			return
+		}
+
+		tal, ok := ldapPeek(sub)
+		if !ok || tal.Class != classApplication {
+			err = errorTxt("LDAPMessage protocolOp missing or malformed")
+			return
+		} else if msg.ProtocolOp, err = ldapReadProtocolOp(sub, tal.Tag); err != nil {
+			return
+		}
+
+		if tal, ok = ldapPeek(sub); ok && tal.Class == classContextSpecific && tal.Tag == 0 {
+			msg.Controls = Controls{}
+			err = sub.ReadConstructed(classContextSpecific, 0, msg.Controls.read)
+		}
+		return
+	})
+
+	if err == nil {
+		*r = msg
+	}
+
+	return err
+}
+
+func (r Controls) write(der *DERPacket) (err error) {
+	for i := 0; i < len(r) && err == nil; i++ {
+		err = r[i].write(der)
+	}
+
+	return
+}
+
+func (r *Controls) read(der *DERPacket) (err error) {
+	for der.HasMoreData() && err == nil {
+		var ctrl Control
+		if err = ctrl.read(der); err == nil {
+			*r = append(*r, ctrl)
+		}
+	}
+
+	return
+}
+
+func (r Control) write(der *DERPacket) (err error) {
+	if len(r.Type) == 0 {
+		err = errorTxt("Control controlType is empty")
+		return
+	}
+
+	_, err = der.WriteConstructed(classUniversal, tagSequence, func(sub *DERPacket) error {
+		ldapWriteString(sub, classUniversal, tagOctetString, string(r.Type))
+		if r.Criticality {
+			ldapWriteBool(sub, true)
+		}
+		if r.Value != nil {
+			ldapWritePrimitive(sub, classUniversal, tagOctetString, r.Value)
+		}
+		return nil
+	})
+
+	return
+}
+
+func (r *Control) read(der *DERPacket) error {
+	return der.ReadConstructed(classUniversal, tagSequence, func(sub *DERPacket) (err error) {
+		var typ []byte
+		if typ, err = ldapReadString(sub, classUniversal, tagOctetString); err != nil {
+			return
+		}
+		r.Type = LDAPOID(typ)
+
+		if ldapNext(sub, classUniversal, tagBoolean) {
+			if r.Criticality, err = ldapReadBool(sub); err != nil {
+				return
+			}
+		}
+		if ldapNext(sub, classUniversal, tagOctetString) {
+			r.Value, err = ldapReadOctets(sub, classUniversal, tagOctetString)
+		}
+		return
+	})
+}
+
+/*
+write writes the components of the receiver instance into der.
+*/
+func (r LDAPResult) write(der *DERPacket) (err error) {
+	ldapWriteEnum(der, int(r.ResultCode))
+	ldapWriteString(der, classUniversal, tagOctetString, string(r.MatchedDN))
+	ldapWriteString(der, classUniversal, tagOctetString, string(r.DiagnosticMessage))
+	if r.Referral != nil {
+		if len(r.Referral) == 0 {
+			err = errorTxt("LDAPResult referral must contain at least one URI")
+		} else {
+			_, err = der.WriteConstructed(classContextSpecific, 3, func(sub *DERPacket) error {
+				return ldapWriteStrings(sub, r.Referral)
+			})
+		}
+	}
+
+	return
+}
+
+/*
+read reads the components of an LDAPResult from der into the receiver
+instance.
+*/
+func (r *LDAPResult) read(der *DERPacket) (err error) {
+	var (
+		code     int
+		dn, diag []byte
+	)
+	if code, err = ldapReadEnum(der); err != nil {
+		return
+	} else if dn, err = ldapReadString(der, classUniversal, tagOctetString); err != nil {
+		return
+	} else if diag, err = ldapReadString(der, classUniversal, tagOctetString); err != nil {
+		return
+	}
+
+	r.ResultCode = ResultCode(code)
+	r.MatchedDN = LDAPDN(dn)
+	r.DiagnosticMessage = LDAPString(diag)
+
+	if ldapNext(der, classContextSpecific, 3) {
+		r.Referral = Referral{}
+		err = der.ReadConstructed(classContextSpecific, 3, func(sub *DERPacket) error {
+			return ldapReadStrings(sub, (*[]LDAPString)(&r.Referral))
+		})
+	}
+
+	return
+}
+
+/*
+write writes the receiver instance into der. The encodings of the values
+are ordered as a DER SET OF, as with [DERPacket.WriteSetOf].
+*/
+func (r PartialAttribute) write(der *DERPacket) (err error) {
+	_, err = der.WriteConstructed(classUniversal, tagSequence, func(sub *DERPacket) (err error) {
+		ldapWriteString(sub, classUniversal, tagOctetString, string(r.Type))
+		vals := make([]any, len(r.Values))
+		for i, val := range r.Values {
+			vals[i] = val
+		}
+		_, err = sub.WriteSetOf(vals...)
+		return
+	})
+
+	return
+}
+
+func (r *PartialAttribute) read(der *DERPacket) error {
+	return der.ReadConstructed(classUniversal, tagSequence, func(sub *DERPacket) (err error) {
+		var typ []byte
+		if typ, err = ldapReadString(sub, classUniversal, tagOctetString); err != nil {
+			return
+		}
+		r.Type = AttributeDescription(typ)
+
+		return sub.ReadConstructed(classUniversal, tagSet, func(vals *DERPacket) (err error) {
+			for vals.HasMoreData() && err == nil {
+				var val OctetString
+				if val, err = ldapReadOctets(vals, classUniversal, tagOctetString); err == nil {
+					r.Values = append(r.Values, val)
+				}
+			}
+			return
+		})
+	})
+}
+
+/*
+ldapWriteAttributes writes attrs as a SEQUENCE OF into der. If nonEmpty
+is true, each attribute must bear at least one value.
+*/
+func ldapWriteAttributes(der *DERPacket, attrs []PartialAttribute, nonEmpty bool) (err error) {
+	_, err = der.WriteConstructed(classUniversal, tagSequence, func(sub *DERPacket) (err error) {
+		for i := 0; i < len(attrs) && err == nil; i++ {
+			if nonEmpty && len(attrs[i].Values) == 0 {
+				err = errorTxt("attribute " + string(attrs[i].Type) + " has no values")
+			} else {
+				err = attrs[i].write(sub)
+			}
+		}
+		return
+	})
+
+	return
+}
+
+/*
+ldapReadAttributes reads a SEQUENCE OF PartialAttribute from der.
+*/
+func ldapReadAttributes(der *DERPacket) (attrs []PartialAttribute, err error) {
+	err = der.ReadConstructed(classUniversal, tagSequence, func(sub *DERPacket) (err error) {
+		for sub.HasMoreData() && err == nil {
+			var attr PartialAttribute
+			if err = attr.read(sub); err == nil {
+				attrs = append(attrs, attr)
+			}
+		}
+		return
+	})
+
+	return
+}
+
+/*
+ldapPeek returns the [TagAndLength] of the next element of der without
+consuming it, alongside a Boolean value indicative of success.
+*/
+func ldapPeek(der *DERPacket) (tal TagAndLength, ok bool) {
+	offset := der.offset
+	if der.HasMoreData() {
+		var err error
+		tal, err = der.TagAndLength()
+		ok = err == nil
+	}
+	der.offset = offset
+
+	return
+}
+
+/*
+ldapNext returns a Boolean value indicative of whether the next element of
+der bears the specified class and tag.
+*/
+func ldapNext(der *DERPacket, class, tag int) bool {
+	tal, ok := ldapPeek(der)
+	return ok && tal.Class == class && tal.Tag == tag
+}
+
+/*
+ldapReadRaw returns the complete encoding of the next element of der.
+*/
+func ldapReadRaw(der *DERPacket) (raw []byte, err error) {
+	start := der.offset
+	var tal TagAndLength
+	if tal, err = der.TagAndLength(); err == nil {
+		if end := der.offset + tal.Length; end > len(der.data) {
+			err = errorTxt("truncated element at offset " + itoa(start))
+		} else {
+			raw = der.data[start:end]
+			der.offset = end
+		}
+	}
+
+	return
+}
+
+func ldapWritePrimitive(der *DERPacket, class, tag int, content []byte) {
+	der.WriteTagAndLength(class, false, tag, len(content))
+	der.data = append(der.data, content...)
+}
+
+/*
+ldapReadOctets returns the content of the next element of der, which must
+be primitive and bear the specified class and tag. The return value is
+never nil.
+*/
+func ldapReadOctets(der *DERPacket, class, tag int) (content []byte, err error) {
+	var tal TagAndLength
+	if tal, err = der.TagAndLength(); err != nil {
+		return
+	} else if err = tal.Expect(class, tag, false); err != nil {
+		return
+	} else if der.offset+tal.Length > len(der.data) {
+		err = errorTxt("truncated element at offset " + itoa(der.offset))
+		return
+	}
+
+	content = append([]byte{}, der.data[der.offset:der.offset+tal.Length]...)
+	der.offset += tal.Length
+
+	return
+}
+
+func ldapWriteString(der *DERPacket, class, tag int, str string) {
+	ldapWritePrimitive(der, class, tag, []byte(str))
+}
+
+/*
+ldapReadString returns the content of the next element of der, as with
+[ldapReadOctets], except that empty content is returned as nil.
+*/
+func ldapReadString(der *DERPacket, class, tag int) (str []byte, err error) {
+	if str, err = ldapReadOctets(der, class, tag); len(str) == 0 {
+		str = nil
+	}
+
+	return
+}
+
+/*
+ldapWriteStrings writes each of strs as an OCTET STRING into der.
+*/
+func ldapWriteStrings(der *DERPacket, strs []LDAPString) error {
+	for _, str := range strs {
+		ldapWriteString(der, classUniversal, tagOctetString, string(str))
+	}
+
+	return nil
+}
+
+/*
+ldapReadStrings reads OCTET STRINGs from der until exhausted.
+*/
+func ldapReadStrings(der *DERPacket, strs *[]LDAPString) (err error) {
+	for der.HasMoreData() && err == nil {
+		var str []byte
+		if str, err = ldapReadString(der, classUniversal, tagOctetString); err == nil {
+			*strs = append(*strs, LDAPString(str))
+		}
+	}
+
+	return
+}
+
+/*
+ldapWriteInt writes i as an INTEGER bearing the specified class and tag.
+*/
+func ldapWriteInt(der *DERPacket, class, tag, i int) {
+	// asn1.Marshal yields a minimal two's complement
+	// encoding, following a two (2) byte header.
+	enc, _ := asn1m(int64(i))
+	ldapWritePrimitive(der, class, tag, enc[2:])
+}
+
+/*
+ldapReadInt returns the value of the next element of der, which must be an
+INTEGER (or ENUMERATED) within the range 0 through maxInt.
+*/
+func ldapReadInt(der *DERPacket, class, tag int) (i int, err error) {
+	var content []byte
+	if content, err = ldapReadOctets(der, class, tag); err != nil {
+		return
+	} else if len(content) == 0 {
+		err = errorTxt("INTEGER has no content octets")
+		return
+	}
+
+	n := newBigInt(0).SetBytes(content)
+	if content[0]&0x80 != 0 || !n.IsInt64() || n.Int64() > ldapMaxInt {
+		err = errorTxt("INTEGER out of range (0 .. maxInt)")
+	} else {
+		i = int(n.Int64())
+	}
+
+	return
+}
+
+func ldapWriteEnum(der *DERPacket, i int) {
+	ldapWriteInt(der, classUniversal, tagEnum, i)
+}
+
+func ldapReadEnum(der *DERPacket) (int, error) {
+	return ldapReadInt(der, classUniversal, tagEnum)
+}
+
+func ldapWriteBool(der *DERPacket, b bool) {
+	content := []byte{0x00}
+	if b {
+		content[0] = 0xff
+	}
+	ldapWritePrimitive(der, classUniversal, tagBoolean, content)
+}
+
+/*
+ldapReadBool returns the value of the next element of der, which must be
+a BOOLEAN. Per BER, any non-zero value is TRUE.
+*/
+func ldapReadBool(der *DERPacket) (bool, error) {
+	return ldapReadBoolTagged(der, classUniversal, tagBoolean)
+}
+
+/*
+ldapReadBoolTagged is as [ldapReadBool], but for a BOOLEAN bearing the
+specified class and tag.
+*/
+func ldapReadBoolTagged(der *DERPacket, class, tag int) (b bool, err error) {
+	var content []byte
+	if content, err = ldapReadOctets(der, class, tag); err == nil {
+		if len(content) != 1 {
+			err = errorTxt("BOOLEAN must consist of a single content octet")
+		} else {
+			b = content[0] != 0x00
+		}
+	}
+
+	return
+}
@@ -0,0 +1,1363 @@
+package dirsyn
+
+/*
+ldap_controls.go implements typed values for widely used LDAP controls,
+each of which may be converted to and from an RFC 4511 [Control].
+*/
+
+/*
+Object identifiers of the controls implemented within this package.
+*/
+const (
+	ControlPagedResults   = `1.2.840.113556.1.4.319`    // RFC 2696
+	ControlSortRequest    = `1.2.840.113556.1.4.473`    // RFC 2891
+	ControlSortResponse   = `1.2.840.113556.1.4.474`    // RFC 2891
+	ControlVLVRequest     = `2.16.840.1.113730.3.4.9`   // draft-ietf-ldapext-ldapv3-vlv
+	ControlVLVResponse    = `2.16.840.1.113730.3.4.10`  // draft-ietf-ldapext-ldapv3-vlv
+	ControlAssertion      = `1.3.6.1.1.12`              // RFC 4528
+	ControlPreRead        = `1.3.6.1.1.13.1`            // RFC 4527
+	ControlPostRead       = `1.3.6.1.1.13.2`            // RFC 4527
+	ControlProxiedAuthz   = `2.16.840.1.113730.3.4.18`  // RFC 4370
+	ControlManageDsaIT    = `2.16.840.1.113730.3.4.2`   // RFC 3296
+	ControlSubentries     = `1.3.6.1.4.1.4203.1.10.1`   // RFC 3672
+	ControlTreeDelete     = `1.2.840.113556.1.4.805`    // draft-armijo-ldap-treedelete
+	ControlPasswordPolicy = `1.3.6.1.4.1.42.2.27.8.5.1` // draft-behera-ldap-password-policy
+	ControlSyncRequest    = `1.3.6.1.4.1.4203.1.9.1.1`  // RFC 4533
+	ControlSyncState      = `1.3.6.1.4.1.4203.1.9.1.2`  // RFC 4533
+	ControlSyncDone       = `1.3.6.1.4.1.4203.1.9.1.3`  // RFC 4533
+)
+
+/*
+LDAPControl is implemented by [Control] and by each of the typed control
+values within this package, such as [PagedResultsControl].
+*/
+type LDAPControl interface {
+	// OID returns the controlType of the receiver instance.
+	OID() string
+
+	// Critical returns the criticality of the receiver instance.
+	Critical() bool
+
+	// Control returns the generic [Control] form of the receiver
+	// instance alongside an error.
+	Control() (Control, error)
+}
+
+/*
+ldapControlDecoders maps control OIDs to functions which decode a generic
+[Control] into its typed [LDAPControl] form. Controls sharing an OID for
+both request and response (e.g.: pre-read) are distinguished by value.
+*/
+var ldapControlDecoders = map[string]func(Control) (LDAPControl, error){
+	ControlPagedResults:             decodePagedResultsControl,
+	ControlSortRequest:              decodeSortRequestControl,
+	ControlSortResponse:             decodeSortResponseControl,
+	ControlVLVRequest:               decodeVLVRequestControl,
+	ControlVLVResponse:              decodeVLVResponseControl,
+	ControlAssertion:                decodeAssertionControl,
+	ControlPreRead:                  decodeReadEntryControl,
+	ControlPostRead:                 decodeReadEntryControl,
+	ControlProxiedAuthz:             decodeProxiedAuthzControl,
+	ControlManageDsaIT:              decodeManageDsaITControl,
+	ControlSubentries:               decodeSubentriesControl,
+	ControlTreeDelete:               decodeTreeDeleteControl,
+	ControlPasswordPolicy:           decodePasswordPolicyControl,
+	ControlSyncRequest:              decodeSyncRequestControl,
+	ControlSyncState:                decodeSyncStateControl,
+	ControlSyncDone:                 decodeSyncDoneControl,
+	ControlTransactionSpecification: decodeTransactionSpecificationControl,
+}
+
+/*
+OID returns the controlType of the receiver instance.
+*/
+func (r Control) OID() string { return string(r.Type) }
+
+/*
+Critical returns the criticality of the receiver instance.
+*/
+func (r Control) Critical() bool { return r.Criticality }
+
+/*
+Control returns the receiver instance alongside a nil error, thereby
+satisfying the [LDAPControl] interface.
+*/
+func (r Control) Control() (Control, error) { return r, nil }
+
+/*
+Decode returns the typed [LDAPControl] form of the receiver instance
+alongside an error. If the controlType is not known to this package,
+the receiver instance is returned as-is.
+*/
+func (r Control) Decode() (LDAPControl, error) {
+	if decode, found := ldapControlDecoders[string(r.Type)]; found {
+		return decode(r)
+	}
+
+	return r, nil
+}
+
+/*
+Get returns the first [Control] within the receiver instance bearing the
+specified controlType, alongside a Boolean value indicative of success.
+*/
+func (r Controls) Get(oid string) (ctrl Control, found bool) {
+	for i := 0; i < len(r) && !found; i++ {
+		if found = string(r[i].Type) == oid; found {
+			ctrl = r[i]
+		}
+	}
+
+	return
+}
+
+/*
+PagedResultsControl implements the Simple Paged Results Manipulation
+control of [RFC 2696]. The same value is used within requests and
+responses.
+
+	realSearchControlValue ::= SEQUENCE {
+	        size            INTEGER (0..maxInt),
+	                                -- requested page size from client
+	                                -- result set size estimate from server
+	        cookie          OCTET STRING }
+
+[RFC 2696]: https://datatracker.ietf.org/doc/html/rfc2696
+*/
+type PagedResultsControl struct {
+	Criticality bool
+	Size        int
+	Cookie      OctetString
+}
+
+/*
+OID returns the controlType of the receiver instance.
+*/
+func (r PagedResultsControl) OID() string { return ControlPagedResults }
+
+/*
+Critical returns the criticality of the receiver instance.
+*/
+func (r PagedResultsControl) Critical() bool { return r.Criticality }
+
+/*
+Control returns the generic [Control] form of the receiver instance
+alongside an error.
+*/
+func (r PagedResultsControl) Control() (Control, error) {
+	return ldapControlWrite(r, func(der *DERPacket) error {
+		return ldapWriteSequence(der, func(sub *DERPacket) error {
+			if err := ldapCheckInt(`size`, r.Size); err != nil {
+				return err
+			}
+			ldapWriteInt(sub, classUniversal, tagInteger, r.Size)
+			ldapWritePrimitive(sub, classUniversal, tagOctetString, r.Cookie)
+			return nil
+		})
+	})
+}
+
+func decodePagedResultsControl(ctrl Control) (LDAPControl, error) {
+	r := PagedResultsControl{Criticality: ctrl.Criticality}
+	err := ldapControlRead(ctrl, func(der *DERPacket) error {
+		return der.ReadConstructed(classUniversal, tagSequence, func(sub *DERPacket) (err error) {
+			if r.Size, err = ldapReadInt(sub, classUniversal, tagInteger); err == nil {
+				r.Cookie, err = ldapReadString(sub, classUniversal, tagOctetString)
+			}
+			return
+		})
+	})
+
+	return r, err
+}
+
+/*
+SortKey implements a single element of the SortKeyList of [RFC 2891].
+
+	SortKeyList ::= SEQUENCE OF SEQUENCE {
+	        attributeType   AttributeDescription,
+	        orderingRule    [0] MatchingRuleId OPTIONAL,
+	        reverseOrder    [1] BOOLEAN DEFAULT FALSE }
+
+[RFC 2891]: https://datatracker.ietf.org/doc/html/rfc2891
+*/
+type SortKey struct {
+	Type         AttributeDescription
+	OrderingRule MatchingRuleID
+	Reverse      bool
+}
+
+/*
+SortRequestControl implements the server side sorting request control of
+[RFC 2891].
+
+[RFC 2891]: https://datatracker.ietf.org/doc/html/rfc2891
+*/
+type SortRequestControl struct {
+	Criticality bool
+	Keys        []SortKey
+}
+
+/*
+OID returns the controlType of the receiver instance.
+*/
+func (r SortRequestControl) OID() string { return ControlSortRequest }
+
+/*
+Critical returns the criticality of the receiver instance.
+*/
+func (r SortRequestControl) Critical() bool { return r.Criticality }
+
+/*
+Control returns the generic [Control] form of the receiver instance
+alongside an error.
+*/
+func (r SortRequestControl) Control() (Control, error) {
+	if len(r.Keys) == 0 {
+		return Control{}, errorTxt("SortKeyList must contain at least one key")
+	}
+
+	return ldapControlWrite(r, func(der *DERPacket) error {
+		return ldapWriteSequence(der, func(keys *DERPacket) (err error) {
+			for i := 0; i < len(r.Keys) && err == nil; i++ {
+				key := r.Keys[i]
+				if len(key.Type) == 0 {
+					err = errorTxt("SortKey attributeType is empty")
+					break
+				}
+				err = ldapWriteSequence(keys, func(sub *DERPacket) error {
+					ldapWritePrimitive(sub, classUniversal, tagOctetString, key.Type)
+					if len(key.OrderingRule) > 0 {
+						ldapWritePrimitive(sub, classContextSpecific, 0, key.OrderingRule)
+					}
+					if key.Reverse {
+						ldapWritePrimitive(sub, classContextSpecific, 1, []byte{0xff})
+					}
+					return nil
+				})
+			}
+			return
+		})
+	})
+}
+
+func decodeSortRequestControl(ctrl Control) (LDAPControl, error) {
+	r := SortRequestControl{Criticality: ctrl.Criticality}
+	err := ldapControlRead(ctrl, func(der *DERPacket) error {
+		return der.ReadConstructed(classUniversal, tagSequence, func(keys *DERPacket) (err error) {
+			for keys.HasMoreData() && err == nil {
+				var key SortKey
+				err = keys.ReadConstructed(classUniversal, tagSequence, func(sub *DERPacket) (err error) {
+					var content []byte
+					if content, err = ldapReadString(sub, classUniversal, tagOctetString); err != nil {
+						return
+					}
+					key.Type = AttributeDescription(content)
+					if ldapNext(sub, classContextSpecific, 0) {
+						if content, err = ldapReadString(sub, classContextSpecific, 0); err != nil {
+							return
+						}
+						key.OrderingRule = MatchingRuleID(content)
+					}
+					if ldapNext(sub, classContextSpecific, 1) {
+						key.Reverse, err = ldapReadBoolTagged(sub, classContextSpecific, 1)
+					}
+					return
+				})
+				r.Keys = append(r.Keys, key)
+			}
+			return
+		})
+	})
+
+	return r, err
+}
+
+/*
+SortResponseControl implements the server side sorting response control
+of [RFC 2891].
+
+	SortResult ::= SEQUENCE {
+	   sortResult  ENUMERATED { ... },
+	   attributeType [0] AttributeDescription OPTIONAL }
+
+[RFC 2891]: https://datatracker.ietf.org/doc/html/rfc2891
+*/
+type SortResponseControl struct {
+	Criticality bool
+	Result      ResultCode
+	Type        AttributeDescription
+}
+
+/*
+OID returns the controlType of the receiver instance.
+*/
+func (r SortResponseControl) OID() string { return ControlSortResponse }
+
+/*
+Critical returns the criticality of the receiver instance.
+*/
+func (r SortResponseControl) Critical() bool { return r.Criticality }
+
+/*
+Control returns the generic [Control] form of the receiver instance
+alongside an error.
+*/
+func (r SortResponseControl) Control() (Control, error) {
+	return ldapControlWrite(r, func(der *DERPacket) error {
+		return ldapWriteSequence(der, func(sub *DERPacket) error {
+			ldapWriteEnum(sub, int(r.Result))
+			if len(r.Type) > 0 {
+				ldapWritePrimitive(sub, classContextSpecific, 0, r.Type)
+			}
+			return nil
+		})
+	})
+}
+
+func decodeSortResponseControl(ctrl Control) (LDAPControl, error) {
+	r := SortResponseControl{Criticality: ctrl.Criticality}
+	err := ldapControlRead(ctrl, func(der *DERPacket) error {
+		return der.ReadConstructed(classUniversal, tagSequence, func(sub *DERPacket) (err error) {
+			var result int
+			if result, err = ldapReadEnum(sub); err == nil {
+				r.Result = ResultCode(result)
+				if ldapNext(sub, classContextSpecific, 0) {
+					var typ []byte
+					typ, err = ldapReadString(sub, classContextSpecific, 0)
+					r.Type = AttributeDescription(typ)
+				}
+			}
+			return
+		})
+	})
+
+	return r, err
+}
+
+/*
+VLVRequestControl implements the virtual list view request control of
+[draft-ietf-ldapext-ldapv3-vlv]. If GreaterThanOrEqual is non-nil, the
+greaterThanOrEqual target is used in place of byOffset.
+
+	VirtualListViewRequest ::= SEQUENCE {
+	        beforeCount    INTEGER (0..maxInt),
+	        afterCount     INTEGER (0..maxInt),
+	        target       CHOICE {
+	                       byOffset        [0] SEQUENCE {
+	                            offset          INTEGER (1 .. maxInt),
+	                            contentCount    INTEGER (0 .. maxInt) },
+	                       greaterThanOrEqual [1] AssertionValue },
+	        contextID     OCTET STRING OPTIONAL }
+
+[draft-ietf-ldapext-ldapv3-vlv]: https://datatracker.ietf.org/doc/html/draft-ietf-ldapext-ldapv3-vlv
+*/
+type VLVRequestControl struct {
+	Criticality        bool
+	BeforeCount        int
+	AfterCount         int
+	Offset             int
+	ContentCount       int
+	GreaterThanOrEqual AssertionValue
+	ContextID          OctetString
+}
+
+/*
+OID returns the controlType of the receiver instance.
+*/
+func (r VLVRequestControl) OID() string { return ControlVLVRequest }
+
+/*
+Critical returns the criticality of the receiver instance.
+*/
+func (r VLVRequestControl) Critical() bool { return r.Criticality }
+
+/*
+Control returns the generic [Control] form of the receiver instance
+alongside an error.
+*/
+func (r VLVRequestControl) Control() (Control, error) {
+	return ldapControlWrite(r, func(der *DERPacket) error {
+		return ldapWriteSequence(der, func(sub *DERPacket) (err error) {
+			for _, i := range []struct {
+				name  string
+				value int
+			}{
+				{`beforeCount`, r.BeforeCount},
+				{`afterCount`, r.AfterCount},
+				{`offset`, r.Offset},
+				{`contentCount`, r.ContentCount},
+			} {
+				if err = ldapCheckInt(i.name, i.value); err != nil {
+					return
+				}
+			}
+
+			ldapWriteInt(sub, classUniversal, tagInteger, r.BeforeCount)
+			ldapWriteInt(sub, classUniversal, tagInteger, r.AfterCount)
+			if r.GreaterThanOrEqual != nil {
+				ldapWritePrimitive(sub, classContextSpecific, 1, r.GreaterThanOrEqual)
+			} else {
+				_, err = sub.WriteConstructed(classContextSpecific, 0, func(off *DERPacket) error {
+					ldapWriteInt(off, classUniversal, tagInteger, r.Offset)
+					ldapWriteInt(off, classUniversal, tagInteger, r.ContentCount)
+					return nil
+				})
+			}
+			if r.ContextID != nil {
+				ldapWritePrimitive(sub, classUniversal, tagOctetString, r.ContextID)
+			}
+			return
+		})
+	})
+}
+
+func decodeVLVRequestControl(ctrl Control) (LDAPControl, error) {
+	r := VLVRequestControl{Criticality: ctrl.Criticality}
+	err := ldapControlRead(ctrl, func(der *DERPacket) error {
+		return der.ReadConstructed(classUniversal, tagSequence, func(sub *DERPacket) (err error) {
+			if r.BeforeCount, err = ldapReadInt(sub, classUniversal, tagInteger); err != nil {
+				return
+			} else if r.AfterCount, err = ldapReadInt(sub, classUniversal, tagInteger); err != nil {
+				return
+			}
+
+			if ldapNext(sub, classContextSpecific, 1) {
+				r.GreaterThanOrEqual, err = ldapReadOctets(sub, classContextSpecific, 1)
+			} else {
+				err = sub.ReadConstructed(classContextSpecific, 0, func(off *DERPacket) (err error) {
+					if r.Offset, err = ldapReadInt(off, classUniversal, tagInteger); err == nil {
+						r.ContentCount, err = ldapReadInt(off, classUniversal, tagInteger)
+					}
+					return
+				})
+			}
+
+			if err == nil && sub.HasMoreData() {
+				r.ContextID, err = ldapReadOctets(sub, classUniversal, tagOctetString)
+			}
+			return
+		})
+	})
+
+	return r, err
+}
+
+/*
+VLVResponseControl implements the virtual list view response control of
+[draft-ietf-ldapext-ldapv3-vlv].
+
+	VirtualListViewResponse ::= SEQUENCE {
+	        targetPosition    INTEGER (0 .. maxInt),
+	        contentCount     INTEGER (0 .. maxInt),
+	        virtualListViewResult ENUMERATED { ... },
+	        contextID     OCTET STRING OPTIONAL }
+
+[draft-ietf-ldapext-ldapv3-vlv]: https://datatracker.ietf.org/doc/html/draft-ietf-ldapext-ldapv3-vlv
+*/
+type VLVResponseControl struct {
+	Criticality    bool
+	TargetPosition int
+	ContentCount   int
+	Result         ResultCode
+	ContextID      OctetString
+}
+
+/*
+OID returns the controlType of the receiver instance.
+*/
+func (r VLVResponseControl) OID() string { return ControlVLVResponse }
+
+/*
+Critical returns the criticality of the receiver instance.
+*/
+func (r VLVResponseControl) Critical() bool { return r.Criticality }
+
+/*
+Control returns the generic [Control] form of the receiver instance
+alongside an error.
+*/
+func (r VLVResponseControl) Control() (Control, error) {
+	return ldapControlWrite(r, func(der *DERPacket) error {
+		return ldapWriteSequence(der, func(sub *DERPacket) (err error) {
+			if err = ldapCheckInt(`targetPosition`, r.TargetPosition); err != nil {
+				return
+			} else if err = ldapCheckInt(`contentCount`, r.ContentCount); err != nil {
+				return
+			}
+
+			ldapWriteInt(sub, classUniversal, tagInteger, r.TargetPosition)
+			ldapWriteInt(sub, classUniversal, tagInteger, r.ContentCount)
+			ldapWriteEnum(sub, int(r.Result))
+			if r.ContextID != nil {
+				ldapWritePrimitive(sub, classUniversal, tagOctetString, r.ContextID)
+			}
+			return
+		})
+	})
+}
+
+func decodeVLVResponseControl(ctrl Control) (LDAPControl, error) {
+	r := VLVResponseControl{Criticality: ctrl.Criticality}
+	err := ldapControlRead(ctrl, func(der *DERPacket) error {
+		return der.ReadConstructed(classUniversal, tagSequence, func(sub *DERPacket) (err error) {
+			var result int
+			if r.TargetPosition, err = ldapReadInt(sub, classUniversal, tagInteger); err != nil {
+				return
+			} else if r.ContentCount, err = ldapReadInt(sub, classUniversal, tagInteger); err != nil {
+				return
+			} else if result, err = ldapReadEnum(sub); err != nil {
+				return
+			}
+
+			r.Result = ResultCode(result)
+			if sub.HasMoreData() {
+				r.ContextID, err = ldapReadOctets(sub, classUniversal, tagOctetString)
+			}
+			return
+		})
+	})
+
+	return r, err
+}
+
+/*
+AssertionControl implements the assertion control of [RFC 4528], the value
+of which is a [Filter].
+
+[RFC 4528]: https://datatracker.ietf.org/doc/html/rfc4528
+*/
+type AssertionControl struct {
+	Criticality bool
+	Filter      Filter
+}
+
+/*
+OID returns the controlType of the receiver instance.
+*/
+func (r AssertionControl) OID() string { return ControlAssertion }
+
+/*
+Critical returns the criticality of the receiver instance.
+*/
+func (r AssertionControl) Critical() bool { return r.Criticality }
+
+/*
+Control returns the generic [Control] form of the receiver instance
+alongside an error.
+*/
+func (r AssertionControl) Control() (Control, error) {
+	if r.Filter == nil {
+		return Control{}, errorTxt("assertion control has no filter")
+	}
+
+	return ldapControlWrite(r, func(der *DERPacket) error {
+		return ldapWriteFilter(der, r.Filter)
+	})
+}
+
+func decodeAssertionControl(ctrl Control) (LDAPControl, error) {
+	r := AssertionControl{Criticality: ctrl.Criticality}
+	err := ldapControlRead(ctrl, func(der *DERPacket) (err error) {
+		r.Filter, err = ldapReadFilter(der)
+		return
+	})
+
+	return r, err
+}
+
+/*
+ReadEntryRequestControl implements the pre-read and post-read request
+controls of [RFC 4527]. Post indicates the post-read control, as opposed
+to the pre-read control.
+
+	controlValue ::= AttributeSelection
+
+[RFC 4527]: https://datatracker.ietf.org/doc/html/rfc4527
+*/
+type ReadEntryRequestControl struct {
+	Criticality bool
+	Post        bool
+	Attributes  []LDAPString
+}
+
+/*
+OID returns the controlType of the receiver instance.
+*/
+func (r ReadEntryRequestControl) OID() string { return ldapReadEntryOID(r.Post) }
+
+/*
+Critical returns the criticality of the receiver instance.
+*/
+func (r ReadEntryRequestControl) Critical() bool { return r.Criticality }
+
+/*
+Control returns the generic [Control] form of the receiver instance
+alongside an error.
+*/
+func (r ReadEntryRequestControl) Control() (Control, error) {
+	return ldapControlWrite(r, func(der *DERPacket) error {
+		return ldapWriteSequence(der, func(sub *DERPacket) error {
+			return ldapWriteStrings(sub, r.Attributes)
+		})
+	})
+}
+
+/*
+ReadEntryResponseControl implements the pre-read and post-read response
+controls of [RFC 4527]. Post indicates the post-read control, as opposed
+to the pre-read control.
+
+	controlValue ::= SearchResultEntry
+
+[RFC 4527]: https://datatracker.ietf.org/doc/html/rfc4527
+*/
+type ReadEntryResponseControl struct {
+	Criticality bool
+	Post        bool
+	Entry       SearchResultEntry
+}
+
+/*
+OID returns the controlType of the receiver instance.
+*/
+func (r ReadEntryResponseControl) OID() string { return ldapReadEntryOID(r.Post) }
+
+/*
+Critical returns the criticality of the receiver instance.
+*/
+func (r ReadEntryResponseControl) Critical() bool { return r.Criticality }
+
+/*
+Control returns the generic [Control] form of the receiver instance
+alongside an error.
+*/
+func (r ReadEntryResponseControl) Control() (Control, error) {
+	return ldapControlWrite(r, r.Entry.writeOp)
+}
+
+func ldapReadEntryOID(post bool) string {
+	if post {
+		return ControlPostRead
+	}
+
+	return ControlPreRead
+}
+
+/*
+decodeReadEntryControl decodes a pre-read or post-read control, wherein
+a value bearing the SearchResultEntry tag denotes a response.
+*/
+func decodeReadEntryControl(ctrl Control) (LDAPControl, error) {
+	post := string(ctrl.Type) == ControlPostRead
+	if len(ctrl.Value) > 0 && ctrl.Value[0] == 0x60|tagSearchResultEntry {
+		r := ReadEntryResponseControl{Criticality: ctrl.Criticality, Post: post}
+		err := ldapControlRead(ctrl, r.Entry.readOp)
+		return r, err
+	}
+
+	r := ReadEntryRequestControl{Criticality: ctrl.Criticality, Post: post}
+	err := ldapControlRead(ctrl, func(der *DERPacket) error {
+		return der.ReadConstructed(classUniversal, tagSequence, func(sub *DERPacket) error {
+			return ldapReadStrings(sub, &r.Attributes)
+		})
+	})
+
+	return r, err
+}
+
+/*
+ProxiedAuthzControl implements the proxied authorization (version 2)
+control of [RFC 4370]. The value is the authzId, as described in § 5.2.1.8
+of RFC 4513, and is not BER encoded. Per the RFC, clients MUST mark this
+control as critical.
+
+[RFC 4370]: https://datatracker.ietf.org/doc/html/rfc4370
+*/
+type ProxiedAuthzControl struct {
+	Criticality bool
+	AuthzID     LDAPString
+}
+
+/*
+OID returns the controlType of the receiver instance.
+*/
+func (r ProxiedAuthzControl) OID() string { return ControlProxiedAuthz }
+
+/*
+Critical returns the criticality of the receiver instance.
+*/
+func (r ProxiedAuthzControl) Critical() bool { return r.Criticality }
+
+/*
+Control returns the generic [Control] form of the receiver instance
+alongside an error.
+*/
+func (r ProxiedAuthzControl) Control() (Control, error) {
+	authzID := OctetString(r.AuthzID)
+	if authzID == nil {
+		// an empty authzId denotes the anonymous identity,
+		// but the value itself is mandatory.
+		authzID = OctetString{}
+	}
+
+	return Control{Type: LDAPOID(r.OID()), Criticality: r.Criticality, Value: authzID}, nil
+}
+
+func decodeProxiedAuthzControl(ctrl Control) (LDAPControl, error) {
+	r := ProxiedAuthzControl{Criticality: ctrl.Criticality}
+	if ctrl.Value == nil {
+		return r, errorTxt("control " + r.OID() + " has no value")
+	} else if len(ctrl.Value) > 0 {
+		r.AuthzID = LDAPString(append([]byte{}, ctrl.Value...))
+	}
+
+	return r, nil
+}
+
+/*
+ManageDsaITControl implements the ManageDsaIT control of [RFC 3296], which
+bears no value.
+
+[RFC 3296]: https://datatracker.ietf.org/doc/html/rfc3296
+*/
+type ManageDsaITControl struct {
+	Criticality bool
+}
+
+/*
+OID returns the controlType of the receiver instance.
+*/
+func (r ManageDsaITControl) OID() string { return ControlManageDsaIT }
+
+/*
+Critical returns the criticality of the receiver instance.
+*/
+func (r ManageDsaITControl) Critical() bool { return r.Criticality }
+
+/*
+Control returns the generic [Control] form of the receiver instance
+alongside a nil error.
+*/
+func (r ManageDsaITControl) Control() (Control, error) {
+	return Control{Type: LDAPOID(r.OID()), Criticality: r.Criticality}, nil
+}
+
+func decodeManageDsaITControl(ctrl Control) (LDAPControl, error) {
+	return ManageDsaITControl{Criticality: ctrl.Criticality}, ldapControlEmpty(ctrl)
+}
+
+/*
+SubentriesControl implements the subentries control of [§ 3 of RFC 3672].
+
+	controlValue ::= BOOLEAN -- visibility
+
+[§ 3 of RFC 3672]: https://datatracker.ietf.org/doc/html/rfc3672#section-3
+*/
+type SubentriesControl struct {
+	Criticality bool
+	Visibility  bool
+}
+
+/*
+OID returns the controlType of the receiver instance.
+*/
+func (r SubentriesControl) OID() string { return ControlSubentries }
+
+/*
+Critical returns the criticality of the receiver instance.
+*/
+func (r SubentriesControl) Critical() bool { return r.Criticality }
+
+/*
+Control returns the generic [Control] form of the receiver instance
+alongside an error.
+*/
+func (r SubentriesControl) Control() (Control, error) {
+	return ldapControlWrite(r, func(der *DERPacket) error {
+		ldapWriteBool(der, r.Visibility)
+		return nil
+	})
+}
+
+func decodeSubentriesControl(ctrl Control) (LDAPControl, error) {
+	r := SubentriesControl{Criticality: ctrl.Criticality}
+	err := ldapControlRead(ctrl, func(der *DERPacket) (err error) {
+		r.Visibility, err = ldapReadBool(der)
+		return
+	})
+
+	return r, err
+}
+
+/*
+TreeDeleteControl implements the tree delete control of
+[draft-armijo-ldap-treedelete], which bears no value.
+
+[draft-armijo-ldap-treedelete]: https://datatracker.ietf.org/doc/html/draft-armijo-ldap-treedelete
+*/
+type TreeDeleteControl struct {
+	Criticality bool
+}
+
+/*
+OID returns the controlType of the receiver instance.
+*/
+func (r TreeDeleteControl) OID() string { return ControlTreeDelete }
+
+/*
+Critical returns the criticality of the receiver instance.
+*/
+func (r TreeDeleteControl) Critical() bool { return r.Criticality }
+
+/*
+Control returns the generic [Control] form of the receiver instance
+alongside a nil error.
+*/
+func (r TreeDeleteControl) Control() (Control, error) {
+	return Control{Type: LDAPOID(r.OID()), Criticality: r.Criticality}, nil
+}
+
+func decodeTreeDeleteControl(ctrl Control) (LDAPControl, error) {
+	return TreeDeleteControl{Criticality: ctrl.Criticality}, ldapControlEmpty(ctrl)
+}
+
+/*
+PasswordPolicyError implements the error ENUMERATED of the password policy
+response control of [draft-behera-ldap-password-policy].
+
+[draft-behera-ldap-password-policy]: https://datatracker.ietf.org/doc/html/draft-behera-ldap-password-policy
+*/
+type PasswordPolicyError int
+
+const (
+	PasswordExpired PasswordPolicyError = iota
+	AccountLocked
+	ChangeAfterReset
+	PasswordModNotAllowed
+	MustSupplyOldPassword
+	InsufficientPasswordQuality
+	PasswordTooShort
+	PasswordTooYoung
+	PasswordInHistory
+	PasswordTooLong
+)
+
+/*
+String returns the string representation of the receiver instance.
+*/
+func (r PasswordPolicyError) String() (s string) {
+	switch r {
+	case PasswordExpired:
+		s = `passwordExpired`
+	case AccountLocked:
+		s = `accountLocked`
+	case ChangeAfterReset:
+		s = `changeAfterReset`
+	case PasswordModNotAllowed:
+		s = `passwordModNotAllowed`
+	case MustSupplyOldPassword:
+		s = `mustSupplyOldPassword`
+	case InsufficientPasswordQuality:
+		s = `insufficientPasswordQuality`
+	case PasswordTooShort:
+		s = `passwordTooShort`
+	case PasswordTooYoung:
+		s = `passwordTooYoung`
+	case PasswordInHistory:
+		s = `passwordInHistory`
+	case PasswordTooLong:
+		s = `passwordTooLong`
+	default:
+		s = `unknown (` + itoa(int(r)) + `)`
+	}
+
+	return
+}
+
+/*
+PasswordPolicyControl implements the password policy control of
+[draft-behera-ldap-password-policy]. The request control bears no value,
+and is produced when Response is false. Otherwise, the response value is
+encoded using the remaining (OPTIONAL) fields, of which at most one of
+TimeBeforeExpiration and GraceAuthNsRemaining may be set.
+
+	PasswordPolicyResponseValue ::= SEQUENCE {
+	   warning [0] CHOICE {
+	      timeBeforeExpiration [0] INTEGER (0 .. maxInt),
+	      graceAuthNsRemaining [1] INTEGER (0 .. maxInt) } OPTIONAL,
+	   error   [1] ENUMERATED { ... } OPTIONAL }
+
+[draft-behera-ldap-password-policy]: https://datatracker.ietf.org/doc/html/draft-behera-ldap-password-policy
+*/
+type PasswordPolicyControl struct {
+	Criticality          bool
+	Response             bool
+	TimeBeforeExpiration *int
+	GraceAuthNsRemaining *int
+	Error                *PasswordPolicyError
+}
+
+/*
+OID returns the controlType of the receiver instance.
+*/
+func (r PasswordPolicyControl) OID() string { return ControlPasswordPolicy }
+
+/*
+Critical returns the criticality of the receiver instance.
+*/
+func (r PasswordPolicyControl) Critical() bool { return r.Criticality }
+
+/*
+Control returns the generic [Control] form of the receiver instance
+alongside an error.
+*/
+func (r PasswordPolicyControl) Control() (Control, error) {
+	if !r.Response {
+		return Control{Type: LDAPOID(r.OID()), Criticality: r.Criticality}, nil
+	} else if r.TimeBeforeExpiration != nil && r.GraceAuthNsRemaining != nil {
+		return Control{}, errorTxt("password policy warning must be either timeBeforeExpiration or graceAuthNsRemaining")
+	}
+
+	return ldapControlWrite(r, func(der *DERPacket) error {
+		return ldapWriteSequence(der, func(sub *DERPacket) (err error) {
+			tag, warning := 0, r.TimeBeforeExpiration
+			if r.GraceAuthNsRemaining != nil {
+				tag, warning = 1, r.GraceAuthNsRemaining
+			}
+
+			if warning != nil {
+				if err = ldapCheckInt(`warning`, *warning); err != nil {
+					return
+				}
+				_, err = sub.WriteConstructed(classContextSpecific, 0, func(w *DERPacket) error {
+					ldapWriteInt(w, classContextSpecific, tag, *warning)
+					return nil
+				})
+			}
+
+			if err == nil && r.Error != nil {
+				ldapWriteInt(sub, classContextSpecific, 1, int(*r.Error))
+			}
+			return
+		})
+	})
+}
+
+func decodePasswordPolicyControl(ctrl Control) (LDAPControl, error) {
+	r := PasswordPolicyControl{Criticality: ctrl.Criticality}
+	if ctrl.Value == nil {
+		return r, nil
+	}
+
+	r.Response = true
+	err := ldapControlRead(ctrl, func(der *DERPacket) error {
+		return der.ReadConstructed(classUniversal, tagSequence, func(sub *DERPacket) (err error) {
+			if ldapNext(sub, classContextSpecific, 0) {
+				err = sub.ReadConstructed(classContextSpecific, 0, func(w *DERPacket) (err error) {
+					tal, _ := ldapPeek(w)
+					var warning int
+					if warning, err = ldapReadInt(w, classContextSpecific, tal.Tag); err != nil {
+						return
+					}
+
+					switch tal.Tag {
+					case 0:
+						r.TimeBeforeExpiration = &warning
+					case 1:
+						r.GraceAuthNsRemaining = &warning
+					default:
+						err = errorTxt("unknown password policy warning [" + itoa(tal.Tag) + "]")
+					}
+					return
+				})
+			}
+
+			if err == nil && sub.HasMoreData() {
+				var code int
+				if code, err = ldapReadInt(sub, classContextSpecific, 1); err == nil {
+					ppe := PasswordPolicyError(code)
+					r.Error = &ppe
+				}
+			}
+			return
+		})
+	})
+
+	return r, err
+}
+
+/*
+SyncMode implements the mode ENUMERATED of the sync request control of
+[§ 2.2 of RFC 4533].
+
+[§ 2.2 of RFC 4533]: https://datatracker.ietf.org/doc/html/rfc4533#section-2.2
+*/
+type SyncMode int
+
+const (
+	SyncRefreshOnly       SyncMode = 1
+	SyncRefreshAndPersist SyncMode = 3
+)
+
+/*
+String returns the string representation of the receiver instance.
+*/
+func (r SyncMode) String() (s string) {
+	switch r {
+	case SyncRefreshOnly:
+		s = `refreshOnly`
+	case SyncRefreshAndPersist:
+		s = `refreshAndPersist`
+	default:
+		s = `unknown (` + itoa(int(r)) + `)`
+	}
+
+	return
+}
+
+/*
+SyncRequestControl implements the sync request control of [§ 2.2 of RFC
+4533]. A nil Cookie is absent from the encoding.
+
+	syncRequestValue ::= SEQUENCE {
+	    mode ENUMERATED {
+	        -- 0 unused
+	        refreshOnly       (1),
+	        -- 2 reserved
+	        refreshAndPersist (3)
+	    },
+	    cookie     syncCookie OPTIONAL,
+	    reloadHint BOOLEAN DEFAULT FALSE
+	}
+
+[§ 2.2 of RFC 4533]: https://datatracker.ietf.org/doc/html/rfc4533#section-2.2
+*/
+type SyncRequestControl struct {
+	Criticality bool
+	Mode        SyncMode
+	Cookie      OctetString
+	ReloadHint  bool
+}
+
+/*
+OID returns the controlType of the receiver instance.
+*/
+func (r SyncRequestControl) OID() string { return ControlSyncRequest }
+
+/*
+Critical returns the criticality of the receiver instance.
+*/
+func (r SyncRequestControl) Critical() bool { return r.Criticality }
+
+/*
+Control returns the generic [Control] form of the receiver instance
+alongside an error.
+*/
+func (r SyncRequestControl) Control() (Control, error) {
+	if r.Mode != SyncRefreshOnly && r.Mode != SyncRefreshAndPersist {
+		return Control{}, errorTxt("invalid sync request mode " + r.Mode.String())
+	}
+
+	return ldapControlWrite(r, func(der *DERPacket) error {
+		return ldapWriteSequence(der, func(sub *DERPacket) error {
+			ldapWriteEnum(sub, int(r.Mode))
+			if r.Cookie != nil {
+				ldapWritePrimitive(sub, classUniversal, tagOctetString, r.Cookie)
+			}
+			if r.ReloadHint {
+				ldapWriteBool(sub, true)
+			}
+			return nil
+		})
+	})
+}
+
+func decodeSyncRequestControl(ctrl Control) (LDAPControl, error) {
+	r := SyncRequestControl{Criticality: ctrl.Criticality}
+	err := ldapControlRead(ctrl, func(der *DERPacket) error {
+		return der.ReadConstructed(classUniversal, tagSequence, func(sub *DERPacket) (err error) {
+			var mode int
+			if mode, err = ldapReadEnum(sub); err != nil {
+				return
+			}
+
+			r.Mode = SyncMode(mode)
+			if ldapNext(sub, classUniversal, tagOctetString) {
+				if r.Cookie, err = ldapReadOctets(sub, classUniversal, tagOctetString); err != nil {
+					return
+				}
+			}
+			if sub.HasMoreData() {
+				r.ReloadHint, err = ldapReadBool(sub)
+			}
+			return
+		})
+	})
+
+	return r, err
+}
+
+/*
+SyncState implements the state ENUMERATED of the sync state control of
+[§ 2.3 of RFC 4533].
+
+[§ 2.3 of RFC 4533]: https://datatracker.ietf.org/doc/html/rfc4533#section-2.3
+*/
+type SyncState int
+
+const (
+	SyncStatePresent SyncState = iota
+	SyncStateAdd
+	SyncStateModify
+	SyncStateDelete
+)
+
+/*
+String returns the string representation of the receiver instance.
+*/
+func (r SyncState) String() (s string) {
+	switch r {
+	case SyncStatePresent:
+		s = `present`
+	case SyncStateAdd:
+		s = `add`
+	case SyncStateModify:
+		s = `modify`
+	case SyncStateDelete:
+		s = `delete`
+	default:
+		s = `unknown (` + itoa(int(r)) + `)`
+	}
+
+	return
+}
+
+/*
+SyncStateControl implements the sync state control of [§ 2.3 of RFC 4533].
+The EntryUUID must be sixteen (16) octets in length. A nil Cookie is absent
+from the encoding.
+
+	syncStateValue ::= SEQUENCE {
+	    state ENUMERATED {
+	        present (0),
+	        add (1),
+	        modify (2),
+	        delete (3)
+	    },
+	    entryUUID syncUUID,
+	    cookie    syncCookie OPTIONAL
+	}
+
+[§ 2.3 of RFC 4533]: https://datatracker.ietf.org/doc/html/rfc4533#section-2.3
+*/
+type SyncStateControl struct {
+	Criticality bool
+	State       SyncState
+	EntryUUID   OctetString
+	Cookie      OctetString
+}
+
+/*
+OID returns the controlType of the receiver instance.
+*/
+func (r SyncStateControl) OID() string { return ControlSyncState }
+
+/*
+Critical returns the criticality of the receiver instance.
+*/
+func (r SyncStateControl) Critical() bool { return r.Criticality }
+
+/*
+Control returns the generic [Control] form of the receiver instance
+alongside an error.
+*/
+func (r SyncStateControl) Control() (Control, error) {
+	if len(r.EntryUUID) != 16 {
+		return Control{}, errorTxt("syncUUID must be 16 octets, got " + itoa(len(r.EntryUUID)))
+	}
+
+	return ldapControlWrite(r, func(der *DERPacket) error {
+		return ldapWriteSequence(der, func(sub *DERPacket) error {
+			ldapWriteEnum(sub, int(r.State))
+			ldapWritePrimitive(sub, classUniversal, tagOctetString, r.EntryUUID)
+			if r.Cookie != nil {
+				ldapWritePrimitive(sub, classUniversal, tagOctetString, r.Cookie)
+			}
+			return nil
+		})
+	})
+}
+
+func decodeSyncStateControl(ctrl Control) (LDAPControl, error) {
+	r := SyncStateControl{Criticality: ctrl.Criticality}
+	err := ldapControlRead(ctrl, func(der *DERPacket) error {
+		return der.ReadConstructed(classUniversal, tagSequence, func(sub *DERPacket) (err error) {
+			var state int
+			if state, err = ldapReadEnum(sub); err != nil {
+				return
+			} else if r.EntryUUID, err = ldapReadOctets(sub, classUniversal, tagOctetString); err != nil {
+				return
+			} else if len(r.EntryUUID) != 16 {
+				err = errorTxt("syncUUID must be 16 octets, got " + itoa(len(r.EntryUUID)))
+				return
+			}
+
+			r.State = SyncState(state)
+			if sub.HasMoreData() {
+				r.Cookie, err = ldapReadOctets(sub, classUniversal, tagOctetString)
+			}
+			return
+		})
+	})
+
+	return r, err
+}
+
+/*
+SyncDoneControl implements the sync done control of [§ 2.4 of RFC 4533].
+A nil Cookie is absent from the encoding.
+
+	syncDoneValue ::= SEQUENCE {
+	    cookie          syncCookie OPTIONAL,
+	    refreshDeletes  BOOLEAN DEFAULT FALSE
+	}
+
+[§ 2.4 of RFC 4533]: https://datatracker.ietf.org/doc/html/rfc4533#section-2.4
+*/
+type SyncDoneControl struct {
+	Criticality    bool
+	Cookie         OctetString
+	RefreshDeletes bool
+}
+
+/*
+OID returns the controlType of the receiver instance.
+*/
+func (r SyncDoneControl) OID() string { return ControlSyncDone }
+
+/*
+Critical returns the criticality of the receiver instance.
+*/
+func (r SyncDoneControl) Critical() bool { return r.Criticality }
+
+/*
+Control returns the generic [Control] form of the receiver instance
+alongside an error.
+*/
+func (r SyncDoneControl) Control() (Control, error) {
+	return ldapControlWrite(r, func(der *DERPacket) error {
+		return ldapWriteSequence(der, func(sub *DERPacket) error {
+			if r.Cookie != nil {
+				ldapWritePrimitive(sub, classUniversal, tagOctetString, r.Cookie)
+			}
+			if r.RefreshDeletes {
+				ldapWriteBool(sub, true)
+			}
+			return nil
+		})
+	})
+}
+
+func decodeSyncDoneControl(ctrl Control) (LDAPControl, error) {
+	r := SyncDoneControl{Criticality: ctrl.Criticality}
+	err := ldapControlRead(ctrl, func(der *DERPacket) error {
+		return der.ReadConstructed(classUniversal, tagSequence, func(sub *DERPacket) (err error) {
+			if ldapNext(sub, classUniversal, tagOctetString) {
+				if r.Cookie, err = ldapReadOctets(sub, classUniversal, tagOctetString); err != nil {
+					return
+				}
+			}
+			if sub.HasMoreData() {
+				r.RefreshDeletes, err = ldapReadBool(sub)
+			}
+			return
+		})
+	})
+
+	return r, err
+}
+
+/*
+ldapControlWrite returns a [Control] bearing the OID and criticality of
+ctrl, and a value produced by the write function.
+*/
+func ldapControlWrite(ctrl LDAPControl, write func(*DERPacket) error) (Control, error) {
+	value, err := ldapEncodeValue(write)
+	if err != nil {
+		return Control{}, err
+	}
+
+	return Control{
+		Type:        LDAPOID(ctrl.OID()),
+		Criticality: ctrl.Critical(),
+		Value:       value,
+	}, nil
+}
+
+func ldapControlRead(ctrl Control, read func(*DERPacket) error) error {
+	return ldapDecodeValue(`control `+string(ctrl.Type), ctrl.Value, read)
+}
+
+func ldapControlEmpty(ctrl Control) error {
+	return ldapEmptyValue(`control `+string(ctrl.Type), ctrl.Value)
+}
+
+/*
+ldapEncodeValue returns the OCTET STRING value produced by the write
+function, as used for control and extended operation values.
+*/
+func ldapEncodeValue(write func(*DERPacket) error) (value OctetString, err error) {
+	der := newDERPacket([]byte{})
+	if err = write(der); err == nil {
+		value = OctetString(der.Data())
+	}
+
+	return
+}
+
+/*
+ldapDecodeValue returns an error following an attempt to read value using
+the read function. The value must be present, and must be consumed in its
+entirety. The what argument describes the value's owner within errors.
+*/
+func ldapDecodeValue(what string, value OctetString, read func(*DERPacket) error) (err error) {
+	if value == nil {
+		return errorTxt(what + " has no value")
+	}
+
+	der := newDERPacket(value)
+	if err = read(der); err == nil && der.HasMoreData() {
+		err = errorTxt("trailing data following " + what + " value")
+	}
+
+	return
+}
+
+/*
+ldapEmptyValue returns an error if value is present.
+*/
+func ldapEmptyValue(what string, value OctetString) (err error) {
+	if value != nil {
+		err = errorTxt(what + " must not bear a value")
+	}
+
+	return
+}
+
+func ldapWriteSequence(der *DERPacket, write func(*DERPacket) error) (err error) {
+	_, err = der.WriteConstructed(classUniversal, tagSequence, write)
+	return
+}
+
+/*
+ldapCheckInt returns an error if i is not within the range 0 through
+maxInt.
+*/
+func ldapCheckInt(name string, i int) (err error) {
+	if i < 0 || i > ldapMaxInt {
+		err = errorTxt(name + " out of range (0 .. maxInt): " + itoa(i))
+	}
+
+	return
+}
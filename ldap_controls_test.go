@@ -0,0 +1,245 @@
+package dirsyn
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+/*
+This example demonstrates the conversion of a typed paged results control
+to its generic [Control] form, and back again.
+*/
+func ExamplePagedResultsControl_Control() {
+	ctrl, err := PagedResultsControl{Criticality: true, Size: 100}.Control()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("%s %t %x\n", ctrl.Type, ctrl.Criticality, ctrl.Value)
+
+	typed, _ := ctrl.Decode()
+	fmt.Println(typed.(PagedResultsControl).Size)
+	// Output:
+	// 1.2.840.113556.1.4.319 true 30050201640400
+	// 100
+}
+
+/*
+This example demonstrates the retrieval and decoding of a response control
+from an instance of [Controls].
+*/
+func ExampleControls_Get() {
+	ctrls := Controls{{
+		Type:  LDAPOID(ControlSortResponse),
+		Value: OctetString{0x30, 0x03, 0x0a, 0x01, 0x35},
+	}}
+
+	if ctrl, found := ctrls.Get(ControlSortResponse); found {
+		typed, err := ctrl.Decode()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Println(typed.(SortResponseControl).Result)
+	}
+	// Output: unwillingToPerform
+}
+
+func TestLDAPControl_codec(t *testing.T) {
+	var r RFC4515
+	filter, _ := r.Filter(`(&(objectClass=person)(cn=x*))`)
+
+	seconds, grace := 3600, 2
+	locked := AccountLocked
+
+	for idx, ctrl := range []LDAPControl{
+		PagedResultsControl{Size: 10, Cookie: OctetString(`cookie`)},
+		PagedResultsControl{Criticality: true},
+		SortRequestControl{Keys: []SortKey{
+			{Type: AttributeDescription(`cn`)},
+			{Type: AttributeDescription(`sn`), OrderingRule: MatchingRuleID(`2.5.13.3`), Reverse: true},
+		}},
+		SortResponseControl{Result: 16, Type: AttributeDescription(`cn`)},
+		SortResponseControl{},
+		VLVRequestControl{BeforeCount: 1, AfterCount: 2, Offset: 3, ContentCount: 4, ContextID: OctetString{}},
+		VLVRequestControl{Criticality: true, GreaterThanOrEqual: AssertionValue(`m`)},
+		VLVResponseControl{TargetPosition: 5, ContentCount: 100, Result: 0, ContextID: OctetString(`ctx`)},
+		VLVResponseControl{Result: 61},
+		AssertionControl{Criticality: true, Filter: filter},
+		ReadEntryRequestControl{Attributes: []LDAPString{LDAPString(`cn`), LDAPString(`entryUUID`)}},
+		ReadEntryRequestControl{Post: true},
+		ReadEntryResponseControl{Entry: SearchResultEntry{
+			ObjectName: LDAPDN(`cn=x`),
+			Attributes: []PartialAttribute{{Type: AttributeDescription(`cn`), Values: []OctetString{OctetString(`x`)}}},
+		}},
+		ReadEntryResponseControl{Post: true, Entry: SearchResultEntry{ObjectName: LDAPDN(`cn=y`)}},
+		ProxiedAuthzControl{Criticality: true, AuthzID: LDAPString(`dn:cn=admin`)},
+		ProxiedAuthzControl{Criticality: true},
+		ManageDsaITControl{Criticality: true},
+		SubentriesControl{Visibility: true},
+		TreeDeleteControl{},
+		PasswordPolicyControl{},
+		PasswordPolicyControl{Response: true},
+		PasswordPolicyControl{Response: true, TimeBeforeExpiration: &seconds},
+		PasswordPolicyControl{Response: true, GraceAuthNsRemaining: &grace, Error: &locked},
+		SyncRequestControl{Mode: SyncRefreshOnly},
+		SyncRequestControl{Criticality: true, Mode: SyncRefreshAndPersist, Cookie: OctetString(`rid=001`), ReloadHint: true},
+		SyncStateControl{State: SyncStateModify, EntryUUID: make(OctetString, 16), Cookie: OctetString(`c`)},
+		SyncDoneControl{},
+		SyncDoneControl{Cookie: OctetString(`c`), RefreshDeletes: true},
+		Control{Type: LDAPOID(`1.3.6.1.4.1.56521.999.1`), Value: OctetString(`opaque`)},
+	} {
+		generic, err := ctrl.Control()
+		if err != nil {
+			t.Errorf("%s[%d] failed: %v", t.Name(), idx, err)
+			continue
+		} else if generic.OID() != ctrl.OID() || generic.Critical() != ctrl.Critical() {
+			t.Errorf("%s[%d] failed: OID or criticality mismatch", t.Name(), idx)
+			continue
+		}
+
+		// ensure the control survives an LDAPMessage round trip
+		msg := LDAPMessage{MessageID: idx + 1, ProtocolOp: UnbindRequest{}, Controls: Controls{generic}}
+		der, err := msg.DER()
+		if err != nil {
+			t.Errorf("%s[%d] failed: %v", t.Name(), idx, err)
+			continue
+		}
+
+		var decoded LDAPMessage
+		if decoded, err = srcs.RFC4511().LDAPMessage(der.Data()); err != nil {
+			t.Errorf("%s[%d] failed: %v", t.Name(), idx, err)
+			continue
+		}
+
+		var typed LDAPControl
+		if typed, err = decoded.Controls[0].Decode(); err != nil {
+			t.Errorf("%s[%d] failed: %v", t.Name(), idx, err)
+			continue
+		}
+
+		want, got := ldapControlComparable(ctrl), ldapControlComparable(typed)
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("%s[%d] failed:\nwant: %#v\ngot:  %#v", t.Name(), idx, want, got)
+		}
+	}
+}
+
+/*
+ldapControlComparable returns ctrl in a form suitable for comparison by
+way of reflect.DeepEqual, wherein filters are compared by string value
+and empty byte values are normalized.
+*/
+func ldapControlComparable(ctrl LDAPControl) any {
+	switch tv := ctrl.(type) {
+	case AssertionControl:
+		return []any{tv.Criticality, tv.Filter.String()}
+	case PagedResultsControl:
+		if len(tv.Cookie) == 0 {
+			tv.Cookie = nil
+		}
+		return tv
+	}
+
+	return ctrl
+}
+
+func TestLDAPControl_codecov(t *testing.T) {
+	for idx, ctrl := range []LDAPControl{
+		PagedResultsControl{Size: -1},
+		SortRequestControl{},
+		SortRequestControl{Keys: []SortKey{{}}},
+		VLVRequestControl{Offset: -1},
+		VLVResponseControl{TargetPosition: -1},
+		VLVResponseControl{ContentCount: -1},
+		AssertionControl{},
+		AssertionControl{Filter: FilterPresent{}},
+		PasswordPolicyControl{Response: true, TimeBeforeExpiration: new(int), GraceAuthNsRemaining: new(int)},
+		PasswordPolicyControl{Response: true, GraceAuthNsRemaining: func() *int { i := -1; return &i }()},
+		SyncRequestControl{},
+		SyncStateControl{EntryUUID: OctetString(`short`)},
+	} {
+		if _, err := ctrl.Control(); err == nil {
+			t.Errorf("%s[%d] failed: expected error, got nil", t.Name(), idx)
+		}
+	}
+
+	for idx, strukt := range []struct {
+		OID   string
+		Value string
+	}{
+		{ControlPagedResults, ``},
+		{ControlPagedResults, `3003020101`},
+		{ControlPagedResults, `30050201010400` + `00`},
+		{ControlSortRequest, `30053003020100`},
+		{ControlSortRequest, `3007300504016181ff`},
+		{ControlSortRequest, `30023000`},
+		{ControlSortResponse, `3003020100`},
+		{ControlVLVRequest, `3006020100020100`},
+		{ControlVLVRequest, `300a020100020100a0020201`},
+		{ControlVLVRequest, `300b020100020100810161` + `05`},
+		{ControlVLVResponse, `3006020100020100`},
+		{ControlVLVResponse, `3009020100020100040100`},
+		{ControlAssertion, `0400`},
+		{ControlPreRead, `3003020101`},
+		{ControlPostRead, `6403040078`},
+		{ControlProxiedAuthz, ``},
+		{ControlManageDsaIT, `00`},
+		{ControlTreeDelete, `00`},
+		{ControlSubentries, `0400`},
+		{ControlPasswordPolicy, `3004a0028200`},
+		{ControlPasswordPolicy, `3004a00280ff`},
+		{ControlPasswordPolicy, `3003810180`},
+		{ControlSyncRequest, `3003020101`},
+		{ControlSyncRequest, `30050a01010400` + `0101`},
+		{ControlSyncRequest, `30060a01010101`},
+		{ControlSyncState, `30050a01000400`},
+		{ControlSyncState, `30030a0100`},
+		{ControlSyncDone, `3003020100`},
+		{ControlSyncDone, `3004040101`},
+	} {
+		// an empty Value string denotes an absent value
+		ctrl := Control{Type: LDAPOID(strukt.OID)}
+		if strukt.Value != `` {
+			ctrl.Value, _ = hexdec(strukt.Value)
+		}
+
+		if _, err := ctrl.Decode(); err == nil {
+			t.Errorf("%s[%d] failed: expected error, got nil", t.Name(), idx)
+		}
+	}
+
+	for idx, strukt := range []struct {
+		Stringer fmt.Stringer
+		Want     string
+	}{
+		{PasswordExpired, `passwordExpired`},
+		{AccountLocked, `accountLocked`},
+		{ChangeAfterReset, `changeAfterReset`},
+		{PasswordModNotAllowed, `passwordModNotAllowed`},
+		{MustSupplyOldPassword, `mustSupplyOldPassword`},
+		{InsufficientPasswordQuality, `insufficientPasswordQuality`},
+		{PasswordTooShort, `passwordTooShort`},
+		{PasswordTooYoung, `passwordTooYoung`},
+		{PasswordInHistory, `passwordInHistory`},
+		{PasswordTooLong, `passwordTooLong`},
+		{PasswordPolicyError(99), `unknown (99)`},
+		{SyncRefreshOnly, `refreshOnly`},
+		{SyncRefreshAndPersist, `refreshAndPersist`},
+		{SyncMode(2), `unknown (2)`},
+		{SyncStatePresent, `present`},
+		{SyncStateAdd, `add`},
+		{SyncStateModify, `modify`},
+		{SyncStateDelete, `delete`},
+		{SyncState(4), `unknown (4)`},
+	} {
+		if got := strukt.Stringer.String(); got != strukt.Want {
+			t.Errorf("%s[%d] failed: want %s, got %s", t.Name(), idx, strukt.Want, got)
+		}
+	}
+
+	if _, found := (Controls{}).Get(ControlPagedResults); found {
+		t.Errorf("%s failed: unexpected control found", t.Name())
+	}
+}
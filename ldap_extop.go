@@ -0,0 +1,843 @@
+package dirsyn
+
+/*
+ldap_extop.go implements typed values for standard LDAP extended operations,
+each of which may be converted to and from an RFC 4511 [ExtendedRequest] or
+[ExtendedResponse].
+*/
+
+import "sync"
+
+/*
+Object identifiers of the extended operations, unsolicited notifications
+and related controls implemented within this package.
+*/
+const (
+	ExtOpStartTLS                   = `1.3.6.1.4.1.1466.20037`  // RFC 4511
+	ExtOpPasswordModify             = `1.3.6.1.4.1.4203.1.11.1` // RFC 3062
+	ExtOpWhoAmI                     = `1.3.6.1.4.1.4203.1.11.3` // RFC 4532
+	ExtOpCancel                     = `1.3.6.1.1.8`             // RFC 3909
+	ExtOpNoticeOfDisconnection      = `1.3.6.1.4.1.1466.20036`  // RFC 4511
+	ExtOpStartTransaction           = `1.3.6.1.1.21.1`          // RFC 5805
+	ExtOpEndTransaction             = `1.3.6.1.1.21.3`          // RFC 5805
+	ExtOpAbortedTransactionNotice   = `1.3.6.1.1.21.4`          // RFC 5805
+	ControlTransactionSpecification = `1.3.6.1.1.21.2`          // RFC 5805
+)
+
+/*
+ExtendedOperation is implemented by [ExtendedRequest], [ExtendedResponse]
+and by each of the typed extended operation values within this package,
+such as [PasswordModifyRequest].
+*/
+type ExtendedOperation interface {
+	// OID returns the object identifier of the extended operation.
+	OID() string
+
+	// ProtocolOp returns the receiver instance in the form of an
+	// ExtendedRequest or ExtendedResponse alongside an error.
+	ProtocolOp() (ProtocolOp, error)
+}
+
+/*
+ldapExtendedCodec contains the functions used to decode the request and
+response forms of an extended operation. A nil function indicates that
+the form in question is not defined.
+*/
+type ldapExtendedCodec struct {
+	request  func(ExtendedRequest) (ExtendedOperation, error)
+	response func(ExtendedResponse) (ExtendedOperation, error)
+}
+
+/*
+ldapExtendedCodecs maps extended operation OIDs to the codecs used by the
+[ExtendedRequest.Decode] and [ExtendedResponse.Decode] methods. Custom
+codecs are added by way of [RFC4511.RegisterExtendedOperation].
+*/
+var ldapExtendedCodecs = map[string]ldapExtendedCodec{
+	ExtOpStartTLS:                 {decodeStartTLSRequest, decodeStartTLSResponse},
+	ExtOpPasswordModify:           {decodePasswordModifyRequest, decodePasswordModifyResponse},
+	ExtOpWhoAmI:                   {decodeWhoAmIRequest, decodeWhoAmIResponse},
+	ExtOpCancel:                   {decodeCancelRequest, decodeCancelResponse},
+	ExtOpNoticeOfDisconnection:    {nil, decodeNoticeOfDisconnection},
+	ExtOpStartTransaction:         {decodeStartTransactionRequest, decodeStartTransactionResponse},
+	ExtOpEndTransaction:           {decodeEndTransactionRequest, decodeEndTransactionResponse},
+	ExtOpAbortedTransactionNotice: {nil, decodeAbortedTransactionNotice},
+}
+
+/*
+ldapExtendedMutex guards ldapExtendedCodecs against concurrent registration.
+*/
+var ldapExtendedMutex sync.RWMutex
+
+/*
+RegisterExtendedOperation returns an error following an attempt to register
+the decoders of a custom extended operation identified by oid, which must
+be a numeric OID. The req function decodes the [ExtendedRequest] form, and
+the resp function decodes the [ExtendedResponse] (or unsolicited notice)
+form. Either may be nil if the form in question is not defined, but not
+both.
+
+Once registered, the decoders are used by the [ExtendedRequest.Decode]
+and [ExtendedResponse.Decode] methods. Registering an OID already known
+to this package replaces its decoders.
+*/
+func (r RFC4511) RegisterExtendedOperation(oid string,
+	req func(ExtendedRequest) (ExtendedOperation, error),
+	resp func(ExtendedResponse) (ExtendedOperation, error)) (err error) {
+
+	if _, err = (RFC4512{}).NumericOID(oid); err != nil {
+		return
+	} else if req == nil && resp == nil {
+		err = errorTxt("extended operation " + oid + " requires a request or response decoder")
+		return
+	}
+
+	ldapExtendedMutex.Lock()
+	defer ldapExtendedMutex.Unlock()
+	ldapExtendedCodecs[oid] = ldapExtendedCodec{req, resp}
+
+	return
+}
+
+/*
+ldapExtendedCodecFor returns the codec registered for oid alongside a
+presence Boolean.
+*/
+func ldapExtendedCodecFor(oid string) (codec ldapExtendedCodec, found bool) {
+	ldapExtendedMutex.RLock()
+	defer ldapExtendedMutex.RUnlock()
+	codec, found = ldapExtendedCodecs[oid]
+
+	return
+}
+
+/*
+OID returns the requestName of the receiver instance.
+*/
+func (r ExtendedRequest) OID() string { return string(r.RequestName) }
+
+/*
+ProtocolOp returns the receiver instance alongside a nil error, thereby
+satisfying the [ExtendedOperation] interface.
+*/
+func (r ExtendedRequest) ProtocolOp() (ProtocolOp, error) { return r, nil }
+
+/*
+Decode returns the typed [ExtendedOperation] form of the receiver instance
+alongside an error. If the requestName is not known to this package, the
+receiver instance is returned as-is.
+*/
+func (r ExtendedRequest) Decode() (ExtendedOperation, error) {
+	if codec, found := ldapExtendedCodecFor(string(r.RequestName)); found {
+		if codec.request == nil {
+			return nil, errorTxt("extended operation " + r.OID() + " has no request form")
+		}
+		return codec.request(r)
+	}
+
+	return r, nil
+}
+
+/*
+OID returns the responseName of the receiver instance, which may be zero.
+*/
+func (r ExtendedResponse) OID() string { return string(r.ResponseName) }
+
+/*
+ProtocolOp returns the receiver instance alongside a nil error, thereby
+satisfying the [ExtendedOperation] interface.
+*/
+func (r ExtendedResponse) ProtocolOp() (ProtocolOp, error) { return r, nil }
+
+/*
+Decode returns the typed [ExtendedOperation] form of the receiver instance
+alongside an error.
+
+As many extended responses do not bear a responseName, the requestName of
+the corresponding [ExtendedRequest] may be provided to select the codec.
+A responseName, if present, takes precedence. If neither is known to this
+package, the receiver instance is returned as-is.
+*/
+func (r ExtendedResponse) Decode(requestName ...string) (ExtendedOperation, error) {
+	oid := string(r.ResponseName)
+	if len(oid) == 0 && len(requestName) > 0 {
+		oid = requestName[0]
+	}
+
+	if codec, found := ldapExtendedCodecFor(oid); found {
+		if codec.response == nil {
+			return nil, errorTxt("extended operation " + oid + " has no response form")
+		}
+		return codec.response(r)
+	}
+
+	return r, nil
+}
+
+/*
+StartTLSRequest implements the StartTLS extended request of [§ 4.14.1 of
+RFC 4511], which bears no value.
+
+[§ 4.14.1 of RFC 4511]: https://datatracker.ietf.org/doc/html/rfc4511#section-4.14.1
+*/
+type StartTLSRequest struct{}
+
+/*
+OID returns the object identifier of the extended operation.
+*/
+func (r StartTLSRequest) OID() string { return ExtOpStartTLS }
+
+/*
+ProtocolOp returns the [ExtendedRequest] form of the receiver instance
+alongside a nil error.
+*/
+func (r StartTLSRequest) ProtocolOp() (ProtocolOp, error) {
+	return ExtendedRequest{RequestName: LDAPOID(r.OID())}, nil
+}
+
+func decodeStartTLSRequest(req ExtendedRequest) (ExtendedOperation, error) {
+	return StartTLSRequest{}, ldapEmptyValue(`StartTLS request`, req.RequestValue)
+}
+
+/*
+StartTLSResponse implements the StartTLS extended response of [§ 4.14.2 of
+RFC 4511], which bears no value. The responseName is not written, but is
+tolerated when decoding.
+
+[§ 4.14.2 of RFC 4511]: https://datatracker.ietf.org/doc/html/rfc4511#section-4.14.2
+*/
+type StartTLSResponse struct {
+	LDAPResult
+}
+
+/*
+OID returns the object identifier of the extended operation.
+*/
+func (r StartTLSResponse) OID() string { return ExtOpStartTLS }
+
+/*
+ProtocolOp returns the [ExtendedResponse] form of the receiver instance
+alongside a nil error.
+*/
+func (r StartTLSResponse) ProtocolOp() (ProtocolOp, error) {
+	return ExtendedResponse{LDAPResult: r.LDAPResult}, nil
+}
+
+func decodeStartTLSResponse(res ExtendedResponse) (ExtendedOperation, error) {
+	return StartTLSResponse{res.LDAPResult}, ldapEmptyValue(`StartTLS response`, res.ResponseValue)
+}
+
+/*
+PasswordModifyRequest implements the Password Modify extended request of
+[§ 2 of RFC 3062]. Nil fields are absent from the encoding, and if all
+fields are nil, the requestValue is absent.
+
+	PasswdModifyRequestValue ::= SEQUENCE {
+	     userIdentity    [0]  OCTET STRING OPTIONAL
+	     oldPasswd       [1]  OCTET STRING OPTIONAL
+	     newPasswd       [2]  OCTET STRING OPTIONAL }
+
+[§ 2 of RFC 3062]: https://datatracker.ietf.org/doc/html/rfc3062#section-2
+*/
+type PasswordModifyRequest struct {
+	UserIdentity OctetString
+	OldPasswd    OctetString
+	NewPasswd    OctetString
+}
+
+/*
+OID returns the object identifier of the extended operation.
+*/
+func (r PasswordModifyRequest) OID() string { return ExtOpPasswordModify }
+
+/*
+ProtocolOp returns the [ExtendedRequest] form of the receiver instance
+alongside an error.
+*/
+func (r PasswordModifyRequest) ProtocolOp() (op ProtocolOp, err error) {
+	req := ExtendedRequest{RequestName: LDAPOID(r.OID())}
+	if r.UserIdentity != nil || r.OldPasswd != nil || r.NewPasswd != nil {
+		req.RequestValue, err = ldapEncodeValue(func(der *DERPacket) error {
+			return ldapWriteSequence(der, func(sub *DERPacket) error {
+				for tag, value := range []OctetString{r.UserIdentity, r.OldPasswd, r.NewPasswd} {
+					if value != nil {
+						ldapWritePrimitive(sub, classContextSpecific, tag, value)
+					}
+				}
+				return nil
+			})
+		})
+	}
+
+	return req, err
+}
+
+func decodePasswordModifyRequest(req ExtendedRequest) (ExtendedOperation, error) {
+	var r PasswordModifyRequest
+	if req.RequestValue == nil {
+		return r, nil
+	}
+
+	err := ldapDecodeValue(`Password Modify request`, req.RequestValue, func(der *DERPacket) error {
+		return der.ReadConstructed(classUniversal, tagSequence, func(sub *DERPacket) (err error) {
+			for tag, value := range []*OctetString{&r.UserIdentity, &r.OldPasswd, &r.NewPasswd} {
+				if err == nil && ldapNext(sub, classContextSpecific, tag) {
+					*value, err = ldapReadOctets(sub, classContextSpecific, tag)
+				}
+			}
+			if err == nil && sub.HasMoreData() {
+				err = errorTxt("unexpected component within Password Modify request")
+			}
+			return
+		})
+	})
+
+	return r, err
+}
+
+/*
+PasswordModifyResponse implements the Password Modify extended response of
+[§ 2 of RFC 3062]. If GenPasswd is nil, the responseValue is absent.
+
+	PasswdModifyResponseValue ::= SEQUENCE {
+	     genPasswd       [0]     OCTET STRING OPTIONAL }
+
+[§ 2 of RFC 3062]: https://datatracker.ietf.org/doc/html/rfc3062#section-2
+*/
+type PasswordModifyResponse struct {
+	LDAPResult
+	GenPasswd OctetString
+}
+
+/*
+OID returns the object identifier of the extended operation.
+*/
+func (r PasswordModifyResponse) OID() string { return ExtOpPasswordModify }
+
+/*
+ProtocolOp returns the [ExtendedResponse] form of the receiver instance
+alongside an error.
+*/
+func (r PasswordModifyResponse) ProtocolOp() (op ProtocolOp, err error) {
+	res := ExtendedResponse{LDAPResult: r.LDAPResult}
+	if r.GenPasswd != nil {
+		res.ResponseValue, err = ldapEncodeValue(func(der *DERPacket) error {
+			return ldapWriteSequence(der, func(sub *DERPacket) error {
+				ldapWritePrimitive(sub, classContextSpecific, 0, r.GenPasswd)
+				return nil
+			})
+		})
+	}
+
+	return res, err
+}
+
+func decodePasswordModifyResponse(res ExtendedResponse) (ExtendedOperation, error) {
+	r := PasswordModifyResponse{LDAPResult: res.LDAPResult}
+	if res.ResponseValue == nil {
+		return r, nil
+	}
+
+	err := ldapDecodeValue(`Password Modify response`, res.ResponseValue, func(der *DERPacket) error {
+		return der.ReadConstructed(classUniversal, tagSequence, func(sub *DERPacket) (err error) {
+			if sub.HasMoreData() {
+				r.GenPasswd, err = ldapReadOctets(sub, classContextSpecific, 0)
+			}
+			return
+		})
+	})
+
+	return r, err
+}
+
+/*
+WhoAmIRequest implements the "Who am I?" extended request of [§ 2.1 of
+RFC 4532], which bears no value.
+
+[§ 2.1 of RFC 4532]: https://datatracker.ietf.org/doc/html/rfc4532#section-2.1
+*/
+type WhoAmIRequest struct{}
+
+/*
+OID returns the object identifier of the extended operation.
+*/
+func (r WhoAmIRequest) OID() string { return ExtOpWhoAmI }
+
+/*
+ProtocolOp returns the [ExtendedRequest] form of the receiver instance
+alongside a nil error.
+*/
+func (r WhoAmIRequest) ProtocolOp() (ProtocolOp, error) {
+	return ExtendedRequest{RequestName: LDAPOID(r.OID())}, nil
+}
+
+func decodeWhoAmIRequest(req ExtendedRequest) (ExtendedOperation, error) {
+	return WhoAmIRequest{}, ldapEmptyValue(`Who am I? request`, req.RequestValue)
+}
+
+/*
+WhoAmIResponse implements the "Who am I?" extended response of [§ 2.2 of
+RFC 4532]. The responseValue, which is not BER encoded, is the authzId of
+the session as described in § 5.2.1.8 of RFC 4513, and is empty for the
+anonymous identity. A nil AuthzID is absent from the encoding, as is
+appropriate for unsuccessful operations.
+
+[§ 2.2 of RFC 4532]: https://datatracker.ietf.org/doc/html/rfc4532#section-2.2
+*/
+type WhoAmIResponse struct {
+	LDAPResult
+	AuthzID LDAPString
+}
+
+/*
+OID returns the object identifier of the extended operation.
+*/
+func (r WhoAmIResponse) OID() string { return ExtOpWhoAmI }
+
+/*
+ProtocolOp returns the [ExtendedResponse] form of the receiver instance
+alongside a nil error.
+*/
+func (r WhoAmIResponse) ProtocolOp() (ProtocolOp, error) {
+	return ExtendedResponse{LDAPResult: r.LDAPResult, ResponseValue: OctetString(r.AuthzID)}, nil
+}
+
+func decodeWhoAmIResponse(res ExtendedResponse) (ExtendedOperation, error) {
+	return WhoAmIResponse{res.LDAPResult, LDAPString(res.ResponseValue)}, nil
+}
+
+/*
+CancelRequest implements the Cancel extended request of [§ 2 of RFC 3909].
+
+	cancelRequestValue ::= SEQUENCE {
+	    cancelID        MessageID
+	                    -- MessageID is as defined in [RFC2251]
+	}
+
+[§ 2 of RFC 3909]: https://datatracker.ietf.org/doc/html/rfc3909#section-2
+*/
+type CancelRequest struct {
+	CancelID int
+}
+
+/*
+OID returns the object identifier of the extended operation.
+*/
+func (r CancelRequest) OID() string { return ExtOpCancel }
+
+/*
+ProtocolOp returns the [ExtendedRequest] form of the receiver instance
+alongside an error.
+*/
+func (r CancelRequest) ProtocolOp() (ProtocolOp, error) {
+	if err := ldapCheckInt(`cancelID`, r.CancelID); err != nil {
+		return nil, err
+	}
+
+	value, err := ldapEncodeValue(func(der *DERPacket) error {
+		return ldapWriteSequence(der, func(sub *DERPacket) error {
+			ldapWriteInt(sub, classUniversal, tagInteger, r.CancelID)
+			return nil
+		})
+	})
+
+	return ExtendedRequest{RequestName: LDAPOID(r.OID()), RequestValue: value}, err
+}
+
+func decodeCancelRequest(req ExtendedRequest) (ExtendedOperation, error) {
+	var r CancelRequest
+	err := ldapDecodeValue(`Cancel request`, req.RequestValue, func(der *DERPacket) error {
+		return der.ReadConstructed(classUniversal, tagSequence, func(sub *DERPacket) (err error) {
+			r.CancelID, err = ldapReadInt(sub, classUniversal, tagInteger)
+			return
+		})
+	})
+
+	return r, err
+}
+
+/*
+CancelResponse implements the Cancel extended response of [§ 2 of RFC
+3909], which bears neither a responseName nor a responseValue.
+
+[§ 2 of RFC 3909]: https://datatracker.ietf.org/doc/html/rfc3909#section-2
+*/
+type CancelResponse struct {
+	LDAPResult
+}
+
+/*
+OID returns the object identifier of the extended operation.
+*/
+func (r CancelResponse) OID() string { return ExtOpCancel }
+
+/*
+ProtocolOp returns the [ExtendedResponse] form of the receiver instance
+alongside a nil error.
+*/
+func (r CancelResponse) ProtocolOp() (ProtocolOp, error) {
+	return ExtendedResponse{LDAPResult: r.LDAPResult}, nil
+}
+
+func decodeCancelResponse(res ExtendedResponse) (ExtendedOperation, error) {
+	return CancelResponse{res.LDAPResult}, ldapEmptyValue(`Cancel response`, res.ResponseValue)
+}
+
+/*
+NoticeOfDisconnection implements the Notice of Disconnection unsolicited
+notification of [§ 4.4.1 of RFC 4511], which bears the responseName and
+no responseValue. Per § 4.4 of RFC 4511, such notifications are conveyed
+within an LDAPMessage bearing a messageID of zero (0).
+
+[§ 4.4.1 of RFC 4511]: https://datatracker.ietf.org/doc/html/rfc4511#section-4.4.1
+*/
+type NoticeOfDisconnection struct {
+	LDAPResult
+}
+
+/*
+OID returns the object identifier of the unsolicited notification.
+*/
+func (r NoticeOfDisconnection) OID() string { return ExtOpNoticeOfDisconnection }
+
+/*
+ProtocolOp returns the [ExtendedResponse] form of the receiver instance
+alongside a nil error.
+*/
+func (r NoticeOfDisconnection) ProtocolOp() (ProtocolOp, error) {
+	return ExtendedResponse{LDAPResult: r.LDAPResult, ResponseName: LDAPOID(r.OID())}, nil
+}
+
+func decodeNoticeOfDisconnection(res ExtendedResponse) (ExtendedOperation, error) {
+	return NoticeOfDisconnection{res.LDAPResult}, ldapEmptyValue(`Notice of Disconnection`, res.ResponseValue)
+}
+
+/*
+StartTransactionRequest implements the Start Transaction extended request
+of [§ 2.1 of RFC 5805], which bears no value.
+
+[§ 2.1 of RFC 5805]: https://datatracker.ietf.org/doc/html/rfc5805#section-2.1
+*/
+type StartTransactionRequest struct{}
+
+/*
+OID returns the object identifier of the extended operation.
+*/
+func (r StartTransactionRequest) OID() string { return ExtOpStartTransaction }
+
+/*
+ProtocolOp returns the [ExtendedRequest] form of the receiver instance
+alongside a nil error.
+*/
+func (r StartTransactionRequest) ProtocolOp() (ProtocolOp, error) {
+	return ExtendedRequest{RequestName: LDAPOID(r.OID())}, nil
+}
+
+func decodeStartTransactionRequest(req ExtendedRequest) (ExtendedOperation, error) {
+	return StartTransactionRequest{}, ldapEmptyValue(`Start Transaction request`, req.RequestValue)
+}
+
+/*
+StartTransactionResponse implements the Start Transaction extended response
+of [§ 2.1 of RFC 5805]. The responseValue, which is not BER encoded, is the
+transaction identifier. A nil TxnID is absent from the encoding, as is
+appropriate for unsuccessful operations.
+
+[§ 2.1 of RFC 5805]: https://datatracker.ietf.org/doc/html/rfc5805#section-2.1
+*/
+type StartTransactionResponse struct {
+	LDAPResult
+	TxnID OctetString
+}
+
+/*
+OID returns the object identifier of the extended operation.
+*/
+func (r StartTransactionResponse) OID() string { return ExtOpStartTransaction }
+
+/*
+ProtocolOp returns the [ExtendedResponse] form of the receiver instance
+alongside a nil error.
+*/
+func (r StartTransactionResponse) ProtocolOp() (ProtocolOp, error) {
+	return ExtendedResponse{LDAPResult: r.LDAPResult, ResponseValue: r.TxnID}, nil
+}
+
+func decodeStartTransactionResponse(res ExtendedResponse) (ExtendedOperation, error) {
+	return StartTransactionResponse{res.LDAPResult, res.ResponseValue}, nil
+}
+
+/*
+EndTransactionRequest implements the End Transaction extended request of
+[§ 2.2 of RFC 5805]. As the commit component defaults to TRUE, the zero
+value of Abort denotes a commit, and the component is only written when
+the transaction is to be aborted.
+
+	txnEndReq ::= SEQUENCE {
+	     commit         BOOLEAN DEFAULT TRUE,
+	     identifier     OCTET STRING }
+
+[§ 2.2 of RFC 5805]: https://datatracker.ietf.org/doc/html/rfc5805#section-2.2
+*/
+type EndTransactionRequest struct {
+	Abort bool
+	TxnID OctetString
+}
+
+/*
+OID returns the object identifier of the extended operation.
+*/
+func (r EndTransactionRequest) OID() string { return ExtOpEndTransaction }
+
+/*
+ProtocolOp returns the [ExtendedRequest] form of the receiver instance
+alongside an error.
+*/
+func (r EndTransactionRequest) ProtocolOp() (ProtocolOp, error) {
+	value, err := ldapEncodeValue(func(der *DERPacket) error {
+		return ldapWriteSequence(der, func(sub *DERPacket) error {
+			if r.Abort {
+				ldapWriteBool(sub, false)
+			}
+			ldapWritePrimitive(sub, classUniversal, tagOctetString, r.TxnID)
+			return nil
+		})
+	})
+
+	return ExtendedRequest{RequestName: LDAPOID(r.OID()), RequestValue: value}, err
+}
+
+func decodeEndTransactionRequest(req ExtendedRequest) (ExtendedOperation, error) {
+	var r EndTransactionRequest
+	err := ldapDecodeValue(`End Transaction request`, req.RequestValue, func(der *DERPacket) error {
+		return der.ReadConstructed(classUniversal, tagSequence, func(sub *DERPacket) (err error) {
+			if ldapNext(sub, classUniversal, tagBoolean) {
+				var commit bool
+				if commit, err = ldapReadBool(sub); err != nil {
+					return
+				}
+				r.Abort = !commit
+			}
+			r.TxnID, err = ldapReadOctets(sub, classUniversal, tagOctetString)
+			return
+		})
+	})
+
+	return r, err
+}
+
+/*
+TransactionUpdateControls implements an element of the updatesControls
+component of the End Transaction extended response of [§ 2.2 of RFC 5805].
+
+	updateControls SEQUENCE {
+	     messageID MessageID,
+	              -- msgid associated with controls
+	     controls  Controls
+	}
+
+[§ 2.2 of RFC 5805]: https://datatracker.ietf.org/doc/html/rfc5805#section-2.2
+*/
+type TransactionUpdateControls struct {
+	MessageID int
+	Controls  Controls
+}
+
+/*
+EndTransactionResponse implements the End Transaction extended response of
+[§ 2.2 of RFC 5805]. A zero MessageID is absent from the encoding, as zero
+(0) is reserved for unsolicited notifications. If MessageID is zero and
+UpdatesControls is nil, the responseValue is absent.
+
+	txnEndRes ::= SEQUENCE {
+	     messageID MessageID OPTIONAL,
+	          -- msgid associated with non-success resultCode
+	     updatesControls SEQUENCE OF updateControls SEQUENCE {
+	          messageID MessageID,
+	               -- msgid associated with controls
+	          controls  Controls
+	     } OPTIONAL
+	}
+
+[§ 2.2 of RFC 5805]: https://datatracker.ietf.org/doc/html/rfc5805#section-2.2
+*/
+type EndTransactionResponse struct {
+	LDAPResult
+	MessageID       int
+	UpdatesControls []TransactionUpdateControls
+}
+
+/*
+OID returns the object identifier of the extended operation.
+*/
+func (r EndTransactionResponse) OID() string { return ExtOpEndTransaction }
+
+/*
+ProtocolOp returns the [ExtendedResponse] form of the receiver instance
+alongside an error.
+*/
+func (r EndTransactionResponse) ProtocolOp() (op ProtocolOp, err error) {
+	res := ExtendedResponse{LDAPResult: r.LDAPResult}
+	if r.MessageID == 0 && r.UpdatesControls == nil {
+		return res, nil
+	} else if err = ldapCheckInt(`messageID`, r.MessageID); err != nil {
+		return
+	}
+
+	res.ResponseValue, err = ldapEncodeValue(func(der *DERPacket) error {
+		return ldapWriteSequence(der, func(sub *DERPacket) (err error) {
+			if r.MessageID != 0 {
+				ldapWriteInt(sub, classUniversal, tagInteger, r.MessageID)
+			}
+			if r.UpdatesControls != nil {
+				err = ldapWriteSequence(sub, func(updates *DERPacket) (err error) {
+					for i := 0; i < len(r.UpdatesControls) && err == nil; i++ {
+						err = r.UpdatesControls[i].write(updates)
+					}
+					return
+				})
+			}
+			return
+		})
+	})
+
+	return res, err
+}
+
+func (r TransactionUpdateControls) write(der *DERPacket) error {
+	if err := ldapCheckInt(`messageID`, r.MessageID); err != nil {
+		return err
+	}
+
+	return ldapWriteSequence(der, func(sub *DERPacket) error {
+		ldapWriteInt(sub, classUniversal, tagInteger, r.MessageID)
+		return ldapWriteSequence(sub, r.Controls.write)
+	})
+}
+
+func (r *TransactionUpdateControls) read(der *DERPacket) error {
+	return der.ReadConstructed(classUniversal, tagSequence, func(sub *DERPacket) (err error) {
+		if r.MessageID, err = ldapReadInt(sub, classUniversal, tagInteger); err == nil {
+			r.Controls = Controls{}
+			err = sub.ReadConstructed(classUniversal, tagSequence, r.Controls.read)
+		}
+		return
+	})
+}
+
+func decodeEndTransactionResponse(res ExtendedResponse) (ExtendedOperation, error) {
+	r := EndTransactionResponse{LDAPResult: res.LDAPResult}
+	if res.ResponseValue == nil {
+		return r, nil
+	}
+
+	err := ldapDecodeValue(`End Transaction response`, res.ResponseValue, func(der *DERPacket) error {
+		return der.ReadConstructed(classUniversal, tagSequence, func(sub *DERPacket) (err error) {
+			if ldapNext(sub, classUniversal, tagInteger) {
+				if r.MessageID, err = ldapReadInt(sub, classUniversal, tagInteger); err != nil {
+					return
+				}
+			}
+			if sub.HasMoreData() {
+				r.UpdatesControls = []TransactionUpdateControls{}
+				err = sub.ReadConstructed(classUniversal, tagSequence, func(updates *DERPacket) (err error) {
+					for updates.HasMoreData() && err == nil {
+						var update TransactionUpdateControls
+						if err = update.read(updates); err == nil {
+							r.UpdatesControls = append(r.UpdatesControls, update)
+						}
+					}
+					return
+				})
+			}
+			return
+		})
+	})
+
+	return r, err
+}
+
+/*
+AbortedTransactionNotice implements the Aborted Transaction unsolicited
+notification of [§ 2.3 of RFC 5805]. The responseValue, which is not BER
+encoded, is the identifier of the aborted transaction.
+
+[§ 2.3 of RFC 5805]: https://datatracker.ietf.org/doc/html/rfc5805#section-2.3
+*/
+type AbortedTransactionNotice struct {
+	LDAPResult
+	TxnID OctetString
+}
+
+/*
+OID returns the object identifier of the unsolicited notification.
+*/
+func (r AbortedTransactionNotice) OID() string { return ExtOpAbortedTransactionNotice }
+
+/*
+ProtocolOp returns the [ExtendedResponse] form of the receiver instance
+alongside an error.
+*/
+func (r AbortedTransactionNotice) ProtocolOp() (ProtocolOp, error) {
+	if r.TxnID == nil {
+		return nil, errorTxt("Aborted Transaction notice has no transaction identifier")
+	}
+
+	return ExtendedResponse{
+		LDAPResult:    r.LDAPResult,
+		ResponseName:  LDAPOID(r.OID()),
+		ResponseValue: r.TxnID,
+	}, nil
+}
+
+func decodeAbortedTransactionNotice(res ExtendedResponse) (ExtendedOperation, error) {
+	r := AbortedTransactionNotice{res.LDAPResult, res.ResponseValue}
+	if r.TxnID == nil {
+		return r, errorTxt("Aborted Transaction notice has no transaction identifier")
+	}
+
+	return r, nil
+}
+
+/*
+TransactionSpecificationControl implements the Transaction Specification
+control of [§ 2.2 of RFC 5805]. The value, which is not BER encoded, is the
+transaction identifier. Per the RFC, this control MUST be marked critical.
+
+[§ 2.2 of RFC 5805]: https://datatracker.ietf.org/doc/html/rfc5805#section-2.2
+*/
+type TransactionSpecificationControl struct {
+	Criticality bool
+	TxnID       OctetString
+}
+
+/*
+OID returns the controlType of the receiver instance.
+*/
+func (r TransactionSpecificationControl) OID() string { return ControlTransactionSpecification }
+
+/*
+Critical returns the criticality of the receiver instance.
+*/
+func (r TransactionSpecificationControl) Critical() bool { return r.Criticality }
+
+/*
+Control returns the generic [Control] form of the receiver instance
+alongside an error.
+*/
+func (r TransactionSpecificationControl) Control() (Control, error) {
+	if r.TxnID == nil {
+		return Control{}, errorTxt("control " + r.OID() + " has no transaction identifier")
+	}
+
+	return Control{Type: LDAPOID(r.OID()), Criticality: r.Criticality, Value: r.TxnID}, nil
+}
+
+func decodeTransactionSpecificationControl(ctrl Control) (LDAPControl, error) {
+	r := TransactionSpecificationControl{Criticality: ctrl.Criticality, TxnID: ctrl.Value}
+	if r.TxnID == nil {
+		return r, errorTxt("control " + r.OID() + " has no value")
+	}
+
+	return r, nil
+}
@@ -0,0 +1,261 @@
+package dirsyn
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+/*
+This example demonstrates the decoding of a Password Modify extended
+response, which bears no responseName, by way of the requestName of the
+corresponding request.
+*/
+func ExampleExtendedResponse_Decode() {
+	res := ExtendedResponse{
+		ResponseValue: OctetString{0x30, 0x06, 0x80, 0x04, 0x73, 0x33, 0x63, 0x72},
+	}
+
+	op, err := res.Decode(ExtOpPasswordModify)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Printf("%s\n", op.(PasswordModifyResponse).GenPasswd)
+	// Output: s3cr
+}
+
+/*
+This example demonstrates the encoding of a "Who am I?" extended request
+within an LDAPMessage.
+*/
+func ExampleWhoAmIRequest_ProtocolOp() {
+	op, _ := WhoAmIRequest{}.ProtocolOp()
+	der, err := LDAPMessage{MessageID: 2, ProtocolOp: op}.DER()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Printf("%x\n", der.Data())
+	// Output: 301e02010277198017312e332e362e312e342e312e343230332e312e31312e33
+}
+
+func TestExtendedOperation_codec(t *testing.T) {
+	success := LDAPResult{}
+	failure := LDAPResult{ResultCode: 53, DiagnosticMessage: LDAPString(`unwilling`)}
+	ctrl, _ := PagedResultsControl{Size: 1}.Control()
+
+	for idx, strukt := range []struct {
+		Op      ExtendedOperation
+		Request string // requestName hint for responses
+	}{
+		{StartTLSRequest{}, ``},
+		{StartTLSResponse{success}, ExtOpStartTLS},
+		{PasswordModifyRequest{}, ``},
+		{PasswordModifyRequest{UserIdentity: OctetString(`u:jesse`), NewPasswd: OctetString(`new`)}, ``},
+		{PasswordModifyRequest{OldPasswd: OctetString{}}, ``},
+		{PasswordModifyResponse{LDAPResult: success}, ExtOpPasswordModify},
+		{PasswordModifyResponse{LDAPResult: success, GenPasswd: OctetString(`gen`)}, ExtOpPasswordModify},
+		{WhoAmIRequest{}, ``},
+		{WhoAmIResponse{success, LDAPString(`dn:cn=admin`)}, ExtOpWhoAmI},
+		{WhoAmIResponse{LDAPResult: failure}, ExtOpWhoAmI},
+		{CancelRequest{CancelID: 5}, ``},
+		{CancelResponse{LDAPResult{ResultCode: 118}}, ExtOpCancel},
+		{NoticeOfDisconnection{LDAPResult{ResultCode: 52}}, ``},
+		{StartTransactionRequest{}, ``},
+		{StartTransactionResponse{success, OctetString(`txn-1`)}, ExtOpStartTransaction},
+		{EndTransactionRequest{TxnID: OctetString(`txn-1`)}, ``},
+		{EndTransactionRequest{Abort: true, TxnID: OctetString(`txn-1`)}, ``},
+		{EndTransactionResponse{LDAPResult: success}, ExtOpEndTransaction},
+		{EndTransactionResponse{LDAPResult: failure, MessageID: 7}, ExtOpEndTransaction},
+		{EndTransactionResponse{LDAPResult: success, UpdatesControls: []TransactionUpdateControls{
+			{MessageID: 3, Controls: Controls{ctrl}},
+			{MessageID: 4, Controls: Controls{}},
+		}}, ExtOpEndTransaction},
+		{AbortedTransactionNotice{failure, OctetString(`txn-1`)}, ``},
+		{ExtendedRequest{RequestName: LDAPOID(`1.3.6.1.4.1.56521.999.2`), RequestValue: OctetString(`x`)}, ``},
+		{ExtendedResponse{LDAPResult: success, ResponseValue: OctetString(`x`)}, `1.3.6.1.4.1.56521.999.2`},
+	} {
+		op, err := strukt.Op.ProtocolOp()
+		if err != nil {
+			t.Errorf("%s[%d] failed: %v", t.Name(), idx, err)
+			continue
+		}
+
+		// ensure the operation survives an LDAPMessage round trip
+		der, err := LDAPMessage{MessageID: idx + 1, ProtocolOp: op}.DER()
+		if err != nil {
+			t.Errorf("%s[%d] failed: %v", t.Name(), idx, err)
+			continue
+		}
+
+		var msg LDAPMessage
+		if msg, err = srcs.RFC4511().LDAPMessage(der.Data()); err != nil {
+			t.Errorf("%s[%d] failed: %v", t.Name(), idx, err)
+			continue
+		}
+
+		var typed ExtendedOperation
+		switch tv := msg.ProtocolOp.(type) {
+		case ExtendedRequest:
+			typed, err = tv.Decode()
+		case ExtendedResponse:
+			typed, err = tv.Decode(strukt.Request)
+		default:
+			err = fmt.Errorf("unexpected protocolOp %T", tv)
+		}
+
+		if err != nil {
+			t.Errorf("%s[%d] failed: %v", t.Name(), idx, err)
+		} else if !reflect.DeepEqual(strukt.Op, typed) {
+			t.Errorf("%s[%d] failed:\nwant: %#v\ngot:  %#v", t.Name(), idx, strukt.Op, typed)
+		} else if typed.OID() != strukt.Op.OID() {
+			t.Errorf("%s[%d] failed: want OID %s, got %s", t.Name(), idx, strukt.Op.OID(), typed.OID())
+		}
+	}
+}
+
+func TestExtendedOperation_codecov(t *testing.T) {
+	for idx, op := range []ExtendedOperation{
+		CancelRequest{CancelID: -1},
+		EndTransactionResponse{MessageID: -1},
+		EndTransactionResponse{UpdatesControls: []TransactionUpdateControls{{MessageID: -1}}},
+		EndTransactionResponse{UpdatesControls: []TransactionUpdateControls{{Controls: Controls{{}}}}},
+		AbortedTransactionNotice{},
+	} {
+		if _, err := op.ProtocolOp(); err == nil {
+			t.Errorf("%s[%d] failed: expected error, got nil", t.Name(), idx)
+		}
+	}
+
+	for idx, req := range []ExtendedRequest{
+		{RequestName: LDAPOID(ExtOpStartTLS), RequestValue: OctetString{}},
+		{RequestName: LDAPOID(ExtOpPasswordModify), RequestValue: OctetString{0x30, 0x02, 0x83, 0x00}},
+		{RequestName: LDAPOID(ExtOpPasswordModify), RequestValue: OctetString{0x04, 0x00}},
+		{RequestName: LDAPOID(ExtOpWhoAmI), RequestValue: OctetString{}},
+		{RequestName: LDAPOID(ExtOpCancel)},
+		{RequestName: LDAPOID(ExtOpCancel), RequestValue: OctetString{0x30, 0x03, 0x02, 0x01, 0xff}},
+		{RequestName: LDAPOID(ExtOpNoticeOfDisconnection)},
+		{RequestName: LDAPOID(ExtOpStartTransaction), RequestValue: OctetString{}},
+		{RequestName: LDAPOID(ExtOpEndTransaction)},
+		{RequestName: LDAPOID(ExtOpEndTransaction), RequestValue: OctetString{0x30, 0x03, 0x01, 0x01, 0x00}},
+		{RequestName: LDAPOID(ExtOpEndTransaction), RequestValue: OctetString{0x30, 0x04, 0x01, 0x02, 0x00, 0x00}},
+		{RequestName: LDAPOID(ExtOpAbortedTransactionNotice)},
+	} {
+		if _, err := req.Decode(); err == nil {
+			t.Errorf("%s[%d] failed: expected error, got nil", t.Name(), idx)
+		}
+	}
+
+	for idx, res := range []ExtendedResponse{
+		{ResponseName: LDAPOID(ExtOpStartTLS), ResponseValue: OctetString{}},
+		{ResponseName: LDAPOID(ExtOpPasswordModify), ResponseValue: OctetString{0x30, 0x02, 0x81, 0x00}},
+		{ResponseName: LDAPOID(ExtOpCancel), ResponseValue: OctetString{}},
+		{ResponseName: LDAPOID(ExtOpNoticeOfDisconnection), ResponseValue: OctetString{}},
+		{ResponseName: LDAPOID(ExtOpEndTransaction), ResponseValue: OctetString{0x30, 0x03, 0x02, 0x01, 0xff}},
+		{ResponseName: LDAPOID(ExtOpEndTransaction), ResponseValue: OctetString{0x30, 0x02, 0x04, 0x00}},
+		{ResponseName: LDAPOID(ExtOpEndTransaction), ResponseValue: OctetString{0x30, 0x04, 0x30, 0x02, 0x30, 0x00}},
+		{ResponseName: LDAPOID(ExtOpAbortedTransactionNotice)},
+	} {
+		if _, err := res.Decode(); err == nil {
+			t.Errorf("%s[%d] failed: expected error, got nil", t.Name(), idx)
+		}
+	}
+
+	// responseName takes precedence over the requestName hint
+	res := ExtendedResponse{ResponseName: LDAPOID(ExtOpNoticeOfDisconnection)}
+	if op, err := res.Decode(ExtOpWhoAmI); err != nil {
+		t.Errorf("%s failed: %v", t.Name(), err)
+	} else if _, ok := op.(NoticeOfDisconnection); !ok {
+		t.Errorf("%s failed: unexpected type %T", t.Name(), op)
+	}
+
+	// transaction specification control
+	if _, err := (TransactionSpecificationControl{}).Control(); err == nil {
+		t.Errorf("%s failed: expected error, got nil", t.Name())
+	} else if _, err = (Control{Type: LDAPOID(ControlTransactionSpecification)}).Decode(); err == nil {
+		t.Errorf("%s failed: expected error, got nil", t.Name())
+	}
+
+	want := TransactionSpecificationControl{Criticality: true, TxnID: OctetString(`txn-1`)}
+	if ctrl, err := want.Control(); err != nil {
+		t.Errorf("%s failed: %v", t.Name(), err)
+	} else if got, err := ctrl.Decode(); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("%s failed: want %#v, got %#v (%v)", t.Name(), want, got, err)
+	}
+}
+
+/*
+ldapTestEchoRequest is a custom extended request used to exercise the
+RFC4511.RegisterExtendedOperation method.
+*/
+type ldapTestEchoRequest struct {
+	Value OctetString
+}
+
+const ldapTestEchoOID = `1.3.6.1.4.1.56521.999.3`
+
+func (r ldapTestEchoRequest) OID() string { return ldapTestEchoOID }
+
+func (r ldapTestEchoRequest) ProtocolOp() (ProtocolOp, error) {
+	return ExtendedRequest{RequestName: LDAPOID(r.OID()), RequestValue: r.Value}, nil
+}
+
+func TestRFC4511_RegisterExtendedOperation(t *testing.T) {
+	var r RFC4511
+	defer func() {
+		ldapExtendedMutex.Lock()
+		delete(ldapExtendedCodecs, ldapTestEchoOID)
+		ldapExtendedMutex.Unlock()
+	}()
+
+	decode := func(req ExtendedRequest) (ExtendedOperation, error) {
+		return ldapTestEchoRequest{Value: req.RequestValue}, nil
+	}
+
+	// Unregistered OIDs are returned as-is.
+	want := ldapTestEchoRequest{Value: OctetString(`ping`)}
+	pop, _ := want.ProtocolOp()
+	if op, err := pop.(ExtendedRequest).Decode(); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	} else if _, ok := op.(ExtendedRequest); !ok {
+		t.Fatalf("%s failed: unexpected type %T", t.Name(), op)
+	}
+
+	if err := r.RegisterExtendedOperation(ldapTestEchoOID, decode, nil); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+
+	// Decode the request by way of its wire encoding.
+	msg := LDAPMessage{MessageID: 1, ProtocolOp: pop}
+	der, _ := msg.DER()
+	decoded, err := r.LDAPMessage(der.Data())
+	if err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+
+	var op ExtendedOperation
+	if op, err = decoded.ProtocolOp.(ExtendedRequest).Decode(); err != nil {
+		t.Errorf("%s failed: %v", t.Name(), err)
+	} else if !reflect.DeepEqual(op, want) {
+		t.Errorf("%s failed: want %#v, got %#v", t.Name(), want, op)
+	}
+
+	// No response form was registered.
+	res := ExtendedResponse{ResponseName: LDAPOID(ldapTestEchoOID)}
+	if _, err = res.Decode(); err == nil {
+		t.Errorf("%s failed: expected error, got nil", t.Name())
+	}
+
+	for idx, oid := range []string{``, `echo`, ldapTestEchoOID} {
+		var req func(ExtendedRequest) (ExtendedOperation, error)
+		if oid != ldapTestEchoOID {
+			req = decode
+		}
+		if err = r.RegisterExtendedOperation(oid, req, nil); err == nil {
+			t.Errorf("%s[%d] failed: expected error, got nil", t.Name(), idx)
+		}
+	}
+}
@@ -0,0 +1,379 @@
+package dirsyn
+
+/*
+ldap_filter.go implements the RFC 4511 wire encoding of [Filter] instances
+for use within the SearchRequest protocol operation.
+*/
+
+/*
+Context-specific tags of the Filter CHOICE per § 4.5.1 of RFC 4511.
+*/
+const (
+	tagFilterAnd = iota
+	tagFilterOr
+	tagFilterNot
+	tagFilterEqualityMatch
+	tagFilterSubstrings
+	tagFilterGreaterOrEqual
+	tagFilterLessOrEqual
+	tagFilterPresent
+	tagFilterApproxMatch
+	tagFilterExtensibleMatch
+)
+
+/*
+ldapMaxFilterDepth limits the nesting of "and", "or" and "not" filters
+during decoding.
+*/
+const ldapMaxFilterDepth = 64
+
+/*
+ldapWriteFilter writes the RFC 4511 encoding of filter into der. A nil
+filter is written as the default filter (see [RFC4515.Filter]).
+
+	Filter ::= CHOICE {
+	     and             [0] SET SIZE (1..MAX) OF filter Filter,
+	     or              [1] SET SIZE (1..MAX) OF filter Filter,
+	     not             [2] Filter,
+	     equalityMatch   [3] AttributeValueAssertion,
+	     substrings      [4] SubstringFilter,
+	     greaterOrEqual  [5] AttributeValueAssertion,
+	     lessOrEqual     [6] AttributeValueAssertion,
+	     present         [7] AttributeDescription,
+	     approxMatch     [8] AttributeValueAssertion,
+	     extensibleMatch [9] MatchingRuleAssertion,
+	     ...  }
+
+Assertion values are written in their raw (unescaped) form. Note that the
+"and" and "or" sets are written in their original order.
+*/
+func ldapWriteFilter(der *DERPacket, filter Filter) (err error) {
+	if filter == nil {
+		filter, _ = marshalFilter(nil)
+	}
+
+	switch tv := filter.(type) {
+	case FilterAnd:
+		err = ldapWriteFilterSet(der, tagFilterAnd, tv)
+	case FilterOr:
+		err = ldapWriteFilterSet(der, tagFilterOr, tv)
+	case FilterNot:
+		if tv.Filter == nil {
+			err = errorTxt("not filter has no nested filter")
+			break
+		}
+		_, err = der.WriteConstructed(classContextSpecific, tagFilterNot, func(sub *DERPacket) error {
+			return ldapWriteFilter(sub, tv.Filter)
+		})
+	case FilterEqualityMatch:
+		err = ldapWriteAVA(der, tagFilterEqualityMatch, AttributeValueAssertion(tv))
+	case FilterGreaterOrEqual:
+		err = ldapWriteAVA(der, tagFilterGreaterOrEqual, AttributeValueAssertion(tv))
+	case FilterLessOrEqual:
+		err = ldapWriteAVA(der, tagFilterLessOrEqual, AttributeValueAssertion(tv))
+	case FilterApproximateMatch:
+		err = ldapWriteAVA(der, tagFilterApproxMatch, AttributeValueAssertion(tv))
+	case FilterPresent:
+		if len(tv.Desc) == 0 {
+			err = errorTxt("present filter has no attribute description")
+			break
+		}
+		ldapWritePrimitive(der, classContextSpecific, tagFilterPresent, tv.Desc)
+	case FilterSubstrings:
+		err = ldapWriteSubstrings(der, tv)
+	case FilterExtensibleMatch:
+		err = ldapWriteExtensibleMatch(der, MatchingRuleAssertion(tv))
+	default:
+		err = errorBadType("Filter")
+	}
+
+	return
+}
+
+func ldapWriteFilterSet(der *DERPacket, tag int, filters []Filter) (err error) {
+	_, err = der.WriteConstructed(classContextSpecific, tag, func(sub *DERPacket) (err error) {
+		for i := 0; i < len(filters) && err == nil; i++ {
+			err = ldapWriteFilter(sub, filters[i])
+		}
+		return
+	})
+
+	return
+}
+
+/*
+ldapWriteAVA writes ava as an AttributeValueAssertion bearing the specified
+context-specific tag.
+
+	AttributeValueAssertion ::= SEQUENCE {
+	     attributeDesc   AttributeDescription,
+	     assertionValue  AssertionValue }
+*/
+func ldapWriteAVA(der *DERPacket, tag int, ava AttributeValueAssertion) (err error) {
+	if len(ava.Desc) == 0 {
+		return errorTxt("AttributeValueAssertion has no attribute description")
+	}
+
+	_, err = der.WriteConstructed(classContextSpecific, tag, func(sub *DERPacket) error {
+		ldapWritePrimitive(sub, classUniversal, tagOctetString, ava.Desc)
+		return ldapWriteFilterValue(sub, classUniversal, tagOctetString, ava.Value)
+	})
+
+	return
+}
+
+/*
+ldapWriteSubstrings writes filter as a SubstringFilter. The "any" value of
+the underlying [SubstringAssertion] is written as one element per asterisk
+delimited substring.
+
+	SubstringFilter ::= SEQUENCE {
+	     type           AttributeDescription,
+	     substrings     SEQUENCE SIZE (1..MAX) OF substring CHOICE {
+	          initial [0] AssertionValue,  -- can occur at most once
+	          any     [1] AssertionValue,
+	          final   [2] AssertionValue } -- can occur at most once
+	     }
+*/
+func ldapWriteSubstrings(der *DERPacket, filter FilterSubstrings) (err error) {
+	ssa := filter.Substrings
+	if len(filter.Type) == 0 {
+		return errorTxt("substrings filter has no attribute description")
+	} else if ssa.IsZero() {
+		return errorTxt("substrings filter has no substrings")
+	}
+
+	_, err = der.WriteConstructed(classContextSpecific, tagFilterSubstrings, func(sub *DERPacket) (err error) {
+		ldapWritePrimitive(sub, classUniversal, tagOctetString, filter.Type)
+		_, err = sub.WriteConstructed(classUniversal, tagSequence, func(subs *DERPacket) (err error) {
+			if len(ssa.Initial) > 0 {
+				err = ldapWriteFilterValue(subs, classContextSpecific, tagSubstringInitial, ssa.Initial)
+			}
+			if len(ssa.Any) > 0 {
+				anys := split(string(ssa.Any), `*`)
+				for i := 0; i < len(anys) && err == nil; i++ {
+					err = ldapWriteFilterValue(subs, classContextSpecific, tagSubstringAny, AssertionValue(anys[i]))
+				}
+			}
+			if len(ssa.Final) > 0 && err == nil {
+				err = ldapWriteFilterValue(subs, classContextSpecific, tagSubstringFinal, ssa.Final)
+			}
+			return
+		})
+		return
+	})
+
+	return
+}
+
+/*
+ldapWriteExtensibleMatch writes mra as a MatchingRuleAssertion.
+
+	MatchingRuleAssertion ::= SEQUENCE {
+	     matchingRule    [1] MatchingRuleId OPTIONAL,
+	     type            [2] AttributeDescription OPTIONAL,
+	     matchValue      [3] AssertionValue,
+	     dnAttributes    [4] BOOLEAN DEFAULT FALSE }
+*/
+func ldapWriteExtensibleMatch(der *DERPacket, mra MatchingRuleAssertion) (err error) {
+	if len(mra.MatchingRule) == 0 && len(mra.Type) == 0 {
+		return errorTxt("extensibleMatch filter requires a matching rule or type")
+	}
+
+	_, err = der.WriteConstructed(classContextSpecific, tagFilterExtensibleMatch, func(sub *DERPacket) (err error) {
+		if len(mra.MatchingRule) > 0 {
+			ldapWritePrimitive(sub, classContextSpecific, 1, mra.MatchingRule)
+		}
+		if len(mra.Type) > 0 {
+			ldapWritePrimitive(sub, classContextSpecific, 2, mra.Type)
+		}
+		if err = ldapWriteFilterValue(sub, classContextSpecific, 3, mra.MatchValue); err == nil && mra.DNAttributes {
+			// DEFAULT FALSE is omitted per DER
+			ldapWritePrimitive(sub, classContextSpecific, 4, []byte{0xff})
+		}
+		return
+	})
+
+	return
+}
+
+/*
+ldapWriteFilterValue writes the raw octets of the escaped [AssertionValue]
+into der, bearing the specified class and tag.
+*/
+func ldapWriteFilterValue(der *DERPacket, class, tag int, val AssertionValue) (err error) {
+	var raw string
+	if raw, err = unescapeFilterValue(val); err == nil {
+		ldapWriteString(der, class, tag, raw)
+	}
+
+	return
+}
+
+/*
+ldapReadFilter reads the next element of der as a [Filter].
+*/
+func ldapReadFilter(der *DERPacket) (Filter, error) {
+	return ldapReadFilterDepth(der, 0)
+}
+
+func ldapReadFilterDepth(der *DERPacket, depth int) (filter Filter, err error) {
+	tal, ok := ldapPeek(der)
+	if !ok {
+		err = errorTxt("missing or malformed Filter")
+		return
+	} else if tal.Class != classContextSpecific {
+		err = errorTxt("Filter CHOICE must be context-specific, got class " + itoa(tal.Class))
+		return
+	} else if depth >= ldapMaxFilterDepth {
+		err = errorTxt("Filter nesting exceeds maximum depth of " + itoa(ldapMaxFilterDepth))
+		return
+	}
+
+	switch tal.Tag {
+	case tagFilterAnd, tagFilterOr:
+		var filters []Filter
+		err = der.ReadConstructed(classContextSpecific, tal.Tag, func(sub *DERPacket) (err error) {
+			for sub.HasMoreData() && err == nil {
+				var f Filter
+				if f, err = ldapReadFilterDepth(sub, depth+1); err == nil {
+					filters = append(filters, f)
+				}
+			}
+			return
+		})
+		if tal.Tag == tagFilterAnd {
+			filter = FilterAnd(filters)
+		} else {
+			filter = FilterOr(filters)
+		}
+	case tagFilterNot:
+		var not FilterNot
+		err = der.ReadConstructed(classContextSpecific, tal.Tag, func(sub *DERPacket) (err error) {
+			if not.Filter, err = ldapReadFilterDepth(sub, depth+1); err == nil && sub.HasMoreData() {
+				err = errorTxt("trailing data following not filter")
+			}
+			return
+		})
+		filter = not
+	case tagFilterEqualityMatch, tagFilterGreaterOrEqual,
+		tagFilterLessOrEqual, tagFilterApproxMatch:
+		var ava AttributeValueAssertion
+		if ava, err = ldapReadAVA(der, tal.Tag); err == nil {
+			filter = map[int]Filter{
+				tagFilterEqualityMatch:  FilterEqualityMatch(ava),
+				tagFilterGreaterOrEqual: FilterGreaterOrEqual(ava),
+				tagFilterLessOrEqual:    FilterLessOrEqual(ava),
+				tagFilterApproxMatch:    FilterApproximateMatch(ava),
+			}[tal.Tag]
+		}
+	case tagFilterPresent:
+		var desc []byte
+		if desc, err = ldapReadOctets(der, classContextSpecific, tal.Tag); err == nil {
+			filter = FilterPresent{Desc: AttributeDescription(desc)}
+		}
+	case tagFilterSubstrings:
+		filter, err = ldapReadSubstrings(der)
+	case tagFilterExtensibleMatch:
+		filter, err = ldapReadExtensibleMatch(der)
+	default:
+		err = errorTxt("unknown Filter CHOICE [" + itoa(tal.Tag) + "]")
+	}
+
+	if err != nil {
+		filter = nil
+	}
+
+	return
+}
+
+func ldapReadAVA(der *DERPacket, tag int) (ava AttributeValueAssertion, err error) {
+	err = der.ReadConstructed(classContextSpecific, tag, func(sub *DERPacket) (err error) {
+		var desc, value []byte
+		if desc, err = ldapReadOctets(sub, classUniversal, tagOctetString); err == nil {
+			if value, err = ldapReadOctets(sub, classUniversal, tagOctetString); err == nil {
+				ava.Desc = AttributeDescription(desc)
+				ava.Value = escapeFilterValue(string(value), false)
+			}
+		}
+		return
+	})
+
+	return
+}
+
+func ldapReadSubstrings(der *DERPacket) (filter FilterSubstrings, err error) {
+	err = der.ReadConstructed(classContextSpecific, tagFilterSubstrings, func(sub *DERPacket) (err error) {
+		var desc []byte
+		if desc, err = ldapReadOctets(sub, classUniversal, tagOctetString); err != nil {
+			return
+		}
+		filter.Type = AttributeDescription(desc)
+
+		return sub.ReadConstructed(classUniversal, tagSequence, func(subs *DERPacket) (err error) {
+			var anys []string
+			var last int = -1
+			for subs.HasMoreData() && err == nil {
+				tal, _ := ldapPeek(subs)
+				var value []byte
+				if value, err = ldapReadOctets(subs, classContextSpecific, tal.Tag); err != nil {
+					break
+				}
+
+				switch {
+				case tal.Tag == tagSubstringInitial && last == -1:
+					filter.Substrings.Initial = escapeFilterValue(string(value), false)
+				case tal.Tag == tagSubstringAny && last < tagSubstringFinal:
+					anys = append(anys, string(escapeFilterValue(string(value), false)))
+				case tal.Tag == tagSubstringFinal && last < tagSubstringFinal:
+					filter.Substrings.Final = escapeFilterValue(string(value), false)
+				default:
+					err = errorTxt("unexpected or misplaced substring CHOICE [" + itoa(tal.Tag) + "]")
+				}
+				last = tal.Tag
+			}
+
+			if err == nil && last == -1 {
+				err = errorTxt("substrings filter has no substrings")
+			} else if len(anys) > 0 {
+				filter.Substrings.Any = AssertionValue(join(anys, `*`))
+			}
+			return
+		})
+	})
+
+	return
+}
+
+func ldapReadExtensibleMatch(der *DERPacket) (filter FilterExtensibleMatch, err error) {
+	err = der.ReadConstructed(classContextSpecific, tagFilterExtensibleMatch, func(sub *DERPacket) (err error) {
+		var content []byte
+		if ldapNext(sub, classContextSpecific, 1) {
+			if content, err = ldapReadOctets(sub, classContextSpecific, 1); err != nil {
+				return
+			}
+			filter.MatchingRule = MatchingRuleID(content)
+		}
+		if ldapNext(sub, classContextSpecific, 2) {
+			if content, err = ldapReadOctets(sub, classContextSpecific, 2); err != nil {
+				return
+			}
+			filter.Type = AttributeDescription(content)
+		}
+		if content, err = ldapReadOctets(sub, classContextSpecific, 3); err != nil {
+			return
+		}
+		filter.MatchValue = escapeFilterValue(string(content), false)
+
+		if sub.HasMoreData() {
+			filter.DNAttributes, err = ldapReadBoolTagged(sub, classContextSpecific, 4)
+		}
+
+		if err == nil && len(filter.MatchingRule) == 0 && len(filter.Type) == 0 {
+			err = errorTxt("extensibleMatch filter requires a matching rule or type")
+		}
+		return
+	})
+
+	return
+}
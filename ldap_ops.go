@@ -0,0 +1,1218 @@
+package dirsyn
+
+/*
+ldap_ops.go implements the protocol operations of RFC 4511, each of which
+satisfies the ProtocolOp interface.
+*/
+
+/*
+APPLICATION tags of the RFC 4511 protocol operations.
+*/
+const (
+	tagBindRequest           = 0
+	tagBindResponse          = 1
+	tagUnbindRequest         = 2
+	tagSearchRequest         = 3
+	tagSearchResultEntry     = 4
+	tagSearchResultDone      = 5
+	tagModifyRequest         = 6
+	tagModifyResponse        = 7
+	tagAddRequest            = 8
+	tagAddResponse           = 9
+	tagDelRequest            = 10
+	tagDelResponse           = 11
+	tagModifyDNRequest       = 12
+	tagModifyDNResponse      = 13
+	tagCompareRequest        = 14
+	tagCompareResponse       = 15
+	tagAbandonRequest        = 16
+	tagSearchResultReference = 19
+	tagExtendedRequest       = 23
+	tagExtendedResponse      = 24
+	tagIntermediateResponse  = 25
+)
+
+/*
+ldapReadProtocolOp returns the [ProtocolOp] read from der, whose next
+element bears the specified APPLICATION tag, alongside an error.
+*/
+func ldapReadProtocolOp(der *DERPacket, tag int) (op ProtocolOp, err error) {
+	switch tag {
+	case tagBindRequest:
+		var req BindRequest
+		err = req.readOp(der)
+		op = req
+	case tagBindResponse:
+		var resp BindResponse
+		err = resp.readOp(der)
+		op = resp
+	case tagUnbindRequest:
+		var req UnbindRequest
+		err = req.readOp(der)
+		op = req
+	case tagSearchRequest:
+		var req SearchRequest
+		err = req.readOp(der)
+		op = req
+	case tagSearchResultEntry:
+		var resp SearchResultEntry
+		err = resp.readOp(der)
+		op = resp
+	case tagSearchResultReference:
+		var resp SearchResultReference
+		err = resp.readOp(der)
+		op = resp
+	case tagModifyRequest:
+		var req ModifyRequest
+		err = req.readOp(der)
+		op = req
+	case tagAddRequest:
+		var req AddRequest
+		err = req.readOp(der)
+		op = req
+	case tagDelRequest:
+		var req DelRequest
+		err = req.readOp(der)
+		op = req
+	case tagModifyDNRequest:
+		var req ModifyDNRequest
+		err = req.readOp(der)
+		op = req
+	case tagCompareRequest:
+		var req CompareRequest
+		err = req.readOp(der)
+		op = req
+	case tagAbandonRequest:
+		var req AbandonRequest
+		err = req.readOp(der)
+		op = req
+	case tagExtendedRequest:
+		var req ExtendedRequest
+		err = req.readOp(der)
+		op = req
+	case tagExtendedResponse:
+		var resp ExtendedResponse
+		err = resp.readOp(der)
+		op = resp
+	case tagIntermediateResponse:
+		var resp IntermediateResponse
+		err = resp.readOp(der)
+		op = resp
+	case tagSearchResultDone, tagModifyResponse, tagAddResponse,
+		tagDelResponse, tagModifyDNResponse, tagCompareResponse:
+		op, err = ldapReadResultOp(der, tag)
+	default:
+		err = errorTxt("unknown LDAP protocolOp [APPLICATION " + itoa(tag) + "]")
+	}
+
+	return
+}
+
+/*
+BindRequest implements the BindRequest of [§ 4.2 of RFC 4511].
+
+	BindRequest ::= [APPLICATION 0] SEQUENCE {
+	     version                 INTEGER (1 ..  127),
+	     name                    LDAPDN,
+	     authentication          AuthenticationChoice }
+
+	AuthenticationChoice ::= CHOICE {
+	     simple                  [0] OCTET STRING,
+	     sasl                    [3] SaslCredentials,
+	     ...  }
+
+If SASL is non-nil, the sasl alternative is used, else the simple.
+
+[§ 4.2 of RFC 4511]: https://datatracker.ietf.org/doc/html/rfc4511#section-4.2
+*/
+type BindRequest struct {
+	Version int
+	Name    LDAPDN
+	Simple  OctetString
+	SASL    *SaslCredentials
+}
+
+/*
+SaslCredentials implements the SaslCredentials of [§ 4.2 of RFC 4511]. A
+nil Credentials value is absent from the encoding.
+
+	SaslCredentials ::= SEQUENCE {
+	     mechanism               LDAPString,
+	     credentials             OCTET STRING OPTIONAL }
+
+[§ 4.2 of RFC 4511]: https://datatracker.ietf.org/doc/html/rfc4511#section-4.2
+*/
+type SaslCredentials struct {
+	Mechanism   LDAPString
+	Credentials OctetString
+}
+
+/*
+Tag returns the APPLICATION tag of the receiver instance.
+*/
+func (r BindRequest) Tag() int { return tagBindRequest }
+
+/*
+Identifier returns the ASN.1 identifier of the receiver instance.
+*/
+func (r BindRequest) Identifier() string { return `bindRequest` }
+
+func (r BindRequest) writeOp(der *DERPacket) (err error) {
+	if r.Version < 1 || r.Version > 127 {
+		err = errorTxt("BindRequest version out of range: " + itoa(r.Version))
+		return
+	}
+
+	_, err = der.WriteConstructed(classApplication, r.Tag(), func(sub *DERPacket) (err error) {
+		ldapWriteInt(sub, classUniversal, tagInteger, r.Version)
+		ldapWriteString(sub, classUniversal, tagOctetString, string(r.Name))
+		if r.SASL == nil {
+			ldapWritePrimitive(sub, classContextSpecific, 0, r.Simple)
+			return
+		}
+
+		_, err = sub.WriteConstructed(classContextSpecific, 3, func(sasl *DERPacket) error {
+			ldapWriteString(sasl, classUniversal, tagOctetString, string(r.SASL.Mechanism))
+			if r.SASL.Credentials != nil {
+				ldapWritePrimitive(sasl, classUniversal, tagOctetString, r.SASL.Credentials)
+			}
+			return nil
+		})
+		return
+	})
+
+	return
+}
+
+func (r *BindRequest) readOp(der *DERPacket) error {
+	return der.ReadConstructed(classApplication, r.Tag(), func(sub *DERPacket) (err error) {
+		var name []byte
+		if r.Version, err = ldapReadInt(sub, classUniversal, tagInteger); err != nil {
+			return
+		} else if r.Version < 1 || r.Version > 127 {
+			err = errorTxt("BindRequest version out of range: " + itoa(r.Version))
+			return
+		} else if name, err = ldapReadString(sub, classUniversal, tagOctetString); err != nil {
+			return
+		}
+		r.Name = LDAPDN(name)
+
+		switch {
+		case ldapNext(sub, classContextSpecific, 0):
+			r.Simple, err = ldapReadOctets(sub, classContextSpecific, 0)
+		case ldapNext(sub, classContextSpecific, 3):
+			r.SASL = new(SaslCredentials)
+			err = sub.ReadConstructed(classContextSpecific, 3, func(sasl *DERPacket) (err error) {
+				var mech []byte
+				if mech, err = ldapReadString(sasl, classUniversal, tagOctetString); err == nil {
+					r.SASL.Mechanism = LDAPString(mech)
+					if sasl.HasMoreData() {
+						r.SASL.Credentials, err = ldapReadOctets(sasl, classUniversal, tagOctetString)
+					}
+				}
+				return
+			})
+		default:
+			err = errorTxt("BindRequest authentication missing or unsupported")
+		}
+		return
+	})
+}
+
+/*
+BindResponse implements the BindResponse of [§ 4.2.2 of RFC 4511]. A nil
+ServerSaslCreds value is absent from the encoding.
+
+	BindResponse ::= [APPLICATION 1] SEQUENCE {
+	     COMPONENTS OF LDAPResult,
+	     serverSaslCreds    [7] OCTET STRING OPTIONAL }
+
+[§ 4.2.2 of RFC 4511]: https://datatracker.ietf.org/doc/html/rfc4511#section-4.2.2
+*/
+type BindResponse struct {
+	LDAPResult
+	ServerSaslCreds OctetString
+}
+
+/*
+Tag returns the APPLICATION tag of the receiver instance.
+*/
+func (r BindResponse) Tag() int { return tagBindResponse }
+
+/*
+Identifier returns the ASN.1 identifier of the receiver instance.
+*/
+func (r BindResponse) Identifier() string { return `bindResponse` }
+
+func (r BindResponse) writeOp(der *DERPacket) error {
+	return ldapWriteResultOp(der, r.Tag(), r.LDAPResult, func(sub *DERPacket) error {
+		if r.ServerSaslCreds != nil {
+			ldapWritePrimitive(sub, classContextSpecific, 7, r.ServerSaslCreds)
+		}
+		return nil
+	})
+}
+
+func (r *BindResponse) readOp(der *DERPacket) error {
+	return ldapReadResult(der, r.Tag(), &r.LDAPResult, func(sub *DERPacket) (err error) {
+		if ldapNext(sub, classContextSpecific, 7) {
+			r.ServerSaslCreds, err = ldapReadOctets(sub, classContextSpecific, 7)
+		}
+		return
+	})
+}
+
+/*
+UnbindRequest implements the UnbindRequest of [§ 4.3 of RFC 4511].
+
+	UnbindRequest ::= [APPLICATION 2] NULL
+
+[§ 4.3 of RFC 4511]: https://datatracker.ietf.org/doc/html/rfc4511#section-4.3
+*/
+type UnbindRequest struct{}
+
+/*
+Tag returns the APPLICATION tag of the receiver instance.
+*/
+func (r UnbindRequest) Tag() int { return tagUnbindRequest }
+
+/*
+Identifier returns the ASN.1 identifier of the receiver instance.
+*/
+func (r UnbindRequest) Identifier() string { return `unbindRequest` }
+
+func (r UnbindRequest) writeOp(der *DERPacket) error {
+	ldapWritePrimitive(der, classApplication, r.Tag(), nil)
+	return nil
+}
+
+func (r *UnbindRequest) readOp(der *DERPacket) (err error) {
+	var content []byte
+	if content, err = ldapReadOctets(der, classApplication, r.Tag()); err == nil && len(content) != 0 {
+		err = errorTxt("UnbindRequest must have zero (0) content octets")
+	}
+
+	return
+}
+
+/*
+DerefAliases implements the derefAliases ENUMERATED component of the
+SearchRequest of [§ 4.5.1 of RFC 4511].
+
+[§ 4.5.1 of RFC 4511]: https://datatracker.ietf.org/doc/html/rfc4511#section-4.5.1
+*/
+type DerefAliases uint8
+
+const (
+	NeverDerefAliases   DerefAliases = iota // 0, neverDerefAliases
+	DerefInSearching                        // 1, derefInSearching
+	DerefFindingBaseObj                     // 2, derefFindingBaseObj
+	DerefAlways                             // 3, derefAlways
+)
+
+/*
+String returns the ASN.1 identifier of the receiver instance.
+*/
+func (r DerefAliases) String() (s string) {
+	s = `<invalid_deref_aliases>`
+	switch r {
+	case NeverDerefAliases:
+		s = `neverDerefAliases`
+	case DerefInSearching:
+		s = `derefInSearching`
+	case DerefFindingBaseObj:
+		s = `derefFindingBaseObj`
+	case DerefAlways:
+		s = `derefAlways`
+	}
+
+	return
+}
+
+/*
+SearchRequest implements the SearchRequest of [§ 4.5.1 of RFC 4511].
+
+	SearchRequest ::= [APPLICATION 3] SEQUENCE {
+	     baseObject      LDAPDN,
+	     scope           ENUMERATED {
+	          baseObject              (0),
+	          singleLevel             (1),
+	          wholeSubtree            (2),
+	          ...  },
+	     derefAliases    ENUMERATED { ... },
+	     sizeLimit       INTEGER (0 ..  maxInt),
+	     timeLimit       INTEGER (0 ..  maxInt),
+	     typesOnly       BOOLEAN,
+	     filter          Filter,
+	     attributes      AttributeSelection }
+
+Scope is expressed using the [SearchScope] constants of this package, e.g.:
+[ScopeSubtree]. A nil Filter is written as "(objectClass=*)".
+
+[§ 4.5.1 of RFC 4511]: https://datatracker.ietf.org/doc/html/rfc4511#section-4.5.1
+*/
+type SearchRequest struct {
+	BaseObject   LDAPDN
+	Scope        SearchScope
+	DerefAliases DerefAliases
+	SizeLimit    int
+	TimeLimit    int
+	TypesOnly    bool
+	Filter       Filter
+	Attributes   []LDAPString
+}
+
+/*
+Tag returns the APPLICATION tag of the receiver instance.
+*/
+func (r SearchRequest) Tag() int { return tagSearchRequest }
+
+/*
+Identifier returns the ASN.1 identifier of the receiver instance.
+*/
+func (r SearchRequest) Identifier() string { return `searchRequest` }
+
+func (r SearchRequest) writeOp(der *DERPacket) (err error) {
+	if r.Scope < ScopeBaseObject || r.Scope > ScopeSubtree {
+		err = errorBadType("search scope")
+		return
+	} else if r.DerefAliases > DerefAlways {
+		err = errorBadType("derefAliases")
+		return
+	} else if r.SizeLimit < 0 || r.TimeLimit < 0 {
+		err = errorTxt("SearchRequest limits must not be negative")
+		return
+	}
+
+	_, err = der.WriteConstructed(classApplication, r.Tag(), func(sub *DERPacket) (err error) {
+		ldapWriteString(sub, classUniversal, tagOctetString, string(r.BaseObject))
+		ldapWriteEnum(sub, int(r.Scope)-1)
+		ldapWriteEnum(sub, int(r.DerefAliases))
+		ldapWriteInt(sub, classUniversal, tagInteger, r.SizeLimit)
+		ldapWriteInt(sub, classUniversal, tagInteger, r.TimeLimit)
+		ldapWriteBool(sub, r.TypesOnly)
+		if err = ldapWriteFilter(sub, r.Filter); err == nil {
+			_, err = sub.WriteConstructed(classUniversal, tagSequence, func(attrs *DERPacket) error {
+				return ldapWriteStrings(attrs, r.Attributes)
+			})
+		}
+		return
+	})
+
+	return
+}
+
+func (r *SearchRequest) readOp(der *DERPacket) error {
+	return der.ReadConstructed(classApplication, r.Tag(), func(sub *DERPacket) (err error) {
+		var (
+			base         []byte
+			scope, deref int
+		)
+		if base, err = ldapReadString(sub, classUniversal, tagOctetString); err != nil {
+			return
+		} else if scope, err = ldapReadEnum(sub); err != nil {
+			return
+		} else if deref, err = ldapReadEnum(sub); err != nil {
+			return
+		} else if scope > 2 || deref > 3 {
+			err = errorTxt("SearchRequest scope or derefAliases out of range")
+			return
+		}
+
+		r.BaseObject = LDAPDN(base)
+		r.Scope = SearchScope(scope + 1)
+		r.DerefAliases = DerefAliases(deref)
+
+		if r.SizeLimit, err = ldapReadInt(sub, classUniversal, tagInteger); err != nil {
+			return
+		} else if r.TimeLimit, err = ldapReadInt(sub, classUniversal, tagInteger); err != nil {
+			return
+		} else if r.TypesOnly, err = ldapReadBool(sub); err != nil {
+			return
+		} else if r.Filter, err = ldapReadFilter(sub); err != nil {
+			return
+		}
+
+		return sub.ReadConstructed(classUniversal, tagSequence, func(attrs *DERPacket) error {
+			return ldapReadStrings(attrs, &r.Attributes)
+		})
+	})
+}
+
+/*
+SearchResultEntry implements the SearchResultEntry of [§ 4.5.2 of RFC 4511].
+
+	SearchResultEntry ::= [APPLICATION 4] SEQUENCE {
+	     objectName      LDAPDN,
+	     attributes      PartialAttributeList }
+
+[§ 4.5.2 of RFC 4511]: https://datatracker.ietf.org/doc/html/rfc4511#section-4.5.2
+*/
+type SearchResultEntry struct {
+	ObjectName LDAPDN
+	Attributes []PartialAttribute
+}
+
+/*
+Tag returns the APPLICATION tag of the receiver instance.
+*/
+func (r SearchResultEntry) Tag() int { return tagSearchResultEntry }
+
+/*
+Identifier returns the ASN.1 identifier of the receiver instance.
+*/
+func (r SearchResultEntry) Identifier() string { return `searchResEntry` }
+
+func (r SearchResultEntry) writeOp(der *DERPacket) (err error) {
+	_, err = der.WriteConstructed(classApplication, r.Tag(), func(sub *DERPacket) error {
+		ldapWriteString(sub, classUniversal, tagOctetString, string(r.ObjectName))
+		return ldapWriteAttributes(sub, r.Attributes, false)
+	})
+
+	return
+}
+
+func (r *SearchResultEntry) readOp(der *DERPacket) error {
+	return der.ReadConstructed(classApplication, r.Tag(), func(sub *DERPacket) (err error) {
+		var name []byte
+		if name, err = ldapReadString(sub, classUniversal, tagOctetString); err == nil {
+			r.ObjectName = LDAPDN(name)
+			r.Attributes, err = ldapReadAttributes(sub)
+		}
+		return
+	})
+}
+
+/*
+SearchResultReference implements the SearchResultReference of [§ 4.5.3 of
+RFC 4511].
+
+	SearchResultReference ::= [APPLICATION 19] SEQUENCE
+	                          SIZE (1..MAX) OF uri URI
+
+[§ 4.5.3 of RFC 4511]: https://datatracker.ietf.org/doc/html/rfc4511#section-4.5.3
+*/
+type SearchResultReference struct {
+	URIs []LDAPString
+}
+
+/*
+Tag returns the APPLICATION tag of the receiver instance.
+*/
+func (r SearchResultReference) Tag() int { return tagSearchResultReference }
+
+/*
+Identifier returns the ASN.1 identifier of the receiver instance.
+*/
+func (r SearchResultReference) Identifier() string { return `searchResRef` }
+
+func (r SearchResultReference) writeOp(der *DERPacket) (err error) {
+	if len(r.URIs) == 0 {
+		err = errorTxt("SearchResultReference must contain at least one URI")
+		return
+	}
+
+	_, err = der.WriteConstructed(classApplication, r.Tag(), func(sub *DERPacket) error {
+		return ldapWriteStrings(sub, r.URIs)
+	})
+
+	return
+}
+
+func (r *SearchResultReference) readOp(der *DERPacket) error {
+	return der.ReadConstructed(classApplication, r.Tag(), func(sub *DERPacket) error {
+		return ldapReadStrings(sub, &r.URIs)
+	})
+}
+
+/*
+SearchResultDone implements the SearchResultDone of [§ 4.5.2 of RFC 4511].
+
+	SearchResultDone ::= [APPLICATION 5] LDAPResult
+
+[§ 4.5.2 of RFC 4511]: https://datatracker.ietf.org/doc/html/rfc4511#section-4.5.2
+*/
+type SearchResultDone struct{ LDAPResult }
+
+/*
+ModifyResponse implements the ModifyResponse of [§ 4.6 of RFC 4511].
+
+	ModifyResponse ::= [APPLICATION 7] LDAPResult
+
+[§ 4.6 of RFC 4511]: https://datatracker.ietf.org/doc/html/rfc4511#section-4.6
+*/
+type ModifyResponse struct{ LDAPResult }
+
+/*
+AddResponse implements the AddResponse of [§ 4.7 of RFC 4511].
+
+	AddResponse ::= [APPLICATION 9] LDAPResult
+
+[§ 4.7 of RFC 4511]: https://datatracker.ietf.org/doc/html/rfc4511#section-4.7
+*/
+type AddResponse struct{ LDAPResult }
+
+/*
+DelResponse implements the DelResponse of [§ 4.8 of RFC 4511].
+
+	DelResponse ::= [APPLICATION 11] LDAPResult
+
+[§ 4.8 of RFC 4511]: https://datatracker.ietf.org/doc/html/rfc4511#section-4.8
+*/
+type DelResponse struct{ LDAPResult }
+
+/*
+ModifyDNResponse implements the ModifyDNResponse of [§ 4.9 of RFC 4511].
+
+	ModifyDNResponse ::= [APPLICATION 13] LDAPResult
+
+[§ 4.9 of RFC 4511]: https://datatracker.ietf.org/doc/html/rfc4511#section-4.9
+*/
+type ModifyDNResponse struct{ LDAPResult }
+
+/*
+CompareResponse implements the CompareResponse of [§ 4.10 of RFC 4511].
+
+	CompareResponse ::= [APPLICATION 15] LDAPResult
+
+[§ 4.10 of RFC 4511]: https://datatracker.ietf.org/doc/html/rfc4511#section-4.10
+*/
+type CompareResponse struct{ LDAPResult }
+
+/*
+Tag returns the APPLICATION tag of the receiver instance.
+*/
+func (r SearchResultDone) Tag() int { return tagSearchResultDone }
+func (r ModifyResponse) Tag() int   { return tagModifyResponse }
+func (r AddResponse) Tag() int      { return tagAddResponse }
+func (r DelResponse) Tag() int      { return tagDelResponse }
+func (r ModifyDNResponse) Tag() int { return tagModifyDNResponse }
+func (r CompareResponse) Tag() int  { return tagCompareResponse }
+
+/*
+Identifier returns the ASN.1 identifier of the receiver instance.
+*/
+func (r SearchResultDone) Identifier() string { return `searchResDone` }
+func (r ModifyResponse) Identifier() string   { return `modifyResponse` }
+func (r AddResponse) Identifier() string      { return `addResponse` }
+func (r DelResponse) Identifier() string      { return `delResponse` }
+func (r ModifyDNResponse) Identifier() string { return `modDNResponse` }
+func (r CompareResponse) Identifier() string  { return `compareResponse` }
+
+func (r SearchResultDone) writeOp(der *DERPacket) error {
+	return ldapWriteResultOp(der, r.Tag(), r.LDAPResult, nil)
+}
+
+func (r ModifyResponse) writeOp(der *DERPacket) error {
+	return ldapWriteResultOp(der, r.Tag(), r.LDAPResult, nil)
+}
+
+func (r AddResponse) writeOp(der *DERPacket) error {
+	return ldapWriteResultOp(der, r.Tag(), r.LDAPResult, nil)
+}
+
+func (r DelResponse) writeOp(der *DERPacket) error {
+	return ldapWriteResultOp(der, r.Tag(), r.LDAPResult, nil)
+}
+
+func (r ModifyDNResponse) writeOp(der *DERPacket) error {
+	return ldapWriteResultOp(der, r.Tag(), r.LDAPResult, nil)
+}
+
+func (r CompareResponse) writeOp(der *DERPacket) error {
+	return ldapWriteResultOp(der, r.Tag(), r.LDAPResult, nil)
+}
+
+/*
+ldapReadResultOp returns the [ProtocolOp], consisting solely of an instance
+of [LDAPResult], read from der alongside an error.
+*/
+func ldapReadResultOp(der *DERPacket, tag int) (op ProtocolOp, err error) {
+	var res LDAPResult
+	if err = ldapReadResult(der, tag, &res, nil); err == nil {
+		switch tag {
+		case tagSearchResultDone:
+			op = SearchResultDone{res}
+		case tagModifyResponse:
+			op = ModifyResponse{res}
+		case tagAddResponse:
+			op = AddResponse{res}
+		case tagDelResponse:
+			op = DelResponse{res}
+		case tagModifyDNResponse:
+			op = ModifyDNResponse{res}
+		case tagCompareResponse:
+			op = CompareResponse{res}
+		}
+	}
+
+	return
+}
+
+/*
+ldapWriteResultOp writes a response operation bearing the specified tag,
+which consists of the components of res followed by those written by the
+optional extra function.
+*/
+func ldapWriteResultOp(der *DERPacket, tag int, res LDAPResult, extra func(*DERPacket) error) (err error) {
+	_, err = der.WriteConstructed(classApplication, tag, func(sub *DERPacket) (err error) {
+		if err = res.write(sub); err == nil && extra != nil {
+			err = extra(sub)
+		}
+		return
+	})
+
+	return
+}
+
+/*
+ldapReadResult reads a response operation bearing the specified tag into
+res, after which the optional extra function reads any components which
+follow those of res.
+*/
+func ldapReadResult(der *DERPacket, tag int, res *LDAPResult, extra func(*DERPacket) error) error {
+	return der.ReadConstructed(classApplication, tag, func(sub *DERPacket) (err error) {
+		if err = res.read(sub); err == nil && extra != nil {
+			err = extra(sub)
+		}
+		return
+	})
+}
+
+/*
+ModifyOperation implements the operation ENUMERATED component of the
+ModifyRequest of [§ 4.6 of RFC 4511], as extended by [RFC 4525].
+
+[§ 4.6 of RFC 4511]: https://datatracker.ietf.org/doc/html/rfc4511#section-4.6
+[RFC 4525]: https://datatracker.ietf.org/doc/html/rfc4525
+*/
+type ModifyOperation uint8
+
+const (
+	ModifyAdd       ModifyOperation = iota // 0, add
+	ModifyDelete                           // 1, delete
+	ModifyReplace                          // 2, replace
+	ModifyIncrement                        // 3, increment (RFC 4525)
+)
+
+/*
+String returns the ASN.1 identifier of the receiver instance.
+*/
+func (r ModifyOperation) String() (s string) {
+	s = `<invalid_modify_operation>`
+	switch r {
+	case ModifyAdd:
+		s = `add`
+	case ModifyDelete:
+		s = `delete`
+	case ModifyReplace:
+		s = `replace`
+	case ModifyIncrement:
+		s = `increment`
+	}
+
+	return
+}
+
+/*
+Change implements a single change within the ModifyRequest of [§ 4.6 of
+RFC 4511].
+
+	change SEQUENCE {
+	     operation       ENUMERATED { ... },
+	     modification    PartialAttribute }
+
+[§ 4.6 of RFC 4511]: https://datatracker.ietf.org/doc/html/rfc4511#section-4.6
+*/
+type Change struct {
+	Operation    ModifyOperation
+	Modification PartialAttribute
+}
+
+/*
+ModifyRequest implements the ModifyRequest of [§ 4.6 of RFC 4511].
+
+	ModifyRequest ::= [APPLICATION 6] SEQUENCE {
+	     object          LDAPDN,
+	     changes         SEQUENCE OF change SEQUENCE { ... } }
+
+[§ 4.6 of RFC 4511]: https://datatracker.ietf.org/doc/html/rfc4511#section-4.6
+*/
+type ModifyRequest struct {
+	Object  LDAPDN
+	Changes []Change
+}
+
+/*
+Tag returns the APPLICATION tag of the receiver instance.
+*/
+func (r ModifyRequest) Tag() int { return tagModifyRequest }
+
+/*
+Identifier returns the ASN.1 identifier of the receiver instance.
+*/
+func (r ModifyRequest) Identifier() string { return `modifyRequest` }
+
+func (r ModifyRequest) writeOp(der *DERPacket) (err error) {
+	_, err = der.WriteConstructed(classApplication, r.Tag(), func(sub *DERPacket) (err error) {
+		ldapWriteString(sub, classUniversal, tagOctetString, string(r.Object))
+		_, err = sub.WriteConstructed(classUniversal, tagSequence, func(changes *DERPacket) (err error) {
+			for i := 0; i < len(r.Changes) && err == nil; i++ {
+				change := r.Changes[i]
+				if change.Operation > ModifyIncrement {
+					err = errorBadType("modify operation")
+					break
+				}
+				_, err = changes.WriteConstructed(classUniversal, tagSequence, func(seq *DERPacket) error {
+					ldapWriteEnum(seq, int(change.Operation))
+					return change.Modification.write(seq)
+				})
+			}
+			return
+		})
+		return
+	})
+
+	return
+}
+
+func (r *ModifyRequest) readOp(der *DERPacket) error {
+	return der.ReadConstructed(classApplication, r.Tag(), func(sub *DERPacket) (err error) {
+		var obj []byte
+		if obj, err = ldapReadString(sub, classUniversal, tagOctetString); err != nil {
+			return
+		}
+		r.Object = LDAPDN(obj)
+
+		return sub.ReadSequenceOf(func(changes *DERPacket) error {
+			return changes.ReadConstructed(classUniversal, tagSequence, func(seq *DERPacket) (err error) {
+				var (
+					change Change
+					op     int
+				)
+				if op, err = ldapReadEnum(seq); err != nil {
+					return
+				} else if op > int(ModifyIncrement) {
+					err = errorBadType("modify operation")
+					return
+				}
+				change.Operation = ModifyOperation(op)
+				if err = change.Modification.read(seq); err == nil {
+					r.Changes = append(r.Changes, change)
+				}
+				return
+			})
+		})
+	})
+}
+
+/*
+AddRequest implements the AddRequest of [§ 4.7 of RFC 4511]. Each attribute
+must bear at least one value.
+
+	AddRequest ::= [APPLICATION 8] SEQUENCE {
+	     entry           LDAPDN,
+	     attributes      AttributeList }
+
+[§ 4.7 of RFC 4511]: https://datatracker.ietf.org/doc/html/rfc4511#section-4.7
+*/
+type AddRequest struct {
+	Entry      LDAPDN
+	Attributes []PartialAttribute
+}
+
+/*
+Tag returns the APPLICATION tag of the receiver instance.
+*/
+func (r AddRequest) Tag() int { return tagAddRequest }
+
+/*
+Identifier returns the ASN.1 identifier of the receiver instance.
+*/
+func (r AddRequest) Identifier() string { return `addRequest` }
+
+func (r AddRequest) writeOp(der *DERPacket) (err error) {
+	_, err = der.WriteConstructed(classApplication, r.Tag(), func(sub *DERPacket) error {
+		ldapWriteString(sub, classUniversal, tagOctetString, string(r.Entry))
+		return ldapWriteAttributes(sub, r.Attributes, true)
+	})
+
+	return
+}
+
+func (r *AddRequest) readOp(der *DERPacket) error {
+	return der.ReadConstructed(classApplication, r.Tag(), func(sub *DERPacket) (err error) {
+		var entry []byte
+		if entry, err = ldapReadString(sub, classUniversal, tagOctetString); err == nil {
+			r.Entry = LDAPDN(entry)
+			r.Attributes, err = ldapReadAttributes(sub)
+		}
+		return
+	})
+}
+
+/*
+DelRequest implements the DelRequest of [§ 4.8 of RFC 4511].
+
+	DelRequest ::= [APPLICATION 10] LDAPDN
+
+[§ 4.8 of RFC 4511]: https://datatracker.ietf.org/doc/html/rfc4511#section-4.8
+*/
+type DelRequest struct {
+	Entry LDAPDN
+}
+
+/*
+Tag returns the APPLICATION tag of the receiver instance.
+*/
+func (r DelRequest) Tag() int { return tagDelRequest }
+
+/*
+Identifier returns the ASN.1 identifier of the receiver instance.
+*/
+func (r DelRequest) Identifier() string { return `delRequest` }
+
+func (r DelRequest) writeOp(der *DERPacket) error {
+	ldapWriteString(der, classApplication, r.Tag(), string(r.Entry))
+	return nil
+}
+
+func (r *DelRequest) readOp(der *DERPacket) (err error) {
+	var entry []byte
+	if entry, err = ldapReadString(der, classApplication, r.Tag()); err == nil {
+		r.Entry = LDAPDN(entry)
+	}
+
+	return
+}
+
+/*
+ModifyDNRequest implements the ModifyDNRequest of [§ 4.9 of RFC 4511]. A
+nil NewSuperior is absent from the encoding.
+
+	ModifyDNRequest ::= [APPLICATION 12] SEQUENCE {
+	     entry           LDAPDN,
+	     newrdn          RelativeLDAPDN,
+	     deleteoldrdn    BOOLEAN,
+	     newSuperior     [0] LDAPDN OPTIONAL }
+
+[§ 4.9 of RFC 4511]: https://datatracker.ietf.org/doc/html/rfc4511#section-4.9
+*/
+type ModifyDNRequest struct {
+	Entry        LDAPDN
+	NewRDN       RelativeLDAPDN
+	DeleteOldRDN bool
+	NewSuperior  *LDAPDN
+}
+
+/*
+Tag returns the APPLICATION tag of the receiver instance.
+*/
+func (r ModifyDNRequest) Tag() int { return tagModifyDNRequest }
+
+/*
+Identifier returns the ASN.1 identifier of the receiver instance.
+*/
+func (r ModifyDNRequest) Identifier() string { return `modDNRequest` }
+
+func (r ModifyDNRequest) writeOp(der *DERPacket) (err error) {
+	_, err = der.WriteConstructed(classApplication, r.Tag(), func(sub *DERPacket) error {
+		ldapWriteString(sub, classUniversal, tagOctetString, string(r.Entry))
+		ldapWriteString(sub, classUniversal, tagOctetString, string(r.NewRDN))
+		ldapWriteBool(sub, r.DeleteOldRDN)
+		if r.NewSuperior != nil {
+			ldapWriteString(sub, classContextSpecific, 0, string(*r.NewSuperior))
+		}
+		return nil
+	})
+
+	return
+}
+
+func (r *ModifyDNRequest) readOp(der *DERPacket) error {
+	return der.ReadConstructed(classApplication, r.Tag(), func(sub *DERPacket) (err error) {
+		var entry, rdn []byte
+		if entry, err = ldapReadString(sub, classUniversal, tagOctetString); err != nil {
+			return
+		} else if rdn, err = ldapReadString(sub, classUniversal, tagOctetString); err != nil {
+			return
+		} else if r.DeleteOldRDN, err = ldapReadBool(sub); err != nil {
+			return
+		}
+		r.Entry = LDAPDN(entry)
+		r.NewRDN = RelativeLDAPDN(rdn)
+
+		if ldapNext(sub, classContextSpecific, 0) {
+			var sup []byte
+			if sup, err = ldapReadString(sub, classContextSpecific, 0); err == nil {
+				dn := LDAPDN(sup)
+				r.NewSuperior = &dn
+			}
+		}
+		return
+	})
+}
+
+/*
+CompareRequest implements the CompareRequest of [§ 4.10 of RFC 4511].
+
+	CompareRequest ::= [APPLICATION 14] SEQUENCE {
+	     entry           LDAPDN,
+	     ava             AttributeValueAssertion }
+
+[§ 4.10 of RFC 4511]: https://datatracker.ietf.org/doc/html/rfc4511#section-4.10
+*/
+type CompareRequest struct {
+	Entry LDAPDN
+	AVA   AttributeValueAssertion
+}
+
+/*
+Tag returns the APPLICATION tag of the receiver instance.
+*/
+func (r CompareRequest) Tag() int { return tagCompareRequest }
+
+/*
+Identifier returns the ASN.1 identifier of the receiver instance.
+*/
+func (r CompareRequest) Identifier() string { return `compareRequest` }
+
+func (r CompareRequest) writeOp(der *DERPacket) (err error) {
+	_, err = der.WriteConstructed(classApplication, r.Tag(), func(sub *DERPacket) (err error) {
+		ldapWriteString(sub, classUniversal, tagOctetString, string(r.Entry))
+		_, err = sub.WriteConstructed(classUniversal, tagSequence, func(ava *DERPacket) error {
+			ldapWriteString(ava, classUniversal, tagOctetString, string(r.AVA.Desc))
+			ldapWritePrimitive(ava, classUniversal, tagOctetString, r.AVA.Value)
+			return nil
+		})
+		return
+	})
+
+	return
+}
+
+func (r *CompareRequest) readOp(der *DERPacket) error {
+	return der.ReadConstructed(classApplication, r.Tag(), func(sub *DERPacket) (err error) {
+		var entry []byte
+		if entry, err = ldapReadString(sub, classUniversal, tagOctetString); err != nil {
+			return
+		}
+		r.Entry = LDAPDN(entry)
+
+		return sub.ReadConstructed(classUniversal, tagSequence, func(ava *DERPacket) (err error) {
+			var desc []byte
+			if desc, err = ldapReadString(ava, classUniversal, tagOctetString); err == nil {
+				r.AVA.Desc = AttributeDescription(desc)
+				r.AVA.Value, err = ldapReadOctets(ava, classUniversal, tagOctetString)
+			}
+			return
+		})
+	})
+}
+
+/*
+AbandonRequest implements the AbandonRequest of [§ 4.11 of RFC 4511].
+
+	AbandonRequest ::= [APPLICATION 16] MessageID
+
+[§ 4.11 of RFC 4511]: https://datatracker.ietf.org/doc/html/rfc4511#section-4.11
+*/
+type AbandonRequest struct {
+	MessageID int
+}
+
+/*
+Tag returns the APPLICATION tag of the receiver instance.
+*/
+func (r AbandonRequest) Tag() int { return tagAbandonRequest }
+
+/*
+Identifier returns the ASN.1 identifier of the receiver instance.
+*/
+func (r AbandonRequest) Identifier() string { return `abandonRequest` }
+
+func (r AbandonRequest) writeOp(der *DERPacket) (err error) {
+	if r.MessageID < 0 || r.MessageID > ldapMaxInt {
+		err = errorTxt("AbandonRequest messageID out of range: " + itoa(r.MessageID))
+	} else {
+		ldapWriteInt(der, classApplication, r.Tag(), r.MessageID)
+	}
+
+	return
+}
+
+func (r *AbandonRequest) readOp(der *DERPacket) (err error) {
+	r.MessageID, err = ldapReadInt(der, classApplication, r.Tag())
+	return
+}
+
+/*
+ExtendedRequest implements the ExtendedRequest of [§ 4.12 of RFC 4511]. A
+nil Value is absent from the encoding.
+
+	ExtendedRequest ::= [APPLICATION 23] SEQUENCE {
+	     requestName      [0] LDAPOID,
+	     requestValue     [1] OCTET STRING OPTIONAL }
+
+[§ 4.12 of RFC 4511]: https://datatracker.ietf.org/doc/html/rfc4511#section-4.12
+*/
+type ExtendedRequest struct {
+	RequestName  LDAPOID
+	RequestValue OctetString
+}
+
+/*
+Tag returns the APPLICATION tag of the receiver instance.
+*/
+func (r ExtendedRequest) Tag() int { return tagExtendedRequest }
+
+/*
+Identifier returns the ASN.1 identifier of the receiver instance.
+*/
+func (r ExtendedRequest) Identifier() string { return `extendedReq` }
+
+func (r ExtendedRequest) writeOp(der *DERPacket) (err error) {
+	if len(r.RequestName) == 0 {
+		err = errorTxt("ExtendedRequest requestName must not be empty")
+		return
+	}
+
+	_, err = der.WriteConstructed(classApplication, r.Tag(), func(sub *DERPacket) error {
+		ldapWriteString(sub, classContextSpecific, 0, string(r.RequestName))
+		if r.RequestValue != nil {
+			ldapWritePrimitive(sub, classContextSpecific, 1, r.RequestValue)
+		}
+		return nil
+	})
+
+	return
+}
+
+func (r *ExtendedRequest) readOp(der *DERPacket) error {
+	return der.ReadConstructed(classApplication, r.Tag(), func(sub *DERPacket) (err error) {
+		var name []byte
+		if name, err = ldapReadString(sub, classContextSpecific, 0); err != nil {
+			return
+		} else if len(name) == 0 {
+			err = errorTxt("ExtendedRequest requestName must not be empty")
+			return
+		}
+		r.RequestName = LDAPOID(name)
+
+		if ldapNext(sub, classContextSpecific, 1) {
+			r.RequestValue, err = ldapReadOctets(sub, classContextSpecific, 1)
+		}
+		return
+	})
+}
+
+/*
+ExtendedResponse implements the ExtendedResponse of [§ 4.12 of RFC 4511].
+A zero ResponseName and nil ResponseValue are absent from the encoding.
+
+	ExtendedResponse ::= [APPLICATION 24] SEQUENCE {
+	     COMPONENTS OF LDAPResult,
+	     responseName     [10] LDAPOID OPTIONAL,
+	     responseValue    [11] OCTET STRING OPTIONAL }
+
+[§ 4.12 of RFC 4511]: https://datatracker.ietf.org/doc/html/rfc4511#section-4.12
+*/
+type ExtendedResponse struct {
+	LDAPResult
+	ResponseName  LDAPOID
+	ResponseValue OctetString
+}
+
+/*
+Tag returns the APPLICATION tag of the receiver instance.
+*/
+func (r ExtendedResponse) Tag() int { return tagExtendedResponse }
+
+/*
+Identifier returns the ASN.1 identifier of the receiver instance.
+*/
+func (r ExtendedResponse) Identifier() string { return `extendedResp` }
+
+func (r ExtendedResponse) writeOp(der *DERPacket) error {
+	return ldapWriteResultOp(der, r.Tag(), r.LDAPResult, func(sub *DERPacket) error {
+		ldapWriteNameValue(sub, 10, r.ResponseName, r.ResponseValue)
+		return nil
+	})
+}
+
+func (r *ExtendedResponse) readOp(der *DERPacket) error {
+	return ldapReadResult(der, r.Tag(), &r.LDAPResult, func(sub *DERPacket) (err error) {
+		r.ResponseName, r.ResponseValue, err = ldapReadNameValue(sub, 10)
+		return
+	})
+}
+
+/*
+IntermediateResponse implements the IntermediateResponse of [§ 4.13 of RFC
+4511]. A zero ResponseName and nil ResponseValue are absent from the
+encoding.
+
+	IntermediateResponse ::= [APPLICATION 25] SEQUENCE {
+	     responseName     [0] LDAPOID OPTIONAL,
+	     responseValue    [1] OCTET STRING OPTIONAL }
+
+[§ 4.13 of RFC 4511]: https://datatracker.ietf.org/doc/html/rfc4511#section-4.13
+*/
+type IntermediateResponse struct {
+	ResponseName  LDAPOID
+	ResponseValue OctetString
+}
+
+/*
+Tag returns the APPLICATION tag of the receiver instance.
+*/
+func (r IntermediateResponse) Tag() int { return tagIntermediateResponse }
+
+/*
+Identifier returns the ASN.1 identifier of the receiver instance.
+*/
+func (r IntermediateResponse) Identifier() string { return `intermediateResponse` }
+
+func (r IntermediateResponse) writeOp(der *DERPacket) (err error) {
+	_, err = der.WriteConstructed(classApplication, r.Tag(), func(sub *DERPacket) error {
+		ldapWriteNameValue(sub, 0, r.ResponseName, r.ResponseValue)
+		return nil
+	})
+
+	return
+}
+
+func (r *IntermediateResponse) readOp(der *DERPacket) error {
+	return der.ReadConstructed(classApplication, r.Tag(), func(sub *DERPacket) (err error) {
+		r.ResponseName, r.ResponseValue, err = ldapReadNameValue(sub, 0)
+		return
+	})
+}
+
+/*
+ldapWriteNameValue writes the OPTIONAL name and value components of an
+ExtendedResponse or IntermediateResponse, tagged [tag] and [tag+1].
+*/
+func ldapWriteNameValue(der *DERPacket, tag int, name LDAPOID, value OctetString) {
+	if len(name) > 0 {
+		ldapWriteString(der, classContextSpecific, tag, string(name))
+	}
+	if value != nil {
+		ldapWritePrimitive(der, classContextSpecific, tag+1, value)
+	}
+}
+
+func ldapReadNameValue(der *DERPacket, tag int) (name LDAPOID, value OctetString, err error) {
+	if ldapNext(der, classContextSpecific, tag) {
+		var str []byte
+		if str, err = ldapReadString(der, classContextSpecific, tag); err != nil {
+			return
+		}
+		name = LDAPOID(str)
+	}
+	if ldapNext(der, classContextSpecific, tag+1) {
+		value, err = ldapReadOctets(der, classContextSpecific, tag+1)
+	}
+
+	return
+}
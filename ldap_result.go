@@ -0,0 +1,369 @@
+package dirsyn
+
+/*
+ldap_result.go implements the LDAP result code enumeration of RFC 4511,
+alongside those result codes registered with IANA by way of extensions.
+*/
+
+/*
+ResultCode implements the resultCode ENUMERATED component of the
+LDAPResult type of [§ 4.1.9 of RFC 4511]. See [Appendix A of RFC 4511]
+for the semantics of each code.
+
+In addition to those codes defined within RFC 4511, the codes registered
+with IANA by way of [RFC 3909], [RFC 3928], [RFC 4370], [RFC 4528] and
+[RFC 4533], as well as those of the LDAP Virtual List View and No-Op
+drafts, are recognized.
+
+[§ 4.1.9 of RFC 4511]: https://datatracker.ietf.org/doc/html/rfc4511#section-4.1.9
+[Appendix A of RFC 4511]: https://datatracker.ietf.org/doc/html/rfc4511#appendix-A
+[RFC 3909]: https://datatracker.ietf.org/doc/html/rfc3909
+[RFC 3928]: https://datatracker.ietf.org/doc/html/rfc3928
+[RFC 4370]: https://datatracker.ietf.org/doc/html/rfc4370
+[RFC 4528]: https://datatracker.ietf.org/doc/html/rfc4528
+[RFC 4533]: https://datatracker.ietf.org/doc/html/rfc4533
+*/
+type ResultCode Enumerated
+
+/*
+ResultCode constants define the result codes of [Appendix A of RFC 4511].
+
+[Appendix A of RFC 4511]: https://datatracker.ietf.org/doc/html/rfc4511#appendix-A
+*/
+const (
+	ResultSuccess                      ResultCode = 0
+	ResultOperationsError              ResultCode = 1
+	ResultProtocolError                ResultCode = 2
+	ResultTimeLimitExceeded            ResultCode = 3
+	ResultSizeLimitExceeded            ResultCode = 4
+	ResultCompareFalse                 ResultCode = 5
+	ResultCompareTrue                  ResultCode = 6
+	ResultAuthMethodNotSupported       ResultCode = 7
+	ResultStrongerAuthRequired         ResultCode = 8
+	ResultReferral                     ResultCode = 10
+	ResultAdminLimitExceeded           ResultCode = 11
+	ResultUnavailableCriticalExtension ResultCode = 12
+	ResultConfidentialityRequired      ResultCode = 13
+	ResultSaslBindInProgress           ResultCode = 14
+	ResultNoSuchAttribute              ResultCode = 16
+	ResultUndefinedAttributeType       ResultCode = 17
+	ResultInappropriateMatching        ResultCode = 18
+	ResultConstraintViolation          ResultCode = 19
+	ResultAttributeOrValueExists       ResultCode = 20
+	ResultInvalidAttributeSyntax       ResultCode = 21
+	ResultNoSuchObject                 ResultCode = 32
+	ResultAliasProblem                 ResultCode = 33
+	ResultInvalidDNSyntax              ResultCode = 34
+	ResultAliasDereferencingProblem    ResultCode = 36
+	ResultInappropriateAuthentication  ResultCode = 48
+	ResultInvalidCredentials           ResultCode = 49
+	ResultInsufficientAccessRights     ResultCode = 50
+	ResultBusy                         ResultCode = 51
+	ResultUnavailable                  ResultCode = 52
+	ResultUnwillingToPerform           ResultCode = 53
+	ResultLoopDetect                   ResultCode = 54
+	ResultNamingViolation              ResultCode = 64
+	ResultObjectClassViolation         ResultCode = 65
+	ResultNotAllowedOnNonLeaf          ResultCode = 66
+	ResultNotAllowedOnRDN              ResultCode = 67
+	ResultEntryAlreadyExists           ResultCode = 68
+	ResultObjectClassModsProhibited    ResultCode = 69
+	ResultAffectsMultipleDSAs          ResultCode = 71
+	ResultOther                        ResultCode = 80
+)
+
+/*
+ResultCode constants define the result codes registered with IANA by
+way of LDAP extensions.
+*/
+const (
+	ResultSortControlMissing     ResultCode = 60    // VLV draft
+	ResultOffsetRangeError       ResultCode = 61    // VLV draft
+	ResultVirtualListViewError   ResultCode = 76    // VLV draft
+	ResultLCUPResourcesExhausted ResultCode = 113   // RFC 3928
+	ResultLCUPSecurityViolation  ResultCode = 114   // RFC 3928
+	ResultLCUPInvalidData        ResultCode = 115   // RFC 3928
+	ResultLCUPUnsupportedScheme  ResultCode = 116   // RFC 3928
+	ResultLCUPReloadRequired     ResultCode = 117   // RFC 3928
+	ResultCanceled               ResultCode = 118   // RFC 3909
+	ResultNoSuchOperation        ResultCode = 119   // RFC 3909
+	ResultTooLate                ResultCode = 120   // RFC 3909
+	ResultCannotCancel           ResultCode = 121   // RFC 3909
+	ResultAssertionFailed        ResultCode = 122   // RFC 4528
+	ResultAuthorizationDenied    ResultCode = 123   // RFC 4370
+	ResultSyncRefreshRequired    ResultCode = 4096  // RFC 4533
+	ResultNoOperation            ResultCode = 16654 // No-Op draft
+)
+
+/*
+ResultCategory describes the broad class of condition indicated by a
+[ResultCode], as returned by [ResultCode.Category].
+*/
+type ResultCategory uint8
+
+/*
+ResultCategory constants define the classes of [ResultCode].
+*/
+const (
+	ResultCategoryOther     ResultCategory = iota // unrecognized or unclassified
+	ResultCategorySuccess                         // operation completed without error
+	ResultCategoryReferral                        // operation must be retried elsewhere
+	ResultCategoryProtocol                        // malformed or unsupported request
+	ResultCategoryTransient                       // operation may succeed if retried later
+	ResultCategorySecurity                        // authentication or authorization problem
+	ResultCategoryAttribute                       // attribute type or value problem
+	ResultCategoryName                            // distinguished name or alias problem
+	ResultCategoryUpdate                          // update would violate the DIT model
+	ResultCategoryService                         // operation refused or abandoned by the service
+)
+
+/*
+String returns the string representation of the receiver instance.
+*/
+func (r ResultCategory) String() (s string) {
+	switch r {
+	case ResultCategorySuccess:
+		s = `success`
+	case ResultCategoryReferral:
+		s = `referral`
+	case ResultCategoryProtocol:
+		s = `protocol`
+	case ResultCategoryTransient:
+		s = `transient`
+	case ResultCategorySecurity:
+		s = `security`
+	case ResultCategoryAttribute:
+		s = `attribute`
+	case ResultCategoryName:
+		s = `name`
+	case ResultCategoryUpdate:
+		s = `update`
+	case ResultCategoryService:
+		s = `service`
+	default:
+		s = `other`
+	}
+
+	return
+}
+
+/*
+resultCodeInfo contains the name, description and category of a single
+[ResultCode].
+*/
+type resultCodeInfo struct {
+	name     string
+	desc     string
+	category ResultCategory
+}
+
+var resultCodes = map[ResultCode]resultCodeInfo{
+	ResultSuccess:                      {`success`, `The operation completed successfully`, ResultCategorySuccess},
+	ResultOperationsError:              {`operationsError`, `The operation is not properly sequenced with relation to other operations`, ResultCategoryProtocol},
+	ResultProtocolError:                {`protocolError`, `The server received data that is not well-formed`, ResultCategoryProtocol},
+	ResultTimeLimitExceeded:            {`timeLimitExceeded`, `The time limit specified by the client was exceeded before the operation could be completed`, ResultCategoryTransient},
+	ResultSizeLimitExceeded:            {`sizeLimitExceeded`, `The size limit specified by the client was exceeded before the operation could be completed`, ResultCategoryTransient},
+	ResultCompareFalse:                 {`compareFalse`, `The Compare operation completed and the assertion evaluated to FALSE or Undefined`, ResultCategorySuccess},
+	ResultCompareTrue:                  {`compareTrue`, `The Compare operation completed and the assertion evaluated to TRUE`, ResultCategorySuccess},
+	ResultAuthMethodNotSupported:       {`authMethodNotSupported`, `The authentication method or mechanism is not supported`, ResultCategorySecurity},
+	ResultStrongerAuthRequired:         {`strongerAuthRequired`, `The server requires strong authentication to complete the operation`, ResultCategorySecurity},
+	ResultReferral:                     {`referral`, `A referral needs to be chased to complete the operation`, ResultCategoryReferral},
+	ResultAdminLimitExceeded:           {`adminLimitExceeded`, `An administrative limit has been exceeded`, ResultCategoryTransient},
+	ResultUnavailableCriticalExtension: {`unavailableCriticalExtension`, `A critical control is unrecognized`, ResultCategoryProtocol},
+	ResultConfidentialityRequired:      {`confidentialityRequired`, `Data confidentiality protections are required`, ResultCategorySecurity},
+	ResultSaslBindInProgress:           {`saslBindInProgress`, `The server requires the client to send a new SASL bind request to continue`, ResultCategorySuccess},
+	ResultNoSuchAttribute:              {`noSuchAttribute`, `The named entry does not contain the specified attribute or attribute value`, ResultCategoryAttribute},
+	ResultUndefinedAttributeType:       {`undefinedAttributeType`, `The attribute description does not refer to a recognized attribute type`, ResultCategoryAttribute},
+	ResultInappropriateMatching:        {`inappropriateMatching`, `The matching rule is not defined for the attribute type concerned`, ResultCategoryAttribute},
+	ResultConstraintViolation:          {`constraintViolation`, `The client supplied an attribute value that does not conform to the constraints placed upon it by the data model`, ResultCategoryAttribute},
+	ResultAttributeOrValueExists:       {`attributeOrValueExists`, `The client supplied an attribute or value to be added to an entry, but the attribute or value already exists`, ResultCategoryAttribute},
+	ResultInvalidAttributeSyntax:       {`invalidAttributeSyntax`, `A purported attribute value does not conform to the syntax of the attribute`, ResultCategoryAttribute},
+	ResultNoSuchObject:                 {`noSuchObject`, `The object does not exist in the DIT`, ResultCategoryName},
+	ResultAliasProblem:                 {`aliasProblem`, `An alias problem has occurred`, ResultCategoryName},
+	ResultInvalidDNSyntax:              {`invalidDNSyntax`, `An LDAPDN or RelativeLDAPDN field does not conform to the required syntax`, ResultCategoryName},
+	ResultAliasDereferencingProblem:    {`aliasDereferencingProblem`, `A problem occurred while dereferencing an alias`, ResultCategoryName},
+	ResultInappropriateAuthentication:  {`inappropriateAuthentication`, `The server requires the client to bind with credentials other than anonymous`, ResultCategorySecurity},
+	ResultInvalidCredentials:           {`invalidCredentials`, `The provided credentials are invalid`, ResultCategorySecurity},
+	ResultInsufficientAccessRights:     {`insufficientAccessRights`, `The client does not have sufficient access rights to perform the operation`, ResultCategorySecurity},
+	ResultBusy:                         {`busy`, `The server is too busy to service the operation`, ResultCategoryTransient},
+	ResultUnavailable:                  {`unavailable`, `The server is shutting down or a subsystem necessary to complete the operation is offline`, ResultCategoryTransient},
+	ResultUnwillingToPerform:           {`unwillingToPerform`, `The server is unwilling to perform the operation`, ResultCategoryService},
+	ResultLoopDetect:                   {`loopDetect`, `The server has detected an internal loop`, ResultCategoryService},
+	ResultSortControlMissing:           {`sortControlMissing`, `A virtual list view was requested without a server side sort request`, ResultCategoryProtocol},
+	ResultOffsetRangeError:             {`offsetRangeError`, `The virtual list view offset or content count is out of range`, ResultCategoryProtocol},
+	ResultNamingViolation:              {`namingViolation`, `The entry's name violates naming restrictions`, ResultCategoryUpdate},
+	ResultObjectClassViolation:         {`objectClassViolation`, `The entry violates object class restrictions`, ResultCategoryUpdate},
+	ResultNotAllowedOnNonLeaf:          {`notAllowedOnNonLeaf`, `The operation is inappropriately acting upon a non-leaf entry`, ResultCategoryUpdate},
+	ResultNotAllowedOnRDN:              {`notAllowedOnRDN`, `The operation is inappropriately attempting to remove a value that forms the entry's relative distinguished name`, ResultCategoryUpdate},
+	ResultEntryAlreadyExists:           {`entryAlreadyExists`, `The request cannot be fulfilled as the entry already exists`, ResultCategoryUpdate},
+	ResultObjectClassModsProhibited:    {`objectClassModsProhibited`, `The attempt to modify object class values of the entry is prohibited`, ResultCategoryUpdate},
+	ResultAffectsMultipleDSAs:          {`affectsMultipleDSAs`, `The operation cannot be performed as it would affect multiple servers`, ResultCategoryUpdate},
+	ResultVirtualListViewError:         {`virtualListViewError`, `The virtual list view could not be processed`, ResultCategoryService},
+	ResultOther:                        {`other`, `The server has encountered an internal error`, ResultCategoryOther},
+	ResultLCUPResourcesExhausted:       {`lcupResourcesExhausted`, `The server is running out of resources to service the client update protocol session`, ResultCategoryTransient},
+	ResultLCUPSecurityViolation:        {`lcupSecurityViolation`, `The client update protocol session would violate security constraints`, ResultCategorySecurity},
+	ResultLCUPInvalidData:              {`lcupInvalidData`, `The client update protocol cookie is invalid`, ResultCategoryProtocol},
+	ResultLCUPUnsupportedScheme:        {`lcupUnsupportedScheme`, `The client update protocol synchronization scheme is not supported`, ResultCategoryProtocol},
+	ResultLCUPReloadRequired:           {`lcupReloadRequired`, `The client update protocol client must reload its content`, ResultCategoryService},
+	ResultCanceled:                     {`canceled`, `The operation was canceled`, ResultCategoryService},
+	ResultNoSuchOperation:              {`noSuchOperation`, `The operation to be canceled could not be found`, ResultCategoryService},
+	ResultTooLate:                      {`tooLate`, `The operation to be canceled has progressed too far to be canceled`, ResultCategoryService},
+	ResultCannotCancel:                 {`cannotCancel`, `The operation to be canceled cannot be canceled`, ResultCategoryService},
+	ResultAssertionFailed:              {`assertionFailed`, `The assertion control evaluated to FALSE or Undefined`, ResultCategoryService},
+	ResultAuthorizationDenied:          {`authorizationDenied`, `The proxied authorization identity is not permitted`, ResultCategorySecurity},
+	ResultSyncRefreshRequired:          {`e-syncRefreshRequired`, `The content synchronization client must refresh its content`, ResultCategoryService},
+	ResultNoOperation:                  {`noOperation`, `The operation completed without effect as requested`, ResultCategorySuccess},
+}
+
+/*
+ResultCode returns an instance of [ResultCode] alongside an error following
+an analysis of x. Valid input types are as follows:
+
+  - Integer representations of result codes, including [Enumerated] instances
+  - Result code names as string values (e.g.: `noSuchObject`), matched without regard for case
+  - Decimal result codes as string values (e.g.: `32`)
+
+An error is returned if x does not identify a result code known to this
+package.
+*/
+func (r RFC4511) ResultCode(x any) (code ResultCode, err error) {
+	code = -1
+	switch tv := x.(type) {
+	case ResultCode:
+		code = tv
+	case Enumerated:
+		code = ResultCode(tv)
+	case int:
+		code = ResultCode(tv)
+	case string:
+		code = strToResultCode(tv)
+	default:
+		err = errorBadType("LDAP result code")
+		return
+	}
+
+	if _, found := resultCodes[code]; !found {
+		err = errorTxt("Unknown LDAP result code")
+	}
+
+	return
+}
+
+/*
+strToResultCode returns a ResultCode based on the string input, or -1 if
+unrecognized.
+*/
+func strToResultCode(x string) (code ResultCode) {
+	code = -1
+	if i, err := atoi(x); err == nil {
+		code = ResultCode(i)
+		return
+	}
+
+	for c, info := range resultCodes {
+		if streqf(x, info.name) {
+			code = c
+			break
+		}
+	}
+
+	return
+}
+
+/*
+String returns the name of the receiver instance, such as "noSuchObject".
+Unrecognized codes are returned in the form "unknown (N)".
+*/
+func (r ResultCode) String() (s string) {
+	if info, found := resultCodes[r]; found {
+		s = info.name
+	} else {
+		s = `unknown (` + itoa(int(r)) + `)`
+	}
+
+	return
+}
+
+/*
+Description returns a brief description of the condition indicated by the
+receiver instance. Zero is returned if the receiver is unrecognized.
+*/
+func (r ResultCode) Description() string {
+	return resultCodes[r].desc
+}
+
+/*
+Category returns the [ResultCategory] of the receiver instance. Instances
+of [ResultCategoryOther] are returned for unrecognized codes.
+*/
+func (r ResultCode) Category() ResultCategory {
+	return resultCodes[r].category
+}
+
+/*
+resultCodeErrors maps errors produced throughout this package onto the
+most appropriate [ResultCode]. Matches are made by way of [errors.Is].
+*/
+var resultCodeErrors = []struct {
+	err  error
+	code ResultCode
+}{
+	{unknownATErr, ResultUndefinedAttributeType},
+	{unknownMRErr, ResultInappropriateMatching},
+	{invalidMR, ResultInappropriateMatching},
+	{inapplicableMRErr, ResultInappropriateMatching},
+	{noEqualityMRErr, ResultInappropriateMatching},
+	{noOrderingMRErr, ResultInappropriateMatching},
+	{noSubstringMRErr, ResultInappropriateMatching},
+	{badAssertionErr, ResultInvalidAttributeSyntax},
+	{badACIv3BDNErr, ResultInvalidDNSyntax},
+	{badACIv3TDNErr, ResultInvalidDNSyntax},
+	{endOfFilterErr, ResultProtocolError},
+	{invalidFilterErr, ResultProtocolError},
+	{emptyFilterSetErr, ResultProtocolError},
+	{unknownBERPacket, ResultProtocolError},
+	{derLengthErr, ResultProtocolError},
+}
+
+/*
+ErrorResultCode returns the [ResultCode] most appropriate for conveying
+err, as produced by this package, within an [LDAPResult]. For example:
+
+  - A distinguished name parsing failure returns [ResultInvalidDNSyntax]
+  - An attribute value which fails syntax verification, including by way of the [RFC4517] constructors, returns [ResultInvalidAttributeSyntax]
+  - A missing MANDATORY value, per [SubschemaSubentry.VerifyAttribute], returns [ResultObjectClassViolation]
+  - Any other object class violation, per [SubschemaSubentry.VerifyEntry], returns [ResultObjectClassViolation]
+  - A reference to an unknown attribute type returns [ResultUndefinedAttributeType]
+  - An unknown or inapplicable matching rule returns [ResultInappropriateMatching]
+  - A malformed encoding, including any *[DERCanonicalError], returns [ResultProtocolError]
+
+[ResultSuccess] is returned if err is nil, while [ResultOther] is returned
+if err cannot be classified.
+*/
+func (r RFC4511) ErrorResultCode(err error) (code ResultCode) {
+	code = ResultOther
+	if err == nil {
+		code = ResultSuccess
+		return
+	}
+
+	var (
+		re  *resultError
+		dce *DERCanonicalError
+	)
+
+	if erras(err, &re) {
+		code = re.code
+	} else if erras(err, &dce) {
+		code = ResultProtocolError
+	} else {
+		for _, rce := range resultCodeErrors {
+			if erris(err, rce.err) {
+				code = rce.code
+				break
+			}
+		}
+	}
+
+	return
+}
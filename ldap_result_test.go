@@ -0,0 +1,145 @@
+package dirsyn
+
+import (
+	"fmt"
+	"testing"
+)
+
+/*
+This example demonstrates the name, description and category of a known
+[ResultCode].
+*/
+func ExampleResultCode_Category() {
+	code := ResultInvalidDNSyntax
+	fmt.Printf("%d %s (%s): %s\n", code, code, code.Category(), code.Description())
+	// Output: 34 invalidDNSyntax (name): An LDAPDN or RelativeLDAPDN field does not conform to the required syntax
+}
+
+/*
+This example demonstrates the selection of a [ResultCode] for conveying
+a distinguished name parsing failure within an [LDAPResult].
+*/
+func ExampleRFC4511_ErrorResultCode() {
+	var r RFC4511
+	_, err := srcs.RFC4514().DistinguishedName(`cn=Jesse,,dc=example,dc=com`)
+	fmt.Println(r.ErrorResultCode(err))
+	// Output: invalidDNSyntax
+}
+
+func TestRFC4511_ResultCode(t *testing.T) {
+	var r RFC4511
+	for idx, strukt := range []struct {
+		Input any
+		Want  ResultCode
+		Valid bool
+	}{
+		{0, ResultSuccess, true},
+		{`noSuchObject`, ResultNoSuchObject, true},
+		{`NOSUCHOBJECT`, ResultNoSuchObject, true},
+		{`e-syncRefreshRequired`, ResultSyncRefreshRequired, true},
+		{`49`, ResultInvalidCredentials, true},
+		{Enumerated(118), ResultCanceled, true},
+		{ResultNoOperation, ResultNoOperation, true},
+		{9, 0, false},
+		{`99`, 0, false},
+		{`bogusCode`, 0, false},
+		{3.14, 0, false},
+	} {
+		code, err := r.ResultCode(strukt.Input)
+		if !strukt.Valid {
+			if err == nil {
+				t.Errorf("%s[%d] failed: expected error, got nil", t.Name(), idx)
+			}
+		} else if err != nil {
+			t.Errorf("%s[%d] failed: %v", t.Name(), idx, err)
+		} else if code != strukt.Want {
+			t.Errorf("%s[%d] failed: want %s, got %s", t.Name(), idx, strukt.Want, code)
+		}
+	}
+
+	// every known code must survive a round trip by name
+	for code, info := range resultCodes {
+		if got, err := r.ResultCode(code.String()); err != nil || got != code {
+			t.Errorf("%s failed: %s did not round trip: %v", t.Name(), info.name, err)
+		} else if code.Description() == `` {
+			t.Errorf("%s failed: %s has no description", t.Name(), info.name)
+		}
+	}
+}
+
+func TestRFC4511_ErrorResultCode(t *testing.T) {
+	var r RFC4512
+	schema, _ := r.SubschemaSubentry()
+	if err := schema.LoadBytes([]byte(goCodeTestSchema)); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+
+	_, dnErr := srcs.RFC4514().DistinguishedName(`cn=Jesse,ou`)
+	_, timeErr := srcs.RFC4517().GeneralizedTime(`bogus`)
+	_, intErr := srcs.RFC4517().Integer(`abc`)
+	_, dstrErr := srcs.RFC4517().DirectoryString(``)
+	_, rdnErr := srcs.RFC4517().DistinguishedName(`cn=Jesse,ou`)
+	_, filterErr := srcs.RFC4515().Filter(`(&(cn=a)(sn=b)`)
+	problems, _ := srcs.RFC4515().ValidateFilter(`(bogus=x)`, schema)
+	_, derErr := srcs.X690().DecodeDER([]byte{0x01, 0x01, 0x01}, DERStrict)
+
+	for idx, strukt := range []struct {
+		Err  error
+		Want ResultCode
+	}{
+		{nil, ResultSuccess},
+		{dnErr, ResultInvalidDNSyntax},
+		{schema.VerifyAttribute(`x-birth-date`, false, `not a time`), ResultInvalidAttributeSyntax},
+		{schema.VerifyAttribute(`cn`, true, []string{}), ResultObjectClassViolation},
+		{schema.VerifyAttribute(`x-birth-date`, false, []string{`19800102030405Z`, `19800102030405Z`}), ResultConstraintViolation},
+		{schema.VerifyAttribute(`bogus`, false, ``), ResultUndefinedAttributeType},
+		{timeErr, ResultInvalidAttributeSyntax},
+		{intErr, ResultInvalidAttributeSyntax},
+		{dstrErr, ResultInvalidAttributeSyntax},
+		{rdnErr, ResultInvalidDNSyntax},
+		{problems[0], ResultUndefinedAttributeType},
+		{inapplicableMRErr, ResultInappropriateMatching},
+		{badAssertionErr, ResultInvalidAttributeSyntax},
+		{filterErr, ResultProtocolError},
+		{derErr, ResultProtocolError},
+		{errorTxt(`something else`), ResultOther},
+	} {
+		if got := srcs.RFC4511().ErrorResultCode(strukt.Err); got != strukt.Want {
+			t.Errorf("%s[%d] failed: want %s, got %s (%v)", t.Name(), idx, strukt.Want, got, strukt.Err)
+		}
+	}
+}
+
+func TestResultCode_codecov(t *testing.T) {
+	for idx, strukt := range []struct {
+		Code     ResultCode
+		Name     string
+		Category ResultCategory
+	}{
+		{ResultSuccess, `success`, ResultCategorySuccess},
+		{ResultReferral, `referral`, ResultCategoryReferral},
+		{ResultProtocolError, `protocolError`, ResultCategoryProtocol},
+		{ResultBusy, `busy`, ResultCategoryTransient},
+		{ResultInvalidCredentials, `invalidCredentials`, ResultCategorySecurity},
+		{ResultInvalidAttributeSyntax, `invalidAttributeSyntax`, ResultCategoryAttribute},
+		{ResultNoSuchObject, `noSuchObject`, ResultCategoryName},
+		{ResultObjectClassViolation, `objectClassViolation`, ResultCategoryUpdate},
+		{ResultCanceled, `canceled`, ResultCategoryService},
+		{ResultOther, `other`, ResultCategoryOther},
+		{ResultCode(9), `unknown (9)`, ResultCategoryOther},
+	} {
+		if got := strukt.Code.String(); got != strukt.Name {
+			t.Errorf("%s[%d] failed: want %s, got %s", t.Name(), idx, strukt.Name, got)
+		} else if cat := strukt.Code.Category(); cat != strukt.Category {
+			t.Errorf("%s[%d] failed: want %s, got %s", t.Name(), idx, strukt.Category, cat)
+		} else if cat.String() == `` {
+			t.Errorf("%s[%d] failed: zero category name", t.Name(), idx)
+		}
+	}
+
+	if desc := ResultCode(9).Description(); desc != `` {
+		t.Errorf("%s failed: unexpected description %q", t.Name(), desc)
+	} else if err := errorResult(ResultOther, nil); err != nil {
+		t.Errorf("%s failed: expected nil, got %v", t.Name(), err)
+	}
+}
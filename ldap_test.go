@@ -0,0 +1,371 @@
+package dirsyn
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+/*
+This example demonstrates the encoding of an LDAPMessage bearing a simple
+BindRequest.
+*/
+func ExampleLDAPMessage_DER() {
+	msg := LDAPMessage{
+		MessageID: 1,
+		ProtocolOp: BindRequest{
+			Version: 3,
+			Name:    LDAPDN(`cn=admin`),
+			Simple:  OctetString(`secret`),
+		},
+	}
+
+	der, err := msg.DER()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("%x\n", der.Data())
+	// Output: 301a02010160150201030408636e3d61646d696e8006736563726574
+}
+
+/*
+This example demonstrates the decoding of an LDAPMessage bearing a
+SearchRequest, as encoded by a common LDAP client using four (4) byte
+lengths.
+*/
+func ExampleRFC4511_LDAPMessage() {
+	var r RFC4511
+	data, _ := hexdec(`3084000000` + `2d020102` + `6384000000` + `24` +
+		`04000a01000a0100020100020100010100` +
+		`870b6f626a656374636c617373` + `308400000000`)
+
+	msg, err := r.LDAPMessage(data)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	req := msg.ProtocolOp.(SearchRequest)
+	fmt.Println(msg.MessageID, msg.ProtocolOp.Identifier(), req.Scope, req.Filter)
+	// Output: 2 searchRequest base (objectclass=*)
+}
+
+func TestLDAPMessage_codec(t *testing.T) {
+	filter, _ := marshalFilter(`(&(objectClass=person)(|(cn=Jesse*)(sn=Coretta)))`)
+	sup := LDAPDN(`ou=People,dc=example,dc=com`)
+	result := LDAPResult{
+		ResultCode:        10,
+		MatchedDN:         LDAPDN(`dc=example,dc=com`),
+		DiagnosticMessage: LDAPString(`referral`),
+		Referral:          Referral{LDAPString(`ldap://a.example.com/`), LDAPString(`ldap://b.example.com/`)},
+	}
+
+	for idx, op := range []ProtocolOp{
+		BindRequest{Version: 3, Name: LDAPDN(`cn=admin`), Simple: OctetString{}},
+		BindRequest{Version: 3, SASL: &SaslCredentials{Mechanism: LDAPString(`EXTERNAL`)}},
+		BindRequest{Version: 3, SASL: &SaslCredentials{Mechanism: LDAPString(`PLAIN`),
+			Credentials: OctetString("\x00u\x00p")}},
+		BindResponse{LDAPResult: LDAPResult{ResultCode: 14}, ServerSaslCreds: OctetString(`challenge`)},
+		BindResponse{LDAPResult: result},
+		UnbindRequest{},
+		SearchRequest{
+			BaseObject:   LDAPDN(`dc=example,dc=com`),
+			Scope:        ScopeSubtree,
+			DerefAliases: DerefAlways,
+			SizeLimit:    100,
+			TimeLimit:    30,
+			TypesOnly:    true,
+			Filter:       filter,
+			Attributes:   []LDAPString{LDAPString(`cn`), LDAPString(`mail`)},
+		},
+		SearchResultEntry{
+			ObjectName: LDAPDN(`cn=Jesse,dc=example,dc=com`),
+			Attributes: []PartialAttribute{
+				{Type: AttributeDescription(`cn`), Values: []OctetString{OctetString(`Jesse`)}},
+				{Type: AttributeDescription(`jpegPhoto`)},
+			},
+		},
+		SearchResultReference{URIs: []LDAPString{LDAPString(`ldap://c.example.com/dc=example,dc=com??sub`)}},
+		SearchResultDone{result},
+		ModifyRequest{
+			Object: LDAPDN(`cn=Jesse,dc=example,dc=com`),
+			Changes: []Change{
+				{ModifyReplace, PartialAttribute{AttributeDescription(`mail`), []OctetString{OctetString(`j@example.com`)}}},
+				{ModifyDelete, PartialAttribute{Type: AttributeDescription(`description`)}},
+				{ModifyIncrement, PartialAttribute{AttributeDescription(`uidNumber`), []OctetString{OctetString(`1`)}}},
+			},
+		},
+		ModifyResponse{LDAPResult{ResultCode: 0}},
+		AddRequest{
+			Entry: LDAPDN(`cn=Jesse,dc=example,dc=com`),
+			Attributes: []PartialAttribute{
+				{AttributeDescription(`objectClass`), []OctetString{OctetString(`top`), OctetString(`person`)}},
+				{AttributeDescription(`cn`), []OctetString{OctetString(`Jesse`)}},
+			},
+		},
+		AddResponse{LDAPResult{ResultCode: 68, DiagnosticMessage: LDAPString(`exists`)}},
+		DelRequest{Entry: LDAPDN(`cn=Jesse,dc=example,dc=com`)},
+		DelResponse{LDAPResult{ResultCode: 32, MatchedDN: LDAPDN(`dc=example,dc=com`)}},
+		ModifyDNRequest{Entry: LDAPDN(`cn=Jesse,dc=example,dc=com`), NewRDN: RelativeLDAPDN(`cn=J`), DeleteOldRDN: true},
+		ModifyDNRequest{Entry: LDAPDN(`cn=Jesse,dc=example,dc=com`), NewRDN: RelativeLDAPDN(`cn=J`), NewSuperior: &sup},
+		ModifyDNResponse{LDAPResult{}},
+		CompareRequest{Entry: LDAPDN(`cn=Jesse,dc=example,dc=com`),
+			AVA: AttributeValueAssertion{Desc: AttributeDescription(`sn`), Value: AssertionValue(`Coretta`)}},
+		CompareResponse{LDAPResult{ResultCode: 6}},
+		AbandonRequest{MessageID: 300},
+		ExtendedRequest{RequestName: LDAPOID(`1.3.6.1.4.1.4203.1.11.3`)},
+		ExtendedRequest{RequestName: LDAPOID(`1.3.6.1.4.1.1466.20037`), RequestValue: OctetString{}},
+		ExtendedResponse{LDAPResult: LDAPResult{}, ResponseValue: OctetString(`dn:cn=admin`)},
+		ExtendedResponse{LDAPResult: result, ResponseName: LDAPOID(`1.3.6.1.4.1.1466.20036`)},
+		IntermediateResponse{ResponseName: LDAPOID(`1.3.6.1.4.1.4203.1.9.1.4`), ResponseValue: OctetString{0x01}},
+		IntermediateResponse{},
+	} {
+		msg := LDAPMessage{MessageID: idx + 1, ProtocolOp: op}
+		if idx%2 == 0 {
+			msg.Controls = Controls{
+				{Type: LDAPOID(`1.2.840.113556.1.4.319`), Criticality: true, Value: OctetString{0x30, 0x00}},
+				{Type: LDAPOID(`2.16.840.1.113730.3.4.2`)},
+			}
+		}
+
+		der, err := msg.DER()
+		if err != nil {
+			t.Errorf("%s[%d] failed: %v", t.Name(), idx, err)
+			continue
+		}
+
+		var decoded LDAPMessage
+		if err = der.Unmarshal(&decoded); err != nil {
+			t.Errorf("%s[%d] failed: %v\n%x", t.Name(), idx, err, der.Data())
+			continue
+		} else if decoded.ProtocolOp.Tag() != op.Tag() || decoded.ProtocolOp.Identifier() != op.Identifier() {
+			t.Errorf("%s[%d] failed: wrong op %s", t.Name(), idx, decoded.ProtocolOp.Identifier())
+			continue
+		}
+
+		// Filters are compared by way of the encoding.
+		if sr, ok := op.(SearchRequest); ok {
+			if got := decoded.ProtocolOp.(SearchRequest).Filter.String(); got != sr.Filter.String() {
+				t.Errorf("%s[%d] failed: want filter %s, got %s", t.Name(), idx, sr.Filter, got)
+			}
+		} else if !reflect.DeepEqual(msg, decoded) {
+			t.Errorf("%s[%d] failed:\nwant: %#v\ngot:  %#v", t.Name(), idx, msg, decoded)
+		}
+
+		again, _ := decoded.DER()
+		if string(again.Data()) != string(der.Data()) {
+			t.Errorf("%s[%d] failed: re-encoding mismatch\nwant: %x\ngot:  %x",
+				t.Name(), idx, der.Data(), again.Data())
+		}
+
+		// DERPacket.Marshal yields the same encoding.
+		marshaled, _ := srcs.X690().DER()
+		if _, err = marshaled.Marshal(msg); err != nil || string(marshaled.Data()) != string(der.Data()) {
+			t.Errorf("%s[%d] failed: Marshal mismatch (%v)", t.Name(), idx, err)
+		}
+	}
+}
+
+func TestLDAPMessage_codecov(t *testing.T) {
+	var r RFC4511
+	for idx, msg := range []LDAPMessage{
+		{MessageID: -1, ProtocolOp: UnbindRequest{}},
+		{MessageID: 1},
+		{MessageID: 1, ProtocolOp: BindRequest{}},
+		{MessageID: 1, ProtocolOp: SearchRequest{}},
+		{MessageID: 1, ProtocolOp: SearchRequest{Scope: ScopeBaseObject, DerefAliases: 4}},
+		{MessageID: 1, ProtocolOp: SearchRequest{Scope: ScopeBaseObject, SizeLimit: -1}},
+		{MessageID: 1, ProtocolOp: SearchRequest{Scope: ScopeBaseObject, Filter: invalidFilter{}}},
+		{MessageID: 1, ProtocolOp: SearchResultReference{}},
+		{MessageID: 1, ProtocolOp: ModifyRequest{Changes: []Change{{Operation: 4}}}},
+		{MessageID: 1, ProtocolOp: AddRequest{Attributes: []PartialAttribute{{Type: AttributeDescription(`cn`)}}}},
+		{MessageID: 1, ProtocolOp: AbandonRequest{MessageID: -1}},
+		{MessageID: 1, ProtocolOp: DelResponse{LDAPResult{Referral: Referral{}}}},
+		{MessageID: 1, ProtocolOp: UnbindRequest{}, Controls: Controls{{}}},
+		{MessageID: 1, ProtocolOp: ExtendedRequest{}},
+	} {
+		if _, err := msg.DER(); err == nil {
+			t.Errorf("%s[%d] failed: expected error, got nil", t.Name(), idx)
+		}
+	}
+
+	for idx, raw := range []string{
+		`3003020101`,                                // no protocolOp
+		`30050201010400`,                            // protocolOp not APPLICATION
+		`30050201017f1e00`,                          // unknown protocolOp
+		`3005020101420100`,                          // unbindRequest with content
+		`30050201ff4200`,                            // negative messageID
+		`3006020101420000`,                          // trailing data
+		`30090201016004020100`,                      // bindRequest version 0
+		`300c020101600702010304008200`,              // unsupported authentication
+		`3006020101500180`,                          // abandon negative
+		`3005020101420000`,                          // trailing data after message
+		`300d020101630801010004000a0103`,            // malformed search
+		`301002010166` + `0b0400300730050a01050400`, // bad modify op
+		`300c0201016a07` + `0400` + `0a0100` + `0400`,
+		`30070201017702` + `8000`, // empty requestName
+	} {
+		data, _ := hexdec(raw)
+		if _, err := r.LDAPMessage(data); err == nil {
+			t.Errorf("%s[%d] failed: expected error, got nil", t.Name(), idx)
+		}
+	}
+
+	if _, err := r.LDAPMessage(nil); err == nil {
+		t.Errorf("%s failed: expected error, got nil", t.Name())
+	}
+	if _, err := r.LDAPMessage((*DERPacket)(nil)); err == nil {
+		t.Errorf("%s failed: expected error, got nil", t.Name())
+	}
+	if _, err := r.LDAPMessage(`bogus`); err == nil {
+		t.Errorf("%s failed: expected error, got nil", t.Name())
+	}
+
+	for _, strukt := range []struct {
+		Stringer interface{ String() string }
+		Want     string
+	}{
+		{NeverDerefAliases, `neverDerefAliases`},
+		{DerefInSearching, `derefInSearching`},
+		{DerefFindingBaseObj, `derefFindingBaseObj`},
+		{DerefAlways, `derefAlways`},
+		{DerefAliases(9), `<invalid_deref_aliases>`},
+		{ModifyAdd, `add`},
+		{ModifyDelete, `delete`},
+		{ModifyReplace, `replace`},
+		{ModifyIncrement, `increment`},
+		{ModifyOperation(9), `<invalid_modify_operation>`},
+		{LDAPOID(`1.2.3`), `1.2.3`},
+	} {
+		if got := strukt.Stringer.String(); got != strukt.Want {
+			t.Errorf("%s failed: want %s, got %s", t.Name(), strukt.Want, got)
+		}
+	}
+}
+
+func TestPartialAttribute_valueOrder(t *testing.T) {
+	// SET OF values are written in DER order, regardless
+	// of the order in which they were provided.
+	attr := PartialAttribute{
+		Type:   AttributeDescription(`cn`),
+		Values: []OctetString{OctetString(`bb`), OctetString(`a`), OctetString(`ab`)},
+	}
+
+	der := newDERPacket(nil)
+	if err := attr.write(der); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+
+	want := `3011` + `0402636e` + `310b` + `040161` + `04026162` + `04026262`
+	if got := hexencs(der.Data()); got != want {
+		t.Errorf("%s failed:\nwant: %s\ngot:  %s", t.Name(), want, got)
+	}
+}
+
+func TestRFC4511_LDAPMessage_lengthOverflow(t *testing.T) {
+	// A long-form length which overflows int must be
+	// rejected rather than cause a panic.
+	var r RFC4511
+	data := []byte{0x30, 0x88, 0x80, 0, 0, 0, 0, 0, 0, 0, 0x02, 0x01, 0x01}
+	if _, err := r.LDAPMessage(data); err == nil {
+		t.Errorf("%s failed: expected error, got nil", t.Name())
+	}
+
+	var msg LDAPMessage
+	if err := msg.UnmarshalDER(&DERPacket{data: data, mode: DERStrict}); err == nil {
+		t.Errorf("%s failed: expected error, got nil", t.Name())
+	}
+}
+
+func TestLDAPFilter_codec(t *testing.T) {
+	var r RFC4515
+	for idx, strukt := range []struct {
+		Filter string
+		Hex    string
+	}{
+		{`(cn=x)`, `a3070402636e040178`},
+		{`(objectClass=*)`, `870b6f626a656374436c617373`},
+		{`(cn=ab*cd*ef*gh)`, `a4160402636e301080026162810263648102656682026768`},
+		{`(sn=Lučić)`, `a30d0402736e04074c75c48d69c487`},
+		{`(cn=a\2ab*c\29*)`, `a40f0402636e30098003612a6281026329`},
+		{`(&(a=1)(!(b>=2))(|(c<=3)(d~=4)))`, ``},
+		{`(cn=*x*)`, ``},
+		{`(cn=x*)`, ``},
+		{`(cn=*x)`, ``},
+		{`(cn:dn:2.5.13.2:=x)`, ``},
+		{`(:caseExactMatch:=y)`, ``},
+		{`(cn=a\2ab\28\29\5c)`, ``},
+		{`(cn=\e3\82\b8)`, ``},
+	} {
+		filter, err := r.Filter(strukt.Filter)
+		if err != nil {
+			t.Errorf("%s[%d] failed: %v", t.Name(), idx, err)
+			continue
+		}
+
+		der := newDERPacket(nil)
+		if err = ldapWriteFilter(der, filter); err != nil {
+			t.Errorf("%s[%d] failed: %v", t.Name(), idx, err)
+			continue
+		} else if got := fmt.Sprintf("%x", der.Data()); strukt.Hex != `` && got != strukt.Hex {
+			t.Errorf("%s[%d] failed:\nwant: %s\ngot:  %s", t.Name(), idx, strukt.Hex, got)
+			continue
+		}
+
+		var decoded Filter
+		if decoded, err = ldapReadFilter(newDERPacket(der.Data())); err != nil {
+			t.Errorf("%s[%d] failed: %v", t.Name(), idx, err)
+		} else if decoded.String() != filter.String() {
+			t.Errorf("%s[%d] failed:\nwant: %s\ngot:  %s", t.Name(), idx, filter, decoded)
+		}
+	}
+}
+
+func TestLDAPFilter_codecov(t *testing.T) {
+	for idx, filter := range []Filter{
+		FilterPresent{},
+		FilterNot{},
+		FilterEqualityMatch{},
+		FilterSubstrings{Type: AttributeDescription(`cn`)},
+		FilterExtensibleMatch{MatchValue: AssertionValue(`x`)},
+		FilterAnd{FilterPresent{}},
+		invalidFilter{},
+	} {
+		if err := ldapWriteFilter(newDERPacket(nil), filter); err == nil {
+			t.Errorf("%s[%d] failed: expected error, got nil", t.Name(), idx)
+		}
+	}
+
+	for idx, raw := range []string{
+		``,
+		`0400`,                       // not context-specific
+		`9e00`,                       // unknown CHOICE
+		`a2050401610400`,             // not with non-filter content
+		`a206870161870161`,           // not with two filters
+		`a3020400`,                   // AVA without value
+		`a4050401613000`,             // no substrings
+		`a40b0401613006820162800163`, // final before initial
+		`a903830178`,                 // no matching rule or type
+		`a9088201618301788400`,       // bad dnAttributes
+		`a9058201618401ff`,           // missing matchValue
+	} {
+		data, _ := hexdec(raw)
+		if _, err := ldapReadFilter(newDERPacket(data)); err == nil {
+			t.Errorf("%s[%d] failed: expected error, got nil", t.Name(), idx)
+		}
+	}
+
+	// nesting beyond ldapMaxFilterDepth
+	var deep Filter = FilterPresent{Desc: AttributeDescription(`cn`)}
+	for i := 0; i < ldapMaxFilterDepth; i++ {
+		deep = FilterNot{deep}
+	}
+
+	der := newDERPacket(nil)
+	if err := ldapWriteFilter(der, deep); err != nil {
+		t.Errorf("%s failed: %v", t.Name(), err)
+	} else if _, err = ldapReadFilter(newDERPacket(der.Data())); err == nil {
+		t.Errorf("%s failed: expected depth error, got nil", t.Name())
+	}
+}
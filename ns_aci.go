@@ -79,8 +79,12 @@ func (r ACIv3Instruction) String() string {
 		r.A + "\"; " + r.PB.String() + ")"
 }
 
-func (r *ACIv3Instruction) parse(x string) (err error) {
-	x = trimS(x)
+func (r *ACIv3Instruction) parse(raw string) (err error) {
+	// retain offsets relative to raw, so
+	// that parse errors are positioned
+	// meaningfully.
+	x, xoff := trimSOffset(raw)
+	defer func() { err = rebaseParseError(err, raw, xoff) }()
 
 	tidx := stridx(x, "version 3.0;")
 	if tidx == -1 || tidx == 0 {
@@ -93,7 +97,8 @@ func (r *ACIv3Instruction) parse(x string) (err error) {
 	}
 
 	if err == nil {
-		n := trimS(x[tidx+12:])
+		n, noff := trimSOffset(x[tidx+12:])
+		noff += tidx + 12
 		if aidx := idxr(n, '"'); aidx == -1 {
 			err = badACIv3InstructionErr
 		} else {
@@ -109,12 +114,15 @@ func (r *ACIv3Instruction) parse(x string) (err error) {
 				r.A += string(a[i])
 			}
 
-			if pb := trimS(a[e:]); len(pb) < 18 {
+			pb, pboff := trimSOffset(a[e:])
+			pboff += noff + aidx + 1 + e
+			if len(pb) < 18 {
 				err = badACIv3PBRErr
 			} else if pb[0] != ';' || pb[len(pb)-1] != ')' {
 				err = badACIv3InstructionErr
 			} else {
-				err = r.PB.parse(trimS(pb[1 : len(pb)-1]))
+				inner, ioff := trimSOffset(pb[1 : len(pb)-1])
+				err = rebaseParseError(r.PB.parse(inner), x, pboff+1+ioff)
 			}
 		}
 	}
@@ -2184,11 +2192,13 @@ func (r NetscapeACIv3) BindRule(x ...any) (ACIv3BindRule, error) {
 type aCIBindRuleToken struct {
 	Type  aCIBindRuleTokenType
 	Value string
+	Pos   int // byte offset within the tokenized input
 }
 
 type aCITargetRuleToken struct {
 	Type  aCITargetRuleTokenType
 	Value string
+	Pos   int // byte offset within the tokenized input
 }
 
 func tokenizeACIv3BindRuleBooleanOperator(input string, tkz []aCIBindRuleToken) []aCIBindRuleToken {
@@ -2208,43 +2218,53 @@ func tokenizeACIv3BindRuleBooleanOperator(input string, tkz []aCIBindRuleToken)
 
 /*
 tokenizeACIv3BindRule tokenizes input into slices of aCIBindRuleToken.
+
+Any error returned is a *ParseError positioned at the offending token.
 */
 func tokenizeACIv3BindRule(input string) (tkz []aCIBindRuleToken, err error) {
 	var tokens []aCIBindRuleToken
+	var begin int // start of the pending token
 	bld := newStrBuilder()
 
 	flush := func() {
 		if bld.Len() > 0 {
 			tokens = append(tokens, tokenizeACIv3BindRuleBooleanOperator(bld.String(), tkz)...)
+			tokens[len(tokens)-1].Pos = begin
 			bld.Reset()
 		}
 	}
 
-	for i := 0; i < len(input); i++ {
+	for i := 0; i < len(input) && err == nil; i++ {
 		ch := input[i]
 		switch ch {
 		case '"':
 			flush() // finish any pending token
-			i++     // skip the starting quote
+			begin = i
+			i++ // skip the starting quote
 			for ; i < len(input) && input[i] != '"'; i++ {
 				bld.WriteByte(input[i])
 			}
-			tokens = append(tokens, aCIBindRuleToken{Type: brValue, Value: bld.String()})
+			if i == len(input) {
+				err = newParseError(input, begin, `closing quote`, unterminatedErr)
+			}
+			tokens = append(tokens, aCIBindRuleToken{Type: brValue, Value: bld.String(), Pos: begin})
 			bld.Reset()
 		case '(':
 			flush()
-			tokens = append(tokens, aCIBindRuleToken{Type: brParenOpen, Value: "("})
+			tokens = append(tokens, aCIBindRuleToken{Type: brParenOpen, Value: "(", Pos: i})
 		case ')':
 			flush()
-			tokens = append(tokens, aCIBindRuleToken{Type: brParenClose, Value: ")"})
+			tokens = append(tokens, aCIBindRuleToken{Type: brParenClose, Value: ")", Pos: i})
 		default:
 			if isWHSP(rune(ch)) {
 				flush()
 			} else if ch == '=' {
 				flush()
+				begin = i
 				bld.WriteByte(ch)
 			} else if runeInSlice(rune(ch), []rune{'>', '<', '!'}) {
 				flush()
+				begin = i
 				bld.WriteByte(ch)
 				// If the next character is '=' then include it.
 				if i+1 < len(input) && input[i+1] == '=' {
@@ -2252,22 +2272,27 @@ func tokenizeACIv3BindRule(input string) (tkz []aCIBindRuleToken, err error) {
 					i++
 				}
 			} else {
+				if bld.Len() == 0 {
+					begin = i
+				}
 				bld.WriteByte(ch)
 			}
 		}
 	}
-	flush()
 
-	tkz, err = combineACIv3BindRuleTokens(tokens)
+	if err == nil {
+		flush()
+		tkz, err = combineACIv3BindRuleTokens(input, tokens)
+	}
 
 	return
 }
 
-func combineACIv3BindRuleTokens(tokens []aCIBindRuleToken) (combined []aCIBindRuleToken, err error) {
+func combineACIv3BindRuleTokens(input string, tokens []aCIBindRuleToken) (combined []aCIBindRuleToken, err error) {
 	// Combine "AND" immediately followed by "NOT" into a single operator token.
 	for i := 0; i < len(tokens); {
 		if tokens[i].Type == brAnd && i+1 < len(tokens) && tokens[i+1].Type == brNot {
-			combined = append(combined, aCIBindRuleToken{Type: brNot, Value: "AND NOT"})
+			combined = append(combined, aCIBindRuleToken{Type: brNot, Value: "AND NOT", Pos: tokens[i].Pos})
 			i += 2 // 1 extra for "NOT"
 		} else {
 			combined = append(combined, tokens[i])
@@ -2280,7 +2305,7 @@ func combineACIv3BindRuleTokens(tokens []aCIBindRuleToken) (combined []aCIBindRu
 	// bogus input.
 	for _, tk := range combined {
 		if tk.Value == "" {
-			err = badACIv3BRTokenErr
+			err = newParseError(input, tk.Pos, `bind rule value`, badACIv3BRTokenErr)
 			break
 		}
 	}
@@ -2394,25 +2419,29 @@ func tokenizeACIv3TargetRule(input string) (tkz []aCITargetRuleToken, err error)
 		for i < length && isSpace(rune(input[i])) {
 			i++
 		}
+		if i == length {
+			break
+		}
 
 		ch := input[i]
 		switch ch {
 		case '(':
-			tkz = append(tkz, aCITargetRuleToken{Type: trParenOpen, Value: "("})
+			tkz = append(tkz, aCITargetRuleToken{Type: trParenOpen, Value: "(", Pos: i})
 			i++
 		case ')':
-			tkz = append(tkz, aCITargetRuleToken{Type: trParenClose, Value: ")"})
+			tkz = append(tkz, aCITargetRuleToken{Type: trParenClose, Value: ")", Pos: i})
 			i++
 		case '=':
-			tkz = append(tkz, aCITargetRuleToken{Type: trOperator, Value: "="})
+			tkz = append(tkz, aCITargetRuleToken{Type: trOperator, Value: "=", Pos: i})
 			i++
 		case '!':
 			// For "!=" operator, the '!' must be immediately followed by '='.
 			if i+1 < length && input[i+1] == '=' {
-				tkz = append(tkz, aCITargetRuleToken{Type: trOperator, Value: "!="})
+				tkz = append(tkz, aCITargetRuleToken{Type: trOperator, Value: "!=", Pos: i})
 				i += 2
 			} else {
-				err = errorTxt("targetRule unexpected token '!' without '=' following")
+				err = newParseError(input, i+1, `'='`,
+					errorTxt("targetRule unexpected token '!' without '=' following"))
 			}
 		case '"':
 			// Parse a quoted string literal.
@@ -2426,16 +2455,17 @@ func tokenizeACIv3TargetRule(input string) (tkz []aCITargetRuleToken, err error)
 		}
 	}
 
-	return tkz, nil
+	return tkz, err
 }
 
 func tokenizeACIv3TargetRuleMultival(i, l int, input string, tkz []aCITargetRuleToken) ([]aCITargetRuleToken, int, error) {
 	var err error
 	if i+1 < l && input[i+1] == '|' {
-		tkz = append(tkz, aCITargetRuleToken{Type: trDelim, Value: "||"})
+		tkz = append(tkz, aCITargetRuleToken{Type: trDelim, Value: "||", Pos: i})
 		i += 2
 	} else {
-		err = errorTxt("targetRule expected '||' for value delimiter, got single '|'")
+		err = newParseError(input, i+1, `'|'`,
+			errorTxt("targetRule expected '||' for value delimiter, got single '|'"))
 	}
 
 	return tkz, i, err
@@ -2450,28 +2480,36 @@ func tokenizeACIv3TargetRuleKeyword(ch byte, i, l int, input string, tkz []aCITa
 			i++
 		}
 		identifier := input[start:i]
-		tkz = append(tkz, aCITargetRuleToken{Type: trKeyword, Value: identifier})
+		tkz = append(tkz, aCITargetRuleToken{Type: trKeyword, Value: identifier, Pos: start})
 	} else {
-		err = errorTxt("targetRule unexpected character '" + string(ch) + "' at position " + itoa(i))
+		err = newParseError(input, i, `target keyword`,
+			errorTxt("targetRule unexpected character '"+string(ch)+"'"))
 	}
 
 	return tkz, i, err
 }
 
 func tokenizeTargetRuleQuotedValue(i, l int, input string, tkz []aCITargetRuleToken) ([]aCITargetRuleToken, int, error) {
+	begin := i
 	i++ // skip the opening quote
 	sb := newStrBuilder()
-	var err error
+	closed := false
 	for i < l {
 		if input[i] == '"' {
 			// Found the closing quote.
 			i++ // consume the closing quote and break.
+			closed = true
 			break
 		}
 		sb.WriteByte(input[i])
 		i++
 	}
-	tkz = append(tkz, aCITargetRuleToken{Type: trValue, Value: sb.String()})
+
+	var err error
+	if !closed {
+		err = newParseError(input, begin, `closing quote`, unterminatedErr)
+	}
+	tkz = append(tkz, aCITargetRuleToken{Type: trValue, Value: sb.String(), Pos: begin})
 	return tkz, i, err
 }
 
@@ -5341,15 +5379,20 @@ func (r *ACIv3PermissionBindRule) parse(x string) (err error) {
 		err = badACIv3PBRErr
 	} else {
 		sp := split(x[:idx], ";")
+		var off int // offset of sp[i] within x
 		for i := 0; i < len(sp) && err == nil; i++ {
 			var pb ACIv3PermissionBindRuleItem = ACIv3PermissionBindRuleItem{
 				&aCIPermissionBindRuleItem{},
 			}
 			if len(sp[i]) > 0 {
-				if err = pb.parse(trimS(sp[i]) + ";"); err == nil {
+				item, ioff := trimSOffset(sp[i])
+				if err = pb.parse(item + ";"); err == nil {
 					r.Push(pb)
+				} else {
+					err = rebaseParseError(err, x, off+ioff)
 				}
 			}
+			off += len(sp[i]) + 1
 		}
 	}
 
@@ -5381,6 +5424,8 @@ func (r *ACIv3PermissionBindRuleItem) parse(x string) (err error) {
 				}
 				err = r.Valid()
 			}
+		} else {
+			err = rebaseParseError(err, x, idx+2)
 		}
 	}
 
@@ -32,7 +32,8 @@ NumericString returns an instance of [NumericString] alongside an error
 following an analysis of x in the context of a Numeric String.
 */
 func (r RFC4517) NumericString(x any) (NumericString, error) {
-	return marshalNumericString(x)
+	val, err := marshalNumericString(x)
+	return val, errorSyntax(err)
 }
 
 func marshalNumericString(x any) (ns NumericString, err error) {
@@ -8,6 +8,8 @@ Numeric OID functionality is sourced from JesseCoretta/go-objectid.
 */
 
 import (
+	"math/big"
+
 	"github.com/JesseCoretta/go-objectid"
 )
 
@@ -75,7 +77,7 @@ OID is a wrapping alias of [RFC4512.OID].
 */
 func (r RFC4517) OID(x any) (err error) {
 	var s RFC4512
-	err = s.OID(x)
+	err = errorSyntax(s.OID(x))
 	return
 }
 
@@ -112,14 +114,16 @@ func marshalNumericOID(x any) (noid NumericOID, err error) {
 NumericOID is a wrapping alias of [RFC4512.NumericOID].
 */
 func (r RFC4517) NumericOID(x any) (NumericOID, error) {
-	return marshalNumericOID(x)
+	val, err := marshalNumericOID(x)
+	return val, errorSyntax(err)
 }
 
 /*
 Descriptor is a wrapping alias of [RFC4512.Descriptor].
 */
 func (r RFC4517) Descriptor(x any) (Descriptor, error) {
-	return marshalDescriptor(x)
+	val, err := marshalDescriptor(x)
+	return val, errorSyntax(err)
 }
 
 /*
@@ -229,3 +233,114 @@ func objectIdentifierFirstComponentMatch(a, b any) (result Boolean, err error) {
 
 	return
 }
+
+/*
+derWriteNumericOID returns an int alongside an error following an attempt
+to write oid as a DER-encoded OBJECT IDENTIFIER per § 8.19 of ITU-T Rec.
+X.690. Each subidentifier is encoded in the fewest possible octets.
+*/
+func derWriteNumericOID(der *DERPacket, oid NumericOID) (n int, err error) {
+	if oid.DotNotation == nil || oid.Len() < 2 {
+		err = errorTxt("OBJECT IDENTIFIER requires at least two (2) arcs")
+		return
+	}
+
+	arcs := split(oid.String(), `.`)
+	nums := make([]*big.Int, len(arcs))
+	for i, arc := range arcs {
+		var ok bool
+		if nums[i], ok = newBigInt(0).SetString(arc, 10); !ok {
+			err = errorTxt("invalid OBJECT IDENTIFIER arc '" + arc + "'")
+			return
+		}
+	}
+
+	if nums[0].Cmp(newBigInt(2)) > 0 ||
+		(nums[0].Cmp(newBigInt(2)) < 0 && nums[1].Cmp(newBigInt(39)) > 0) {
+		err = errorTxt("invalid OBJECT IDENTIFIER root arcs " + arcs[0] + "." + arcs[1])
+		return
+	}
+
+	// The first two arcs are combined into one subidentifier.
+	first := newBigInt(0).Mul(nums[0], newBigInt(40))
+	content := encodeBase128BigInt(first.Add(first, nums[1]))
+	for _, num := range nums[2:] {
+		content = append(content, encodeBase128BigInt(num)...)
+	}
+
+	n = derWritePrimitive(der, tagOID, content)
+
+	return
+}
+
+/*
+derReadNumericOID returns an error following an attempt to read a DER-encoded
+OBJECT IDENTIFIER into x.
+*/
+func derReadNumericOID(x *NumericOID, der *DERPacket, tal TagAndLength) (err error) {
+	var content []byte
+	if content, err = derReadPrimitive(der, tal, tagOID); err != nil {
+		return
+	} else if len(content) == 0 {
+		err = errorTxt("OBJECT IDENTIFIER has no content octets")
+		return
+	}
+
+	var arcs []string
+	for i := 0; i < len(content); {
+		if content[i] == 0x80 {
+			err = errorTxt("OBJECT IDENTIFIER subidentifier not minimally encoded")
+			return
+		}
+
+		sub := newBigInt(0)
+		for ; i < len(content); i++ {
+			sub.Lsh(sub, 7)
+			sub.Or(sub, newBigInt(int64(content[i]&0x7f)))
+			if content[i]&0x80 == 0 {
+				break
+			}
+		}
+		if i == len(content) {
+			err = errorTxt("truncated OBJECT IDENTIFIER subidentifier")
+			return
+		}
+		i++
+
+		if len(arcs) == 0 {
+			// Split the first subidentifier into two arcs.
+			root := int64(2)
+			if sub.Cmp(newBigInt(80)) < 0 {
+				root = sub.Int64() / 40
+			}
+			sub.Sub(sub, newBigInt(root*40))
+			arcs = append(arcs, fmtInt(root, 10))
+		}
+		arcs = append(arcs, sub.String())
+	}
+
+	*x, err = marshalNumericOID(join(arcs, `.`))
+
+	return
+}
+
+/*
+encodeBase128BigInt returns the base-128 encoding of value, in which all
+octets save the last bear the high-order bit.
+*/
+func encodeBase128BigInt(value *big.Int) (out []byte) {
+	v := newBigInt(0).Set(value)
+	mask := newBigInt(0x7f)
+	for {
+		b := byte(newBigInt(0).And(v, mask).Int64())
+		if len(out) > 0 {
+			b |= 0x80
+		}
+		out = append([]byte{b}, out...)
+		if v.Rsh(v, 7); v.Sign() == 0 {
+			break
+		}
+	}
+
+	return
+}
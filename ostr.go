@@ -57,7 +57,8 @@ OctetString returns an instance of [OctetString] alongside an error
 following an analysis of x in the context of an Octet String.
 */
 func (r RFC4517) OctetString(x any) (OctetString, error) {
-	return marshalOctetString(x)
+	val, err := marshalOctetString(x)
+	return val, errorSyntax(err)
 }
 
 func octetString(x any) (result Boolean) {
@@ -39,7 +39,8 @@ DeliveryMethod returns an error following an analysis of x in the context
 of a [DeliveryMethod].
 */
 func (r RFC4517) DeliveryMethod(x any) (DeliveryMethod, error) {
-	return marshalDeliveryMethod(x)
+	val, err := marshalDeliveryMethod(x)
+	return val, errorSyntax(err)
 }
 
 func marshalDeliveryMethod(x any) (dm DeliveryMethod, err error) {
@@ -125,7 +126,8 @@ PostalAddress returns an error following an analysis of x in the context
 of a [PostalAddress].
 */
 func (r RFC4517) PostalAddress(x any) (PostalAddress, error) {
-	return marshalPostalAddress(x)
+	val, err := marshalPostalAddress(x)
+	return val, errorSyntax(err)
 }
 
 func marshalPostalAddress(x any) (pa PostalAddress, err error) {
@@ -190,7 +192,8 @@ OtherMailbox returns an error following an analysis of x in the context
 of an [OtherMailbox].
 */
 func (r RFC4517) OtherMailbox(x any) (OtherMailbox, error) {
-	return marshalOtherMailbox(x)
+	val, err := marshalOtherMailbox(x)
+	return val, errorSyntax(err)
 }
 
 func marshalOtherMailbox(x any) (om OtherMailbox, err error) {
@@ -57,7 +57,8 @@ PrintableString returns an error following an analysis of x in the context
 of a [PrintableString].
 */
 func (r RFC4517) PrintableString(x any) (PrintableString, error) {
-	return marshalPrintableString(x)
+	val, err := marshalPrintableString(x)
+	return val, errorSyntax(err)
 }
 
 func marshalPrintableString(x any) (ps PrintableString, err error) {
@@ -94,3 +95,36 @@ func marshalPrintableString(x any) (ps PrintableString, err error) {
 
 	return
 }
+
+/*
+derWritePrintableString returns an int alongside an error following an
+attempt to write ps as a DER-encoded PrintableString.
+*/
+func derWritePrintableString(der *DERPacket, ps PrintableString) (n int, err error) {
+	if len(ps) > 0 {
+		_, err = marshalPrintableString(string(ps))
+	}
+	if err == nil {
+		n = derWritePrimitive(der, tagPrintableString, []byte(ps))
+	}
+
+	return
+}
+
+/*
+derReadPrintableString returns an error following an attempt to read a
+DER-encoded PrintableString into x.
+*/
+func derReadPrintableString(x *PrintableString, der *DERPacket, tal TagAndLength) (err error) {
+	var content []byte
+	if content, err = derReadPrimitive(der, tal, tagPrintableString); err == nil {
+		if len(content) > 0 {
+			_, err = marshalPrintableString(string(content))
+		}
+		if err == nil {
+			*x = PrintableString(content)
+		}
+	}
+
+	return
+}
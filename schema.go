@@ -222,6 +222,9 @@ File and directory naming schemes MUST guarantee the appropriate
 ordering of any and all sub types, sub rules and sub classes which
 would rely on the presence of dependency definitions (e.g.: 'cn'
 cannot exist without 'name').
+
+See [SubschemaSubentry.LoadDirectory] for an alternative in which
+ordering is not significant, and all errors are reported.
 */
 func (r *SubschemaSubentry) ReadDirectory(dir string) (err error) {
 
@@ -294,61 +297,12 @@ Each definition MUST begin with one (1) of the following keywords:
 Case is not significant in the keyword matching process.
 */
 func (r *SubschemaSubentry) ReadBytes(data []byte) error {
-	keywords := []string{
-		`ldapSyntaxes`, `ldapSyntax`,
-		`matchingRules`, `matchingRule`,
-		`attributeTypes`, `attributeType`,
-		`objectClasses`, `objectClass`,
-		`dITContentRules`, `dITContentRule`,
-		`nameForms`, `nameForm`,
-		`dITStructureRules`, `dITStructureRule`,
-	}
-
-	data = removeBashComments(data)
-	data = brepAll(data, []byte("$\n"), []byte("$ "))
-	lines := bsplit(data, []byte("\n"))
-
-	var (
-		result [][]byte
-		cur    []byte
-	)
-
-	// Returns a boolean and the keyword IF said
-	// keyword is at the start of the line.
-	isKeywordLine := func(line []byte) (bool, string) {
-		for _, keyword := range keywords {
-			if bhasPfx(blc(line), blc([]byte(keyword))) {
-				return true, keyword
-			}
-		}
-		return false, ""
-	}
-
-	for _, line := range lines {
-		line = []byte(condenseWHSP(line))
-		if len(line) == 0 {
-			continue
-		}
-
-		if isKeyword, _ := isKeywordLine(line); isKeyword {
-			if len(cur) > 0 {
-				result = append(result, cur)
-			}
-			cur = line
-		} else {
-			if len(cur) > 0 {
-				cur = append(cur, ' ')
-				cur = append(cur, line...)
-			}
-		}
-	}
-
-	// Add the final segment
-	if len(cur) > 0 {
-		result = append(result, cur)
+	var defs [][]byte
+	for _, chunk := range splitSchemaDefinitions(``, data) {
+		defs = append(defs, chunk.def)
 	}
 
-	return r.registerSchemaByCase(result)
+	return r.registerSchemaByCase(defs)
 }
 
 func (r *SubschemaSubentry) Push(defs ...SchemaDefinition) {
@@ -4579,7 +4533,8 @@ func marshalExtension(token, typ string, tkz *schemaTokenizer) (ext Extension, e
 			Values:  parseMultiVal(tkz),
 		}
 	} else {
-		err = errorTxt(typ + ": Unknown token in definition: " + token)
+		err = tkz.parseError(`definition keyword or extension`,
+			errorTxt(typ+": Unknown token in definition: "+token))
 	}
 
 	return
@@ -4609,8 +4564,7 @@ func marshalLDAPSyntax(x any) (def *LDAPSyntax, err error) {
 	def = new(LDAPSyntax)
 	def.Extensions = make(map[int]Extension)
 
-	input = trimS(trimDefinitionLabelToken(input))
-	tkz := newSchemaTokenizer(input)
+	tkz := newDefinitionTokenizer(input)
 	if tkz.next() && tkz.this() == `(` {
 		tkz.next()
 	}
@@ -4632,6 +4586,10 @@ func marshalLDAPSyntax(x any) (def *LDAPSyntax, err error) {
 		}
 	}
 
+	if err == nil {
+		err = tkz.err
+	}
+
 	return
 }
 
@@ -4659,8 +4617,7 @@ func marshalMatchingRule(x any) (def *MatchingRule, err error) {
 	def = new(MatchingRule)
 	def.Extensions = make(map[int]Extension)
 
-	input = trimS(trimDefinitionLabelToken(input))
-	tkz := newSchemaTokenizer(input)
+	tkz := newDefinitionTokenizer(input)
 	tkz.startTokenParen()
 
 	def.NumericOID = tkz.this()
@@ -4686,6 +4643,10 @@ func marshalMatchingRule(x any) (def *MatchingRule, err error) {
 		}
 	}
 
+	if err == nil {
+		err = tkz.err
+	}
+
 	return
 }
 
@@ -4713,8 +4674,7 @@ func marshalMatchingRuleUse(x any) (def *MatchingRuleUse, err error) {
 	def = new(MatchingRuleUse)
 	def.Extensions = make(map[int]Extension)
 
-	input = trimS(trimDefinitionLabelToken(input))
-	tkz := newSchemaTokenizer(input)
+	tkz := newDefinitionTokenizer(input)
 	tkz.startTokenParen()
 
 	def.NumericOID = tkz.this()
@@ -4740,6 +4700,10 @@ func marshalMatchingRuleUse(x any) (def *MatchingRuleUse, err error) {
 		}
 	}
 
+	if err == nil {
+		err = tkz.err
+	}
+
 	return
 }
 
@@ -4767,8 +4731,7 @@ func marshalAttributeType(x any) (def *AttributeType, err error) {
 	def = new(AttributeType)
 	def.Extensions = make(map[int]Extension)
 
-	input = trimS(trimDefinitionLabelToken(input))
-	tkz := newSchemaTokenizer(input)
+	tkz := newDefinitionTokenizer(input)
 	tkz.startTokenParen()
 
 	def.NumericOID = tkz.this()
@@ -4789,7 +4752,9 @@ func marshalAttributeType(x any) (def *AttributeType, err error) {
 		case "SUBSTR", "SUBSTRING", "EQUALITY", "ORDERING", "SYNTAX":
 			err = def.handleSyntaxMatchingRules(token, tkz)
 		case "SINGLE-VALUE", "COLLECTIVE", "OBSOLETE", "NO-USER-MODIFICATION":
-			err = def.handleBoolean(token)
+			if err = def.handleBoolean(token); err != nil {
+				err = tkz.parseError(``, err)
+			}
 		case "USAGE":
 			def.Usage = tkz.nextToken()
 		default:
@@ -4798,6 +4763,10 @@ func marshalAttributeType(x any) (def *AttributeType, err error) {
 		}
 	}
 
+	if err == nil {
+		err = tkz.err
+	}
+
 	return
 }
 
@@ -4833,7 +4802,9 @@ func (r *AttributeType) handleSyntaxMatchingRules(token string, tkz *schemaToken
 	case "SUBSTR", "SUBSTRING":
 		r.Substring = tkz.nextToken()
 	case "SYNTAX":
-		r.MinUpperBounds, r.Syntax, err = trimAttributeSyntaxMUB(tkz.nextToken())
+		if r.MinUpperBounds, r.Syntax, err = trimAttributeSyntaxMUB(tkz.nextToken()); err != nil {
+			err = tkz.parseError(`numeric OID with optional {bound}`, err)
+		}
 	}
 
 	return
@@ -4890,8 +4861,7 @@ func marshalObjectClass(x any) (def *ObjectClass, err error) {
 	def = new(ObjectClass)
 	def.Extensions = make(map[int]Extension)
 
-	input = trimS(trimDefinitionLabelToken(input))
-	tkz := newSchemaTokenizer(input)
+	tkz := newDefinitionTokenizer(input)
 	tkz.startTokenParen()
 
 	def.NumericOID = tkz.this()
@@ -4923,6 +4893,10 @@ func marshalObjectClass(x any) (def *ObjectClass, err error) {
 		}
 	}
 
+	if err == nil {
+		err = tkz.err
+	}
+
 	return
 }
 
@@ -4974,8 +4948,7 @@ func marshalDITContentRule(x any) (def *DITContentRule, err error) {
 	def = new(DITContentRule)
 	def.Extensions = make(map[int]Extension)
 
-	input = trimS(trimDefinitionLabelToken(input))
-	tkz := newSchemaTokenizer(input)
+	tkz := newDefinitionTokenizer(input)
 	tkz.startTokenParen()
 
 	def.NumericOID = tkz.this()
@@ -5007,6 +4980,10 @@ func marshalDITContentRule(x any) (def *DITContentRule, err error) {
 		}
 	}
 
+	if err == nil {
+		err = tkz.err
+	}
+
 	return
 }
 
@@ -5034,8 +5011,7 @@ func marshalNameForm(x any) (def *NameForm, err error) {
 	def = new(NameForm)
 	def.Extensions = make(map[int]Extension)
 
-	input = trimS(trimDefinitionLabelToken(input))
-	tkz := newSchemaTokenizer(input)
+	tkz := newDefinitionTokenizer(input)
 	tkz.startTokenParen()
 
 	def.NumericOID = tkz.this()
@@ -5065,6 +5041,10 @@ func marshalNameForm(x any) (def *NameForm, err error) {
 		}
 	}
 
+	if err == nil {
+		err = tkz.err
+	}
+
 	return
 }
 
@@ -5105,8 +5085,7 @@ func marshalDITStructureRule(x any) (def *DITStructureRule, err error) {
 	def = new(DITStructureRule)
 	def.Extensions = make(map[int]Extension)
 
-	input = trimS(trimDefinitionLabelToken(input))
-	tkz := newSchemaTokenizer(input)
+	tkz := newDefinitionTokenizer(input)
 	tkz.startTokenParen()
 
 	def.RuleID = tkz.this()
@@ -5133,6 +5112,10 @@ func marshalDITStructureRule(x any) (def *DITStructureRule, err error) {
 		}
 	}
 
+	if err == nil {
+		err = tkz.err
+	}
+
 	return
 }
 
@@ -5168,10 +5151,39 @@ type schemaTokenizer struct {
 	input []rune
 	pos   int
 	cur   string
+
+	src   string // original (untrimmed) definition
+	base  int    // byte offset of input within src
+	start int    // rune offset of cur within input
+	err   error  // first tokenization error, if any
 }
 
 func newSchemaTokenizer(input string) *schemaTokenizer {
-	return &schemaTokenizer{input: []rune(input), pos: 0}
+	return &schemaTokenizer{input: []rune(input), pos: 0, src: input}
+}
+
+/*
+newDefinitionTokenizer returns a *schemaTokenizer for the definition
+within input, sans any leading label token (e.g.: "attributeTypes:").
+Token positions remain relative to input for error reporting.
+*/
+func newDefinitionTokenizer(input string) *schemaTokenizer {
+	def := trimS(trimDefinitionLabelToken(input))
+	tkz := newSchemaTokenizer(def)
+	tkz.src = input
+	if idx := stridx(input, def); idx != -1 {
+		tkz.base = idx
+	}
+
+	return tkz
+}
+
+/*
+parseError returns a *ParseError positioned at the current token.
+*/
+func (t *schemaTokenizer) parseError(expected string, cause error) error {
+	offset := t.base + len(string(t.input[:t.start]))
+	return newParseError(t.src, offset, expected, cause)
 }
 
 func (t *schemaTokenizer) next() bool {
@@ -5181,13 +5193,18 @@ func (t *schemaTokenizer) next() bool {
 	}
 
 	start := t.pos
+	t.start = start
 	if t.input[t.pos] == '\'' {
 		t.pos++
 		for t.pos < len(t.input) && (t.input[t.pos] != '\'' ||
 			(t.pos > start && t.input[t.pos-1] == '\\')) {
 			t.pos++
 		}
-		t.pos++
+		if t.pos < len(t.input) {
+			t.pos++
+		} else if t.err == nil {
+			t.err = t.parseError(`closing quote`, unterminatedErr)
+		}
 	} else if t.input[t.pos] == '(' || t.input[t.pos] == ')' {
 		t.pos++
 	} else {
@@ -5271,6 +5288,13 @@ func trimDefinitionLabelToken(input string) string {
 		if hasPfx(low, lc(token)) {
 			rest := input[len(token):]
 
+			// Make sure we didn't just match the leading
+			// portion of a longer token, such as the case
+			// of "matchingRule" vs. "matchingRuleUse".
+			if len(rest) > 0 && !(rest[0] == ':' || rest[0] == '(' || isSpace(rune(rest[0]))) {
+				continue
+			}
+
 			// Skip optional colon or space
 			rest = trimS(trimL(rest, ":"))
 
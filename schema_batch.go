@@ -0,0 +1,297 @@
+package dirsyn
+
+/*
+schema_batch.go contains SubschemaSubentry methods for the registration
+of batches of schema definitions in dependency order.
+*/
+
+/*
+SchemaCycleError describes a cycle of dependencies among the definitions
+submitted to [SubschemaSubentry.RegisterBatch], such as two object classes
+which are each other's superclass.
+*/
+type SchemaCycleError struct {
+	// Cycle contains the definitions which comprise the
+	// cycle, in dependency order. The first definition
+	// is repeated at the end.
+	Cycle []SchemaDefinition
+}
+
+/*
+Error returns the string representation of the receiver instance.
+*/
+func (r SchemaCycleError) Error() string {
+	path := make([]string, len(r.Cycle))
+	for i, def := range r.Cycle {
+		path[i] = def.Type() + ` '` + def.Identifier() + `'`
+	}
+
+	return `Dependency cycle: ` + join(path, ` -> `)
+}
+
+/*
+RegisterBatch returns an error following an attempt to register defs, in
+which any mix of definition types may appear in any order.
+
+Each slice of defs may be a [SchemaDefinition] qualifier instance, or the
+string (or []byte) representation of a definition beginning with one (1)
+of the keywords described in [SubschemaSubentry.ReadBytes].
+
+The definitions are sorted such that each is registered only after those
+definitions within the batch upon which it depends, such as its superior
+type, class or structure rule, its SYNTAX or its matching rules. Any such
+dependency which does not appear within the batch must already have been
+registered within the receiver instance.
+
+If the input contains a dependency cycle, an instance of [SchemaCycleError]
+is returned and nothing is registered. Likewise, nothing is registered if
+any input cannot be parsed. Otherwise, registration proceeds in sorted
+order, stopping at the first failure.
+
+A [MatchingRuleUse] definition within defs is not registered on its own,
+as these are maintained automatically. Instead, its APPLIES values are
+merged into the existing instance maintained for its matching rule.
+*/
+func (r *SubschemaSubentry) RegisterBatch(defs ...any) (err error) {
+	var batch []SchemaDefinition
+	for i := 0; i < len(defs) && err == nil; i++ {
+		var def SchemaDefinition
+		switch tv := defs[i].(type) {
+		case SchemaDefinition:
+			def = tv
+		case string:
+			def, err = parseSchemaDefinition([]byte(tv))
+		case []byte:
+			def, err = parseSchemaDefinition(tv)
+		default:
+			err = errorBadType("SchemaDefinition")
+		}
+		batch = append(batch, def)
+	}
+
+	if err == nil {
+		if batch, err = sortSchemaDefinitions(batch); err == nil {
+			for i := 0; i < len(batch) && err == nil; i++ {
+				if err = r.register(batch[i]); err != nil {
+					err = errorTxt(batch[i].Identifier() + " " + err.Error())
+				}
+			}
+		}
+	}
+
+	return
+}
+
+/*
+registerMatchingRuleUse merges the APPLIES values of def into the
+*[MatchingRuleUse] maintained for the same matching rule, such that
+the order of the values in def prevails. The DESC, OBSOLETE and
+extension clauses of def are adopted if not already present.
+*/
+func (r *SubschemaSubentry) registerMatchingRuleUse(def *MatchingRuleUse) (err error) {
+	mru, idx := r.MatchingRuleUses.Get(def.NumericOID)
+	if idx == -1 {
+		err = errorTxt("matchingRuleUse: Unknown matching rule: '" +
+			def.NumericOID + "'")
+		return
+	}
+
+	// The APPLIES values of def come first, in the order
+	// given, followed by any others already present.
+	var (
+		applies []string
+		seen    = make(map[*AttributeType]bool)
+	)
+	for _, at := range def.Applies {
+		typ, idx := r.AttributeType(at)
+		if idx == -1 {
+			err = errorTxt("matchingRuleUse: Unknown APPLIES attribute type: '" +
+				at + "'")
+			return
+		} else if !seen[typ] {
+			seen[typ] = true
+			applies = append(applies, at)
+		}
+	}
+	for _, at := range mru.Applies {
+		if typ, idx := r.AttributeType(at); idx == -1 || !seen[typ] {
+			seen[typ] = true
+			applies = append(applies, at)
+		}
+	}
+
+	mru.Applies = applies
+
+	// Adopt any descriptive clauses not already
+	// present within the maintained instance.
+	if mru.Description == "" {
+		mru.Description = def.Description
+	}
+	mru.Obsolete = mru.Obsolete || def.Obsolete
+	if mru.Extensions == nil {
+		mru.Extensions = make(map[int]Extension)
+	}
+	for _, ext := range orderedExtensions(def.Extensions) {
+		var found bool
+		for _, have := range mru.Extensions {
+			if found = streqf(have.XString, ext.XString); found {
+				break
+			}
+		}
+		if !found {
+			mru.Extensions[len(mru.Extensions)] = ext
+		}
+	}
+
+	return
+}
+
+/*
+schemaBatchIndex maps lowercased names and numeric OIDs (or rule IDs)
+of the definitions within a batch to their respective indices, with one
+map per namespace.
+*/
+type schemaBatchIndex map[string]map[string]int
+
+func (r schemaBatchIndex) add(ns string, idx int, keys ...string) {
+	if _, found := r[ns]; !found {
+		r[ns] = make(map[string]int)
+	}
+	for _, key := range keys {
+		if key != "" {
+			r[ns][lc(key)] = idx
+		}
+	}
+}
+
+func (r schemaBatchIndex) lookup(ns string, keys ...[]string) (deps []int) {
+	for _, slice := range keys {
+		for _, key := range slice {
+			if idx, found := r[ns][lc(key)]; found {
+				deps = append(deps, idx)
+			}
+		}
+	}
+
+	return
+}
+
+/*
+sortSchemaDefinitions returns defs sorted such that each definition
+appears after all other definitions within defs upon which it depends.
+The relative order of unrelated definitions is preserved.
+*/
+func sortSchemaDefinitions(defs []SchemaDefinition) (sorted []SchemaDefinition, err error) {
+	index := make(schemaBatchIndex)
+	for i, def := range defs {
+		switch tv := def.(type) {
+		case *LDAPSyntax:
+			index.add(`ls`, i, tv.NumericOID)
+		case *MatchingRule:
+			index.add(`mr`, i, append([]string{tv.NumericOID}, tv.Name...)...)
+		case *AttributeType:
+			index.add(`at`, i, append([]string{tv.NumericOID}, tv.Name...)...)
+		case *ObjectClass:
+			index.add(`oc`, i, append([]string{tv.NumericOID}, tv.Name...)...)
+		case *NameForm:
+			index.add(`nf`, i, append([]string{tv.NumericOID}, tv.Name...)...)
+		case *DITStructureRule:
+			index.add(`dsr`, i, append([]string{tv.RuleID}, tv.Name...)...)
+		}
+	}
+
+	deps := make([][]int, len(defs))
+	for i, def := range defs {
+		deps[i] = schemaDefinitionDeps(index, def)
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	var (
+		state = make([]int, len(defs))
+		stack []int
+		visit func(int) bool
+	)
+
+	// depth-first, post-order traversal. Definitions
+	// on the stack which are encountered again form a
+	// cycle.
+	visit = func(i int) bool {
+		switch state[i] {
+		case visited:
+			return true
+		case visiting:
+			var cycle []SchemaDefinition
+			for j := len(stack) - 1; j >= 0; j-- {
+				if stack[j] == i {
+					for _, k := range stack[j:] {
+						cycle = append(cycle, defs[k])
+					}
+					break
+				}
+			}
+			err = SchemaCycleError{Cycle: append(cycle, defs[i])}
+			return false
+		}
+
+		state[i] = visiting
+		stack = append(stack, i)
+		for _, dep := range deps[i] {
+			if dep != i && !visit(dep) {
+				return false
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[i] = visited
+		sorted = append(sorted, defs[i])
+
+		return true
+	}
+
+	for i := 0; i < len(defs); i++ {
+		if !visit(i) {
+			sorted = nil
+			break
+		}
+	}
+
+	return
+}
+
+/*
+schemaDefinitionDeps returns the indices of the definitions within a
+batch upon which def depends.
+*/
+func schemaDefinitionDeps(index schemaBatchIndex, def SchemaDefinition) (deps []int) {
+	one := func(x string) []string { return []string{x} }
+
+	switch tv := def.(type) {
+	case *MatchingRule:
+		deps = index.lookup(`ls`, one(tv.Syntax))
+	case *AttributeType:
+		deps = append(index.lookup(`ls`, one(tv.Syntax)),
+			index.lookup(`mr`, one(tv.Equality), one(tv.Ordering), one(tv.Substring))...)
+		deps = append(deps, index.lookup(`at`, one(tv.SuperType))...)
+	case *MatchingRuleUse:
+		deps = append(index.lookup(`mr`, one(tv.NumericOID)),
+			index.lookup(`at`, tv.Applies)...)
+	case *ObjectClass:
+		deps = append(index.lookup(`oc`, tv.SuperClasses),
+			index.lookup(`at`, tv.Must, tv.May)...)
+	case *DITContentRule:
+		deps = append(index.lookup(`oc`, one(tv.NumericOID), tv.Aux),
+			index.lookup(`at`, tv.Must, tv.May, tv.Not)...)
+	case *NameForm:
+		deps = append(index.lookup(`oc`, one(tv.OC)),
+			index.lookup(`at`, tv.Must, tv.May)...)
+	case *DITStructureRule:
+		deps = append(index.lookup(`nf`, one(tv.Form)),
+			index.lookup(`dsr`, tv.SuperRules)...)
+	}
+
+	return
+}
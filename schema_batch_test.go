@@ -0,0 +1,125 @@
+package dirsyn
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+/*
+This example demonstrates the means for registering definitions of any
+type in an order which does not satisfy their dependencies.
+*/
+func ExampleSubschemaSubentry_RegisterBatch() {
+	var r RFC4512
+	schema, _ := r.SubschemaSubentry()
+
+	err := schema.RegisterBatch(
+		`objectClasses: ( 2.5.6.6 NAME 'person' SUP top STRUCTURAL MUST ( sn $ cn ) )`,
+		`objectClasses: ( 2.5.6.0 NAME 'top' ABSTRACT MUST objectClass )`,
+		`attributeTypes: ( 2.5.4.4 NAME 'sn' SUP name )`,
+		`attributeTypes: ( 2.5.4.3 NAME 'cn' SUP name )`,
+		`attributeTypes: ( 2.5.4.41 NAME 'name' EQUALITY caseIgnoreMatch SYNTAX 1.3.6.1.4.1.1466.115.121.1.15 )`,
+		`attributeTypes: ( 2.5.4.0 NAME 'objectClass' SYNTAX 1.3.6.1.4.1.1466.115.121.1.38 )`,
+		`matchingRules: ( 2.5.13.2 NAME 'caseIgnoreMatch' SYNTAX 1.3.6.1.4.1.1466.115.121.1.15 )`,
+		`ldapSyntaxes: ( 1.3.6.1.4.1.1466.115.121.1.15 DESC 'Directory String' )`,
+		`ldapSyntaxes: ( 1.3.6.1.4.1.1466.115.121.1.38 DESC 'OID' )`,
+	)
+
+	fmt.Println(err, schema.ObjectClasses.Len(), schema.AttributeTypes.Len())
+	// Output: <nil> 2 4
+}
+
+func TestSubschemaSubentry_RegisterBatch(t *testing.T) {
+	var r RFC4512
+
+	for idx, strukt := range []struct {
+		Defs  []any
+		Cycle []string
+	}{
+		{
+			Defs: []any{
+				`objectClasses: ( 1.3.6.1.4.1.56521.999.10 NAME 'a' SUP b )`,
+				`objectClasses: ( 1.3.6.1.4.1.56521.999.11 NAME 'b' SUP c )`,
+				`objectClasses: ( 1.3.6.1.4.1.56521.999.12 NAME 'c' SUP a )`,
+			},
+			Cycle: []string{`a`, `b`, `c`, `a`},
+		},
+		{
+			Defs: []any{
+				`nameForms: ( 1.3.6.1.4.1.56521.999.20 NAME 'form' OC a MUST cn )`,
+				`dITStructureRules: ( 1 NAME 'one' FORM form SUP 2 )`,
+				`dITStructureRules: ( 2 NAME 'two' FORM form SUP ( 1 2 ) )`,
+			},
+			Cycle: []string{`one`, `two`, `one`},
+		},
+	} {
+		schema, _ := r.SubschemaSubentry()
+		err := schema.RegisterBatch(strukt.Defs...)
+
+		var cerr SchemaCycleError
+		if !errors.As(err, &cerr) {
+			t.Errorf("%s[%d] failed: expected SchemaCycleError, got %T (%v)",
+				t.Name(), idx, err, err)
+			continue
+		}
+
+		var got []string
+		for _, def := range cerr.Cycle {
+			got = append(got, def.Identifier())
+		}
+		if fmt.Sprint(got) != fmt.Sprint(strukt.Cycle) {
+			t.Errorf("%s[%d] failed:\nwant: %v\ngot:  %v", t.Name(), idx, strukt.Cycle, got)
+		} else if schema.ObjectClasses.Len()+schema.NameForms.Len() != 0 {
+			t.Errorf("%s[%d] failed: definitions registered despite cycle", t.Name(), idx)
+		}
+	}
+}
+
+func TestSubschemaSubentry_RegisterBatch_matchingRuleUse(t *testing.T) {
+	var r RFC4512
+	schema, _ := r.SubschemaSubentry()
+
+	err := schema.RegisterBatch(
+		`matchingRuleUse: ( 2.5.13.2 APPLIES ( cn $ sn ) )`,
+		`attributeTypes: ( 2.5.4.4 NAME 'sn' SYNTAX 1.3.6.1.4.1.1466.115.121.1.15 )`,
+		`attributeTypes: ( 2.5.4.3 NAME 'cn' EQUALITY caseIgnoreMatch SYNTAX 1.3.6.1.4.1.1466.115.121.1.15 )`,
+		`matchingRules: ( 2.5.13.2 NAME 'caseIgnoreMatch' SYNTAX 1.3.6.1.4.1.1466.115.121.1.15 )`,
+		`ldapSyntaxes: ( 1.3.6.1.4.1.1466.115.121.1.15 DESC 'Directory String' )`,
+	)
+	if err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+
+	mru, idx := schema.MatchingRuleUses.Get(`2.5.13.2`)
+	if idx == -1 {
+		t.Fatalf("%s failed: matchingRuleUse not found", t.Name())
+	} else if want := `[cn sn]`; fmt.Sprint(mru.Applies) != want {
+		t.Errorf("%s failed:\nwant: %s\ngot:  %v", t.Name(), want, mru.Applies)
+	}
+}
+
+func TestSubschemaSubentry_RegisterBatch_codecov(t *testing.T) {
+	var r RFC4512
+	schema, _ := r.SubschemaSubentry()
+
+	for idx, defs := range [][]any{
+		{3.14},
+		{`attributeTypes: ( 2.5.4.3 BOGUS )`},
+		{[]byte(`bogus`)},
+		{`attributeTypes: ( 2.5.4.3 NAME 'cn' SUP bogusType )`},
+		{`matchingRuleUse: ( 2.5.13.2 APPLIES cn )`},
+	} {
+		if err := schema.RegisterBatch(defs...); err == nil {
+			t.Errorf("%s[%d] failed: expected error, got nil", t.Name(), idx)
+		}
+	}
+
+	if err := schema.RegisterBatch(
+		`ldapSyntaxes: ( 1.3.6.1.4.1.1466.115.121.1.15 DESC 'Directory String' )`,
+		`matchingRules: ( 2.5.13.2 NAME 'caseIgnoreMatch' SYNTAX 1.3.6.1.4.1.1466.115.121.1.15 )`,
+		`matchingRuleUse: ( 2.5.13.2 APPLIES bogusType )`,
+	); err == nil {
+		t.Errorf("%s failed: expected error, got nil", t.Name())
+	}
+}
@@ -0,0 +1,213 @@
+package dirsyn
+
+/*
+schema_closure.go contains SubschemaSubentry methods for the extraction
+of self-contained subsets of a schema.
+*/
+
+/*
+Closure returns a new instance of *[SubschemaSubentry] alongside an error
+following an attempt to extract the transitive closure of the definitions
+identified by terms from the receiver instance.
+
+Each term may be the numeric OID or name (descriptor) of an [ObjectClass],
+[AttributeType] or [NameForm] registered within the receiver instance, and
+is resolved in that order.
+
+The return instance contains exactly those definitions upon which the input
+definitions depend, whether directly or indirectly:
+
+  - superior classes, and their MUST and MAY attribute types
+  - superior types, as well as syntaxes and matching rules
+  - name forms, and their MUST and MAY attribute types
+  - superior structure rules
+
+Additionally, any [DITContentRule], [NameForm] or [DITStructureRule] which
+references a class or form within the closure is included, alongside its
+own dependencies.
+
+[MatchingRuleUse] instances are maintained automatically by the return
+instance, and as such only reflect attribute types within the closure.
+
+Definitions within the return instance are copies, and may be modified
+without consequence to the receiver instance.
+*/
+func (r *SubschemaSubentry) Closure(terms ...string) (sch *SubschemaSubentry, err error) {
+	c := schemaClosure{src: r, seen: make(map[SchemaDefinition]bool)}
+
+	for _, term := range terms {
+		if oc, idx := r.ObjectClass(term); idx != -1 {
+			c.add(oc)
+		} else if at, idx := r.AttributeType(term); idx != -1 {
+			c.add(at)
+		} else if nf, idx := r.NameForm(term); idx != -1 {
+			c.add(nf)
+		} else {
+			err = errorTxt("Unknown definition: '" + term + "'")
+			return
+		}
+	}
+
+	c.addReferrers()
+
+	var r4512 RFC4512
+	if sch, err = r4512.SubschemaSubentry(); err == nil {
+		err = sch.RegisterBatch(c.definitions()...)
+	}
+
+	return
+}
+
+/*
+schemaClosure tracks the definitions found within the closure of a
+source *[SubschemaSubentry].
+*/
+type schemaClosure struct {
+	src  *SubschemaSubentry
+	seen map[SchemaDefinition]bool
+}
+
+/*
+add records def, as well as all definitions upon which it depends.
+*/
+func (r schemaClosure) add(def SchemaDefinition) {
+	if r.seen[def] {
+		return
+	}
+	r.seen[def] = true
+
+	switch tv := def.(type) {
+	case *MatchingRule:
+		r.syntax(tv.Syntax)
+	case *AttributeType:
+		r.syntax(tv.Syntax)
+		r.rules(tv.Equality, tv.Ordering, tv.Substring)
+		r.types(tv.SuperType)
+	case *ObjectClass:
+		r.classes(tv.SuperClasses...)
+		r.types(tv.Must...)
+		r.types(tv.May...)
+	case *DITContentRule:
+		r.classes(tv.NumericOID)
+		r.classes(tv.Aux...)
+		r.types(tv.Must...)
+		r.types(tv.May...)
+		r.types(tv.Not...)
+	case *NameForm:
+		r.classes(tv.OC)
+		r.types(tv.Must...)
+		r.types(tv.May...)
+	case *DITStructureRule:
+		if nf, idx := r.src.NameForm(tv.Form); idx != -1 {
+			r.add(nf)
+		}
+		for _, sup := range tv.SuperRules {
+			if dsr, idx := r.src.DITStructureRule(sup); idx != -1 {
+				r.add(dsr)
+			}
+		}
+	}
+}
+
+func (r schemaClosure) syntax(id string) {
+	if ls, idx := r.src.LDAPSyntax(id); idx != -1 {
+		r.add(ls)
+	}
+}
+
+func (r schemaClosure) rules(ids ...string) {
+	for _, id := range ids {
+		if mr, idx := r.src.MatchingRule(id); idx != -1 {
+			r.add(mr)
+		}
+	}
+}
+
+func (r schemaClosure) types(ids ...string) {
+	for _, id := range ids {
+		if at, idx := r.src.AttributeType(id); idx != -1 {
+			r.add(at)
+		}
+	}
+}
+
+func (r schemaClosure) classes(ids ...string) {
+	for _, id := range ids {
+		if oc, idx := r.src.ObjectClass(id); idx != -1 {
+			r.add(oc)
+		}
+	}
+}
+
+/*
+addReferrers records all content rules, name forms and structure rules
+which reference a class or form within the closure. As these may in turn
+introduce new classes or forms, this is repeated until nothing changes.
+*/
+func (r schemaClosure) addReferrers() {
+	for grew := true; grew; {
+		grew = false
+		refers := func(def SchemaDefinition, found bool) {
+			if found && !r.seen[def] {
+				r.add(def)
+				grew = true
+			}
+		}
+
+		for i := 0; i < r.src.DITContentRules.Len(); i++ {
+			dcr := r.src.DITContentRules.Index(i)
+			oc, idx := r.src.ObjectClass(dcr.NumericOID)
+			refers(dcr, idx != -1 && r.seen[oc])
+		}
+
+		for i := 0; i < r.src.NameForms.Len(); i++ {
+			nf := r.src.NameForms.Index(i)
+			oc, idx := r.src.ObjectClass(nf.OC)
+			refers(nf, idx != -1 && r.seen[oc])
+		}
+
+		for i := 0; i < r.src.DITStructureRules.Len(); i++ {
+			dsr := r.src.DITStructureRules.Index(i)
+			nf, idx := r.src.NameForm(dsr.Form)
+			refers(dsr, idx != -1 && r.seen[nf])
+		}
+	}
+}
+
+/*
+definitions returns the string representations of all definitions within
+the closure, each bearing its keyword, in the order in which they appear
+within the source *[SubschemaSubentry].
+*/
+func (r schemaClosure) definitions() (defs []any) {
+	src := r.src
+	emit := func(def SchemaDefinition) {
+		if r.seen[def] {
+			defs = append(defs, def.Type()+": "+def.String())
+		}
+	}
+
+	for i := 0; i < src.LDAPSyntaxes.Len(); i++ {
+		emit(src.LDAPSyntaxes.Index(i))
+	}
+	for i := 0; i < src.MatchingRules.Len(); i++ {
+		emit(src.MatchingRules.Index(i))
+	}
+	for i := 0; i < src.AttributeTypes.Len(); i++ {
+		emit(src.AttributeTypes.Index(i))
+	}
+	for i := 0; i < src.ObjectClasses.Len(); i++ {
+		emit(src.ObjectClasses.Index(i))
+	}
+	for i := 0; i < src.DITContentRules.Len(); i++ {
+		emit(src.DITContentRules.Index(i))
+	}
+	for i := 0; i < src.NameForms.Len(); i++ {
+		emit(src.NameForms.Index(i))
+	}
+	for i := 0; i < src.DITStructureRules.Len(); i++ {
+		emit(src.DITStructureRules.Index(i))
+	}
+
+	return
+}
@@ -0,0 +1,105 @@
+package dirsyn
+
+import (
+	"fmt"
+	"testing"
+)
+
+var closureTestSchema = `
+ldapSyntaxes: ( 1.3.6.1.4.1.1466.115.121.1.15 DESC 'Directory String' )
+ldapSyntaxes: ( 1.3.6.1.4.1.1466.115.121.1.38 DESC 'OID' )
+ldapSyntaxes: ( 1.3.6.1.4.1.1466.115.121.1.27 DESC 'INTEGER' )
+matchingRules: ( 2.5.13.2 NAME 'caseIgnoreMatch' SYNTAX 1.3.6.1.4.1.1466.115.121.1.15 )
+matchingRules: ( 2.5.13.0 NAME 'objectIdentifierMatch' SYNTAX 1.3.6.1.4.1.1466.115.121.1.38 )
+matchingRules: ( 2.5.13.14 NAME 'integerMatch' SYNTAX 1.3.6.1.4.1.1466.115.121.1.27 )
+attributeTypes: ( 2.5.4.0 NAME 'objectClass' EQUALITY objectIdentifierMatch SYNTAX 1.3.6.1.4.1.1466.115.121.1.38 )
+attributeTypes: ( 2.5.4.41 NAME 'name' EQUALITY caseIgnoreMatch SYNTAX 1.3.6.1.4.1.1466.115.121.1.15 )
+attributeTypes: ( 2.5.4.3 NAME 'cn' SUP name )
+attributeTypes: ( 2.5.4.4 NAME 'sn' SUP name )
+attributeTypes: ( 2.5.4.10 NAME 'o' SUP name )
+attributeTypes: ( 1.3.6.1.1.1.1.0 NAME 'uidNumber' EQUALITY integerMatch SYNTAX 1.3.6.1.4.1.1466.115.121.1.27 )
+objectClasses: ( 2.5.6.0 NAME 'top' ABSTRACT MUST objectClass )
+objectClasses: ( 2.5.6.6 NAME 'person' SUP top STRUCTURAL MUST ( sn $ cn ) )
+objectClasses: ( 2.5.6.4 NAME 'organization' SUP top STRUCTURAL MUST o )
+objectClasses: ( 1.3.6.1.1.1.2.0 NAME 'posixAccount' SUP top AUXILIARY MUST uidNumber )
+dITContentRules: ( 2.5.6.6 NAME 'personContentRule' AUX posixAccount )
+nameForms: ( 1.3.6.1.4.1.56521.999.1 NAME 'personForm' OC person MUST cn )
+nameForms: ( 1.3.6.1.4.1.56521.999.2 NAME 'orgForm' OC organization MUST o )
+dITStructureRules: ( 1 NAME 'orgRule' FORM orgForm )
+dITStructureRules: ( 2 NAME 'personRule' FORM personForm SUP 1 )
+`
+
+/*
+This example demonstrates the means for extracting the subset of a schema
+upon which a single [ObjectClass] depends.
+*/
+func ExampleSubschemaSubentry_Closure() {
+	var r RFC4512
+	schema, _ := r.SubschemaSubentry()
+	if err := schema.LoadBytes([]byte(closureTestSchema)); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	sub, err := schema.Closure(`organization`)
+	fmt.Println(err)
+	fmt.Println(sub.ObjectClasses.String())
+	// Output:
+	// <nil>
+	// objectClasses: ( 2.5.6.0 NAME 'top' ABSTRACT MUST objectClass )
+	// objectClasses: ( 2.5.6.4 NAME 'organization' SUP top STRUCTURAL MUST o )
+}
+
+func TestSubschemaSubentry_Closure(t *testing.T) {
+	var r RFC4512
+	schema, _ := r.SubschemaSubentry()
+	if err := schema.LoadBytes([]byte(closureTestSchema)); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+
+	for idx, strukt := range []struct {
+		Terms  []string
+		Counts [7]int // ls, mr, at, oc, dcr, nf, dsr
+	}{
+		{[]string{`name`}, [7]int{1, 1, 1, 0, 0, 0, 0}},
+		{[]string{`top`}, [7]int{1, 1, 1, 1, 0, 0, 0}},
+		{[]string{`organization`}, [7]int{2, 2, 3, 2, 0, 1, 1}},
+		// person pulls in its content rule (and thus posixAccount),
+		// its name form and structure rule, and thereby the superior
+		// structure rule for organizations.
+		{[]string{`person`}, [7]int{3, 3, 6, 4, 1, 2, 2}},
+		{[]string{`personForm`, `uidNumber`}, [7]int{3, 3, 6, 4, 1, 2, 2}},
+	} {
+		sub, err := schema.Closure(strukt.Terms...)
+		if err != nil {
+			t.Errorf("%s[%d] failed: %v", t.Name(), idx, err)
+			continue
+		}
+
+		got := [7]int{
+			sub.LDAPSyntaxes.Len(),
+			sub.MatchingRules.Len(),
+			sub.AttributeTypes.Len(),
+			sub.ObjectClasses.Len(),
+			sub.DITContentRules.Len(),
+			sub.NameForms.Len(),
+			sub.DITStructureRules.Len(),
+		}
+		if got != strukt.Counts {
+			t.Errorf("%s[%d] failed:\nwant: %v\ngot:  %v\n%s",
+				t.Name(), idx, strukt.Counts, got, sub)
+		}
+	}
+
+	// Make sure the closure contains copies
+	sub, _ := schema.Closure(`cn`)
+	at, _ := sub.AttributeType(`cn`)
+	at.Description = `modified`
+	if orig, _ := schema.AttributeType(`cn`); orig.Description != `` {
+		t.Errorf("%s failed: source definition modified", t.Name())
+	}
+
+	if _, err := schema.Closure(`bogus`); err == nil {
+		t.Errorf("%s failed: expected error, got nil", t.Name())
+	}
+}
@@ -0,0 +1,356 @@
+package dirsyn
+
+/*
+schema_gocode.go contains SubschemaSubentry methods for the generation of
+Go source code from object class definitions.
+*/
+
+import (
+	"go/format"
+	"math/big"
+	"time"
+)
+
+/*
+GoCodeOptions contains the options which govern the behavior of the
+[SubschemaSubentry.GoCode] method.
+*/
+type GoCodeOptions struct {
+	// Package contains the name of the package declared
+	// by the generated source. The default is "schema".
+	Package string
+
+	// Classes, if non-zero, limits generation to the
+	// object classes bearing the specified numeric OIDs
+	// or names (descriptors). Otherwise, all STRUCTURAL
+	// and AUXILIARY classes are generated.
+	Classes []string
+}
+
+/*
+GoCode returns formatted Go source code alongside an error following an
+attempt to generate a struct type for each STRUCTURAL or AUXILIARY class
+within the receiver instance, as limited by opts.
+
+Each struct contains one (1) field per attribute type returned by the
+[ObjectClass.AllMust] and [ObjectClass.AllMay] methods, tagged with the
+name of said type (e.g.: `ldap:"cn"`, or `ldap:"cn,omitempty"` if not
+mandatory). The Go type of each field is chosen by way of the effective
+syntax of the attribute type (see [AttributeType.EffectiveSyntax]):
+
+  - time.Time for Generalized Time
+  - *big.Int for INTEGER
+  - []byte for Octet String
+  - string for all others
+
+Fields associated with multi-valued attribute types bear slice types
+of the above, e.g.: []string.
+
+Each struct is also given a Validate method, which verifies the contents
+of each field against the *[SubschemaSubentry] provided at runtime by way
+of the [SubschemaSubentry.VerifyAttribute] method.
+*/
+func (r *SubschemaSubentry) GoCode(opts GoCodeOptions) (src []byte, err error) {
+	var classes []*ObjectClass
+	if len(opts.Classes) > 0 {
+		for _, term := range opts.Classes {
+			oc, idx := r.ObjectClass(term)
+			if idx == -1 {
+				err = errorTxt("Unknown object class: '" + term + "'")
+				return
+			} else if oc.Kind == 2 {
+				err = errorTxt("Cannot generate ABSTRACT object class: '" + term + "'")
+				return
+			}
+			classes = append(classes, oc)
+		}
+	} else {
+		for i := 0; i < r.ObjectClasses.Len(); i++ {
+			if oc := r.ObjectClasses.Index(i); oc.Kind != 2 {
+				classes = append(classes, oc)
+			}
+		}
+	}
+
+	pkg := opts.Package
+	if pkg == "" {
+		pkg = `schema`
+	}
+
+	var (
+		body    []string
+		imports = make(map[string]bool)
+		types   = make(map[string]bool)
+	)
+
+	for _, oc := range classes {
+		name := uniqueGoIdentifier(goIdentifier(oc.Identifier()), types)
+		body = append(body, goCodeStruct(name, oc, imports)...)
+	}
+
+	out := []string{
+		"// Code generated by dirsyn; DO NOT EDIT.",
+		"",
+		"package " + pkg,
+		"",
+		"import (",
+	}
+	for _, imp := range []string{`math/big`, `time`} {
+		if imports[imp] {
+			out = append(out, `"`+imp+`"`)
+		}
+	}
+	if len(imports) > 0 {
+		// separate standard library imports
+		out = append(out, "")
+	}
+	out = append(out, `"github.com/JesseCoretta/go-dirsyn"`, ")")
+	out = append(out, body...)
+
+	src, err = format.Source([]byte(join(out, "\n")))
+
+	return
+}
+
+/*
+goCodeStruct returns the lines of source code for the struct type and
+Validate method generated for oc. Any packages required by the fields
+are recorded within imports.
+*/
+func goCodeStruct(name string, oc *ObjectClass, imports map[string]bool) (lines []string) {
+	type goField struct {
+		name string
+		attr string
+		typ  string
+		must bool
+	}
+
+	var (
+		fields []goField
+		names  = map[string]bool{`Validate`: true}
+		seen   = make(map[*AttributeType]bool)
+	)
+
+	for _, set := range []struct {
+		types *AttributeTypes
+		must  bool
+	}{
+		{oc.AllMust(), true},
+		{oc.AllMay(), false},
+	} {
+		for i := 0; i < set.types.Len(); i++ {
+			at := set.types.Index(i)
+			if seen[at] {
+				// MUST has precedence over MAY
+				continue
+			}
+			seen[at] = true
+
+			typ, imp := goCodeType(at)
+			if imp != "" {
+				imports[imp] = true
+			}
+			fields = append(fields, goField{
+				name: uniqueGoIdentifier(goIdentifier(at.Identifier()), names),
+				attr: at.Identifier(),
+				typ:  typ,
+				must: set.must,
+			})
+		}
+	}
+
+	lines = append(lines, "",
+		"// "+name+" implements the \""+oc.Identifier()+"\""+
+			stringClassKind(oc.Kind)+" object class ("+oc.NumericOID+").")
+	if oc.Description != "" {
+		lines = append(lines, "//", "// "+oc.Description)
+	}
+	lines = append(lines, "type "+name+" struct {")
+	for _, field := range fields {
+		tag := field.attr
+		if !field.must {
+			tag += `,omitempty`
+		}
+		lines = append(lines, field.name+" "+field.typ+" `ldap:\""+tag+"\"`")
+	}
+	lines = append(lines, "}", "",
+		"// Validate returns an error following the verification of each field",
+		"// of the receiver instance against schema.",
+		"func (r "+name+") Validate(schema *dirsyn.SubschemaSubentry) (err error) {",
+		"for _, field := range []struct {",
+		"name string",
+		"must bool",
+		"value any",
+		"}{")
+	for _, field := range fields {
+		lines = append(lines, "{\""+field.attr+"\", "+bool2str(field.must)+", r."+field.name+"},")
+	}
+	lines = append(lines, "} {",
+		"if err = schema.VerifyAttribute(field.name, field.must, field.value); err != nil {",
+		"break",
+		"}",
+		"}",
+		"",
+		"return",
+		"}")
+
+	return
+}
+
+/*
+goCodeType returns the Go type for fields associated with at, alongside
+the package, if any, upon which said type depends.
+*/
+func goCodeType(at *AttributeType) (typ, imp string) {
+	typ = `string`
+	if syntax := at.EffectiveSyntax(); syntax != nil {
+		switch syntax.NumericOID {
+		case `1.3.6.1.4.1.1466.115.121.1.24`:
+			typ, imp = `time.Time`, `time`
+		case `1.3.6.1.4.1.1466.115.121.1.27`:
+			typ, imp = `*big.Int`, `math/big`
+		case `1.3.6.1.4.1.1466.115.121.1.40`:
+			typ = `[]byte`
+		}
+	}
+
+	if !at.Single {
+		typ = `[]` + typ
+	}
+
+	return
+}
+
+/*
+goIdentifier returns an exported Go identifier derived from the input
+descriptor, e.g.: "x-my-attr" becomes "XMyAttr".
+*/
+func goIdentifier(desc string) string {
+	bld := newStrBuilder()
+	upper := true
+	for _, ch := range desc {
+		switch {
+		case ('a' <= ch && ch <= 'z') || ('A' <= ch && ch <= 'Z'):
+			if upper && 'a' <= ch && ch <= 'z' {
+				ch -= 'a' - 'A'
+			}
+			upper = false
+			bld.WriteRune(ch)
+		case '0' <= ch && ch <= '9':
+			if bld.Len() == 0 {
+				bld.WriteRune('X')
+			}
+			bld.WriteRune(ch)
+			upper = true
+		default:
+			upper = true
+		}
+	}
+
+	if bld.Len() == 0 {
+		bld.WriteRune('X')
+	}
+
+	return bld.String()
+}
+
+/*
+uniqueGoIdentifier returns id, or id with a numerical suffix if already
+present within names. The return value is recorded within names.
+*/
+func uniqueGoIdentifier(id string, names map[string]bool) string {
+	unique := id
+	for i := 2; names[unique]; i++ {
+		unique = id + itoa(i)
+	}
+	names[unique] = true
+
+	return unique
+}
+
+/*
+VerifyAttribute returns an error following an attempt to verify value
+against the effective syntax of the attribute type identified by attr.
+If must is true, an error is returned if value is zero.
+
+This method is used by the Validate methods of the types generated by
+[SubschemaSubentry.GoCode], and value may therefore be a string, []byte,
+time.Time, *big.Int or slices of any of these. Instances of time.Time are
+verified in the UTC Generalized Time format.
+
+Values whose syntax is not known to this package are not verified.
+*/
+func (r *SubschemaSubentry) VerifyAttribute(attr string, must bool, value any) (err error) {
+	at, idx := r.AttributeType(attr)
+	if idx == -1 {
+		err = errorResult(ResultUndefinedAttributeType,
+			errorTxt("Unknown attribute type: '"+attr+"'"))
+		return
+	}
+
+	var values []any
+	switch tv := value.(type) {
+	case string:
+		if tv != "" {
+			values = append(values, tv)
+		}
+	case []byte:
+		if tv != nil {
+			values = append(values, tv)
+		}
+	case time.Time:
+		if !tv.IsZero() {
+			values = append(values, tv.UTC().Format(`20060102150405Z`))
+		}
+	case *big.Int:
+		if tv != nil {
+			values = append(values, tv)
+		}
+	case []string:
+		for _, v := range tv {
+			values = append(values, v)
+		}
+	case [][]byte:
+		for _, v := range tv {
+			values = append(values, v)
+		}
+	case []time.Time:
+		for _, v := range tv {
+			values = append(values, v.UTC().Format(`20060102150405Z`))
+		}
+	case []*big.Int:
+		for _, v := range tv {
+			values = append(values, v)
+		}
+	default:
+		err = errorBadType(attr)
+		return
+	}
+
+	if len(values) == 0 {
+		if must {
+			err = errorResult(ResultObjectClassViolation,
+				errorTxt(attr+": Missing MANDATORY value"))
+		}
+		return
+	} else if at.Single && len(values) > 1 {
+		err = errorResult(ResultConstraintViolation,
+			errorTxt(attr+": Multiple values for SINGLE-VALUE type"))
+		return
+	}
+
+	syntax := at.EffectiveSyntax()
+	if syntax == nil {
+		err = errorTxt(attr + ": No effective syntax")
+		return
+	}
+
+	for i := 0; i < len(values) && err == nil; i++ {
+		if syntax.Verify(values[i]).False() {
+			err = errorResult(ResultInvalidAttributeSyntax,
+				errorTxt(attr+": Value violates syntax "+syntax.NumericOID))
+		}
+	}
+
+	return
+}
@@ -0,0 +1,167 @@
+package dirsyn
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+var goCodeTestSchema = `
+ldapSyntaxes: ( 1.3.6.1.4.1.1466.115.121.1.15 DESC 'Directory String' )
+ldapSyntaxes: ( 1.3.6.1.4.1.1466.115.121.1.24 DESC 'Generalized Time' )
+ldapSyntaxes: ( 1.3.6.1.4.1.1466.115.121.1.27 DESC 'INTEGER' )
+ldapSyntaxes: ( 1.3.6.1.4.1.1466.115.121.1.40 DESC 'Octet String' )
+attributeTypes: ( 2.5.4.41 NAME 'name' SYNTAX 1.3.6.1.4.1.1466.115.121.1.15 )
+attributeTypes: ( 2.5.4.3 NAME 'cn' SUP name )
+attributeTypes: ( 1.3.6.1.4.1.56521.999.1 NAME 'x-birth-date' SYNTAX 1.3.6.1.4.1.1466.115.121.1.24 SINGLE-VALUE )
+attributeTypes: ( 1.3.6.1.4.1.56521.999.2 NAME 'shoeSize' SYNTAX 1.3.6.1.4.1.1466.115.121.1.27 SINGLE-VALUE )
+attributeTypes: ( 1.3.6.1.4.1.56521.999.3 NAME 'secret' SYNTAX 1.3.6.1.4.1.1466.115.121.1.40 )
+objectClasses: ( 2.5.6.0 NAME 'top' ABSTRACT )
+objectClasses: ( 1.3.6.1.4.1.56521.999.4 NAME 'x-being' DESC 'A living being' SUP top STRUCTURAL MUST cn MAY ( x-birth-date $ secret $ cn ) )
+objectClasses: ( 1.3.6.1.4.1.56521.999.5 NAME 'shoeWearer' SUP top AUXILIARY MAY shoeSize )
+`
+
+/*
+This example demonstrates the means for generating a Go struct type for
+a single [ObjectClass].
+*/
+func ExampleSubschemaSubentry_GoCode() {
+	var r RFC4512
+	schema, _ := r.SubschemaSubentry()
+	if err := schema.LoadBytes([]byte(goCodeTestSchema)); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	src, err := schema.GoCode(GoCodeOptions{
+		Package: `people`,
+		Classes: []string{`x-being`},
+	})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Print(string(src))
+	// Output:
+	// // Code generated by dirsyn; DO NOT EDIT.
+	//
+	// package people
+	//
+	// import (
+	// 	"time"
+	//
+	// 	"github.com/JesseCoretta/go-dirsyn"
+	// )
+	//
+	// // XBeing implements the "x-being" STRUCTURAL object class (1.3.6.1.4.1.56521.999.4).
+	// //
+	// // A living being
+	// type XBeing struct {
+	// 	Cn         []string  `ldap:"cn"`
+	// 	XBirthDate time.Time `ldap:"x-birth-date,omitempty"`
+	// 	Secret     [][]byte  `ldap:"secret,omitempty"`
+	// }
+	//
+	// // Validate returns an error following the verification of each field
+	// // of the receiver instance against schema.
+	// func (r XBeing) Validate(schema *dirsyn.SubschemaSubentry) (err error) {
+	// 	for _, field := range []struct {
+	// 		name  string
+	// 		must  bool
+	// 		value any
+	// 	}{
+	// 		{"cn", true, r.Cn},
+	// 		{"x-birth-date", false, r.XBirthDate},
+	// 		{"secret", false, r.Secret},
+	// 	} {
+	// 		if err = schema.VerifyAttribute(field.name, field.must, field.value); err != nil {
+	// 			break
+	// 		}
+	// 	}
+	//
+	// 	return
+	// }
+}
+
+func TestSubschemaSubentry_GoCode(t *testing.T) {
+	var r RFC4512
+	schema, _ := r.SubschemaSubentry()
+	if err := schema.LoadBytes([]byte(goCodeTestSchema)); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+
+	src, err := schema.GoCode(GoCodeOptions{})
+	if err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+
+	for _, want := range []string{
+		"package schema\n",
+		"\"math/big\"\n",
+		"type XBeing struct {",
+		"type ShoeWearer struct {",
+		"ShoeSize *big.Int `ldap:\"shoeSize,omitempty\"`",
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("%s failed: missing %q in:\n%s", t.Name(), want, src)
+		}
+	}
+	if strings.Contains(string(src), "type Top struct") {
+		t.Errorf("%s failed: ABSTRACT class generated", t.Name())
+	}
+
+	for _, bogus := range []string{`bogus`, `top`} {
+		if _, err = schema.GoCode(GoCodeOptions{Classes: []string{bogus}}); err == nil {
+			t.Errorf("%s failed: expected error for %s, got nil", t.Name(), bogus)
+		}
+	}
+
+	for in, want := range map[string]string{
+		`cn`:           `Cn`,
+		`x-birth-date`: `XBirthDate`,
+		`1st-value`:    `X1StValue`,
+		`-`:            `X`,
+	} {
+		if got := goIdentifier(in); got != want {
+			t.Errorf("%s failed: want %s, got %s", t.Name(), want, got)
+		}
+	}
+}
+
+func TestSubschemaSubentry_VerifyAttribute(t *testing.T) {
+	var r RFC4512
+	schema, _ := r.SubschemaSubentry()
+	if err := schema.LoadBytes([]byte(goCodeTestSchema)); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+
+	for idx, strukt := range []struct {
+		Attr  string
+		Must  bool
+		Value any
+		Valid bool
+	}{
+		{`cn`, true, []string{`Jesse`}, true},
+		{`cn`, true, []string{}, false},
+		{`cn`, false, ``, true},
+		{`cn`, true, `Jesse`, true},
+		{`x-birth-date`, false, time.Date(1980, 1, 2, 3, 4, 5, 0, time.UTC), true},
+		{`x-birth-date`, false, time.Time{}, true},
+		{`x-birth-date`, false, `not a time`, false},
+		{`x-birth-date`, false, []time.Time{time.Now(), time.Now()}, false},
+		{`shoeSize`, false, big.NewInt(11), true},
+		{`shoeSize`, false, (*big.Int)(nil), true},
+		{`shoeSize`, false, []*big.Int{big.NewInt(11)}, true},
+		{`secret`, true, [][]byte{[]byte(`hush`)}, true},
+		{`secret`, true, []byte(`hush`), true},
+		{`secret`, false, 3.14, false},
+		{`bogus`, false, ``, false},
+	} {
+		if err := schema.VerifyAttribute(strukt.Attr, strukt.Must, strukt.Value); (err == nil) != strukt.Valid {
+			t.Errorf("%s[%d] failed: want valid=%t, got %v", t.Name(), idx, strukt.Valid, err)
+		}
+	}
+}
@@ -0,0 +1,437 @@
+package dirsyn
+
+/*
+schema_graph.go contains SubschemaSubentry methods for the rendering of
+schema hierarchies as Graphviz DOT or Mermaid text.
+*/
+
+/*
+SchemaGraphs is a bitmask which selects the hierarchies rendered by the
+[SubschemaSubentry.Graph] method.
+*/
+type SchemaGraphs uint8
+
+const (
+	// ObjectClassGraph selects the object class
+	// inheritance tree.
+	ObjectClassGraph SchemaGraphs = 1 << iota
+
+	// AttributeTypeGraph selects the attribute
+	// type supertype tree.
+	AttributeTypeGraph
+
+	// DITStructureRuleGraph selects the DIT
+	// structure rule hierarchy.
+	DITStructureRuleGraph
+
+	// NameFormGraph selects name forms and the
+	// object classes to which they are bound.
+	NameFormGraph
+
+	// AllGraphs selects all of the above.
+	AllGraphs = ObjectClassGraph | AttributeTypeGraph |
+		DITStructureRuleGraph | NameFormGraph
+)
+
+/*
+SchemaGraphOptions contains the options which govern the behavior of the
+[SubschemaSubentry.Graph] method. The zero value renders all hierarchies
+as Graphviz DOT text.
+*/
+type SchemaGraphOptions struct {
+	// Mermaid, if true, results in Mermaid flowchart
+	// text rather than Graphviz DOT text.
+	Mermaid bool
+
+	// Graphs selects the hierarchies to be rendered.
+	// A value of zero is equivalent to AllGraphs.
+	Graphs SchemaGraphs
+
+	// Origin, if non-zero, limits the graph to those
+	// definitions which bear an X-ORIGIN extension
+	// value matching Origin. Case is not significant.
+	Origin string
+
+	// Root, if non-zero, is the numeric OID or name of
+	// an object class. Object classes are limited to
+	// Root and its subordinate classes, and all other
+	// definitions are limited to those which relate to
+	// said classes.
+	Root string
+
+	// KindStyles maps an object class kind (0 for
+	// STRUCTURAL, 1 for AUXILIARY and 2 for ABSTRACT)
+	// to styling text, which is used verbatim in place
+	// of the default. For DOT output, this should be a
+	// list of node attributes (e.g.: "color=red"). For
+	// Mermaid output, this should be the body of a
+	// classDef statement (e.g.: "fill:#f99").
+	KindStyles map[uint8]string
+}
+
+/*
+Graph returns the Graphviz DOT or Mermaid text rendering of the hierarchies
+within the receiver instance as selected through opts, alongside an error
+should opts name an unknown Root class.
+
+Edges always point from the subordinate definition to its superior, or
+from a name form to the class it names. Object classes are styled by kind,
+with ABSTRACT classes drawn dashed, AUXILIARY classes drawn dotted and
+STRUCTURAL classes drawn bold unless overridden through opts.
+
+Each structure rule is labeled with its rule ID and the object class named
+by its name form, while each name form is labeled with the object class to
+which it is bound, such that these relationships remain visible even when
+the classes themselves are not rendered.
+*/
+func (r *SubschemaSubentry) Graph(opts SchemaGraphOptions) (out string, err error) {
+	if opts.Graphs == 0 {
+		opts.Graphs = AllGraphs
+	}
+
+	g := &schemaGraph{opts: opts}
+	if err = g.selectClasses(r); err != nil {
+		return
+	}
+	g.selectTypes(r)
+	g.selectForms(r)
+	g.selectRules(r)
+
+	out = g.render()
+
+	return
+}
+
+type schemaGraph struct {
+	opts  SchemaGraphOptions
+	ocs   []*ObjectClass
+	ats   []*AttributeType
+	nfs   []*NameForm
+	dsrs  []*DITStructureRule
+	nodes map[string]bool
+	edges []string
+	out   []string
+}
+
+func (r *schemaGraph) write(x ...string) { r.out = append(r.out, x...) }
+
+/*
+origin returns a Boolean value indicative of whether origins satisfies
+the Origin option.
+*/
+func (r *schemaGraph) origin(origins []string) bool {
+	return r.opts.Origin == "" || strInSlice(r.opts.Origin, origins)
+}
+
+func (r *schemaGraph) selectClasses(schema *SubschemaSubentry) (err error) {
+	var root *ObjectClass
+	if r.opts.Root != "" {
+		var idx int
+		if root, idx = schema.ObjectClass(r.opts.Root); idx == -1 {
+			err = errorTxt("Unknown root object class: '" + r.opts.Root + "'")
+			return
+		}
+	}
+
+	for i := 0; i < schema.ObjectClasses.Len(); i++ {
+		oc := schema.ObjectClasses.Index(i)
+		if r.origin(oc.XOrigin()) && (root == nil || classDescends(oc, root, nil)) {
+			r.ocs = append(r.ocs, oc)
+		}
+	}
+
+	return
+}
+
+/*
+classDescends returns a Boolean value indicative of whether oc is, or
+descends from, root.
+*/
+func classDescends(oc, root *ObjectClass, seen map[*ObjectClass]bool) bool {
+	if oc == root {
+		return true
+	} else if seen == nil {
+		seen = make(map[*ObjectClass]bool)
+	}
+
+	seen[oc] = true
+	for _, sup := range oc.SuperClasses {
+		if def, idx := oc.schema.ObjectClasses.Get(sup); idx != -1 && !seen[def] {
+			if classDescends(def, root, seen) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (r *schemaGraph) selectTypes(schema *SubschemaSubentry) {
+	var used map[*AttributeType]bool
+	if r.opts.Root != "" {
+		// Only those types used by the selected classes,
+		// alongside their respective supertypes.
+		used = make(map[*AttributeType]bool)
+		for _, oc := range r.ocs {
+			for _, set := range []*AttributeTypes{oc.AllMust(), oc.AllMay()} {
+				for i := 0; i < set.Len(); i++ {
+					for at := set.Index(i); at != nil && !used[at]; at = at.SuperiorType() {
+						used[at] = true
+					}
+				}
+			}
+		}
+	}
+
+	for i := 0; i < schema.AttributeTypes.Len(); i++ {
+		at := schema.AttributeTypes.Index(i)
+		if r.origin(at.XOrigin()) && (used == nil || used[at]) {
+			r.ats = append(r.ats, at)
+		}
+	}
+}
+
+func (r *schemaGraph) selectForms(schema *SubschemaSubentry) {
+	for i := 0; i < schema.NameForms.Len(); i++ {
+		nf := schema.NameForms.Index(i)
+		if !r.origin(nf.XOrigin()) {
+			continue
+		} else if r.opts.Root != "" {
+			if oc, idx := schema.ObjectClass(nf.OC); idx == -1 || !r.hasClass(oc) {
+				continue
+			}
+		}
+		r.nfs = append(r.nfs, nf)
+	}
+}
+
+func (r *schemaGraph) selectRules(schema *SubschemaSubentry) {
+	for i := 0; i < schema.DITStructureRules.Len(); i++ {
+		dsr := schema.DITStructureRules.Index(i)
+		if !r.origin(dsr.XOrigin()) {
+			continue
+		} else if r.opts.Root != "" {
+			if nf, idx := schema.NameForm(dsr.Form); idx == -1 || !r.hasForm(nf) {
+				continue
+			}
+		}
+		r.dsrs = append(r.dsrs, dsr)
+	}
+}
+
+func (r *schemaGraph) hasClass(oc *ObjectClass) bool {
+	for _, def := range r.ocs {
+		if def == oc {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *schemaGraph) hasForm(nf *NameForm) bool {
+	for _, def := range r.nfs {
+		if def == nf {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *schemaGraph) drawn(which SchemaGraphs) bool {
+	return r.opts.Graphs&which != 0
+}
+
+/*
+render returns the final text rendering of the receiver instance.
+*/
+func (r *schemaGraph) render() string {
+	r.nodes = make(map[string]bool)
+
+	if r.opts.Mermaid {
+		r.write("flowchart BT\n")
+	} else {
+		r.write("digraph schema {\n\trankdir=BT;\n\tnode [shape=box];\n")
+	}
+
+	if r.drawn(ObjectClassGraph) {
+		r.subgraph(`objectClasses`, func() {
+			for _, oc := range r.ocs {
+				r.node(`oc`, oc.NumericOID, oc.Identifier(), r.classStyle(oc.Kind))
+			}
+		})
+		for _, oc := range r.ocs {
+			for _, sup := range oc.SuperClasses {
+				if def, idx := oc.schema.ObjectClass(sup); idx != -1 {
+					r.edge(`oc`, oc.NumericOID, `oc`, def.NumericOID, ``)
+				}
+			}
+		}
+	}
+
+	if r.drawn(AttributeTypeGraph) {
+		r.subgraph(`attributeTypes`, func() {
+			for _, at := range r.ats {
+				r.node(`at`, at.NumericOID, at.Identifier(), ``)
+			}
+		})
+		for _, at := range r.ats {
+			if sup := at.SuperiorType(); sup != nil {
+				r.edge(`at`, at.NumericOID, `at`, sup.NumericOID, ``)
+			}
+		}
+	}
+
+	if r.drawn(NameFormGraph) {
+		r.subgraph(`nameForms`, func() {
+			for _, nf := range r.nfs {
+				r.node(`nf`, nf.NumericOID, nf.Identifier()+` (`+nf.OC+`)`, ``)
+			}
+		})
+		for _, nf := range r.nfs {
+			if oc, idx := nf.schema.ObjectClass(nf.OC); idx != -1 {
+				r.edge(`nf`, nf.NumericOID, `oc`, oc.NumericOID, `OC`)
+			}
+		}
+	}
+
+	if r.drawn(DITStructureRuleGraph) {
+		r.subgraph(`dITStructureRules`, func() {
+			for _, dsr := range r.dsrs {
+				label := dsr.RuleID + `: ` + dsr.Identifier()
+				if noc := dsr.NamedObjectClass(); noc.Valid() {
+					label += ` [` + noc.Identifier() + `]`
+				}
+				r.node(`dsr`, dsr.RuleID, label, ``)
+			}
+		})
+		for _, dsr := range r.dsrs {
+			sups := dsr.SuperiorStructureRules()
+			for i := 0; i < sups.Len(); i++ {
+				r.edge(`dsr`, dsr.RuleID, `dsr`, sups.Index(i).RuleID, ``)
+			}
+			if nf, idx := dsr.schema.NameForm(dsr.Form); idx != -1 {
+				r.edge(`dsr`, dsr.RuleID, `nf`, nf.NumericOID, `FORM`)
+			}
+		}
+	}
+
+	// Edges are only drawn between nodes which were
+	// actually rendered.
+	r.write(r.edges...)
+
+	if r.opts.Mermaid {
+		if r.drawn(ObjectClassGraph) && len(r.ocs) > 0 {
+			for _, kind := range []uint8{0, 1, 2} {
+				r.write("\tclassDef " + classKindName(kind) +
+					` ` + r.kindStyle(kind) + "\n")
+			}
+		}
+	} else {
+		r.write("}\n")
+	}
+
+	return join(r.out, ``)
+}
+
+func (r *schemaGraph) subgraph(name string, body func()) {
+	if r.opts.Mermaid {
+		r.write("\tsubgraph " + name + "\n")
+		body()
+		r.write("\tend\n")
+	} else {
+		r.write("\tsubgraph cluster_" + name + " {\n\t\tlabel=\"" + name + "\";\n")
+		body()
+		r.write("\t}\n")
+	}
+}
+
+func (r *schemaGraph) node(ns, id, label, style string) {
+	key := r.nodeID(ns, id)
+	r.nodes[key] = true
+
+	if r.opts.Mermaid {
+		label = repAll(label, `"`, `#quot;`)
+		line := "\t\t" + key + `["` + label + `"]`
+		if style != "" {
+			// Mermaid styles are applied by class name.
+			line += `:::` + style
+		}
+		r.write(line + "\n")
+	} else {
+		label = repAll(repAll(label, `\`, `\\`), `"`, `\"`)
+		attrs := `label="` + label + `"`
+		if style != "" {
+			attrs += `, ` + style
+		}
+		r.write("\t\t" + key + ` [` + attrs + "];\n")
+	}
+}
+
+func (r *schemaGraph) edge(fns, from, tns, to, label string) {
+	src, dst := r.nodeID(fns, from), r.nodeID(tns, to)
+	if !r.nodes[src] || !r.nodes[dst] {
+		return
+	}
+
+	var edge string
+	if r.opts.Mermaid {
+		if label != "" {
+			edge = "\t" + src + ` -- ` + label + ` --> ` + dst + "\n"
+		} else {
+			edge = "\t" + src + ` --> ` + dst + "\n"
+		}
+	} else {
+		edge = "\t" + src + ` -> ` + dst
+		if label != "" {
+			edge += ` [label="` + label + `"]`
+		}
+		edge += ";\n"
+	}
+
+	r.edges = append(r.edges, edge)
+}
+
+/*
+nodeID returns the node identifier for id within namespace ns. Mermaid
+identifiers may not contain periods, thus these are replaced.
+*/
+func (r *schemaGraph) nodeID(ns, id string) string {
+	if r.opts.Mermaid {
+		return ns + `_` + repAll(id, `.`, `_`)
+	}
+
+	return `"` + ns + `:` + id + `"`
+}
+
+/*
+classStyle returns the node styling for an object class of the given
+kind. For Mermaid output, this is the name of the class defined through
+a classDef statement bearing the applicable kindStyle.
+*/
+func (r *schemaGraph) classStyle(kind uint8) string {
+	if r.opts.Mermaid {
+		return classKindName(kind)
+	}
+
+	return r.kindStyle(kind)
+}
+
+/*
+kindStyle returns the styling text for the given object class kind.
+*/
+func (r *schemaGraph) kindStyle(kind uint8) (style string) {
+	if style = r.opts.KindStyles[kind]; style == "" {
+		if r.opts.Mermaid {
+			style = [3]string{`stroke-width:3px`, `stroke-dasharray:2 2`,
+				`stroke-dasharray:6 4`}[kind%3]
+		} else {
+			style = [3]string{`style=bold`, `style=dotted`, `style=dashed`}[kind%3]
+		}
+	}
+
+	return
+}
+
+func classKindName(kind uint8) string {
+	return [3]string{`structural`, `auxiliary`, `abstract`}[kind%3]
+}
@@ -0,0 +1,118 @@
+package dirsyn
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+/*
+This example demonstrates the means for rendering the inheritance tree
+of an object class as Graphviz DOT text.
+*/
+func ExampleSubschemaSubentry_Graph() {
+	var r RFC4512
+	schema, _ := r.SubschemaSubentry()
+	if err := schema.LoadBytes([]byte(closureTestSchema)); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	dot, _ := schema.Graph(SchemaGraphOptions{
+		Graphs: ObjectClassGraph,
+		Root:   `top`,
+		KindStyles: map[uint8]string{
+			1: `style=filled, fillcolor=lightgrey`,
+		},
+	})
+	fmt.Print(dot)
+	// Output:
+	// digraph schema {
+	// 	rankdir=BT;
+	// 	node [shape=box];
+	// 	subgraph cluster_objectClasses {
+	// 		label="objectClasses";
+	// 		"oc:2.5.6.0" [label="top", style=dashed];
+	// 		"oc:2.5.6.6" [label="person", style=bold];
+	// 		"oc:2.5.6.4" [label="organization", style=bold];
+	// 		"oc:1.3.6.1.1.1.2.0" [label="posixAccount", style=filled, fillcolor=lightgrey];
+	// 	}
+	// 	"oc:2.5.6.6" -> "oc:2.5.6.0";
+	// 	"oc:2.5.6.4" -> "oc:2.5.6.0";
+	// 	"oc:1.3.6.1.1.1.2.0" -> "oc:2.5.6.0";
+	// }
+}
+
+func TestSubschemaSubentry_Graph(t *testing.T) {
+	var r RFC4512
+	schema, _ := r.SubschemaSubentry()
+	if err := schema.LoadBytes([]byte(closureTestSchema + `
+objectClasses: ( 1.3.6.1.4.1.56521.999.3 NAME 'custom' SUP top AUXILIARY MAY uidNumber X-ORIGIN 'Custom' )
+attributeTypes: ( 1.3.6.1.4.1.56521.999.4 NAME 'customType' SUP name X-ORIGIN 'custom' )
+`)); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+
+	for idx, strukt := range []struct {
+		Options SchemaGraphOptions
+		Want    []string
+		Not     []string
+	}{
+		{
+			Options: SchemaGraphOptions{},
+			Want: []string{
+				`"at:2.5.4.3" -> "at:2.5.4.41";`,
+				`"nf:1.3.6.1.4.1.56521.999.1" -> "oc:2.5.6.6" [label="OC"];`,
+				`"dsr:2" [label="2: personRule [person]"];`,
+				`"dsr:2" -> "dsr:1";`,
+				`"dsr:2" -> "nf:1.3.6.1.4.1.56521.999.1" [label="FORM"];`,
+			},
+		},
+		{
+			Options: SchemaGraphOptions{Mermaid: true, Root: `organization`},
+			Want: []string{
+				"flowchart BT\n",
+				`oc_2_5_6_4["organization"]:::structural`,
+				`at_2_5_4_10 --> at_2_5_4_41`,
+				`nf_1_3_6_1_4_1_56521_999_2 -- OC --> oc_2_5_6_4`,
+				`dsr_1 -- FORM --> nf_1_3_6_1_4_1_56521_999_2`,
+				"\tclassDef abstract stroke-dasharray:6 4\n",
+			},
+			Not: []string{`oc_2_5_6_0[`, `at_2_5_4_3[`, `dsr_2[`, `-- OC --> oc_2_5_6_6`},
+		},
+		{
+			Options: SchemaGraphOptions{Origin: `CUSTOM`, Graphs: ObjectClassGraph | AttributeTypeGraph},
+			Want: []string{
+				`"oc:1.3.6.1.4.1.56521.999.3" [label="custom", style=dotted];`,
+				`"at:1.3.6.1.4.1.56521.999.4" [label="customType"];`,
+			},
+			Not: []string{`"oc:2.5.6.0" [`, ` -> `, `nameForms`},
+		},
+		{
+			Options: SchemaGraphOptions{Mermaid: true, Graphs: NameFormGraph},
+			Want:    []string{`nf_1_3_6_1_4_1_56521_999_1["personForm (person)"]`},
+			Not:     []string{` --> `, `classDef`},
+		},
+	} {
+		out, err := schema.Graph(strukt.Options)
+		if err != nil {
+			t.Errorf("%s[%d] failed: %v", t.Name(), idx, err)
+			continue
+		}
+
+		for _, want := range strukt.Want {
+			if !strings.Contains(out, want) {
+				t.Errorf("%s[%d] failed: missing %q in:\n%s", t.Name(), idx, want, out)
+			}
+		}
+		for _, not := range strukt.Not {
+			if strings.Contains(out, not) {
+				t.Errorf("%s[%d] failed: unexpected %q in:\n%s", t.Name(), idx, not, out)
+			}
+		}
+	}
+
+	if _, err := schema.Graph(SchemaGraphOptions{Root: `bogus`}); err == nil {
+		t.Errorf("%s failed: expected error, got nil", t.Name())
+	}
+}
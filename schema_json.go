@@ -0,0 +1,659 @@
+package dirsyn
+
+/*
+schema_json.go contains JSON encoding and decoding methods for instances
+of SchemaDefinition and SubschemaSubentry.
+*/
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+/*
+extensionJSON is the JSON representation of an Extension.
+*/
+type extensionJSON struct {
+	XString string   `json:"xString"`
+	Values  []string `json:"values"`
+}
+
+type ldapSyntaxJSON struct {
+	NumericOID  string          `json:"numericOID"`
+	Description string          `json:"description,omitempty"`
+	Extensions  []extensionJSON `json:"extensions,omitempty"`
+}
+
+type matchingRuleJSON struct {
+	NumericOID  string          `json:"numericOID"`
+	Name        []string        `json:"name,omitempty"`
+	Description string          `json:"description,omitempty"`
+	Obsolete    bool            `json:"obsolete,omitempty"`
+	Syntax      string          `json:"syntax"`
+	Extensions  []extensionJSON `json:"extensions,omitempty"`
+}
+
+type attributeTypeJSON struct {
+	NumericOID         string          `json:"numericOID"`
+	Name               []string        `json:"name,omitempty"`
+	Description        string          `json:"description,omitempty"`
+	Obsolete           bool            `json:"obsolete,omitempty"`
+	SuperType          string          `json:"superType,omitempty"`
+	Equality           string          `json:"equality,omitempty"`
+	Ordering           string          `json:"ordering,omitempty"`
+	Substring          string          `json:"substring,omitempty"`
+	Syntax             string          `json:"syntax,omitempty"`
+	MinUpperBounds     uint            `json:"minUpperBounds,omitempty"`
+	Single             bool            `json:"singleValue,omitempty"`
+	Collective         bool            `json:"collective,omitempty"`
+	NoUserModification bool            `json:"noUserModification,omitempty"`
+	Usage              string          `json:"usage,omitempty"`
+	Extensions         []extensionJSON `json:"extensions,omitempty"`
+}
+
+type matchingRuleUseJSON struct {
+	NumericOID  string          `json:"numericOID"`
+	Name        []string        `json:"name,omitempty"`
+	Description string          `json:"description,omitempty"`
+	Obsolete    bool            `json:"obsolete,omitempty"`
+	Applies     []string        `json:"applies"`
+	Extensions  []extensionJSON `json:"extensions,omitempty"`
+}
+
+type objectClassJSON struct {
+	NumericOID   string          `json:"numericOID"`
+	Name         []string        `json:"name,omitempty"`
+	Description  string          `json:"description,omitempty"`
+	Obsolete     bool            `json:"obsolete,omitempty"`
+	SuperClasses []string        `json:"superClasses,omitempty"`
+	Kind         string          `json:"kind"`
+	Must         []string        `json:"must,omitempty"`
+	May          []string        `json:"may,omitempty"`
+	Extensions   []extensionJSON `json:"extensions,omitempty"`
+}
+
+type dITContentRuleJSON struct {
+	NumericOID  string          `json:"numericOID"`
+	Name        []string        `json:"name,omitempty"`
+	Description string          `json:"description,omitempty"`
+	Obsolete    bool            `json:"obsolete,omitempty"`
+	Aux         []string        `json:"aux,omitempty"`
+	Must        []string        `json:"must,omitempty"`
+	May         []string        `json:"may,omitempty"`
+	Not         []string        `json:"not,omitempty"`
+	Extensions  []extensionJSON `json:"extensions,omitempty"`
+}
+
+type nameFormJSON struct {
+	NumericOID  string          `json:"numericOID"`
+	Name        []string        `json:"name,omitempty"`
+	Description string          `json:"description,omitempty"`
+	Obsolete    bool            `json:"obsolete,omitempty"`
+	OC          string          `json:"oc"`
+	Must        []string        `json:"must"`
+	May         []string        `json:"may,omitempty"`
+	Extensions  []extensionJSON `json:"extensions,omitempty"`
+}
+
+type dITStructureRuleJSON struct {
+	RuleID      string          `json:"ruleID"`
+	Name        []string        `json:"name,omitempty"`
+	Description string          `json:"description,omitempty"`
+	Obsolete    bool            `json:"obsolete,omitempty"`
+	Form        string          `json:"form"`
+	SuperRules  []string        `json:"superRules,omitempty"`
+	Extensions  []extensionJSON `json:"extensions,omitempty"`
+}
+
+/*
+subschemaSubentryJSON is the JSON representation of a SubschemaSubentry.
+*/
+type subschemaSubentryJSON struct {
+	LDAPSyntaxes      []*LDAPSyntax       `json:"ldapSyntaxes,omitempty"`
+	MatchingRules     []*MatchingRule     `json:"matchingRules,omitempty"`
+	AttributeTypes    []*AttributeType    `json:"attributeTypes,omitempty"`
+	MatchingRuleUses  []*MatchingRuleUse  `json:"matchingRuleUses,omitempty"`
+	ObjectClasses     []*ObjectClass      `json:"objectClasses,omitempty"`
+	DITContentRules   []*DITContentRule   `json:"dITContentRules,omitempty"`
+	NameForms         []*NameForm         `json:"nameForms,omitempty"`
+	DITStructureRules []*DITStructureRule `json:"dITStructureRules,omitempty"`
+}
+
+/*
+orderedExtensions returns the values of exts ordered by key.
+*/
+func orderedExtensions(exts map[int]Extension) (out []Extension) {
+	keys := make([]int, 0, len(exts))
+	for key := range exts {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+
+	for _, key := range keys {
+		out = append(out, exts[key])
+	}
+
+	return
+}
+
+/*
+marshalExtensionsJSON returns the JSON representation of exts, ordered
+by key.
+*/
+func marshalExtensionsJSON(exts map[int]Extension) (out []extensionJSON) {
+	for _, ext := range orderedExtensions(exts) {
+		out = append(out, extensionJSON(ext))
+	}
+
+	return
+}
+
+/*
+unmarshalExtensionsJSON returns the map of Extension instances from the
+JSON representation in exts.
+*/
+func unmarshalExtensionsJSON(exts []extensionJSON) (out map[int]Extension, err error) {
+	out = make(map[int]Extension)
+	for i := 0; i < len(exts) && err == nil; i++ {
+		if !hasPfx(exts[i].XString, `X-`) || len(exts[i].Values) == 0 {
+			err = errorTxt("Invalid extension: '" + exts[i].XString + "'")
+		} else {
+			out[i] = Extension{XString: exts[i].XString, Values: exts[i].Values}
+		}
+	}
+
+	return
+}
+
+/*
+unmarshalDefinitionJSON decodes data into the JSON mirror value, after
+which populate is called to transfer the contents into def. An error is
+returned if def is not valid thereafter.
+*/
+func unmarshalDefinitionJSON(data []byte, mirror any, def SchemaDefinition, populate func() error) (err error) {
+	if err = json.Unmarshal(data, mirror); err == nil {
+		if err = populate(); err == nil && !def.Valid() {
+			err = errorTxt(def.Type() + ": Invalid definition")
+		}
+	}
+
+	return
+}
+
+/*
+MarshalJSON returns the JSON representation of the receiver instance
+alongside an error, if any. For example:
+
+	{"numericOID":"1.3.6.1.4.1.1466.115.121.1.15","description":"Directory String","extensions":[{"xString":"X-ORIGIN","values":["RFC4517"]}]}
+*/
+func (r LDAPSyntax) MarshalJSON() ([]byte, error) {
+	return json.Marshal(ldapSyntaxJSON{
+		NumericOID:  r.NumericOID,
+		Description: r.Description,
+		Extensions:  marshalExtensionsJSON(r.Extensions),
+	})
+}
+
+/*
+UnmarshalJSON returns an error following an attempt to decode data, as
+produced by [LDAPSyntax.MarshalJSON], into the receiver instance.
+*/
+func (r *LDAPSyntax) UnmarshalJSON(data []byte) error {
+	var m ldapSyntaxJSON
+	return unmarshalDefinitionJSON(data, &m, r, func() (err error) {
+		*r = LDAPSyntax{NumericOID: m.NumericOID, Description: m.Description}
+		r.Extensions, err = unmarshalExtensionsJSON(m.Extensions)
+		return
+	})
+}
+
+/*
+MarshalJSON returns the JSON representation of the receiver instance
+alongside an error, if any. For example:
+
+	{"numericOID":"2.5.13.2","name":["caseIgnoreMatch"],"syntax":"1.3.6.1.4.1.1466.115.121.1.15"}
+*/
+func (r MatchingRule) MarshalJSON() ([]byte, error) {
+	return json.Marshal(matchingRuleJSON{
+		NumericOID:  r.NumericOID,
+		Name:        r.Name,
+		Description: r.Description,
+		Obsolete:    r.Obsolete,
+		Syntax:      r.Syntax,
+		Extensions:  marshalExtensionsJSON(r.Extensions),
+	})
+}
+
+/*
+UnmarshalJSON returns an error following an attempt to decode data, as
+produced by [MatchingRule.MarshalJSON], into the receiver instance.
+*/
+func (r *MatchingRule) UnmarshalJSON(data []byte) error {
+	var m matchingRuleJSON
+	return unmarshalDefinitionJSON(data, &m, r, func() (err error) {
+		*r = MatchingRule{
+			NumericOID:  m.NumericOID,
+			Name:        m.Name,
+			Description: m.Description,
+			Obsolete:    m.Obsolete,
+			Syntax:      m.Syntax,
+		}
+		r.Extensions, err = unmarshalExtensionsJSON(m.Extensions)
+		return
+	})
+}
+
+/*
+MarshalJSON returns the JSON representation of the receiver instance
+alongside an error, if any. For example:
+
+	{"numericOID":"2.5.4.3","name":["cn","commonName"],"superType":"name"}
+*/
+func (r AttributeType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(attributeTypeJSON{
+		NumericOID:         r.NumericOID,
+		Name:               r.Name,
+		Description:        r.Description,
+		Obsolete:           r.Obsolete,
+		SuperType:          r.SuperType,
+		Equality:           r.Equality,
+		Ordering:           r.Ordering,
+		Substring:          r.Substring,
+		Syntax:             r.Syntax,
+		MinUpperBounds:     r.MinUpperBounds,
+		Single:             r.Single,
+		Collective:         r.Collective,
+		NoUserModification: r.NoUserModification,
+		Usage:              r.Usage,
+		Extensions:         marshalExtensionsJSON(r.Extensions),
+	})
+}
+
+/*
+UnmarshalJSON returns an error following an attempt to decode data, as
+produced by [AttributeType.MarshalJSON], into the receiver instance.
+*/
+func (r *AttributeType) UnmarshalJSON(data []byte) error {
+	var m attributeTypeJSON
+	return unmarshalDefinitionJSON(data, &m, r, func() (err error) {
+		*r = AttributeType{
+			NumericOID:         m.NumericOID,
+			Name:               m.Name,
+			Description:        m.Description,
+			Obsolete:           m.Obsolete,
+			SuperType:          m.SuperType,
+			Equality:           m.Equality,
+			Ordering:           m.Ordering,
+			Substring:          m.Substring,
+			Syntax:             m.Syntax,
+			MinUpperBounds:     m.MinUpperBounds,
+			Single:             m.Single,
+			Collective:         m.Collective,
+			NoUserModification: m.NoUserModification,
+			Usage:              m.Usage,
+		}
+		r.Extensions, err = unmarshalExtensionsJSON(m.Extensions)
+		return
+	})
+}
+
+/*
+MarshalJSON returns the JSON representation of the receiver instance
+alongside an error, if any. For example:
+
+	{"numericOID":"2.5.13.2","name":["caseIgnoreMatch"],"applies":["cn","sn"]}
+*/
+func (r MatchingRuleUse) MarshalJSON() ([]byte, error) {
+	return json.Marshal(matchingRuleUseJSON{
+		NumericOID:  r.NumericOID,
+		Name:        r.Name,
+		Description: r.Description,
+		Obsolete:    r.Obsolete,
+		Applies:     r.Applies,
+		Extensions:  marshalExtensionsJSON(r.Extensions),
+	})
+}
+
+/*
+UnmarshalJSON returns an error following an attempt to decode data, as
+produced by [MatchingRuleUse.MarshalJSON], into the receiver instance.
+*/
+func (r *MatchingRuleUse) UnmarshalJSON(data []byte) error {
+	var m matchingRuleUseJSON
+	return unmarshalDefinitionJSON(data, &m, r, func() (err error) {
+		*r = MatchingRuleUse{
+			NumericOID:  m.NumericOID,
+			Name:        m.Name,
+			Description: m.Description,
+			Obsolete:    m.Obsolete,
+			Applies:     m.Applies,
+		}
+		r.Extensions, err = unmarshalExtensionsJSON(m.Extensions)
+		return
+	})
+}
+
+/*
+MarshalJSON returns the JSON representation of the receiver instance
+alongside an error, if any. For example:
+
+	{"numericOID":"2.5.6.6","name":["person"],"superClasses":["top"],"kind":"STRUCTURAL","must":["sn","cn"]}
+*/
+func (r ObjectClass) MarshalJSON() ([]byte, error) {
+	return json.Marshal(objectClassJSON{
+		NumericOID:   r.NumericOID,
+		Name:         r.Name,
+		Description:  r.Description,
+		Obsolete:     r.Obsolete,
+		SuperClasses: r.SuperClasses,
+		Kind:         trimL(stringClassKind(r.Kind), ` `),
+		Must:         r.Must,
+		May:          r.May,
+		Extensions:   marshalExtensionsJSON(r.Extensions),
+	})
+}
+
+/*
+UnmarshalJSON returns an error following an attempt to decode data, as
+produced by [ObjectClass.MarshalJSON], into the receiver instance.
+
+The "kind" value may be STRUCTURAL, AUXILIARY or ABSTRACT, and defaults
+to STRUCTURAL if absent. Case is not significant.
+*/
+func (r *ObjectClass) UnmarshalJSON(data []byte) error {
+	var m objectClassJSON
+	return unmarshalDefinitionJSON(data, &m, r, func() (err error) {
+		*r = ObjectClass{
+			NumericOID:   m.NumericOID,
+			Name:         m.Name,
+			Description:  m.Description,
+			Obsolete:     m.Obsolete,
+			SuperClasses: m.SuperClasses,
+			Must:         m.Must,
+			May:          m.May,
+		}
+
+		switch uc(m.Kind) {
+		case ``, `STRUCTURAL`:
+			r.Kind = 0
+		case `AUXILIARY`:
+			r.Kind = 1
+		case `ABSTRACT`:
+			r.Kind = 2
+		default:
+			err = errorTxt("objectClass: Invalid kind: '" + m.Kind + "'")
+			return
+		}
+
+		r.Extensions, err = unmarshalExtensionsJSON(m.Extensions)
+		return
+	})
+}
+
+/*
+MarshalJSON returns the JSON representation of the receiver instance
+alongside an error, if any. For example:
+
+	{"numericOID":"2.5.6.6","name":["personContentRule"],"aux":["posixAccount"],"not":["x121Address"]}
+*/
+func (r DITContentRule) MarshalJSON() ([]byte, error) {
+	return json.Marshal(dITContentRuleJSON{
+		NumericOID:  r.NumericOID,
+		Name:        r.Name,
+		Description: r.Description,
+		Obsolete:    r.Obsolete,
+		Aux:         r.Aux,
+		Must:        r.Must,
+		May:         r.May,
+		Not:         r.Not,
+		Extensions:  marshalExtensionsJSON(r.Extensions),
+	})
+}
+
+/*
+UnmarshalJSON returns an error following an attempt to decode data, as
+produced by [DITContentRule.MarshalJSON], into the receiver instance.
+*/
+func (r *DITContentRule) UnmarshalJSON(data []byte) error {
+	var m dITContentRuleJSON
+	return unmarshalDefinitionJSON(data, &m, r, func() (err error) {
+		*r = DITContentRule{
+			NumericOID:  m.NumericOID,
+			Name:        m.Name,
+			Description: m.Description,
+			Obsolete:    m.Obsolete,
+			Aux:         m.Aux,
+			Must:        m.Must,
+			May:         m.May,
+			Not:         m.Not,
+		}
+		r.Extensions, err = unmarshalExtensionsJSON(m.Extensions)
+		return
+	})
+}
+
+/*
+MarshalJSON returns the JSON representation of the receiver instance
+alongside an error, if any. For example:
+
+	{"numericOID":"1.3.6.1.4.1.56521.999.1","name":["personForm"],"oc":"person","must":["cn"]}
+*/
+func (r NameForm) MarshalJSON() ([]byte, error) {
+	return json.Marshal(nameFormJSON{
+		NumericOID:  r.NumericOID,
+		Name:        r.Name,
+		Description: r.Description,
+		Obsolete:    r.Obsolete,
+		OC:          r.OC,
+		Must:        r.Must,
+		May:         r.May,
+		Extensions:  marshalExtensionsJSON(r.Extensions),
+	})
+}
+
+/*
+UnmarshalJSON returns an error following an attempt to decode data, as
+produced by [NameForm.MarshalJSON], into the receiver instance.
+*/
+func (r *NameForm) UnmarshalJSON(data []byte) error {
+	var m nameFormJSON
+	return unmarshalDefinitionJSON(data, &m, r, func() (err error) {
+		*r = NameForm{
+			NumericOID:  m.NumericOID,
+			Name:        m.Name,
+			Description: m.Description,
+			Obsolete:    m.Obsolete,
+			OC:          m.OC,
+			Must:        m.Must,
+			May:         m.May,
+		}
+		r.Extensions, err = unmarshalExtensionsJSON(m.Extensions)
+		return
+	})
+}
+
+/*
+MarshalJSON returns the JSON representation of the receiver instance
+alongside an error, if any. For example:
+
+	{"ruleID":"2","name":["personRule"],"form":"personForm","superRules":["1"]}
+*/
+func (r DITStructureRule) MarshalJSON() ([]byte, error) {
+	return json.Marshal(dITStructureRuleJSON{
+		RuleID:      r.RuleID,
+		Name:        r.Name,
+		Description: r.Description,
+		Obsolete:    r.Obsolete,
+		Form:        r.Form,
+		SuperRules:  r.SuperRules,
+		Extensions:  marshalExtensionsJSON(r.Extensions),
+	})
+}
+
+/*
+UnmarshalJSON returns an error following an attempt to decode data, as
+produced by [DITStructureRule.MarshalJSON], into the receiver instance.
+*/
+func (r *DITStructureRule) UnmarshalJSON(data []byte) error {
+	var m dITStructureRuleJSON
+	return unmarshalDefinitionJSON(data, &m, r, func() (err error) {
+		*r = DITStructureRule{
+			RuleID:      m.RuleID,
+			Name:        m.Name,
+			Description: m.Description,
+			Obsolete:    m.Obsolete,
+			Form:        m.Form,
+			SuperRules:  m.SuperRules,
+		}
+		r.Extensions, err = unmarshalExtensionsJSON(m.Extensions)
+		return
+	})
+}
+
+/*
+MarshalJSON returns the JSON representation of the receiver instance
+alongside an error, if any. The JSON object bears one (1) key for each
+populated collection of definitions, e.g.: "ldapSyntaxes", each bearing
+an array of definitions as produced by the MarshalJSON method of the
+relevant [SchemaDefinition] qualifier type.
+*/
+func (r SubschemaSubentry) MarshalJSON() ([]byte, error) {
+	var m subschemaSubentryJSON
+	if r.LDAPSyntaxes != nil {
+		m.LDAPSyntaxes = r.LDAPSyntaxes.defs
+	}
+	if r.MatchingRules != nil {
+		m.MatchingRules = r.MatchingRules.defs
+	}
+	if r.AttributeTypes != nil {
+		m.AttributeTypes = r.AttributeTypes.defs
+	}
+	if r.MatchingRuleUses != nil {
+		m.MatchingRuleUses = r.MatchingRuleUses.defs
+	}
+	if r.ObjectClasses != nil {
+		m.ObjectClasses = r.ObjectClasses.defs
+	}
+	if r.DITContentRules != nil {
+		m.DITContentRules = r.DITContentRules.defs
+	}
+	if r.NameForms != nil {
+		m.NameForms = r.NameForms.defs
+	}
+	if r.DITStructureRules != nil {
+		m.DITStructureRules = r.DITStructureRules.defs
+	}
+
+	return json.Marshal(m)
+}
+
+/*
+UnmarshalJSON returns an error following an attempt to decode data, as
+produced by [SubschemaSubentry.MarshalJSON], and to register the decoded
+definitions within the receiver instance.
+
+Definitions are registered by way of [SubschemaSubentry.RegisterBatch],
+thus they may appear in any order, and are subject to the same checks as
+those performed by the relevant Register method. Definitions identical to
+those already registered within the receiver instance, such as primed
+syntaxes and matching rules, are silently skipped.
+
+If the receiver instance is a zero instance of [SubschemaSubentry], it is
+initialized as if by [RFC4512.SubschemaSubentry] without priming.
+*/
+func (r *SubschemaSubentry) UnmarshalJSON(data []byte) (err error) {
+	var m subschemaSubentryJSON
+	if err = json.Unmarshal(data, &m); err != nil {
+		return
+	}
+
+	if r.LDAPSyntaxes == nil {
+		var r4512 RFC4512
+		sch, _ := r4512.SubschemaSubentry()
+		*r = *sch
+		r.LDAPSyntaxes.setSchema(r)
+		r.MatchingRules.setSchema(r)
+		r.AttributeTypes.setSchema(r)
+		r.MatchingRuleUses.setSchema(r)
+		r.ObjectClasses.setSchema(r)
+		r.DITContentRules.setSchema(r)
+		r.NameForms.setSchema(r)
+		r.DITStructureRules.setSchema(r)
+	}
+
+	var defs []any
+	add := func(def SchemaDefinition) {
+		if !r.registered(def) {
+			defs = append(defs, def)
+		}
+	}
+
+	for _, def := range m.LDAPSyntaxes {
+		add(def)
+	}
+	for _, def := range m.MatchingRules {
+		add(def)
+	}
+	for _, def := range m.AttributeTypes {
+		add(def)
+	}
+	for _, def := range m.MatchingRuleUses {
+		add(def)
+	}
+	for _, def := range m.ObjectClasses {
+		add(def)
+	}
+	for _, def := range m.DITContentRules {
+		add(def)
+	}
+	for _, def := range m.NameForms {
+		add(def)
+	}
+	for _, def := range m.DITStructureRules {
+		add(def)
+	}
+
+	return r.RegisterBatch(defs...)
+}
+
+/*
+registered returns a Boolean value indicative of whether a definition
+identical to def is already registered within the receiver instance.
+[MatchingRuleUse] instances are never considered registered, as these
+are merged rather than registered.
+*/
+func (r *SubschemaSubentry) registered(def SchemaDefinition) bool {
+	var found SchemaDefinition
+	switch tv := def.(type) {
+	case *LDAPSyntax:
+		if d, idx := r.LDAPSyntax(tv.NumericOID); idx != -1 {
+			found = d
+		}
+	case *MatchingRule:
+		if d, idx := r.MatchingRule(tv.NumericOID); idx != -1 {
+			found = d
+		}
+	case *AttributeType:
+		if d, idx := r.AttributeType(tv.NumericOID); idx != -1 {
+			found = d
+		}
+	case *ObjectClass:
+		if d, idx := r.ObjectClass(tv.NumericOID); idx != -1 {
+			found = d
+		}
+	case *DITContentRule:
+		if d, idx := r.DITContentRule(tv.NumericOID); idx != -1 {
+			found = d
+		}
+	case *NameForm:
+		if d, idx := r.NameForm(tv.NumericOID); idx != -1 {
+			found = d
+		}
+	case *DITStructureRule:
+		if d, idx := r.DITStructureRule(tv.RuleID); idx != -1 {
+			found = d
+		}
+	}
+
+	return found != nil && found.String() == def.String()
+}
@@ -0,0 +1,134 @@
+package dirsyn
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+/*
+This example demonstrates the JSON encoding of a single [ObjectClass].
+*/
+func ExampleObjectClass_MarshalJSON() {
+	oc, _ := marshalObjectClass(`( 2.5.6.6 NAME 'person' SUP top STRUCTURAL
+		MUST ( sn $ cn ) MAY description X-ORIGIN 'RFC4519' )`)
+
+	data, _ := json.Marshal(oc)
+	fmt.Println(string(data))
+	// Output: {"numericOID":"2.5.6.6","name":["person"],"superClasses":["top"],"kind":"STRUCTURAL","must":["sn","cn"],"may":["description"],"extensions":[{"xString":"X-ORIGIN","values":["RFC4519"]}]}
+}
+
+/*
+This example demonstrates the means for decoding the JSON representation
+of a schema into an instance of *[SubschemaSubentry], during which each
+definition is subjected to the usual dependency checks.
+*/
+func ExampleSubschemaSubentry_UnmarshalJSON() {
+	var schema SubschemaSubentry
+	err := json.Unmarshal([]byte(`{
+		"attributeTypes":[{"numericOID":"2.5.4.3","name":["cn"],"superType":"name"}],
+		"ldapSyntaxes":[{"numericOID":"1.3.6.1.4.1.1466.115.121.1.15","description":"Directory String"}]
+	}`), &schema)
+	fmt.Println(err)
+	// Output: cn attributeType: Unknown SUP (supertype): 'name'
+}
+
+func TestSubschemaSubentry_JSON(t *testing.T) {
+	var r RFC4512
+	schema, _ := r.SubschemaSubentry()
+	if err := schema.LoadBytes([]byte(closureTestSchema + `
+matchingRuleUse: ( 2.5.13.2 DESC 'caseIgnoreMatch use' APPLIES ( cn $ sn ) X-ORIGIN 'test' )
+attributeTypes: ( 1.3.6.1.4.1.56521.999.5 NAME ( 'x-test' 'x-test-alias' ) DESC 'test type'
+	OBSOLETE SUP name EQUALITY caseIgnoreMatch SYNTAX 1.3.6.1.4.1.1466.115.121.1.15{64}
+	SINGLE-VALUE NO-USER-MODIFICATION USAGE directoryOperation X-ORIGIN ( 'a' 'b' ) X-FOO 'bar' )
+`)); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+
+	data, err := json.Marshal(schema)
+	if err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+
+	var decoded SubschemaSubentry
+	if err = json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("%s failed: %v\n%s", t.Name(), err, data)
+	}
+
+	for idx, pair := range [][2]fmt.Stringer{
+		{schema.LDAPSyntaxes, decoded.LDAPSyntaxes},
+		{schema.MatchingRules, decoded.MatchingRules},
+		{schema.AttributeTypes, decoded.AttributeTypes},
+		{schema.MatchingRuleUses, decoded.MatchingRuleUses},
+		{schema.ObjectClasses, decoded.ObjectClasses},
+		{schema.DITContentRules, decoded.DITContentRules},
+		{schema.NameForms, decoded.NameForms},
+		{schema.DITStructureRules, decoded.DITStructureRules},
+	} {
+		if want, got := pair[0].String(), pair[1].String(); want != got {
+			t.Errorf("%s[%d] failed:\nwant: %s\ngot:  %s", t.Name(), idx, want, got)
+		}
+	}
+
+	// decoded definitions must belong to the decoded schema
+	if at, _ := decoded.AttributeType(`cn`); at.EffectiveSyntax() == nil {
+		t.Errorf("%s failed: decoded definition not bound to schema", t.Name())
+	}
+
+	// Unmarshaling the same data again should be a no-op,
+	// as all definitions are identical.
+	if err = json.Unmarshal(data, &decoded); err != nil {
+		t.Errorf("%s failed: %v", t.Name(), err)
+	}
+}
+
+func TestSchemaDefinition_JSON_codecov(t *testing.T) {
+	for idx, strukt := range []struct {
+		Def  SchemaDefinition
+		JSON string
+	}{
+		{&LDAPSyntax{}, `{"numericOID":"1.2.3","description":"test"}`},
+		{&MatchingRule{}, `{"numericOID":"1.2.3","name":["testMatch"],"syntax":"1.2.4"}`},
+		{&AttributeType{}, `{"numericOID":"1.2.3","name":["test"],"syntax":"1.2.4","singleValue":true}`},
+		{&MatchingRuleUse{}, `{"numericOID":"1.2.3","applies":["cn"]}`},
+		{&ObjectClass{}, `{"numericOID":"1.2.3","name":["test"],"kind":"AUXILIARY","may":["cn"]}`},
+		{&ObjectClass{}, `{"numericOID":"1.2.3","kind":"ABSTRACT"}`},
+		{&DITContentRule{}, `{"numericOID":"1.2.3","aux":["a"],"not":["cn"]}`},
+		{&NameForm{}, `{"numericOID":"1.2.3","oc":"person","must":["cn"]}`},
+		{&DITStructureRule{}, `{"ruleID":"2","form":"personForm","superRules":["1"],"extensions":[{"xString":"X-ORIGIN","values":["test"]}]}`},
+	} {
+		if err := json.Unmarshal([]byte(strukt.JSON), strukt.Def); err != nil {
+			t.Errorf("%s[%d] failed: %v", t.Name(), idx, err)
+		} else if data, err := json.Marshal(strukt.Def); err != nil {
+			t.Errorf("%s[%d] failed: %v", t.Name(), idx, err)
+		} else if got := string(data); got != strukt.JSON && idx != 4 && idx != 5 {
+			t.Errorf("%s[%d] failed:\nwant: %s\ngot:  %s", t.Name(), idx, strukt.JSON, got)
+		}
+	}
+
+	for idx, strukt := range []struct {
+		Def  SchemaDefinition
+		JSON string
+	}{
+		{&LDAPSyntax{}, `{"numericOID":"bogus"}`},
+		{&MatchingRule{}, `[]`},
+		{&AttributeType{}, `{"numericOID":"1.2.3","extensions":[{"xString":"BOGUS","values":["x"]}]}`},
+		{&MatchingRuleUse{}, `{"numericOID":"1.2.3","extensions":[{"xString":"X-EMPTY"}]}`},
+		{&ObjectClass{}, `{"numericOID":"1.2.3","kind":"BOGUS"}`},
+		{&DITContentRule{}, `{"numericOID":""}`},
+		{&NameForm{}, `{"numericOID":"1.2.3"}`},
+		{&DITStructureRule{}, `{"ruleID":"x"}`},
+	} {
+		if err := json.Unmarshal([]byte(strukt.JSON), strukt.Def); err == nil {
+			t.Errorf("%s[%d] failed: expected error, got nil", t.Name(), idx)
+		}
+	}
+
+	var schema SubschemaSubentry
+	if err := json.Unmarshal([]byte(`{"ldapSyntaxes":{}}`), &schema); err == nil {
+		t.Errorf("%s failed: expected error, got nil", t.Name())
+	}
+	if data, err := json.Marshal(SubschemaSubentry{}); err != nil || string(data) != `{}` {
+		t.Errorf("%s failed: unexpected result %s (%v)", t.Name(), data, err)
+	}
+}
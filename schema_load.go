@@ -0,0 +1,401 @@
+package dirsyn
+
+/*
+schema_load.go contains SubschemaSubentry methods for the collective
+loading of schema definitions, in which all errors are gathered rather
+than just the first.
+*/
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sort"
+)
+
+/*
+SchemaLoadError describes the failure of a single schema definition to
+be parsed or registered during a call to [SubschemaSubentry.LoadDirectory],
+[SubschemaSubentry.LoadFiles] or [SubschemaSubentry.LoadBytes].
+*/
+type SchemaLoadError struct {
+	// File contains the path of the file in which the
+	// offending definition appears. It is zero if the
+	// definition did not originate from a file.
+	File string
+
+	// Line contains the one-based line number at which
+	// the offending definition begins.
+	Line int
+
+	// Identifier contains the descriptor or numeric OID
+	// (or rule ID) of the offending definition, or the
+	// leading token of the definition if it could not be
+	// parsed.
+	Identifier string
+
+	// Err describes the nature of the failure.
+	Err error
+}
+
+/*
+Error returns the string representation of the receiver instance, which
+includes the file, line and identifier of the offending definition.
+*/
+func (r SchemaLoadError) Error() (s string) {
+	if r.File != "" {
+		s = r.File + `:`
+	}
+	s += itoa(r.Line) + `: `
+	if r.Identifier != "" {
+		s += r.Identifier + `: `
+	}
+	if r.Err != nil {
+		s += r.Err.Error()
+	}
+
+	return
+}
+
+/*
+Unwrap returns the underlying error of the receiver instance.
+*/
+func (r SchemaLoadError) Unwrap() error { return r.Err }
+
+/*
+SchemaLoadErrors contains slices of [SchemaLoadError], ordered by file
+and line.
+*/
+type SchemaLoadErrors []SchemaLoadError
+
+/*
+Error returns the string representation of the receiver instance, with
+each [SchemaLoadError] appearing on a line of its own.
+*/
+func (r SchemaLoadErrors) Error() string {
+	msgs := make([]string, len(r))
+	for i := 0; i < len(r); i++ {
+		msgs[i] = r[i].Error()
+	}
+
+	return join(msgs, "\n")
+}
+
+/*
+Unwrap returns slices of the underlying errors of the receiver instance,
+for use with [errors.Is] and [errors.As].
+*/
+func (r SchemaLoadErrors) Unwrap() []error {
+	errs := make([]error, len(r))
+	for i := 0; i < len(r); i++ {
+		errs[i] = r[i]
+	}
+
+	return errs
+}
+
+/*
+schemaChunk contains a single (condensed) schema definition alongside
+the file and line from which it originated.
+*/
+type schemaChunk struct {
+	file string
+	line int
+	def  []byte
+}
+
+/*
+LoadDirectory returns an error following an attempt to load all schema
+definitions found within files bearing the ".schema" extension at, or
+beneath, dir.
+
+Unlike [SubschemaSubentry.ReadDirectory], loading does not stop upon the
+first bad definition. All definitions are parsed, after which those which
+failed registration -- perhaps due to a dependency defined later in the
+same file, or in a subsequent file -- are retried until no further
+progress is possible. As such, file ordering is not significant.
+
+If any definitions could not be loaded, the error returned is an instance
+of [SchemaLoadErrors], each describing the file, line and identifier of an
+offending definition. All other definitions will have been registered.
+*/
+func (r *SubschemaSubentry) LoadDirectory(dir string) (err error) {
+	dir = trimR(dir, `/`)
+
+	var files []string
+	if _, err = ostat(dir); err == nil {
+		err = filepath.Walk(dir, func(p string, d fs.FileInfo, err error) error {
+			if err == nil && !d.IsDir() && hasSfx(d.Name(), ".schema") {
+				files = append(files, p)
+			}
+
+			return err
+		})
+	}
+
+	if err == nil {
+		err = r.LoadFiles(files...)
+	}
+
+	return
+}
+
+/*
+LoadFiles returns an error following an attempt to load all schema
+definitions found within the specified files, each of which MUST bear
+the ".schema" extension.
+
+See [SubschemaSubentry.LoadDirectory] for details regarding the loading
+process, and the error returned.
+*/
+func (r *SubschemaSubentry) LoadFiles(files ...string) error {
+	var (
+		chunks []schemaChunk
+		errs   SchemaLoadErrors
+	)
+
+	for _, file := range files {
+		if !hasSfx(file, `.schema`) {
+			errs = append(errs, SchemaLoadError{File: file,
+				Err: errorTxt("Filename MUST end in `.schema`")})
+		} else if data, err := readFile(file); err != nil {
+			errs = append(errs, SchemaLoadError{File: file, Err: err})
+		} else {
+			chunks = append(chunks, splitSchemaDefinitions(file, data)...)
+		}
+	}
+
+	return r.loadSchemaChunks(chunks, errs)
+}
+
+/*
+LoadBytes returns an error following an attempt to load all schema
+definitions found within data, which is formatted in the manner expected
+by [SubschemaSubentry.ReadBytes].
+
+See [SubschemaSubentry.LoadDirectory] for details regarding the loading
+process, and the error returned.
+*/
+func (r *SubschemaSubentry) LoadBytes(data []byte) error {
+	return r.loadSchemaChunks(splitSchemaDefinitions(``, data), nil)
+}
+
+func (r *SubschemaSubentry) loadSchemaChunks(chunks []schemaChunk, errs SchemaLoadErrors) error {
+	type pendingDef struct {
+		chunk schemaChunk
+		def   SchemaDefinition
+		err   error
+	}
+
+	// Parse everything first, so that syntax
+	// problems are reported exactly once.
+	var pending []pendingDef
+	for _, chunk := range chunks {
+		if def, err := parseSchemaDefinition(chunk.def); err != nil {
+			errs = append(errs, SchemaLoadError{
+				File:       chunk.file,
+				Line:       chunk.line,
+				Identifier: schemaChunkIdentifier(chunk.def),
+				Err:        err,
+			})
+		} else {
+			pending = append(pending, pendingDef{chunk: chunk, def: def})
+		}
+	}
+
+	// Register what we can, retrying failures
+	// for as long as each pass makes progress,
+	// as a failure may have been the result of
+	// a dependency which was not yet registered.
+	for progress := true; progress && len(pending) > 0; {
+		progress = false
+		var retry []pendingDef
+		for _, p := range pending {
+			if p.err = r.register(p.def); p.err != nil {
+				retry = append(retry, p)
+			} else {
+				progress = true
+			}
+		}
+		pending = retry
+	}
+
+	for _, p := range pending {
+		errs = append(errs, SchemaLoadError{
+			File:       p.chunk.file,
+			Line:       p.chunk.line,
+			Identifier: p.def.Identifier(),
+			Err:        p.err,
+		})
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(errs, func(i, j int) bool {
+		if errs[i].File != errs[j].File {
+			return errs[i].File < errs[j].File
+		}
+		return errs[i].Line < errs[j].Line
+	})
+
+	return errs
+}
+
+/*
+register returns an error following an attempt to register def within
+the receiver instance by way of the appropriate Register method.
+
+Note that *[MatchingRuleUse] instances are merged into those maintained
+automatically, rather than registered outright.
+*/
+func (r *SubschemaSubentry) register(def SchemaDefinition) (err error) {
+	switch tv := def.(type) {
+	case *LDAPSyntax:
+		err = r.RegisterLDAPSyntax(tv)
+	case *MatchingRule:
+		err = r.RegisterMatchingRule(tv)
+	case *AttributeType:
+		err = r.RegisterAttributeType(tv)
+	case *MatchingRuleUse:
+		err = r.registerMatchingRuleUse(tv)
+	case *ObjectClass:
+		err = r.RegisterObjectClass(tv)
+	case *DITContentRule:
+		err = r.RegisterDITContentRule(tv)
+	case *NameForm:
+		err = r.RegisterNameForm(tv)
+	case *DITStructureRule:
+		err = r.RegisterDITStructureRule(tv)
+	default:
+		err = errorBadType("SchemaDefinition")
+	}
+
+	return
+}
+
+/*
+parseSchemaDefinition returns a [SchemaDefinition] qualifier instance
+alongside an error following an attempt to parse def, which MUST begin
+with one (1) of the keywords described in [SubschemaSubentry.ReadBytes].
+*/
+func parseSchemaDefinition(def []byte) (sd SchemaDefinition, err error) {
+	low := blc(def)
+	switch {
+	case bhasPfx(low, []byte(`ldapsyntax`)):
+		var d *LDAPSyntax
+		if d, err = marshalLDAPSyntax(def); err == nil {
+			sd = d
+		}
+	case bhasPfx(low, []byte(`matchingruleuse`)):
+		var d *MatchingRuleUse
+		if d, err = marshalMatchingRuleUse(def); err == nil {
+			sd = d
+		}
+	case bhasPfx(low, []byte(`matchingrule`)):
+		var d *MatchingRule
+		if d, err = marshalMatchingRule(def); err == nil {
+			sd = d
+		}
+	case bhasPfx(low, []byte(`attributetype`)):
+		var d *AttributeType
+		if d, err = marshalAttributeType(def); err == nil {
+			sd = d
+		}
+	case bhasPfx(low, []byte(`objectclass`)):
+		var d *ObjectClass
+		if d, err = marshalObjectClass(def); err == nil {
+			sd = d
+		}
+	case bhasPfx(low, []byte(`ditcontentrule`)):
+		var d *DITContentRule
+		if d, err = marshalDITContentRule(def); err == nil {
+			sd = d
+		}
+	case bhasPfx(low, []byte(`nameform`)):
+		var d *NameForm
+		if d, err = marshalNameForm(def); err == nil {
+			sd = d
+		}
+	case bhasPfx(low, []byte(`ditstructurerule`)):
+		var d *DITStructureRule
+		if d, err = marshalDITStructureRule(def); err == nil {
+			sd = d
+		}
+	default:
+		err = errorTxt("Invalid definition: " + string(def))
+	}
+
+	return
+}
+
+/*
+schemaChunkIdentifier returns the leading token of the definition within
+def, which is normally its numeric OID or rule ID.
+*/
+func schemaChunkIdentifier(def []byte) string {
+	tkz := newDefinitionTokenizer(string(def))
+	tkz.startTokenParen()
+	return tkz.this()
+}
+
+/*
+splitSchemaDefinitions returns slices of schemaChunk, each containing a
+single condensed definition found within data alongside the line number
+upon which it began. Comments are removed, and definitions are delimited
+by lines beginning with one (1) of the keywords described in the
+[SubschemaSubentry.ReadBytes] method.
+*/
+func splitSchemaDefinitions(file string, data []byte) (chunks []schemaChunk) {
+	var (
+		cur    schemaChunk
+		dollar bool // last line ended with a '$' delimiter
+	)
+
+	for i, raw := range bsplit(data, []byte("\n")) {
+		if idx := bidx(raw, []byte(`#`)); idx != -1 {
+			raw = raw[:idx]
+		}
+
+		line := []byte(condenseWHSP(raw))
+		if len(line) == 0 {
+			continue
+		}
+
+		// a line following a '$' delimiter always continues
+		// the current definition, regardless of its content.
+		if !dollar && isSchemaKeywordLine(line) {
+			if len(cur.def) > 0 {
+				chunks = append(chunks, cur)
+			}
+			cur = schemaChunk{file: file, line: i + 1, def: line}
+		} else if len(cur.def) > 0 {
+			cur.def = append(cur.def, ' ')
+			cur.def = append(cur.def, line...)
+		}
+
+		dollar = bhasSfx(raw, []byte(`$`))
+	}
+
+	// Add the final segment
+	if len(cur.def) > 0 {
+		chunks = append(chunks, cur)
+	}
+
+	return
+}
+
+/*
+isSchemaKeywordLine returns a Boolean value indicative of whether line
+begins with a definition keyword (e.g.: "attributeTypes").
+*/
+func isSchemaKeywordLine(line []byte) bool {
+	low := blc(line)
+	for _, keyword := range headerTokens {
+		if bhasPfx(low, blc([]byte(keyword))) {
+			return true
+		}
+	}
+
+	return false
+}
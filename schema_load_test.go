@@ -0,0 +1,169 @@
+package dirsyn
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+/*
+This example demonstrates the means for loading schema definitions in
+which dependencies appear after their dependents, and in which some
+definitions are faulty.
+*/
+func ExampleSubschemaSubentry_LoadBytes() {
+	var r RFC4512
+	schema, _ := r.SubschemaSubentry()
+
+	err := schema.LoadBytes([]byte(`
+	attributeTypes: ( 2.5.4.3 NAME 'cn' SUP name )
+	attributeTypes: ( 2.5.4.41 NAME 'name'
+		SYNTAX 1.3.6.1.4.1.1466.115.121.1.15 )
+	ldapSyntaxes: ( 1.3.6.1.4.1.1466.115.121.1.15 DESC 'Directory String' )
+	attributeTypes: ( 2.5.4.4 NAME 'sn' SUP bogusType )
+	attributeTypes: ( 2.5.4.5 NAME 'serialNumber' BOGUS )
+	`))
+
+	fmt.Println(schema.AttributeTypes.Len())
+	fmt.Println(err)
+	// Output:
+	// 2
+	// 6: sn: attributeType: Unknown SUP (supertype): 'bogusType'
+	// 7: 2.5.4.5: attributeType: Unknown token in definition: BOGUS at line 1, column 47: expected definition keyword or extension near "Number' BOGUS )"
+}
+
+func TestSubschemaSubentry_LoadDirectory(t *testing.T) {
+	dir, err := os.MkdirTemp("", "schema-load-test")
+	if err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+	defer os.RemoveAll(dir)
+
+	for file, content := range map[string]string{
+		// a.schema depends upon b.schema, which is
+		// visited afterwards.
+		"a.schema": `# classes
+objectClasses: ( 2.5.6.6 NAME 'person'
+	SUP top STRUCTURAL
+	MUST ( sn $
+		cn ) )
+objectClasses: ( 2.5.6.0 NAME 'top' ABSTRACT MUST objectClass )
+
+attributeTypes: ( 2.5.4.0 NAME 'objectClass'
+	EQUALITY objectIdentifierMatch
+	SYNTAX 1.3.6.1.4.1.1466.115.121.1.38 )
+attributeTypes: ( 2.5.4.4 NAME 'sn' SUP name SYNTAX {x} )
+`,
+		"sub/b.schema": `attributeTypes: ( 2.5.4.3 NAME 'cn' SUP name )
+attributeTypes: ( 2.5.4.41 NAME 'name'
+	EQUALITY caseIgnoreMatch
+	SYNTAX 1.3.6.1.4.1.1466.115.121.1.15 )
+nameForms: ( 1.3.6.1.4.1.56521.999.1 NAME 'bogusForm' OC bogusClass MUST cn )
+matchingRules: ( 2.5.13.2 NAME 'caseIgnoreMatch' SYNTAX 1.3.6.1.4.1.1466.115.121.1.15 )
+matchingRules: ( 2.5.13.0 NAME 'objectIdentifierMatch' SYNTAX 1.3.6.1.4.1.1466.115.121.1.38 )
+ldapSyntaxes: ( 1.3.6.1.4.1.1466.115.121.1.15 DESC 'Directory String' )
+ldapSyntaxes: ( 1.3.6.1.4.1.1466.115.121.1.38 DESC 'OID' )
+`,
+		"c.txt": `bogus`,
+	} {
+		path := filepath.Join(dir, file)
+		if err = os.MkdirAll(filepath.Dir(path), 0o700); err == nil {
+			err = os.WriteFile(path, []byte(content), 0o600)
+		}
+		if err != nil {
+			t.Fatalf("%s failed: %v", t.Name(), err)
+		}
+	}
+
+	var r RFC4512
+	schema, _ := r.SubschemaSubentry()
+
+	err = schema.LoadDirectory(dir + `/`)
+
+	var errs SchemaLoadErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("%s failed: expected SchemaLoadErrors, got %T (%v)", t.Name(), err, err)
+	} else if len(errs) != 3 {
+		t.Fatalf("%s failed: expected 3 errors, got %d:\n%v", t.Name(), len(errs), err)
+	}
+
+	for idx, want := range []struct {
+		File       string
+		Line       int
+		Identifier string
+	}{
+		{`a.schema`, 2, `person`},
+		{`a.schema`, 11, `2.5.4.4`},
+		{`sub/b.schema`, 5, `bogusForm`},
+	} {
+		got := errs[idx]
+		if got.File != filepath.Join(dir, want.File) || got.Line != want.Line ||
+			got.Identifier != want.Identifier {
+			t.Errorf("%s[%d] failed:\nwant: %s:%d %s\ngot:  %s:%d %s",
+				t.Name(), idx, want.File, want.Line, want.Identifier,
+				got.File, got.Line, got.Identifier)
+		}
+	}
+
+	if _, idx := schema.ObjectClass(`top`); idx == -1 {
+		t.Errorf("%s failed: top not registered", t.Name())
+	} else if _, idx = schema.AttributeType(`cn`); idx == -1 {
+		t.Errorf("%s failed: cn not registered", t.Name())
+	}
+
+	var perr *ParseError
+	if !errors.As(err, &perr) {
+		t.Errorf("%s failed: expected *ParseError within %v", t.Name(), err)
+	}
+}
+
+func TestSubschemaSubentry_LoadFiles_codecov(t *testing.T) {
+	var r RFC4512
+	schema, _ := r.SubschemaSubentry()
+
+	if err := schema.LoadDirectory(`/nonexistent/path`); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("%s failed: expected ErrNotExist, got %v", t.Name(), err)
+	}
+
+	err := schema.LoadFiles(`/tmp/file.txt`, `/nonexistent/file.schema`)
+	if errs, ok := err.(SchemaLoadErrors); !ok || len(errs) != 2 {
+		t.Errorf("%s failed: unexpected result %v", t.Name(), err)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("%s failed: expected ErrNotExist within %v", t.Name(), err)
+	}
+
+	if err = schema.LoadBytes([]byte("matchingRuleUse: ( 2.5.13.2 APPLIES cn )")); err == nil {
+		t.Errorf("%s failed: expected error, got nil", t.Name())
+	}
+
+	if err = schema.register(nil); err == nil {
+		t.Errorf("%s failed: expected error, got nil", t.Name())
+	}
+
+	if err = schema.LoadBytes([]byte(`
+		ldapSyntaxes: ( 1.3.6.1.4.1.56521.999.2 DESC 'test' )
+		matchingRules: ( 1.3.6.1.4.1.56521.999.3 NAME 'testMatch' SYNTAX 1.3.6.1.4.1.56521.999.2 )
+		dITContentRules: ( 2.5.6.6 NAME 'person' )
+		dITStructureRules: ( 1 NAME 'rule' FORM bogusForm )`)); err == nil {
+		t.Errorf("%s failed: expected error, got nil", t.Name())
+	}
+
+	for _, bogus := range []string{
+		`ldapSyntaxes: ( 1.2.3 BOGUS )`,
+		`matchingRules: ( 1.2.3 BOGUS )`,
+		`objectClasses: ( 1.2.3 BOGUS )`,
+		`dITContentRules: ( 1.2.3 BOGUS )`,
+		`nameForms: ( 1.2.3 BOGUS )`,
+		`dITStructureRules: ( 1 BOGUS )`,
+	} {
+		if _, err = parseSchemaDefinition([]byte(bogus)); err == nil {
+			t.Errorf("%s failed: expected error for %s, got nil", t.Name(), bogus)
+		}
+	}
+
+	if s := (SchemaLoadError{Line: 1}).Error(); s != `1: ` {
+		t.Errorf("%s failed: unexpected string %q", t.Name(), s)
+	}
+}
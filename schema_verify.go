@@ -0,0 +1,127 @@
+package dirsyn
+
+/*
+schema_verify.go contains SubschemaSubentry methods for the verification
+of entire entries against the object classes they name.
+*/
+
+import "sort"
+
+/*
+extensibleObjectOID is the numeric OID of the "extensibleObject" AUXILIARY
+class, which permits any user attribute type, per [§ 4.3 of RFC 4512].
+
+[§ 4.3 of RFC 4512]: https://datatracker.ietf.org/doc/html/rfc4512#section-4.3
+*/
+const extensibleObjectOID = `1.3.6.1.4.1.1466.101.120.111`
+
+/*
+VerifyEntry returns an error following an attempt to verify entry against
+the object classes named by its "objectclass" values. An error bearing
+[ResultObjectClassViolation] is returned if:
+
+  - no object class is named, or an unknown object class is named
+  - no STRUCTURAL class is named
+  - the STRUCTURAL classes named do not belong to a single superclass chain
+  - a value is absent for an attribute type required (MUST) by any class
+  - a user attribute type is not permitted (MUST or MAY) by any class, and
+    "extensibleObject" is not named
+
+The values of each attribute type are also verified in the manner of the
+[SubschemaSubentry.VerifyAttribute] method.
+*/
+func (r *SubschemaSubentry) VerifyEntry(entry FilterEntry) (err error) {
+	var classes, structural []*ObjectClass
+	var extensible bool
+	for _, term := range entry[`objectclass`] {
+		oc, idx := r.ObjectClass(term)
+		if idx == -1 {
+			err = errorResult(ResultObjectClassViolation,
+				errorTxt("Unknown object class: '"+term+"'"))
+			return
+		} else if oc.Kind == 0 {
+			structural = append(structural, oc)
+		}
+		extensible = extensible || oc.NumericOID == extensibleObjectOID
+		classes = append(classes, oc)
+	}
+
+	if len(classes) == 0 {
+		err = errorResult(ResultObjectClassViolation,
+			errorTxt("No object classes"))
+		return
+	} else if len(structural) == 0 {
+		err = errorResult(ResultObjectClassViolation,
+			errorTxt("No STRUCTURAL object class"))
+		return
+	} else if !structuralChain(structural) {
+		err = errorResult(ResultObjectClassViolation,
+			errorTxt("Multiple STRUCTURAL object class chains"))
+		return
+	}
+
+	var must []*AttributeType
+	permitted := make(map[string]bool)
+	for _, oc := range classes {
+		for i, all := 0, oc.AllMust(); i < all.Len(); i++ {
+			if at := all.Index(i); !permitted[at.NumericOID] {
+				must = append(must, at)
+				permitted[at.NumericOID] = true
+			}
+		}
+		for i, all := 0, oc.AllMay(); i < all.Len(); i++ {
+			permitted[all.Index(i).NumericOID] = true
+		}
+	}
+
+	var keys []string
+	for key := range entry {
+		if key != `objectclass` {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	values := make(map[string][]string)
+	for _, key := range keys {
+		attr := AttributeDescription(key).Type()
+		at, idx := r.AttributeType(attr)
+		if idx == -1 {
+			err = errorResult(ResultUndefinedAttributeType,
+				errorTxt("Unknown attribute type: '"+attr+"'"))
+			return
+		}
+
+		operational := len(at.Usage) > 0 && lc(at.Usage) != `userapplications`
+		if !permitted[at.NumericOID] && !extensible && !operational {
+			err = errorResult(ResultObjectClassViolation,
+				errorTxt(attr+": Not permitted by object classes"))
+			return
+		} else if err = r.VerifyAttribute(attr, false, entry[key]); err != nil {
+			return
+		}
+		values[at.NumericOID] = append(values[at.NumericOID], entry[key]...)
+	}
+
+	for i := 0; i < len(must) && err == nil; i++ {
+		err = r.VerifyAttribute(must[i].Identifier(), true, values[must[i].NumericOID])
+	}
+
+	return
+}
+
+/*
+structuralChain returns a Boolean value indicative of a single class within
+classes being subordinate to, or the same as, each of the others.
+*/
+func structuralChain(classes []*ObjectClass) (chain bool) {
+	for i := 0; i < len(classes) && !chain; i++ {
+		chain = true
+		for j := 0; j < len(classes) && chain; j++ {
+			chain = classes[j].NumericOID == classes[i].NumericOID ||
+				classes[j].SuperClassOf(classes[i])
+		}
+	}
+
+	return
+}
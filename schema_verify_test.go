@@ -0,0 +1,79 @@
+package dirsyn
+
+import (
+	"fmt"
+	"testing"
+)
+
+var verifyTestSchema = goCodeTestSchema + `
+attributeTypes: ( 2.5.18.1 NAME 'createTimestamp' SYNTAX 1.3.6.1.4.1.1466.115.121.1.24 SINGLE-VALUE NO-USER-MODIFICATION USAGE directoryOperation )
+objectClasses: ( 1.3.6.1.4.1.56521.999.6 NAME 'x-thing' SUP top STRUCTURAL )
+objectClasses: ( 1.3.6.1.4.1.56521.999.7 NAME 'x-person' SUP x-being STRUCTURAL )
+objectClasses: ( 1.3.6.1.4.1.1466.101.120.111 NAME 'extensibleObject' SUP top AUXILIARY )
+`
+
+/*
+This example demonstrates the means for verifying an entire entry against
+the object classes it names.
+*/
+func ExampleSubschemaSubentry_VerifyEntry() {
+	var r RFC4512
+	schema, _ := r.SubschemaSubentry()
+	if err := schema.LoadBytes([]byte(verifyTestSchema)); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	err := schema.VerifyEntry(FilterEntry{
+		`objectclass`: {`top`, `x-being`},
+		`cn`:          {`Jesse`},
+		`shoesize`:    {`9`},
+	})
+	fmt.Println(err, RFC4511{}.ErrorResultCode(err))
+	// Output: shoesize: Not permitted by object classes objectClassViolation
+}
+
+func TestSubschemaSubentry_VerifyEntry(t *testing.T) {
+	var r RFC4512
+	schema, _ := r.SubschemaSubentry()
+	if err := schema.LoadBytes([]byte(verifyTestSchema)); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+
+	for idx, strukt := range []struct {
+		Entry FilterEntry
+		Want  ResultCode
+	}{
+		// no object classes
+		{FilterEntry{`cn`: {`Jesse`}}, ResultObjectClassViolation},
+		// unknown object class
+		{FilterEntry{`objectclass`: {`bogus`}}, ResultObjectClassViolation},
+		// no STRUCTURAL object class
+		{FilterEntry{`objectclass`: {`top`, `shoeWearer`}}, ResultObjectClassViolation},
+		// unrelated STRUCTURAL object classes
+		{FilterEntry{`objectclass`: {`x-being`, `x-thing`}, `cn`: {`Jesse`}}, ResultObjectClassViolation},
+		// missing MUST value
+		{FilterEntry{`objectclass`: {`x-being`}}, ResultObjectClassViolation},
+		// attribute type not permitted
+		{FilterEntry{`objectclass`: {`x-being`}, `cn`: {`Jesse`}, `shoesize`: {`9`}}, ResultObjectClassViolation},
+		// unknown attribute type
+		{FilterEntry{`objectclass`: {`x-being`}, `cn`: {`Jesse`}, `bogus`: {`x`}}, ResultUndefinedAttributeType},
+		// value violates syntax
+		{FilterEntry{`objectclass`: {`x-being`}, `cn;lang-en`: {`Jesse`}, `x-birth-date`: {`bogus`}}, ResultInvalidAttributeSyntax},
+		// SINGLE-VALUE violation
+		{FilterEntry{`objectclass`: {`x-being`}, `cn`: {`Jesse`}, `x-birth-date`: {`19800102030405Z`, `19800102030406Z`}}, ResultConstraintViolation},
+		// MUST value satisfied by way of an attribute option
+		{FilterEntry{`objectclass`: {`top`, `x-being`, `shoeWearer`}, `cn;lang-en`: {`Jesse`}, `shoesize`: {`9`}}, ResultSuccess},
+		// STRUCTURAL classes within a single superclass chain
+		{FilterEntry{`objectclass`: {`x-being`, `x-person`}, `cn`: {`Jesse`}}, ResultSuccess},
+		// extensibleObject permits any user attribute type
+		{FilterEntry{`objectclass`: {`x-thing`, `extensibleObject`}, `cn`: {`Jesse`}, `shoesize`: {`9`}}, ResultSuccess},
+		// operational attribute types are always permitted
+		{FilterEntry{`objectclass`: {`x-thing`}, `createtimestamp`: {`19800102030405Z`}}, ResultSuccess},
+	} {
+		err := schema.VerifyEntry(strukt.Entry)
+		if got := (RFC4511{}).ErrorResultCode(err); got != strukt.Want {
+			t.Errorf("%s[%d] failed: want %s, got %s (%v)", t.Name(), idx, strukt.Want, got, err)
+		}
+	}
+}
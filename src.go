@@ -74,6 +74,11 @@ RFC2307 returns the [RFC2307] constructor type.
 */
 func (r Sources) RFC2307() RFC2307 { return RFC2307{} }
 
+/*
+RFC3641 returns the [RFC3641] constructor type.
+*/
+func (r Sources) RFC3641() RFC3641 { return RFC3641{} }
+
 /*
 RFC3672 returns the [RFC3672] constructor type.
 */
@@ -128,6 +133,7 @@ type (
 	X690 struct{ *standard } // ITU-T Rec. X.690
 
 	RFC2307 struct{ *standard } // RFC 2307
+	RFC3641 struct{ *standard } // RFC 3641
 	RFC3672 struct{ *standard } // RFC 3672
 	RFC4511 struct{ *standard } // RFC 4511
 	RFC4512 struct{ *standard } // RFC 4512
@@ -177,6 +183,11 @@ Document returns the string representation of the RFC 2307 document URL.
 */
 func (r RFC2307) Document() string { return rfcURLPrefix + `rfc2307` }
 
+/*
+Document returns the string representation of the RFC 3641 document URL.
+*/
+func (r RFC3641) Document() string { return rfcURLPrefix + `rfc3641` }
+
 /*
 Document returns the string representation of the RFC 3672 document URL.
 */
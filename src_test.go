@@ -21,6 +21,7 @@ func TestSrc_codecov(t *testing.T) {
 	var r11 RFC4517
 	var r12 RFC4523
 	var r13 RFC4530
+	var r14 RFC3641
 
 	srcs.ACIv3()
 	srcs.X680()
@@ -28,6 +29,7 @@ func TestSrc_codecov(t *testing.T) {
 	srcs.X501()
 	srcs.X520()
 	srcs.RFC2307()
+	srcs.RFC3641()
 	srcs.RFC3672()
 	srcs.RFC4511()
 	srcs.RFC4512()
@@ -52,4 +54,5 @@ func TestSrc_codecov(t *testing.T) {
 	r11.Document()
 	r12.Document()
 	r13.Document()
+	r14.Document()
 }
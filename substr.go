@@ -85,7 +85,13 @@ String returns the string representation of the receiver instance.
 func (r SubstringAssertion) String() (s string) {
 	Any := func() string {
 		if len(r.Any) > 0 {
-			return `*` + r.Any.String() + `*`
+			// Any bears asterisk-delimited components,
+			// each of which is escaped individually.
+			anys := split(string(r.Any), `*`)
+			for i := range anys {
+				anys[i] = AssertionValue(anys[i]).Escaped()
+			}
+			return `*` + join(anys, `*`) + `*`
 		}
 		return `*`
 	}
@@ -119,7 +125,8 @@ SubstringAssertion returns an error following an analysis of x in the
 context of a Substring Assertion.
 */
 func (r RFC4517) SubstringAssertion(x any) (SubstringAssertion, error) {
-	return marshalSubstringAssertion(x)
+	val, err := marshalSubstringAssertion(x)
+	return val, errorSyntax(err)
 }
 
 func substringAssertion(x any) (result Boolean) {
@@ -83,12 +83,12 @@ From [§ 6 of RFC 3642]:
 [§ 6 of RFC 3642]: https://datatracker.ietf.org/doc/html/rfc3642#section-6
 */
 type SubtreeSpecification struct {
-	Base LocalName `asn1:"tag:0,default:"`
+	Base LocalName `asn1:"tag:0,default:" gser:"base,default:"`
 
 	// COMPONENTS OF chopSpecification
 	ChopSpecification
 
-	SpecificationFilter Refinement `asn1:"tag:4,optional"`
+	SpecificationFilter Refinement `asn1:"tag:4,optional" gser:"specificationFilter,optional"`
 }
 
 /*
@@ -126,7 +126,13 @@ func (r RFC3672) SubtreeSpecification(x any) (ss SubtreeSpecification, err error
 	if err = checkSubtreeEncaps(raw); err != nil {
 		return
 	}
-	raw = trimS(raw[1 : len(raw)-1])
+
+	// retain the offset of the trimmed interior, so
+	// that any parse errors may be positioned upon
+	// the original input.
+	outer := raw
+	raw, offset := trimSOffset(raw[1 : len(raw)-1])
+	defer func() { err = rebaseParseError(err, outer, offset+1) }()
 
 	var ranges map[string][]int = make(map[string][]int, 0)
 
@@ -152,6 +158,8 @@ func (r *SubtreeSpecification) marshalBase(raw string, ranges map[string][]int)
 		if r.Base, end, err = subtreeBase(raw[begin:]); err == nil {
 			end += begin + 1
 			ranges[`base`] = []int{begin, end}
+		} else {
+			err = parseErrorAt(err, raw, begin, `local name`)
 		}
 	}
 
@@ -165,6 +173,8 @@ func (r *SubtreeSpecification) marshalExclusions(raw string, ranges map[string][
 		if r.ChopSpecification.Exclusions, end, err = subtreeExclusions(raw, begin); err == nil {
 			end = begin + end
 			ranges[`specificExclusions`] = []int{begin, end}
+		} else {
+			err = parseErrorAt(err, raw, begin, `specific exclusions`)
 		}
 	}
 
@@ -178,6 +188,8 @@ func (r *SubtreeSpecification) marshalMinimum(raw string, ranges map[string][]in
 		if r.ChopSpecification.Minimum, end, err = subtreeMinMax(raw, begin); err == nil {
 			end = begin + end
 			ranges[`minimum`] = []int{begin, end}
+		} else {
+			err = parseErrorAt(err, raw, begin, `base distance`)
 		}
 	}
 
@@ -191,6 +203,8 @@ func (r *SubtreeSpecification) marshalMaximum(raw string, ranges map[string][]in
 		if r.ChopSpecification.Maximum, end, err = subtreeMinMax(raw, begin); err == nil {
 			end = begin + end
 			ranges[`maximum`] = []int{begin, end}
+		} else {
+			err = parseErrorAt(err, raw, begin, `base distance`)
 		}
 	}
 
@@ -200,9 +214,10 @@ func (r *SubtreeSpecification) marshalMaximum(raw string, ranges map[string][]in
 func (r *SubtreeSpecification) marshalSpecFilter(raw string, ranges map[string][]int) (err error) {
 	if begin := stridx(raw, `specificationFilter `); begin != -1 {
 		begin += 20
-		var err error
 		if r.SpecificationFilter, err = subtreeRefinement(raw, begin); err == nil {
 			ranges[`specificationFilter`] = []int{begin, begin + len(raw) - begin}
+		} else {
+			err = parseErrorAt(err, raw, begin, `refinement`)
 		}
 	}
 
@@ -210,8 +225,12 @@ func (r *SubtreeSpecification) marshalSpecFilter(raw string, ranges map[string][
 }
 
 func checkSubtreeEncaps(raw string) (err error) {
-	if raw[0] != '{' || raw[len(raw)-1] != '}' {
-		err = errorTxt("SubtreeSpecification {} encapsulation error")
+	if raw[0] != '{' {
+		err = newParseError(raw, 0, `'{'`,
+			errorTxt("SubtreeSpecification {} encapsulation error"))
+	} else if raw[len(raw)-1] != '}' {
+		err = newParseError(raw, len(raw), `'}'`,
+			errorTxt("SubtreeSpecification {} encapsulation error"))
 	}
 	return
 }
@@ -296,8 +315,8 @@ From [Appendix A of RFC 3672]:
 [§ 2.1 of RFC 3672]: https://datatracker.ietf.org/doc/html/rfc3672#section-2.1
 */
 type SpecificExclusion struct {
-	ChopBefore LocalName `asn1:"tag:0"`
-	ChopAfter  LocalName `asn1:"tag:1"`
+	ChopBefore LocalName `asn1:"tag:0" gser:"chopBefore"`
+	ChopAfter  LocalName `asn1:"tag:1" gser:"chopAfter"`
 }
 
 /*
@@ -305,9 +324,9 @@ ChopSpecification implements the chopSpecification component of an
 instance of [SubtreeSpecification].
 */
 type ChopSpecification struct {
-	Exclusions SpecificExclusions `asn1:"tag:1,optional"`
-	Minimum    BaseDistance       `asn1:"tag:2,default:0"`
-	Maximum    BaseDistance       `asn1:"tag:3,optional"`
+	Exclusions SpecificExclusions `asn1:"tag:1,set,optional" gser:"specificExclusions,optional,choice"`
+	Minimum    BaseDistance       `asn1:"tag:2,default:0" gser:"minimum,default:0"`
+	Maximum    BaseDistance       `asn1:"tag:3,optional" gser:"maximum,optional"`
 }
 
 func (r ChopSpecification) IsZero() bool {
@@ -492,6 +511,59 @@ func (r SpecificExclusion) BER() (*ber.Packet, error) {
 		desc), nil
 }
 
+/*
+MarshalDER returns an int alongside an error following an attempt to write
+the receiver instance into der as the appropriate CHOICE alternative, i.e.:
+chopBefore [0] or chopAfter [1]. See also [DERMarshaler].
+*/
+func (r SpecificExclusion) MarshalDER(der *DERPacket) (n int, err error) {
+	if r.IsZero() || r.Choice() == "" {
+		err = errorTxt("Nil SpecificExclusion, cannot DER encode")
+		return
+	}
+
+	tag, val := 0, r.ChopBefore.String()
+	if r.Choice() == `after` {
+		tag, val = 1, r.ChopAfter.String()
+	}
+
+	n = der.WriteTagAndLength(classContextSpecific, false, tag, len(val))
+	der.data = append(der.data, val...)
+	der.offset = len(der.data)
+	n += len(val)
+
+	return
+}
+
+/*
+UnmarshalDER returns an error following an attempt to read the chopBefore
+[0] or chopAfter [1] CHOICE alternative from der into the receiver instance.
+See also [DERUnmarshaler].
+*/
+func (r *SpecificExclusion) UnmarshalDER(der *DERPacket) (err error) {
+	var tal TagAndLength
+	if tal, err = der.TagAndLength(); err != nil {
+		return
+	} else if err = tal.ExpectClass(classContextSpecific); err != nil {
+		return
+	} else if tal.Tag > 1 {
+		err = errorTxt("unexpected SpecificExclusion tag " + itoa(tal.Tag))
+		return
+	} else if der.offset+tal.Length > len(der.data) {
+		err = errorTxt("insufficient data for SpecificExclusion")
+		return
+	}
+
+	val := LocalName(der.data[der.offset : der.offset+tal.Length])
+	der.offset += tal.Length
+
+	if *r = (SpecificExclusion{ChopBefore: val}); tal.Tag == 1 {
+		*r = SpecificExclusion{ChopAfter: val}
+	}
+
+	return
+}
+
 func subtreeExclusions(raw string, begin int) (excl SpecificExclusions, end int, err error) {
 	end = -1
 
@@ -991,6 +1063,129 @@ func (r RefinementItem) Index(_ int) Refinement {
 	return r
 }
 
+/*
+MarshalGSER returns the "item" CHOICE alternative of the receiver instance
+as an instance of [GSERValue] alongside an error. See also [GSERMarshaler].
+*/
+func (r RefinementItem) MarshalGSER() (value GSERValue, err error) {
+	var item GSERValue
+	if item, err = gserMarshalString(string(r), gserFieldParams{identifier: true}); err == nil {
+		value = gserRefinementChoice(r.Choice(), item)
+	}
+
+	return
+}
+
+/*
+MarshalGSER returns the "and" CHOICE alternative of the receiver instance
+as an instance of [GSERValue] alongside an error. See also [GSERMarshaler].
+*/
+func (r RefinementAnd) MarshalGSER() (GSERValue, error) {
+	return marshalRefinementsGSER(r.Choice(), r)
+}
+
+/*
+MarshalGSER returns the "or" CHOICE alternative of the receiver instance
+as an instance of [GSERValue] alongside an error. See also [GSERMarshaler].
+*/
+func (r RefinementOr) MarshalGSER() (GSERValue, error) {
+	return marshalRefinementsGSER(r.Choice(), r)
+}
+
+/*
+MarshalGSER returns the "not" CHOICE alternative of the receiver instance
+as an instance of [GSERValue] alongside an error. See also [GSERMarshaler].
+*/
+func (r RefinementNot) MarshalGSER() (value GSERValue, err error) {
+	if r.IsZero() {
+		err = errorTxt("Nil RefinementNot, cannot GSER encode")
+		return
+	}
+
+	var not GSERValue
+	if not, err = gserMarshalValue(valOf(r.Refinement), gserFieldParams{}); err == nil {
+		value = gserRefinementChoice(r.Choice(), not)
+	}
+
+	return
+}
+
+func marshalRefinementsGSER(choice string, refs []Refinement) (value GSERValue, err error) {
+	set := GSERValue{Kind: GSERSequenceOf}
+	for i := 0; i < len(refs) && err == nil; i++ {
+		var elem GSERValue
+		if elem, err = gserMarshalValue(valOf(refs[i]), gserFieldParams{}); err == nil {
+			set.Elements = append(set.Elements, elem)
+		}
+	}
+
+	if err == nil {
+		value = gserRefinementChoice(choice, set)
+	}
+
+	return
+}
+
+func gserRefinementChoice(choice string, alt GSERValue) GSERValue {
+	return GSERValue{Kind: GSERChoice, Components: []GSERComponent{{choice, alt}}}
+}
+
+/*
+unmarshalRefinementGSER returns the [Refinement] read from the GSER value
+alongside an error. This is used by [GSERValue.Unmarshal] for fields of
+the [Refinement] interface type, such as that of [SubtreeSpecification].
+*/
+func unmarshalRefinementGSER(g GSERValue) (ref Refinement, err error) {
+	if g.Kind != GSERChoice || len(g.Components) != 1 {
+		err = errorTxt("Refinement must be a GSER CHOICE bearing one alternative")
+		return
+	}
+
+	alt := g.Components[0]
+	switch alt.Name {
+	case `item`:
+		if alt.Value.Kind != GSEROID && alt.Value.Kind != GSERIdentifier {
+			err = errorTxt("Refinement item must be an OBJECT IDENTIFIER")
+		} else {
+			ref = RefinementItem(alt.Value.Text)
+		}
+	case `and`, `or`:
+		var refs []Refinement
+		if refs, err = unmarshalRefinementsGSER(alt.Value); err == nil {
+			if ref = RefinementAnd(refs); alt.Name == `or` {
+				ref = RefinementOr(refs)
+			}
+		}
+	case `not`:
+		var not Refinement
+		if not, err = unmarshalRefinementGSER(alt.Value); err == nil {
+			ref = RefinementNot{not}
+		}
+	default:
+		err = errorTxt("unknown Refinement CHOICE alternative '" + alt.Name + "'")
+	}
+
+	return
+}
+
+func unmarshalRefinementsGSER(g GSERValue) (refs []Refinement, err error) {
+	// An empty value is indistinguishable from an empty SEQUENCE.
+	if g.Kind != GSERSequenceOf && !(g.Kind == GSERSequence && len(g.Components) == 0) {
+		err = errorTxt("Refinement and/or must be a GSER SET OF")
+		return
+	}
+
+	refs = make([]Refinement, 0, len(g.Elements))
+	for i := 0; i < len(g.Elements) && err == nil; i++ {
+		var ref Refinement
+		if ref, err = unmarshalRefinementGSER(g.Elements[i]); err == nil {
+			refs = append(refs, ref)
+		}
+	}
+
+	return
+}
+
 func subtreeBase(x any) (base LocalName, end int, err error) {
 	end = -1
 	var raw string
@@ -1012,7 +1207,9 @@ func subtreeBase(x any) (base LocalName, end int, err error) {
 		}
 	}
 
-	if err = isSafeUTF8(raw[1:end]); err == nil {
+	if end == -1 {
+		err = newParseError(raw, 0, `closing quote`, unterminatedErr)
+	} else if err = isSafeUTF8(raw[1:end]); err == nil {
 		base = LocalName(raw[1:end])
 	}
 
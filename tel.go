@@ -117,7 +117,8 @@ From [§ 1.4 of RFC 4512]:
 [§ 3.3.11 of RFC 4517]: https://datatracker.ietf.org/doc/html/rfc4517#section-3.3.11
 */
 func (r RFC4517) FacsimileTelephoneNumber(x any) (FacsimileTelephoneNumber, error) {
-	return marshalFacsimileTelephoneNumber(x)
+	val, err := marshalFacsimileTelephoneNumber(x)
+	return val, errorSyntax(err)
 }
 
 func facsimileTelephoneNumber(x any) (result Boolean) {
@@ -186,7 +187,8 @@ TelephoneNumber returns an instance of [TelephoneNumber] alongside an error
 following an analysis of x in the context of a Telephone Number.
 */
 func (r RFC4517) TelephoneNumber(x any) (TelephoneNumber, error) {
-	return marshalTelephoneNumber(x)
+	val, err := marshalTelephoneNumber(x)
+	return val, errorSyntax(err)
 }
 
 func telephoneNumber(x any) (result Boolean) {
@@ -259,7 +261,8 @@ TelexNumber returns an error following an analysis of x in the context
 of a Telex Number.
 */
 func (r RFC4517) TelexNumber(x any) (TelexNumber, error) {
-	return marshalTelexNumber(x)
+	val, err := marshalTelexNumber(x)
+	return val, errorSyntax(err)
 }
 
 func telexNumber(x any) (result Boolean) {
@@ -408,7 +411,8 @@ TeletexTerminalIdentifier returns an error following an analysis of x in
 the context of a Teletex Terminal Identifier.
 */
 func (r RFC4517) TeletexTerminalIdentifier(x any) (TeletexTerminalIdentifier, error) {
-	return marshalTeletexTerminalIdentifier(x)
+	val, err := marshalTeletexTerminalIdentifier(x)
+	return val, errorSyntax(err)
 }
 
 func teletexTerminalIdentifier(x any) (result Boolean) {
@@ -44,7 +44,7 @@ GeneralizedTime returns an instance of [GeneralizedTime] alongside an error.
 */
 func (r RFC4517) GeneralizedTime(x any) (gt GeneralizedTime, err error) {
 	gt, err = marshalGenTime(x)
-	return
+	return gt, errorSyntax(err)
 }
 
 func generalizedTime(x any) (result Boolean) {
@@ -167,7 +167,7 @@ UTCTime returns an error following an analysis of x in the context of a
 */
 func (r RFC4517) UTCTime(x any) (utc UTCTime, err error) {
 	utc, err = marshalUTCTime(x)
-	return
+	return utc, errorSyntax(err)
 }
 
 func uTCTime(x any) (result Boolean) {
@@ -335,3 +335,87 @@ func compareTimes(assert any, utc bool, funk func(time.Time) bool) (result bool)
 
 	return
 }
+
+/*
+derWriteGeneralizedTime writes gt as a DER-encoded GeneralizedTime. Per
+§ 11.7 of ITU-T Rec. X.690, the value is expressed in UTC ("Z") and any
+fractional seconds are written without trailing zeros.
+*/
+func derWriteGeneralizedTime(der *DERPacket, gt GeneralizedTime) int {
+	raw := time.Time(gt).UTC().Format(`20060102150405.999999999`) + `Z`
+	return derWritePrimitive(der, tagGeneralizedTime, []byte(raw))
+}
+
+/*
+derCanonicalTime returns an error if content, having been decoded as t,
+differs from the canonical DER encoding produced by the write function.
+*/
+func derCanonicalTime(content []byte, t time.Time,
+	write func(*DERPacket, time.Time), cerr error) (err error) {
+
+	// Time values are always short enough for a single
+	// length octet, thus the content follows two octets.
+	der := newDERPacket([]byte{})
+	write(der, t)
+	if len(der.data) < 2 || string(der.data[2:]) != string(content) {
+		err = cerr
+	}
+
+	return
+}
+
+/*
+derReadGeneralizedTime returns an error following an attempt to read a
+DER-encoded GeneralizedTime into x.
+*/
+func derReadGeneralizedTime(x *GeneralizedTime, der *DERPacket, tal TagAndLength) (err error) {
+	var content []byte
+	if content, err = derReadPrimitive(der, tal, tagGeneralizedTime); err == nil {
+		if *x, err = marshalGenTime(string(content)); err == nil && der.mode == DERStrict {
+			err = derCanonicalTime(content, time.Time(*x),
+				func(d *DERPacket, t time.Time) {
+					derWriteGeneralizedTime(d, GeneralizedTime(t))
+				}, derGeneralizedTimeErr)
+		}
+	}
+
+	return
+}
+
+/*
+derWriteUTCTime returns an int alongside an error following an attempt to
+write utc as a DER-encoded UTCTime. Per § 11.8 of ITU-T Rec. X.690, the
+value is expressed in UTC ("Z") and includes seconds. Years which cannot
+be recovered from two (2) digits, i.e.: those outside of 1969 through 2068,
+are rejected.
+*/
+func derWriteUTCTime(der *DERPacket, utc UTCTime) (n int, err error) {
+	t := time.Time(utc).UTC()
+	if year := t.Year(); year < 1969 || year > 2068 {
+		err = errorTxt("year " + itoa(year) + " cannot be expressed as UTCTime")
+	} else {
+		n = derWritePrimitive(der, tagUTCTime, []byte(t.Format(`060102150405`)+`Z`))
+	}
+
+	return
+}
+
+/*
+derReadUTCTime returns an error following an attempt to read a DER-encoded
+UTCTime into x.
+*/
+func derReadUTCTime(x *UTCTime, der *DERPacket, tal TagAndLength) (err error) {
+	var content []byte
+	if content, err = derReadPrimitive(der, tal, tagUTCTime); err == nil {
+		if len(content) == 0 {
+			err = errorBadLength(`UTC Time`, 0)
+		} else if *x, err = marshalUTCTime(string(content)); err == nil && der.mode == DERStrict {
+			err = derCanonicalTime(content, time.Time(*x),
+				func(d *DERPacket, t time.Time) {
+					derWriteUTCTime(d, UTCTime(t))
+				}, derUTCTimeErr)
+		}
+	}
+
+	return
+}
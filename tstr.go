@@ -42,7 +42,8 @@ an error following an analysis of x in the context of a Teletex String, per
 [ITU-T Rec. T.61]: https://www.itu.int/rec/T-REC-T.61
 */
 func (r RFC4517) TeletexString(x any) (TeletexString, error) {
-	return marshalTeletexString(x)
+	val, err := marshalTeletexString(x)
+	return val, errorSyntax(err)
 }
 
 func teletexString(x any) (result Boolean) {
@@ -82,3 +83,36 @@ func marshalTeletexString(x any) (ts TeletexString, err error) {
 
 	return
 }
+
+/*
+derWriteTeletexString returns an int alongside an error following an
+attempt to write ts as a DER-encoded TeletexString.
+*/
+func derWriteTeletexString(der *DERPacket, ts TeletexString) (n int, err error) {
+	if len(ts) > 0 {
+		_, err = marshalTeletexString(string(ts))
+	}
+	if err == nil {
+		n = derWritePrimitive(der, tagT61String, []byte(ts))
+	}
+
+	return
+}
+
+/*
+derReadTeletexString returns an error following an attempt to read a
+DER-encoded TeletexString into x.
+*/
+func derReadTeletexString(x *TeletexString, der *DERPacket, tal TagAndLength) (err error) {
+	var content []byte
+	if content, err = derReadPrimitive(der, tal, tagT61String); err == nil {
+		if len(content) > 0 {
+			_, err = marshalTeletexString(string(content))
+		}
+		if err == nil {
+			*x = TeletexString(content)
+		}
+	}
+
+	return
+}
@@ -71,6 +71,9 @@ var (
 	sfold    func(rune) rune                         = unicode.SimpleFold
 	isLetter func(rune) bool                         = unicode.IsLetter
 	utf8OK   func(string) bool                       = utf8.ValidString
+	runeOK   func(rune) bool                         = utf8.ValidRune
+	runeCnt  func(string) int                        = utf8.RuneCountInString
+	runeBgn  func(byte) bool                         = utf8.RuneStart
 	utf16Enc func([]rune) []uint16                   = utf16.Encode
 	isSpace  func(rune) bool                         = unicode.IsSpace
 	isPunct  func(rune) bool                         = unicode.IsPunct
@@ -625,3 +628,34 @@ func init() {
 		{0x0080, 0x008F, 1},
 	}}
 }
+
+/*
+derWriteUTF8String returns an int alongside an error following an attempt
+to write u as a DER-encoded UTF8String.
+*/
+func derWriteUTF8String(der *DERPacket, u UTF8String) (n int, err error) {
+	if !utf8OK(string(u)) {
+		err = errorTxt("invalid UTF8String: failed UTF8 checks")
+	} else {
+		n = derWritePrimitive(der, tagUTF8String, []byte(u))
+	}
+
+	return
+}
+
+/*
+derReadUTF8String returns an error following an attempt to read a DER-encoded
+UTF8String into x.
+*/
+func derReadUTF8String(x *UTF8String, der *DERPacket, tal TagAndLength) (err error) {
+	var content []byte
+	if content, err = derReadPrimitive(der, tal, tagUTF8String); err == nil {
+		if !utf8OK(string(content)) {
+			err = errorTxt("invalid UTF8String: failed UTF8 checks")
+		} else {
+			*x = UTF8String(content)
+		}
+	}
+
+	return
+}
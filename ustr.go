@@ -12,7 +12,8 @@ UniversalString returns an instance of [UniversalString] alongside an error
 following an analysis of x in the context of a UniversalString.
 */
 func (r RFC4517) UniversalString(x any) (UniversalString, error) {
-	return marshalUniversalString(x)
+	val, err := marshalUniversalString(x)
+	return val, errorSyntax(err)
 }
 
 func universalString(x any) (result Boolean) {
@@ -57,3 +58,52 @@ func (r UniversalString) String() string {
 IsZero returns a Boolean value indicative of a nil receiver state.
 */
 func (r UniversalString) IsZero() bool { return len(r) == 0 }
+
+/*
+derWriteUniversalString returns an int alongside an error following an
+attempt to write us as a DER-encoded UniversalString, in which each
+character is encoded as four (4) big-endian octets (UCS-4).
+*/
+func derWriteUniversalString(der *DERPacket, us UniversalString) (n int, err error) {
+	if !utf8OK(string(us)) {
+		err = errorTxt("invalid UniversalString: failed UTF8 checks")
+		return
+	}
+
+	var content []byte
+	for _, ch := range string(us) {
+		content = append(content, byte(ch>>24), byte(ch>>16), byte(ch>>8), byte(ch))
+	}
+
+	n = derWritePrimitive(der, tagUniversalString, content)
+
+	return
+}
+
+/*
+derReadUniversalString returns an error following an attempt to read a
+DER-encoded UniversalString into x.
+*/
+func derReadUniversalString(x *UniversalString, der *DERPacket, tal TagAndLength) (err error) {
+	var content []byte
+	if content, err = derReadPrimitive(der, tal, tagUniversalString); err != nil {
+		return
+	} else if len(content)%4 != 0 {
+		err = errorTxt("UniversalString content length must be a multiple of four (4)")
+		return
+	}
+
+	runes := make([]rune, len(content)/4)
+	for i := range runes {
+		c := content[i*4:]
+		runes[i] = rune(c[0])<<24 | rune(c[1])<<16 | rune(c[2])<<8 | rune(c[3])
+		if !runeOK(runes[i]) {
+			err = errorTxt("invalid UniversalString character at position " + itoa(i))
+			return
+		}
+	}
+
+	*x = UniversalString(runes)
+
+	return
+}